@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/mcp"
+)
+
+// authRealm is the realm announced in WWW-Authenticate challenges.
+const authRealm = "mcp-ripestat"
+
+// accessPolicy is the authentication policy built from the active
+// configuration.
+type accessPolicy struct {
+	// required protects the MCP and metrics endpoints; admin endpoints are
+	// always protected.
+	required bool
+
+	authenticator auth.Authenticator
+
+	// metadata is the OAuth protected resource metadata, published at
+	// metadataURL; nil when OAuth is disabled.
+	metadata    *auth.ProtectedResourceMetadata
+	metadataURL string
+}
+
+// newAccessPolicy builds the access policy for cfg.
+func newAccessPolicy(cfg *config.Config) (*accessPolicy, error) {
+	authenticator, err := cfg.Authenticator()
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &accessPolicy{
+		required:      cfg.Auth.Enabled || cfg.OAuth.Enabled,
+		authenticator: authenticator,
+		metadata:      cfg.ResourceMetadata(),
+	}
+	if policy.metadata != nil {
+		if policy.metadataURL, err = auth.ResourceMetadataURL(policy.metadata.Resource); err != nil {
+			return nil, fmt.Errorf("oauth.resource: %w", err)
+		}
+	}
+
+	return policy, nil
+}
+
+// challenge formats a WWW-Authenticate Bearer challenge with the given
+// parameters, pointing OAuth clients at the resource metadata.
+func (p *accessPolicy) challenge(params ...string) string {
+	parts := []string{fmt.Sprintf("realm=%q", authRealm)}
+	if p != nil && p.metadataURL != "" {
+		parts = append(parts, fmt.Sprintf("resource_metadata=%q", p.metadataURL))
+	}
+	for i := 0; i+1 < len(params); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", params[i], params[i+1]))
+	}
+	return "Bearer " + strings.Join(parts, ", ")
+}
+
+// access holds the active access policy; nil before run has applied a
+// configuration.
+var access atomic.Pointer[accessPolicy]
+
+// requireScope authenticates requests and rejects callers without scope.
+// The principal is stored in the request context. CORS preflight requests
+// carry no credentials and are passed through.
+func requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		policy := access.Load()
+		if r.Method == http.MethodOptions || (scope != auth.ScopeAdmin && (policy == nil || !policy.required)) {
+			next(w, r)
+			return
+		}
+
+		var authenticator auth.Authenticator = auth.Chain{}
+		if policy != nil {
+			authenticator = policy.authenticator
+		}
+
+		principal, err := authenticator.Authenticate(r)
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials):
+			w.Header().Set("WWW-Authenticate", policy.challenge())
+			writeJSONError(w, "unauthorized", http.StatusUnauthorized)
+			return
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			slog.WarnContext(r.Context(), "authentication failed", "path", r.URL.Path, "remote_addr", r.RemoteAddr, "err", err)
+			w.Header().Set("WWW-Authenticate", policy.challenge("error", "invalid_token"))
+			writeJSONError(w, "unauthorized", http.StatusUnauthorized)
+			return
+		case err != nil:
+			slog.ErrorContext(r.Context(), "authentication unavailable", "path", r.URL.Path, "err", err)
+			writeJSONError(w, "authentication unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			slog.WarnContext(r.Context(), "insufficient scope", "path", r.URL.Path, "subject", principal.Subject, "required", scope)
+			w.Header().Set("WWW-Authenticate", policy.challenge("error", "insufficient_scope", "scope", scope))
+			writeJSONError(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		slog.DebugContext(r.Context(), "authenticated request", "path", r.URL.Path, "subject", principal.Subject, "method", principal.Method)
+		next(w, r.WithContext(mcp.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+// protectedResourceHandler serves the OAuth protected resource metadata, or
+// 404 when OAuth is disabled.
+func protectedResourceHandler(w http.ResponseWriter, r *http.Request) {
+	policy := access.Load()
+	if policy == nil || policy.metadata == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSONError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	writeJSON(w, policy.metadata, http.StatusOK)
+}
@@ -0,0 +1,599 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/mcp"
+)
+
+// testIssuer signs Ed25519 JWTs and writes its public key as a JWKS file.
+type testIssuer struct {
+	key      ed25519.PrivateKey
+	jwks     []byte
+	jwksPath string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	jwks, _ := json.Marshal(map[string][]auth.JWK{"keys": {{
+		Kty: "OKP", Crv: "Ed25519", Kid: "test", Alg: "EdDSA",
+		X: base64.RawURLEncoding.EncodeToString(pub),
+	}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+
+	return &testIssuer{key: key, jwks: jwks, jwksPath: path}
+}
+
+// testAudience is the audience of tokens issued by testIssuer.token.
+const testAudience = "https://mcp.example.com/mcp"
+
+func (i *testIssuer) token(subject, scope string) string {
+	return i.sign(map[string]interface{}{
+		"sub":   subject,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scope,
+	})
+}
+
+func (i *testIssuer) sign(payload map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "EdDSA", "kid": "test", "typ": "JWT"})
+	claims, _ := json.Marshal(payload)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(i.key, []byte(signed)))
+}
+
+// newAuthTestServer serves the protected endpoints with handlers that report
+// the authenticated principal.
+func newAuthTestServer(t *testing.T, cfg *config.Config) *httptest.Server {
+	t.Helper()
+	restoreSettings(t)
+
+	if err := applyConfig(mcp.NewServer("test-server", version, false), cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	whoami := func(w http.ResponseWriter, r *http.Request) {
+		subject := "anonymous"
+		if principal, ok := mcp.PrincipalFromContext(r.Context()); ok {
+			subject = principal.Subject
+		}
+		writeJSON(w, map[string]string{"subject": subject}, http.StatusOK)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", requireScope(auth.ScopeMCP, whoami))
+	mux.HandleFunc("/metrics", requireScope(auth.ScopeMetrics, whoami))
+	mux.HandleFunc("/admin/tools", requireScope(auth.ScopeAdmin, whoami))
+	mux.HandleFunc(auth.ProtectedResourceMetadataPath+"/", protectedResourceHandler)
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+type authResult struct {
+	status    int
+	subject   string
+	challenge string
+}
+
+func authGet(t *testing.T, url string, header ...string) authResult {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]string
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	return authResult{status: resp.StatusCode, subject: body["subject"], challenge: resp.Header.Get("WWW-Authenticate")}
+}
+
+func TestRequireScope_Disabled(t *testing.T) {
+	cfg := testConfig("0")
+	cfg.Server.AdminToken = "admin-secret"
+	ts := newAuthTestServer(t, cfg)
+
+	if got := authGet(t, ts.URL+"/mcp"); got.status != http.StatusOK || got.subject != "anonymous" {
+		t.Errorf("Expected anonymous MCP access, got %+v", got)
+	}
+	if got := authGet(t, ts.URL+"/metrics"); got.status != http.StatusOK {
+		t.Errorf("Expected open metrics, got %+v", got)
+	}
+	if got := authGet(t, ts.URL+"/admin/tools"); got.status != http.StatusUnauthorized {
+		t.Errorf("Expected admin endpoints to stay protected, got %+v", got)
+	}
+	if got := authGet(t, ts.URL+"/admin/tools", "Authorization", "Bearer admin-secret"); got.status != http.StatusOK || got.subject != "admin" {
+		t.Errorf("Expected admin token to grant access, got %+v", got)
+	}
+}
+
+func TestRequireScope_APIKeys(t *testing.T) {
+	cfg := testConfig("0")
+	cfg.Auth.Enabled = true
+	cfg.Auth.APIKeys = map[string]config.APIKeyConfig{
+		"agent":      {Key: "agent-key"},
+		"prometheus": {Key: "scrape-key", Scopes: []string{auth.ScopeMetrics}},
+	}
+	ts := newAuthTestServer(t, cfg)
+
+	got := authGet(t, ts.URL+"/mcp")
+	if got.status != http.StatusUnauthorized || !strings.HasPrefix(got.challenge, "Bearer realm=") {
+		t.Errorf("Expected 401 challenge without credentials, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/mcp", auth.APIKeyHeader, "wrong")
+	if got.status != http.StatusUnauthorized || !strings.Contains(got.challenge, `error="invalid_token"`) {
+		t.Errorf("Expected invalid_token for an unknown key, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/mcp", auth.APIKeyHeader, "agent-key")
+	if got.status != http.StatusOK || got.subject != "agent" {
+		t.Errorf("Expected agent principal, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/metrics", auth.APIKeyHeader, "agent-key")
+	if got.status != http.StatusForbidden || !strings.Contains(got.challenge, `scope="metrics"`) {
+		t.Errorf("Expected insufficient_scope for metrics, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/metrics", "Authorization", "Bearer scrape-key")
+	if got.status != http.StatusOK || got.subject != "prometheus" {
+		t.Errorf("Expected prometheus principal, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/mcp", "Authorization", "Bearer scrape-key")
+	if got.status != http.StatusForbidden {
+		t.Errorf("Expected metrics key to be refused on /mcp, got %+v", got)
+	}
+}
+
+func TestRequireScope_JWT(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	cfg := testConfig("0")
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWKSFile = issuer.jwksPath
+	cfg.Auth.JWTAudience = testAudience
+	ts := newAuthTestServer(t, cfg)
+
+	got := authGet(t, ts.URL+"/mcp", "Authorization", "Bearer "+issuer.token("alice", "mcp"))
+	if got.status != http.StatusOK || got.subject != "alice" {
+		t.Errorf("Expected alice principal, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/admin/tools", "Authorization", "Bearer "+issuer.token("alice", "mcp"))
+	if got.status != http.StatusForbidden {
+		t.Errorf("Expected MCP token to be refused on admin endpoints, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/admin/tools", "Authorization", "Bearer "+issuer.token("ops", "admin"))
+	if got.status != http.StatusOK || got.subject != "ops" {
+		t.Errorf("Expected admin token principal, got %+v", got)
+	}
+
+	forged := newTestIssuer(t).token("alice", "mcp")
+	got = authGet(t, ts.URL+"/mcp", "Authorization", "Bearer "+forged)
+	if got.status != http.StatusUnauthorized || !strings.Contains(got.challenge, `error="invalid_token"`) {
+		t.Errorf("Expected token from another key to be rejected, got %+v", got)
+	}
+}
+
+func TestRequireScope_JWTAudienceWithOAuth(t *testing.T) {
+	issuer := newTestIssuer(t)
+	stub := newIssuerStub(t, issuer)
+
+	// Both verifiers trust the issuer's key.
+	cfg := testConfig("0")
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWKSFile = issuer.jwksPath
+	cfg.Auth.JWTAudience = testAudience
+	cfg.OAuth.Enabled = true
+	cfg.OAuth.Resource = testAudience
+	cfg.OAuth.AuthorizationServers = []string{stub.URL}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	ts := newAuthTestServer(t, cfg)
+
+	token := func(audience string) string {
+		return "Bearer " + issuer.sign(map[string]interface{}{
+			"sub":   "alice",
+			"iss":   stub.URL,
+			"aud":   audience,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "mcp",
+		})
+	}
+
+	got := authGet(t, ts.URL+"/mcp", "Authorization", token(testAudience))
+	if got.status != http.StatusOK || got.subject != "alice" {
+		t.Errorf("Expected alice principal, got %+v", got)
+	}
+
+	got = authGet(t, ts.URL+"/mcp", "Authorization", token("https://other.example.com/mcp"))
+	if got.status != http.StatusUnauthorized || !strings.Contains(got.challenge, `error="invalid_token"`) {
+		t.Errorf("Expected a token for another audience to be rejected by every verifier, got %+v", got)
+	}
+
+	cfg.Auth.JWTAudience = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "auth.jwt_audience") {
+		t.Errorf("Expected jwks_file without an audience to be rejected, got %v", err)
+	}
+}
+
+func TestRequireScope_PreflightPassesThrough(t *testing.T) {
+	cfg := testConfig("0")
+	cfg.Auth.Enabled = true
+	cfg.Auth.APIKeys = map[string]config.APIKeyConfig{"agent": {Key: "agent-key"}}
+	ts := newAuthTestServer(t, cfg)
+
+	req, _ := http.NewRequest(http.MethodOptions, ts.URL+"/mcp", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected preflight to skip authentication, got %d", resp.StatusCode)
+	}
+}
+
+func TestApplyConfig_InvalidJWKS(t *testing.T) {
+	restoreSettings(t)
+
+	cfg := testConfig("0")
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWKSFile = filepath.Join(t.TempDir(), "missing.json")
+
+	err := applyConfig(mcp.NewServer("test-server", version, false), cfg)
+	if err == nil || !strings.Contains(err.Error(), "auth.jwks_file") {
+		t.Errorf("Expected JWKS error, got %v", err)
+	}
+}
+
+// newIssuerStub serves authorization server metadata and the issuer's JWKS.
+func newIssuerStub(t *testing.T, issuer *testIssuer) *httptest.Server {
+	t.Helper()
+
+	var ts *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]string{"issuer": ts.URL, "jwks_uri": ts.URL + "/jwks"}, http.StatusOK)
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(issuer.jwks)
+	})
+
+	ts = httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestOAuthResourceServer(t *testing.T) {
+	issuer := newTestIssuer(t)
+	stub := newIssuerStub(t, issuer)
+
+	cfg := testConfig("0")
+	cfg.OAuth.Enabled = true
+	cfg.OAuth.Resource = "https://mcp.example.com/mcp"
+	cfg.OAuth.AuthorizationServers = []string{stub.URL}
+	ts := newAuthTestServer(t, cfg)
+
+	claims := func(modify func(map[string]interface{})) string {
+		c := map[string]interface{}{
+			"sub":   "alice",
+			"iss":   stub.URL,
+			"aud":   "https://mcp.example.com/mcp",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "mcp",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return "Bearer " + issuer.sign(c)
+	}
+
+	t.Run("metadata", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/.well-known/oauth-protected-resource/mcp")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var metadata auth.ProtectedResourceMetadata
+		if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+			t.Fatalf("Failed to decode metadata: %v", err)
+		}
+		if metadata.Resource != "https://mcp.example.com/mcp" {
+			t.Errorf("Expected resource, got %q", metadata.Resource)
+		}
+		if len(metadata.AuthorizationServers) != 1 || metadata.AuthorizationServers[0] != stub.URL {
+			t.Errorf("Expected issuer stub as authorization server, got %v", metadata.AuthorizationServers)
+		}
+	})
+
+	t.Run("challenge points at metadata", func(t *testing.T) {
+		got := authGet(t, ts.URL+"/mcp")
+		want := `resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource/mcp"`
+		if got.status != http.StatusUnauthorized || !strings.Contains(got.challenge, want) {
+			t.Errorf("Expected challenge with %s, got %+v", want, got)
+		}
+	})
+
+	t.Run("accepts audience-bound token", func(t *testing.T) {
+		got := authGet(t, ts.URL+"/mcp", "Authorization", claims(nil))
+		if got.status != http.StatusOK || got.subject != "alice" {
+			t.Errorf("Expected alice principal, got %+v", got)
+		}
+	})
+
+	t.Run("rejects token for another resource", func(t *testing.T) {
+		token := claims(func(c map[string]interface{}) { c["aud"] = "https://other.example.com/mcp" })
+		got := authGet(t, ts.URL+"/mcp", "Authorization", token)
+		if got.status != http.StatusUnauthorized || !strings.Contains(got.challenge, `error="invalid_token"`) {
+			t.Errorf("Expected invalid_token, got %+v", got)
+		}
+	})
+
+	t.Run("rejects token without audience", func(t *testing.T) {
+		got := authGet(t, ts.URL+"/mcp", "Authorization", claims(func(c map[string]interface{}) { delete(c, "aud") }))
+		if got.status != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %+v", got)
+		}
+	})
+
+	t.Run("rejects token from another issuer", func(t *testing.T) {
+		token := claims(func(c map[string]interface{}) { c["iss"] = "https://issuer.example.com" })
+		got := authGet(t, ts.URL+"/mcp", "Authorization", token)
+		if got.status != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %+v", got)
+		}
+	})
+}
+
+func TestProtectedResourceHandler_Disabled(t *testing.T) {
+	ts := newAuthTestServer(t, testConfig("0"))
+
+	resp, err := http.Get(ts.URL + "/.well-known/oauth-protected-resource/mcp")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 without OAuth, got %d", resp.StatusCode)
+	}
+}
+
+func TestToolCallQuotas_Sessions(t *testing.T) {
+	restoreSettings(t)
+
+	cfg := testConfig("0")
+	cfg.Quotas.Enabled = true
+	cfg.Quotas.DailyQuota = 1
+
+	server := mcp.NewServer("test-server", version, false)
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mcpHandler(w, r, server)
+	}))
+	t.Cleanup(ts.Close)
+
+	// post sends a request from the same client with the given session ID,
+	// returning the session ID the server answered with and the JSON-RPC error.
+	post := func(sessionID string, request *mcp.Request) (string, *mcp.Error) {
+		t.Helper()
+		body, err := json.Marshal(request)
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+		req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", "http://localhost:3000")
+		if sessionID != "" {
+			req.Header.Set("MCP-Session-ID", sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var response mcp.Response
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.Header.Get("MCP-Session-ID"), response.Error
+	}
+	// getWhois without arguments fails validation without calling RIPEstat.
+	call := mcp.NewRequest("tools/call", map[string]interface{}{"name": "getWhois", "arguments": map[string]interface{}{}}, 2)
+
+	sessionID, _ := post("", mcp.NewRequest("initialize", map[string]interface{}{"protocolVersion": "2025-06-18"}, 1))
+	if sessionID == "" {
+		t.Fatal("Expected initialize to issue a session ID")
+	}
+	if _, rpcErr := post(sessionID, call); rpcErr != nil {
+		t.Fatalf("Expected the first call of the session to be allowed, got %+v", rpcErr)
+	}
+	if _, rpcErr := post(sessionID, call); rpcErr == nil || rpcErr.Code != mcp.RateLimitError {
+		t.Errorf("Expected the session quota to be exhausted, got %+v", rpcErr)
+	}
+
+	// Without an issued session, dropping or rotating the session ID does not
+	// escape the client address's quota.
+	if _, rpcErr := post("", call); rpcErr != nil {
+		t.Fatalf("Expected the first call from the client address to be allowed, got %+v", rpcErr)
+	}
+	for _, sessionID := range []string{"", "rotated-1", "rotated-2"} {
+		if _, rpcErr := post(sessionID, call); rpcErr == nil || rpcErr.Code != mcp.RateLimitError {
+			t.Errorf("Expected session %q to share the exhausted client address quota, got %+v", sessionID, rpcErr)
+		}
+	}
+}
+
+func TestToolCallQuotas_PerPrincipal(t *testing.T) {
+	restoreSettings(t)
+
+	cfg := testConfig("0")
+	cfg.Auth.Enabled = true
+	cfg.Auth.APIKeys = map[string]config.APIKeyConfig{
+		"agent": {Key: "agent-key", Scopes: []string{auth.ScopeMCP, auth.ScopeMetrics}},
+	}
+	cfg.Quotas.Enabled = true
+	cfg.Quotas.DailyQuota = 1
+
+	server := mcp.NewServer("test-server", version, false)
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", requireScope(auth.ScopeMCP, func(w http.ResponseWriter, r *http.Request) {
+		mcpHandler(w, r, server)
+	}))
+	mux.HandleFunc("/metrics", requireScope(auth.ScopeMetrics, func(w http.ResponseWriter, r *http.Request) {
+		metricsHandler(w, r, server)
+	}))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	// getWhois without arguments fails validation without calling RIPEstat.
+	call := func(id int) mcp.Request {
+		return *mcp.NewRequest("tools/call", map[string]interface{}{"name": "getWhois", "arguments": map[string]interface{}{}}, id)
+	}
+	body, err := json.Marshal([]interface{}{
+		mcp.NewRequest("initialize", map[string]interface{}{"protocolVersion": "2025-06-18"}, 1),
+		call(2),
+		call(3),
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal batch: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.APIKeyHeader, "agent-key")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var responses []mcp.Response
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		t.Fatalf("Failed to decode batch response: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses, got %d", len(responses))
+	}
+	// Batch members run concurrently, so either call may be the one limited.
+	var limited *mcp.Error
+	for _, response := range responses[1:] {
+		if response.Error != nil {
+			if limited != nil {
+				t.Fatalf("Expected one tool call to be allowed, got %+v", response.Error)
+			}
+			limited = response.Error
+		}
+	}
+	if limited == nil || limited.Code != mcp.RateLimitError || !strings.Contains(limited.Message, "principal:agent") {
+		t.Fatalf("Expected rate limit error for the principal, got %+v", limited)
+	}
+	if data, ok := limited.Data.(map[string]interface{}); !ok || data["limit"] != "daily_quota" || data["reset_at"] == "" {
+		t.Errorf("Expected limit details in error data, got %+v", limited.Data)
+	}
+
+	metricsReq, err := http.NewRequest(http.MethodGet, ts.URL+"/metrics", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	metricsReq.Header.Set(auth.APIKeyHeader, "agent-key")
+	metricsResp, err := http.DefaultClient.Do(metricsReq)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer metricsResp.Body.Close()
+
+	var summary struct {
+		Quotas []struct {
+			Caller        string `json:"caller"`
+			CallsToday    int    `json:"calls_today"`
+			QuotaExceeded int    `json:"quota_exceeded"`
+		} `json:"quotas"`
+	}
+	if err := json.NewDecoder(metricsResp.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode metrics: %v", err)
+	}
+	if len(summary.Quotas) != 1 || summary.Quotas[0].Caller != "principal:agent" ||
+		summary.Quotas[0].CallsToday != 1 || summary.Quotas[0].QuotaExceeded != 1 {
+		t.Errorf("Unexpected quota usage %+v", summary.Quotas)
+	}
+
+	promReq, err := http.NewRequest(http.MethodGet, ts.URL+"/metrics?format=prometheus", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	promReq.Header.Set(auth.APIKeyHeader, "agent-key")
+	promResp, err := http.DefaultClient.Do(promReq)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer promResp.Body.Close()
+
+	exposition, err := io.ReadAll(promResp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read metrics: %v", err)
+	}
+	for _, want := range []string{
+		`mcp_quota_used{caller="agent",kind="principal"} 1`,
+		`mcp_quota_limit{caller="agent",kind="principal"} 1`,
+	} {
+		if !strings.Contains(string(exposition), want) {
+			t.Errorf("Expected %q in the Prometheus metrics", want)
+		}
+	}
+}
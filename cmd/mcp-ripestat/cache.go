@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/resource"
+	"github.com/taihen/mcp-ripestat/internal/warming"
+)
+
+// cacheWarmer refreshes the watchlist in the shared cache; applyConfig sets
+// its targets and run starts it.
+var cacheWarmer = warming.New(cache.Shared().TTL)
+
+// cacheStatus is the body returned by the /admin/cache endpoint.
+type cacheStatus struct {
+	Enabled     bool                       `json:"enabled"`
+	DefaultTTL  config.Duration            `json:"default_ttl"`
+	NegativeTTL config.Duration            `json:"negative_ttl"`
+	TTLs        map[string]config.Duration `json:"ttls"`
+	Total       cache.Stats                `json:"total"`
+	Endpoints   map[string]cache.Stats     `json:"endpoints"`
+	Warming     []warming.Status           `json:"warming,omitempty"`
+}
+
+// cacheTTLUpdate is the body accepted by PUT /admin/cache.
+type cacheTTLUpdate struct {
+	TTLs map[string]config.Duration `json:"ttls"`
+}
+
+// cachePurgeResult is the body returned by DELETE /admin/cache.
+type cachePurgeResult struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Purged   int    `json:"purged"`
+}
+
+// adminCacheHandler shows cache statistics and TTLs (GET), changes TTLs
+// (PUT) or purges entries by endpoint and resource (DELETE). TTL changes last
+// until the configuration is reloaded.
+func adminCacheHandler(w http.ResponseWriter, r *http.Request, c *cache.Cache) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var update cacheTTLUpdate
+		decoder := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&update); err != nil {
+			writeJSONError(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+			return
+		}
+		for endpoint, ttl := range update.TTLs {
+			if endpoint == "" || ttl <= 0 {
+				writeJSONError(w, fmt.Sprintf("invalid TTL %q for endpoint %q: must be positive", time.Duration(ttl), endpoint), http.StatusBadRequest)
+				return
+			}
+		}
+		for endpoint, ttl := range update.TTLs {
+			c.SetTTL(endpoint, ttl.Std())
+			slog.InfoContext(r.Context(), "cache TTL updated", "endpoint", endpoint, "ttl", ttl.Std())
+		}
+	case http.MethodDelete:
+		query := r.URL.Query()
+		raw := strings.TrimSpace(query.Get("resource"))
+		result := cachePurgeResult{Endpoint: query.Get("endpoint"), Resource: canonicalResource(raw)}
+		result.Purged = c.Purge(result.Endpoint, result.Resource)
+		// Free-text resources, such as searchcomplete queries, are cached as
+		// given rather than in canonical form.
+		if !strings.EqualFold(raw, result.Resource) {
+			result.Purged += c.Purge(result.Endpoint, raw)
+		}
+		slog.InfoContext(r.Context(), "cache purged", "endpoint", result.Endpoint, "resource", result.Resource, "purged", result.Purged)
+		writeJSON(w, result, http.StatusOK)
+		return
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeJSONError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ttls, fallback := c.TTLs()
+	status := cacheStatus{
+		Enabled:     currentConfig().Cache.Enabled,
+		DefaultTTL:  config.Duration(fallback),
+		NegativeTTL: config.Duration(c.NegativeTTL()),
+		TTLs:        make(map[string]config.Duration, len(ttls)),
+		Total:       c.Stats(),
+		Endpoints:   c.StatsByEndpoint(),
+		Warming:     cacheWarmer.Status(),
+	}
+	for endpoint, ttl := range ttls {
+		status.TTLs[endpoint] = config.Duration(ttl)
+	}
+	writeJSON(w, status, http.StatusOK)
+}
+
+// canonicalResource returns the form tools cache a resource under, such as
+// "AS3333" for "as 3333" or "193.0.0.0/21" for "193.0.0.1/21". Input that is
+// not a resource is returned unchanged.
+func canonicalResource(value string) string {
+	if value == "" {
+		return ""
+	}
+	r, err := resource.Parse(value)
+	if err != nil {
+		return value
+	}
+	return r.Value
+}
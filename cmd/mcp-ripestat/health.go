@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/health"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/whatsmyip"
+)
+
+// upstreamProbe checks that RIPEstat answers, using the whats-my-ip data
+// call as it is the cheapest one. Its timing follows the health settings.
+var upstreamProbe = health.NewProbe("upstream", probeRIPEstat, 30*time.Second, 5*time.Second)
+
+// probeRIPEstat calls RIPEstat, bypassing the response cache so that a cached
+// answer cannot report the upstream as reachable.
+func probeRIPEstat(ctx context.Context) error {
+	_, err := whatsmyip.GetWhatsMyIP(client.WithCacheBypass(ctx))
+	return err
+}
+
+// healthzHandler reports liveness. It succeeds whenever the process serves
+// HTTP; dependencies are checked by /readyz.
+func healthzHandler(w http.ResponseWriter, _ *http.Request, startTime time.Time) {
+	writeJSON(w, map[string]interface{}{
+		"status": health.StatusOK,
+		"uptime": time.Since(startTime).Round(time.Second).String(),
+	}, http.StatusOK)
+}
+
+// readyzHandler reports readiness with the status of each dependency. It
+// answers 503 when a check fails so that load balancers stop routing to the
+// instance.
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	report := health.Run(r.Context(), readinessChecks(currentConfig().Health)...)
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, report, status)
+}
+
+// readinessChecks returns the checks run by /readyz.
+func readinessChecks(cfg config.HealthConfig) []health.Checker {
+	checks := []health.Checker{
+		configCheck,
+		func(context.Context) health.Check {
+			outcomes := client.RecentOutcomes(cfg.ErrorWindow.Std())
+			check := health.ErrorRate("upstream_errors", outcomes.Requests, outcomes.Failures, cfg.MaxErrorRate, cfg.MinRequests)
+			check.Details["throttled"] = outcomes.Throttled
+			check.Details["window"] = cfg.ErrorWindow.Std().String()
+			return check
+		},
+		func(context.Context) health.Check {
+			inUse, capacity := client.LimiterUsage()
+			return health.Saturation("limiter", inUse, capacity)
+		},
+	}
+	if cfg.Probe {
+		checks = append(checks, upstreamProbe.Check)
+	}
+	return checks
+}
+
+// configCheck fails when the active configuration is invalid and reports a
+// degraded status when the last reload was rejected, since the instance then
+// runs with an older configuration than the one on disk.
+func configCheck(context.Context) health.Check {
+	check := health.Check{Name: "config", Status: health.StatusOK}
+	if err := currentConfig().Validate(); err != nil {
+		check.Status = health.StatusFail
+		check.Message = err.Error()
+		return check
+	}
+	if failure := reloadFailure.Load(); failure != nil {
+		check.Status = health.StatusDegraded
+		check.Message = "last reload failed, running with the previous configuration: " + *failure
+	}
+	return check
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/listener"
+)
+
+// newCertReloader loads the TLS certificate when the TLS listener is
+// enabled; it returns nil otherwise.
+func newCertReloader(cfg *config.Config) (*listener.CertReloader, error) {
+	if !slices.Contains(cfg.Server.Listeners, config.ListenerTLS) {
+		return nil, nil
+	}
+
+	clientCAFile := ""
+	if cfg.TLS.ClientAuth != config.ClientAuthNone {
+		clientCAFile = cfg.TLS.ClientCAFile
+	}
+	certs, err := listener.NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: %w", err)
+	}
+	return certs, nil
+}
+
+// serve opens the named listener and serves HTTP on it until the server is
+// shut down. Failures are logged; the other listeners keep serving.
+func serve(server *http.Server, name string, cfg *config.Config, certs *listener.CertReloader) {
+	var (
+		l   net.Listener
+		err error
+	)
+	switch name {
+	case config.ListenerTCP:
+		l, err = net.Listen("tcp", ":"+cfg.Server.Port)
+	case config.ListenerTLS:
+		l, err = net.Listen("tcp", ":"+cfg.TLS.Port)
+		if err == nil {
+			l = tls.NewListener(l, certs.TLSConfig(cfg.TLS.ClientAuthType()))
+		}
+	case config.ListenerUnix:
+		mode, modeErr := cfg.Unix.FileMode()
+		if modeErr != nil {
+			err = modeErr
+			break
+		}
+		l, err = listener.Unix(cfg.Unix.Path, mode)
+	default:
+		err = fmt.Errorf("unknown listener %q", name)
+	}
+	if err != nil {
+		slog.Error("server failed to start", "listener", name, "err", err)
+		return
+	}
+
+	slog.Info("MCP RIPEstat server starting", "listener", name, "addr", l.Addr().String())
+	if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("server failed", "listener", name, "err", err)
+	}
+}
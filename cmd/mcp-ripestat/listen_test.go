@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/config"
+)
+
+// testPKI is a CA with a server certificate for localhost and a client
+// certificate.
+type testPKI struct {
+	caFile, certFile, keyFile string
+	roots                     *x509.CertPool
+	client                    tls.Certificate
+}
+
+func newTestPKI(t *testing.T, clientName string) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	newKey := func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("GenerateKey failed: %v", err)
+		}
+		return key
+	}
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	caKey := newKey()
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, commonName string, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key := newKey()
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: commonName},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("CreateCertificate failed: %v", err)
+		}
+		return der, key
+	}
+
+	pki := &testPKI{roots: x509.NewCertPool()}
+	pki.roots.AddCert(ca)
+	pki.caFile = writePEM("ca.pem", "CERTIFICATE", caDER)
+
+	serverDER, serverKey := issue(2, "localhost", x509.ExtKeyUsageServerAuth)
+	serverKeyDER, _ := x509.MarshalPKCS8PrivateKey(serverKey)
+	pki.certFile = writePEM("server.pem", "CERTIFICATE", serverDER)
+	pki.keyFile = writePEM("server-key.pem", "PRIVATE KEY", serverKeyDER)
+
+	clientDER, clientKey := issue(3, clientName, x509.ExtKeyUsageClientAuth)
+	pki.client = tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+	return pki
+}
+
+// freePort returns a TCP port that was free a moment ago.
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+func TestRun_Listeners(t *testing.T) {
+	restoreSettings(t)
+
+	pki := newTestPKI(t, "ops")
+	socket := filepath.Join(t.TempDir(), "mcp.sock")
+
+	cfg := testConfig("0")
+	cfg.Server.Listeners = []string{config.ListenerTLS, config.ListenerUnix}
+	cfg.Auth.Enabled = true
+	cfg.TLS.Port = freePort(t)
+	cfg.TLS.CertFile = pki.certFile
+	cfg.TLS.KeyFile = pki.keyFile
+	cfg.TLS.ClientCAFile = pki.caFile
+	cfg.TLS.ClientAuth = config.ClientAuthOptional
+	cfg.TLS.Clients = map[string]config.ClientCertConfig{"ops": {Scopes: []string{"metrics"}}}
+	cfg.Unix.Path = socket
+	cfg.Unix.Mode = "0600"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- run(ctx, options{Config: cfg}) }()
+	defer func() {
+		cancel()
+		if err := <-errCh; err != nil {
+			t.Errorf("run failed: %v", err)
+		}
+		if _, err := os.Stat(socket); !os.IsNotExist(err) {
+			t.Errorf("Expected socket to be removed on shutdown, got %v", err)
+		}
+	}()
+
+	tlsClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      pki.roots,
+			Certificates: certs,
+			MinVersion:   tls.VersionTLS12,
+		}}}
+	}
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	tlsURL := "https://localhost:" + cfg.TLS.Port + "/status"
+
+	get := func(t *testing.T, client *http.Client, url string) int {
+		t.Helper()
+		var lastErr error
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		t.Fatalf("GET %s failed: %v", url, lastErr)
+		return 0
+	}
+
+	if code := get(t, tlsClient(pki.client), tlsURL); code != http.StatusOK {
+		t.Errorf("Expected client certificate to grant the metrics scope, got %d", code)
+	}
+	if code := get(t, tlsClient(), tlsURL); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a client certificate, got %d", code)
+	}
+	if code := get(t, unixClient, "http://mcp/status"); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 on the Unix socket without credentials, got %d", code)
+	}
+	if code := get(t, unixClient, "http://mcp/healthz"); code != http.StatusOK {
+		t.Errorf("Expected /healthz on the Unix socket, got %d", code)
+	}
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected socket mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestRun_InvalidCertificate(t *testing.T) {
+	restoreSettings(t)
+
+	cfg := testConfig("0")
+	cfg.Server.Listeners = []string{config.ListenerTLS}
+	cfg.TLS.CertFile = filepath.Join(t.TempDir(), "missing.pem")
+	cfg.TLS.KeyFile = cfg.TLS.CertFile
+
+	if err := run(context.Background(), options{Config: cfg}); err == nil {
+		t.Error("Expected run to fail without a certificate")
+	}
+}
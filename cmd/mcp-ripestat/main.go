@@ -5,7 +5,6 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"expvar"
 	"flag"
 	"fmt"
@@ -18,18 +17,37 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/config"
 	"github.com/taihen/mcp-ripestat/internal/mcp"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/logging"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/metrics"
+	"github.com/taihen/mcp-ripestat/internal/tracing"
 )
 
 // version is set via -ldflags during build time.
 var version = "dev"
 
+// streamKeepaliveInterval is how often an idle notification stream sends a comment.
+var streamKeepaliveInterval = 30 * time.Second
+
 func main() {
 	port := flag.String("port", "8080", "Port for the server to listen on")
 	debug := flag.Bool("debug", false, "Enable debug logging")
 	showVersion := flag.Bool("version", false, "Show version information")
 	help := flag.Bool("help", false, "Print all possible flags")
+	configPath := flag.String("config", os.Getenv("MCP_RIPESTAT_CONFIG"), "Path to a TOML configuration file")
+	toolsAllow := flag.String("tools-allow", "", "Comma-separated tools or categories to expose (default: all)")
+	toolsDeny := flag.String("tools-deny", "", "Comma-separated tools or categories to hide")
+	adminToken := flag.String("admin-token", "", "Bearer token for the /admin endpoints (disabled when empty)")
+	listen := flag.String("listen", "", "Comma-separated listeners to open: tcp, tls, unix (default: tcp)")
+	tlsPort := flag.String("tls-port", "8443", "Port for the TLS listener")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file, reloaded when it changes")
+	tlsKey := flag.String("tls-key", "", "TLS private key file")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA bundle to verify TLS client certificates against")
+	tlsClientAuth := flag.String("tls-client-auth", "none", "TLS client certificates: none, optional or require")
+	unixSocket := flag.String("unix-socket", "", "Path of the Unix domain socket listener")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
@@ -49,84 +67,207 @@ func main() {
 		os.Exit(0)
 	}
 
-	logLevel := slog.LevelInfo
-	if *debug {
-		logLevel = slog.LevelDebug
-	}
-
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+	// Every package logs through this handler, including the RIPEstat client,
+	// so one format and one level apply to all log lines.
+	logger := slog.New(logging.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})))
 
 	slog.SetDefault(logger)
 
-	if err := run(context.Background(), *port); err != nil {
+	// Flags given on the command line take precedence over the configuration
+	// file and environment, also when the configuration is reloaded.
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	load := func() (*config.Config, error) {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if setFlags["port"] {
+			cfg.Server.Port = *port
+		}
+		if setFlags["debug"] {
+			cfg.Server.Debug = *debug
+		}
+		if setFlags["admin-token"] {
+			cfg.Server.AdminToken = *adminToken
+		}
+		if setFlags["tools-allow"] {
+			cfg.Tools.Allow = mcp.ParseToolList(*toolsAllow)
+		}
+		if setFlags["tools-deny"] {
+			cfg.Tools.Deny = mcp.ParseToolList(*toolsDeny)
+		}
+		if setFlags["listen"] {
+			cfg.Server.Listeners = strings.FieldsFunc(*listen, func(r rune) bool { return r == ',' || r == ' ' })
+		}
+		if setFlags["tls-port"] {
+			cfg.TLS.Port = *tlsPort
+		}
+		if setFlags["tls-cert"] {
+			cfg.TLS.CertFile = *tlsCert
+		}
+		if setFlags["tls-key"] {
+			cfg.TLS.KeyFile = *tlsKey
+		}
+		if setFlags["tls-client-ca"] {
+			cfg.TLS.ClientCAFile = *tlsClientCA
+		}
+		if setFlags["tls-client-auth"] {
+			cfg.TLS.ClientAuth = *tlsClientAuth
+		}
+		if setFlags["unix-socket"] {
+			cfg.Unix.Path = *unixSocket
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+		return cfg, nil
+	}
+
+	cfg, err := load()
+	if err != nil {
+		slog.Error("failed to load configuration", "err", err)
+		os.Exit(1)
+	}
+
+	if err := run(context.Background(), options{Config: cfg, Load: load}); err != nil {
 		slog.Error("server failed", "err", err)
 		os.Exit(1)
 	}
 }
 
-func run(ctx context.Context, port string) error {
+// options holds the settings for run.
+type options struct {
+	// Config is the initial configuration; nil uses the defaults.
+	Config *config.Config
+
+	// Load re-reads the configuration on SIGHUP; nil disables reloading.
+	Load func() (*config.Config, error)
+}
+
+func run(ctx context.Context, opts options) error {
 	startTime := time.Now()
 	mux := http.NewServeMux()
 
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
+	certs, err := newCertReloader(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Create MCP server
 	mcpServer := mcp.NewServer("mcp-ripestat", version, false)
+	if err := applyConfig(mcpServer, cfg); err != nil {
+		return err
+	}
 
 	// Add MCP JSON-RPC endpoint
-	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/mcp", requireScope(auth.ScopeMCP, func(w http.ResponseWriter, r *http.Request) {
 		mcpHandler(w, r, mcpServer)
-	})
+	}))
 
 	mux.HandleFunc("/.well-known/mcp/manifest.json", func(w http.ResponseWriter, r *http.Request) {
 		manifestHandler(w, r)
 	})
 
+	// OAuth protected resource metadata, also served below the resource path
+	mux.HandleFunc(auth.ProtectedResourceMetadataPath, protectedResourceHandler)
+	mux.HandleFunc(auth.ProtectedResourceMetadataPath+"/", protectedResourceHandler)
+
 	// Warmup endpoint to prevent cold starts
 	mux.HandleFunc("/warmup", warmupHandler)
 
+	// Liveness and readiness probes for orchestrators
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		healthzHandler(w, r, startTime)
+	})
+	mux.HandleFunc("/readyz", readyzHandler)
+
 	// Status endpoint for debugging cold starts
-	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/status", requireScope(auth.ScopeMetrics, func(w http.ResponseWriter, r *http.Request) {
 		statusHandler(w, r, startTime)
-	})
+	}))
 
 	// Metrics endpoint for operational monitoring
-	mux.Handle("/debug/vars", expvar.Handler())
-	mux.HandleFunc("/metrics", metricsHandler)
-
-	addr := ":" + port
+	mux.HandleFunc("/debug/vars", requireScope(auth.ScopeMetrics, expvar.Handler().ServeHTTP))
+	mux.HandleFunc("/metrics", requireScope(auth.ScopeMetrics, func(w http.ResponseWriter, r *http.Request) {
+		metricsHandler(w, r, mcpServer)
+	}))
+
+	// Admin endpoints require the admin scope, granted by the admin token
+	mux.HandleFunc("/admin/tools", requireScope(auth.ScopeAdmin, func(w http.ResponseWriter, r *http.Request) {
+		adminToolsHandler(w, r, mcpServer)
+	}))
+	mux.HandleFunc("/admin/cache", requireScope(auth.ScopeAdmin, func(w http.ResponseWriter, r *http.Request) {
+		adminCacheHandler(w, r, cache.Shared())
+	}))
 
 	server := &http.Server{
-		Addr:              addr,
-		Handler:           mux,
-		ReadHeaderTimeout: 10 * time.Second, // Prevent Slowloris attacks
+		Handler:           withRequestID(mux),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout.Std(), // Prevent Slowloris attacks
 	}
 
-	go func() {
-		slog.Info("MCP RIPEstat server starting", "addr", server.Addr)
-		err := server.ListenAndServe()
+	for _, name := range cfg.Server.Listeners {
+		go serve(server, name, cfg, certs)
+	}
 
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("server failed to start", "err", err)
-		}
-	}()
+	// Keep the warming watchlist fresh until shutdown
+	warmCtx, stopWarming := context.WithCancel(ctx)
+	defer stopWarming()
+	go cacheWarmer.Run(warmCtx, mcpServer.RefreshTool)
 
-	// Wait for shutdown signal
+	// Wait for shutdown signal, reloading the configuration on SIGHUP
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	select {
-	case <-quit:
-		slog.Info("shutting down server...")
-	case <-ctx.Done():
-		slog.Info("shutting down server due to context cancellation...")
+	defer signal.Stop(quit)
+
+	hup := make(chan os.Signal, 1)
+	if opts.Load != nil {
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+	}
+
+wait:
+	for {
+		select {
+		case <-hup:
+			cfg = reloadConfig(mcpServer, cfg, opts.Load)
+			if certs != nil {
+				if err := certs.Reload(); err != nil {
+					slog.Warn("failed to reload TLS certificate, keeping the previous one", "err", err)
+				}
+			}
+		case <-quit:
+			slog.Info("shutting down server...")
+			break wait
+		case <-ctx.Done():
+			slog.Info("shutting down server due to context cancellation...")
+			break wait
+		}
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Std())
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 
+	if auditLogger := mcpServer.SetAuditLogger(nil); auditLogger != nil {
+		if err := auditLogger.Close(); err != nil {
+			slog.Warn("failed to close audit log", "err", err)
+		}
+	}
+
+	shutdownTracer(tracing.SetTracer(nil))
+
 	slog.Info("server exited gracefully")
 
 	return nil
@@ -161,7 +302,7 @@ type Return struct {
 }
 
 func manifestHandler(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("received manifest request", "remote_addr", r.RemoteAddr)
+	slog.DebugContext(r.Context(), "received manifest request", "remote_addr", r.RemoteAddr)
 
 	var functions []Function
 
@@ -181,14 +322,14 @@ func mcpHandler(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
 		protocolVersion = "2025-06-18" // Default to latest
 	}
 
-	slog.Debug("received MCP request", "method", r.Method, "remote_addr", r.RemoteAddr, "origin", origin, "protocol_version", protocolVersion)
+	slog.DebugContext(r.Context(), "received MCP request", "method", r.Method, "remote_addr", r.RemoteAddr, "origin", origin, "protocol_version", protocolVersion)
 
 	// Determine client capabilities based on protocol version
 	supportsStreamableHTTP := isProtocolVersionAtLeast(protocolVersion, "2025-06-18")
 
 	// For older protocol versions (< 2025-06-18), use simplified handling
 	if !supportsStreamableHTTP {
-		slog.Debug("using simplified handling for older protocol version", "version", protocolVersion)
+		slog.DebugContext(r.Context(), "using simplified handling for older protocol version", "version", protocolVersion)
 		handleLegacyMCPClient(w, r, server)
 		return
 	}
@@ -207,39 +348,47 @@ func mcpHandler(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
 		// POST with Origin header indicates streamable HTTP client
 		if origin != "" {
 			isStreamableHTTP = true
-			slog.Debug("POST with origin detected as streamable HTTP", "origin", origin)
+			slog.DebugContext(r.Context(), "POST with origin detected as streamable HTTP", "origin", origin)
 		}
 	}
 
-	slog.Debug("request classification", "is_streamable", isStreamableHTTP, "method", r.Method, "has_origin", origin != "", "protocol_version", protocolVersion)
+	slog.DebugContext(r.Context(), "request classification", "is_streamable", isStreamableHTTP, "method", r.Method, "has_origin", origin != "", "protocol_version", protocolVersion)
 
 	if isStreamableHTTP {
-		slog.Debug("processing as streamable HTTP request")
+		slog.DebugContext(r.Context(), "processing as streamable HTTP request")
 		// Validate streamable HTTP requirements
 		if !validateStreamableHTTP(w, r) {
 			return
 		}
 
 		// Handle session management
-		sessionID := getOrCreateSession(r, w)
-		slog.Debug("session management", "session_id", sessionID)
+		sessionID, issued := getOrCreateSession(r, w)
+		slog.DebugContext(r.Context(), "session management", "session_id", sessionID, "issued", issued)
+		if issued {
+			r = r.WithContext(mcp.WithIssuedSession(r.Context()))
+		}
 
 		// Route based on HTTP method
 		switch r.Method {
 		case http.MethodPost:
-			slog.Debug("routing to handleMCPRequest for streamable POST")
+			slog.DebugContext(r.Context(), "routing to handleMCPRequest for streamable POST")
 			handleMCPRequest(w, r, server, sessionID)
 		case http.MethodGet:
-			slog.Debug("routing to handleMCPQuery for GET")
+			if acceptsEventStream(r) {
+				slog.DebugContext(r.Context(), "routing to handleMCPStream for GET")
+				handleMCPStream(w, r, server, sessionID)
+				return
+			}
+			slog.DebugContext(r.Context(), "routing to handleMCPQuery for GET")
 			handleMCPQuery(w, r, server, sessionID)
 		case http.MethodOptions:
-			slog.Debug("routing to handleCORS for OPTIONS")
+			slog.DebugContext(r.Context(), "routing to handleCORS for OPTIONS")
 			handleCORS(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	} else {
-		slog.Debug("processing as regular MCP client (POST-only)")
+		slog.DebugContext(r.Context(), "processing as regular MCP client (POST-only)")
 		// Handle regular MCP clients (POST without streamable support)
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -252,13 +401,8 @@ func mcpHandler(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
 // validateStreamableHTTP validates HTTP transport requirements.
 func validateStreamableHTTP(w http.ResponseWriter, r *http.Request) bool {
 	// Origin validation (required by MCP spec).
-	if origin := r.Header.Get("Origin"); origin != "" {
-		if !isValidOrigin(origin) {
-			slog.Warn("invalid origin rejected", "origin", origin)
-			http.Error(w, "Invalid origin", http.StatusForbidden)
-			return false
-		}
-		w.Header().Set("Access-Control-Allow-Origin", origin)
+	if !checkOrigin(w, r) {
+		return false
 	}
 
 	// Protocol version handling.
@@ -267,7 +411,7 @@ func validateStreamableHTTP(w http.ResponseWriter, r *http.Request) bool {
 		protocolVersion = "2025-06-18"
 	}
 	if !isSupportedProtocolVersion(protocolVersion) {
-		slog.Warn("unsupported protocol version", "version", protocolVersion)
+		slog.WarnContext(r.Context(), "unsupported protocol version", "version", protocolVersion)
 		http.Error(w, "Unsupported protocol version", http.StatusBadRequest)
 		return false
 	}
@@ -276,35 +420,35 @@ func validateStreamableHTTP(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
-// isValidOrigin validates the origin header.
-func isValidOrigin(origin string) bool {
-	// Allow common development origins.
-	allowedOrigins := []string{
-		"http://localhost",
-		"https://localhost",
-		"http://127.0.0.1",
-		"https://127.0.0.1",
-		"https://cursor.sh",
-		"https://claude.ai",
-	}
-
-	for _, allowed := range allowedOrigins {
-		if strings.HasPrefix(origin, allowed) {
-			return true
-		}
+// checkOrigin applies the origin policy. Requests without an Origin header
+// come from non-browser clients and are allowed; a disallowed origin is
+// rejected with 403 to prevent DNS rebinding. Allowed origins are echoed
+// back in Access-Control-Allow-Origin.
+func checkOrigin(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
 	}
 
-	return false
+	w.Header().Add("Vary", "Origin")
+	if !isValidOrigin(origin) {
+		slog.WarnContext(r.Context(), "invalid origin rejected", "origin", origin)
+		http.Error(w, "Invalid origin", http.StatusForbidden)
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+
+	return true
+}
+
+// isValidOrigin validates the origin header against the configured policy.
+func isValidOrigin(origin string) bool {
+	return currentOriginPolicy().Allows(origin)
 }
 
 // isSupportedProtocolVersion checks if protocol version is supported.
 func isSupportedProtocolVersion(version string) bool {
-	supportedVersions := []string{
-		"2025-06-18",
-		"2025-03-26", // Backward compatibility.
-	}
-
-	for _, supported := range supportedVersions {
+	for _, supported := range currentConfig().Transport.ProtocolVersions {
 		if version == supported {
 			return true
 		}
@@ -313,14 +457,16 @@ func isSupportedProtocolVersion(version string) bool {
 	return false
 }
 
-// getOrCreateSession manages session IDs.
-func getOrCreateSession(r *http.Request, w http.ResponseWriter) string {
+// getOrCreateSession manages session IDs. It reports whether the ID was
+// issued for this request rather than sent by the client.
+func getOrCreateSession(r *http.Request, w http.ResponseWriter) (string, bool) {
 	sessionID := r.Header.Get("MCP-Session-ID")
-	if sessionID == "" {
-		sessionID = generateSessionID()
-		w.Header().Set("MCP-Session-ID", sessionID)
+	if sessionID != "" {
+		return sessionID, false
 	}
-	return sessionID
+	sessionID = generateSessionID()
+	w.Header().Set("MCP-Session-ID", sessionID)
+	return sessionID, true
 }
 
 // generateSessionID creates a new session ID.
@@ -333,18 +479,55 @@ func generateSessionID() string {
 	return hex.EncodeToString(bytes)
 }
 
+// requestIDHeader carries the ID correlating a request with its log lines,
+// audit entry and tool errors.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs accepted from callers.
+const maxRequestIDLength = 128
+
+// withRequestID accepts the caller's X-Request-ID, or generates one, echoes
+// it in the response and stores it in the request context.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = generateSessionID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(mcp.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// validRequestID reports whether a caller-supplied request ID is safe to log
+// and echo: up to maxRequestIDLength letters, digits and "-_.:/+=".
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-_.:/+=", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // handleMCPRequest handles POST requests (standard JSON-RPC).
 func handleMCPRequest(w http.ResponseWriter, r *http.Request, server *mcp.Server, sessionID string) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		slog.Error("failed to read request body", "err", err)
+		slog.ErrorContext(r.Context(), "failed to read request body", "err", err)
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
 	// Extended timeout for cold start scenarios.
-	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), currentConfig().Server.RequestTimeout.Std())
 	defer cancel()
 
 	// Store HTTP request and session in context.
@@ -353,7 +536,7 @@ func handleMCPRequest(w http.ResponseWriter, r *http.Request, server *mcp.Server
 
 	response, err := server.ProcessMessage(ctx, body)
 	if err != nil {
-		slog.Error("failed to process MCP message", "err", err)
+		slog.ErrorContext(ctx, "failed to process MCP message", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -368,18 +551,18 @@ func handleMCPRequest(w http.ResponseWriter, r *http.Request, server *mcp.Server
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		slog.Error("failed to write MCP response", "err", err)
+		slog.ErrorContext(ctx, "failed to write MCP response", "err", err)
 	}
 }
 
 // handleMCPQuery handles GET requests (query parameters to JSON-RPC).
 func handleMCPQuery(w http.ResponseWriter, r *http.Request, server *mcp.Server, sessionID string) {
 	query := r.URL.Query()
-	slog.Debug("handling GET request", "query_params", query, "has_method", query.Get("method") != "")
+	slog.DebugContext(r.Context(), "handling GET request", "query_params", query, "has_method", query.Get("method") != "")
 
 	// Check if this is a valid MCP query request (has method parameter)
 	if query.Get("method") == "" {
-		slog.Debug("GET request to MCP endpoint without method parameter, returning endpoint info", "query", query, "user_agent", r.Header.Get("User-Agent"))
+		slog.DebugContext(r.Context(), "GET request to MCP endpoint without method parameter, returning endpoint info", "query", query, "user_agent", r.Header.Get("User-Agent"))
 		// Return basic endpoint information for health checks and discovery
 		// This helps MCP clients and tooling understand the endpoint capabilities
 		response := map[string]interface{}{
@@ -403,7 +586,7 @@ func handleMCPQuery(w http.ResponseWriter, r *http.Request, server *mcp.Server,
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(response); err != nil {
-			slog.Error("failed to write endpoint info response", "err", err)
+			slog.ErrorContext(r.Context(), "failed to write endpoint info response", "err", err)
 		}
 		return
 	}
@@ -411,13 +594,13 @@ func handleMCPQuery(w http.ResponseWriter, r *http.Request, server *mcp.Server,
 	// Convert query parameters to JSON-RPC request.
 	requestData, err := server.ParseQueryToRequest(query)
 	if err != nil {
-		slog.Error("failed to parse query parameters", "err", err)
+		slog.ErrorContext(r.Context(), "failed to parse query parameters", "err", err)
 		http.Error(w, fmt.Sprintf("Bad request: %v", err), http.StatusBadRequest)
 		return
 	}
 
 	// Extended timeout for cold start scenarios.
-	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), currentConfig().Server.RequestTimeout.Std())
 	defer cancel()
 
 	// Store HTTP request and session in context.
@@ -426,7 +609,7 @@ func handleMCPQuery(w http.ResponseWriter, r *http.Request, server *mcp.Server,
 
 	response, err := server.ProcessMessage(ctx, requestData)
 	if err != nil {
-		slog.Error("failed to process MCP query", "err", err)
+		slog.ErrorContext(ctx, "failed to process MCP query", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -441,19 +624,76 @@ func handleMCPQuery(w http.ResponseWriter, r *http.Request, server *mcp.Server,
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		slog.Error("failed to write MCP query response", "err", err)
+		slog.ErrorContext(ctx, "failed to write MCP query response", "err", err)
+	}
+}
+
+// acceptsEventStream reports whether the client asked for a server-sent event stream.
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// handleMCPStream opens a server-sent event stream carrying server-initiated
+// notifications, such as notifications/tools/list_changed, for a session.
+func handleMCPStream(w http.ResponseWriter, r *http.Request, server *mcp.Server, sessionID string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	notifications, unsubscribe := server.SubscribeNotifications(sessionID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	slog.DebugContext(r.Context(), "notification stream opened", "session_id", sessionID)
+
+	keepalive := time.NewTicker(streamKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			slog.DebugContext(r.Context(), "notification stream closed", "session_id", sessionID)
+			return
+		case <-keepalive.C:
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case notif, ok := <-notifications:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(notif)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "failed to encode notification", "err", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
 	}
 }
 
 // handleCORS handles CORS preflight requests.
 func handleCORS(w http.ResponseWriter, r *http.Request) {
 	origin := r.Header.Get("Origin")
-	if origin != "" && isValidOrigin(origin) {
-		w.Header().Set("Access-Control-Allow-Origin", origin)
+	if origin != "" {
+		w.Header().Set("Vary", "Origin")
+		if isValidOrigin(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
 	}
 
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, MCP-Protocol-Version, MCP-Session-ID")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, MCP-Protocol-Version, MCP-Session-ID, X-Request-ID, traceparent")
+	w.Header().Set("Access-Control-Expose-Headers", "MCP-Session-ID, X-Request-ID")
 	w.Header().Set("Access-Control-Max-Age", "86400")
 
 	w.WriteHeader(http.StatusOK)
@@ -508,13 +748,15 @@ func isProtocolVersionAtLeast(version, minVersion string) bool {
 
 // handleLegacyMCPClient handles requests from clients using protocol versions < 2025-06-18.
 func handleLegacyMCPClient(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
-	origin := r.Header.Get("Origin")
+	// Legacy clients get the same origin policy as streamable HTTP clients.
+	if !checkOrigin(w, r) {
+		return
+	}
 
-	// Simple CORS handling for legacy clients
-	if origin != "" && isValidOrigin(origin) {
-		w.Header().Set("Access-Control-Allow-Origin", origin)
+	if r.Header.Get("Origin") != "" {
 		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, MCP-Protocol-Version")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, MCP-Protocol-Version, X-Request-ID, traceparent")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 	}
 
 	// Handle OPTIONS (CORS preflight)
@@ -537,14 +779,14 @@ func handleLegacyMCPClient(w http.ResponseWriter, r *http.Request, server *mcp.S
 func handleSimpleMCPRequest(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		slog.Error("failed to read request body", "err", err)
+		slog.ErrorContext(r.Context(), "failed to read request body", "err", err)
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
 	// Extended timeout for cold start scenarios
-	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), currentConfig().Server.RequestTimeout.Std())
 	defer cancel()
 
 	// Store HTTP request in context (without session management)
@@ -552,7 +794,7 @@ func handleSimpleMCPRequest(w http.ResponseWriter, r *http.Request, server *mcp.
 
 	response, err := server.ProcessMessage(ctx, body)
 	if err != nil {
-		slog.Error("failed to process MCP message", "err", err)
+		slog.ErrorContext(ctx, "failed to process MCP message", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -567,16 +809,91 @@ func handleSimpleMCPRequest(w http.ResponseWriter, r *http.Request, server *mcp.
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		slog.Error("failed to write MCP response", "err", err)
+		slog.ErrorContext(ctx, "failed to write MCP response", "err", err)
 	}
 }
 
-func metricsHandler(w http.ResponseWriter, _ *http.Request) {
+// metricsHandler reports upstream metrics and per-caller tool call usage as
+// JSON, or in the Prometheus text format when the request asks for it.
+func metricsHandler(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
+	if wantsPrometheus(r) {
+		w.Header().Set("Content-Type", metrics.PrometheusContentType)
+		w.WriteHeader(http.StatusOK)
+		families := append(metrics.PrometheusFamilies(), server.QuotaFamilies()...)
+		if err := metrics.WritePrometheus(w, families); err != nil {
+			slog.ErrorContext(r.Context(), "failed to write metrics response", "err", err)
+		}
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
 	summary := metrics.Summary()
+	summary["quotas"] = server.QuotaUsage()
 	if err := json.NewEncoder(w).Encode(summary); err != nil {
-		slog.Error("failed to encode metrics response", "err", err)
+		slog.ErrorContext(r.Context(), "failed to encode metrics response", "err", err)
+	}
+}
+
+// wantsPrometheus reports whether a metrics request asks for the Prometheus
+// text format, either with ?format=prometheus or an Accept header preferring
+// text/plain or OpenMetrics. JSON remains the default.
+func wantsPrometheus(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "prometheus":
+		return true
+	case "json":
+		return false
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(strings.TrimSpace(accepted), ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "application/json", "*/*":
+			return false
+		case "text/plain", "application/openmetrics-text":
+			return true
+		}
+	}
+	return false
+}
+
+// toolsStatus is the body returned by the /admin/tools endpoint.
+type toolsStatus struct {
+	mcp.ToolFilter
+	Enabled    []string            `json:"enabled"`
+	Categories map[string][]string `json:"categories"`
+}
+
+// adminToolsHandler shows (GET) or replaces (PUT) the tool filter. Changes take
+// effect immediately and connected sessions are notified. They last until the
+// configuration is reloaded, which restores the filter of the configuration.
+func adminToolsHandler(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var filter mcp.ToolFilter
+		decoder := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&filter); err != nil {
+			writeJSONError(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := server.SetToolFilter(filter); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.InfoContext(r.Context(), "tool filter updated", "allow", filter.Allow, "deny", filter.Deny)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeJSONError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
+
+	writeJSON(w, toolsStatus{
+		ToolFilter: server.ToolFilter(),
+		Enabled:    server.EnabledTools(),
+		Categories: mcp.ToolCategories(),
+	}, http.StatusOK)
 }
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/mcp"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+)
+
+func newAdminTestServer(t *testing.T, server *mcp.Server) *httptest.Server {
+	t.Helper()
+	restoreSettings(t)
+
+	cfg := testConfig("0")
+	cfg.Server.AdminToken = "secret"
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", requireScope(auth.ScopeMCP, func(w http.ResponseWriter, r *http.Request) {
+		mcpHandler(w, r, server)
+	}))
+	mux.HandleFunc("/admin/tools", requireScope(auth.ScopeAdmin, func(w http.ResponseWriter, r *http.Request) {
+		adminToolsHandler(w, r, server)
+	}))
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func adminRequest(t *testing.T, method, url, token, body string) (*http.Response, toolsStatus) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var status toolsStatus
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return resp, status
+}
+
+func TestAdminToolsHandler(t *testing.T) {
+	server := mcp.NewServer("test-server", version, false)
+	ts := newAdminTestServer(t, server)
+
+	resp, _ := adminRequest(t, http.MethodGet, ts.URL+"/admin/tools", "", "")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	resp, _ = adminRequest(t, http.MethodGet, ts.URL+"/admin/tools", "wrong", "")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with wrong token, got %d", resp.StatusCode)
+	}
+
+	resp, status := adminRequest(t, http.MethodGet, ts.URL+"/admin/tools", "secret", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	if len(status.Enabled) != len(mcp.CreateToolsList().Tools) {
+		t.Errorf("Expected all tools enabled, got %d", len(status.Enabled))
+	}
+	if len(status.Categories["historical"]) == 0 {
+		t.Error("Expected categories in response")
+	}
+
+	resp, status = adminRequest(t, http.MethodPut, ts.URL+"/admin/tools", "secret", `{"deny": ["historical", "getWhatsMyIP"]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	for _, name := range status.Enabled {
+		if name == "getWhatsMyIP" || name == "getBGPlay" {
+			t.Errorf("Denied tool %s still enabled", name)
+		}
+	}
+	if server.IsToolEnabled("getRoutingHistory") {
+		t.Error("Expected filter to be applied to the MCP server")
+	}
+
+	for _, body := range []string{`{"deny": ["nope"]}`, `{"block": []}`, `not json`} {
+		resp, _ = adminRequest(t, http.MethodPut, ts.URL+"/admin/tools", "secret", body)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("body %s: expected 400, got %d", body, resp.StatusCode)
+		}
+	}
+
+	resp, _ = adminRequest(t, http.MethodDelete, ts.URL+"/admin/tools", "secret", "")
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestMCPStream_ToolsListChanged(t *testing.T) {
+	server := mcp.NewServer("test-server", version, false)
+	ts := newAdminTestServer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/mcp", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2025-06-18")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+	if resp.Header.Get("MCP-Session-ID") == "" {
+		t.Error("Expected a session ID for the stream")
+	}
+
+	resp2, _ := adminRequest(t, http.MethodPut, ts.URL+"/admin/tools", "secret", `{"deny": ["client"]}`)
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp2.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+
+		var notif mcp.Notification
+		if err := json.Unmarshal([]byte(data), &notif); err != nil {
+			t.Fatalf("Invalid notification %q: %v", data, err)
+		}
+		if notif.Method != "notifications/tools/list_changed" {
+			t.Fatalf("Expected list_changed notification, got %s", notif.Method)
+		}
+		return
+	}
+	t.Fatalf("Stream ended without notification: %v", scanner.Err())
+}
+
+func TestRun_InvalidToolFilter(t *testing.T) {
+	cfg := testConfig("0")
+	cfg.Tools.Deny = []string{"nope"}
+
+	err := run(context.Background(), options{Config: cfg})
+	if err == nil || !strings.Contains(err.Error(), "invalid tool filter") {
+		t.Errorf("Expected invalid tool filter error, got %v", err)
+	}
+}
+
+func TestAdminCacheHandler(t *testing.T) {
+	restoreSettings(t)
+	cfg := testConfig("0")
+	cfg.Server.AdminToken = "secret"
+	if err := applyConfig(mcp.NewServer("test-server", version, false), cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	c := cache.NewWithTTLs(map[string]time.Duration{"whois": time.Hour})
+	ctx := context.Background()
+	c.Set(ctx, "/data/whois", url.Values{"resource": {"193.0.0.1"}}, "a")
+	c.Set(ctx, "/data/whois", url.Values{"resource": {"AS3333"}}, "b")
+	c.Set(ctx, "/data/routing-status", url.Values{"resource": {"AS3333"}}, "c")
+
+	ts := httptest.NewServer(requireScope(auth.ScopeAdmin, func(w http.ResponseWriter, r *http.Request) {
+		adminCacheHandler(w, r, c)
+	}))
+	defer ts.Close()
+
+	request := func(method, query, token, body string, v interface{}) int {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+query, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && v != nil {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	if code := request(http.MethodGet, "", "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", code)
+	}
+
+	var status cacheStatus
+	if code := request(http.MethodGet, "", "secret", "", &status); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if !status.Enabled || status.Total.ActiveEntries != 3 || status.Endpoints["whois"].ActiveEntries != 2 ||
+		status.TTLs["whois"] != config.Duration(time.Hour) {
+		t.Errorf("Unexpected cache status %+v", status)
+	}
+
+	if code := request(http.MethodPut, "", "secret", `{"ttls": {"routing-status": "1m"}}`, &status); code != http.StatusOK {
+		t.Fatalf("Expected 200 for a TTL change, got %d", code)
+	}
+	if ttl, _ := c.GetTTL("routing-status"); ttl != time.Minute || status.TTLs["routing-status"] != config.Duration(time.Minute) {
+		t.Errorf("Expected the TTL change to apply, got %v", ttl)
+	}
+	for _, body := range []string{`{"ttls": {"whois": "0s"}}`, `{"ttls": {"whois": "soon"}}`, `{"default": "1m"}`} {
+		if code := request(http.MethodPut, "", "secret", body, nil); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, code)
+		}
+	}
+
+	var purge cachePurgeResult
+	if code := request(http.MethodDelete, "?endpoint=whois&resource=as%203333", "secret", "", &purge); code != http.StatusOK ||
+		purge.Purged != 1 || purge.Resource != "AS3333" {
+		t.Errorf("Expected one entry purged by its canonical resource, got %d %+v", code, purge)
+	}
+	c.Set(ctx, "/data/routing-status", url.Values{"resource": {"193.0.0.0/21"}}, "d")
+	if code := request(http.MethodDelete, "?resource=193.0.0.1/21", "secret", "", &purge); code != http.StatusOK || purge.Purged != 1 {
+		t.Errorf("Expected the prefix entry purged, got %d %+v", code, purge)
+	}
+	c.Set(ctx, "/data/searchcomplete", url.Values{"resource": {"ripe ncc"}}, "e")
+	if code := request(http.MethodDelete, "?resource=RIPE%20NCC", "secret", "", &purge); code != http.StatusOK || purge.Purged != 1 {
+		t.Errorf("Expected the free-text entry purged, got %d %+v", code, purge)
+	}
+	if code := request(http.MethodDelete, "", "secret", "", &purge); code != http.StatusOK || purge.Purged != 2 {
+		t.Errorf("Expected the remaining entries purged, got %d %+v", code, purge)
+	}
+
+	if code := request(http.MethodPost, "", "secret", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", code)
+	}
+}
@@ -151,6 +151,31 @@ func TestIsValidOrigin(t *testing.T) {
 			origin:   "http://localhost",
 			expected: true,
 		},
+		{
+			name:     "rebinding subdomain of localhost",
+			origin:   "http://localhost.evil.com",
+			expected: false,
+		},
+		{
+			name:     "rebinding subdomain of loopback address",
+			origin:   "http://127.0.0.1.evil.com:8080",
+			expected: false,
+		},
+		{
+			name:     "allowed domain as prefix",
+			origin:   "https://claude.ai.evil.com",
+			expected: false,
+		},
+		{
+			name:     "user info before host",
+			origin:   "http://localhost@evil.com",
+			expected: false,
+		},
+		{
+			name:     "null origin",
+			origin:   "null",
+			expected: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -163,6 +188,57 @@ func TestIsValidOrigin(t *testing.T) {
 	}
 }
 
+func TestMCPHandler_OriginPolicy(t *testing.T) {
+	restoreSettings(t)
+
+	server := mcp.NewServer("test-server", "1.0.0", false)
+
+	post := func(origin, protocolVersion string) int {
+		req := httptest.NewRequest("POST", "http://example.com/mcp", strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("MCP-Protocol-Version", protocolVersion)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		recorder := httptest.NewRecorder()
+		mcpHandler(recorder, req, server)
+		return recorder.Code
+	}
+
+	if code := post("http://localhost.evil.com", "2025-06-18"); code != http.StatusForbidden {
+		t.Errorf("Expected rebinding origin to be rejected, got %d", code)
+	}
+	if code := post("http://localhost.evil.com", "2025-03-26"); code != http.StatusForbidden {
+		t.Errorf("Expected legacy client with rebinding origin to be rejected, got %d", code)
+	}
+
+	cfg := testConfig("0")
+	cfg.Transport.AllowedOrigins = []string{"https://*.console.example.com"}
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	if code := post("https://ops.console.example.com", "2025-06-18"); code != http.StatusNoContent {
+		t.Errorf("Expected configured console origin to be allowed, got %d", code)
+	}
+	if code := post("http://localhost:3000", "2025-06-18"); code != http.StatusForbidden {
+		t.Errorf("Expected origin missing from the allowlist to be rejected, got %d", code)
+	}
+
+	cfg = testConfig("0")
+	cfg.Transport.OriginMode = "deny-all"
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	if code := post("http://localhost:3000", "2025-06-18"); code != http.StatusForbidden {
+		t.Errorf("Expected deny-all to reject browser origins, got %d", code)
+	}
+	if code := post("", "2025-06-18"); code != http.StatusNoContent {
+		t.Errorf("Expected deny-all to allow requests without an origin, got %d", code)
+	}
+}
+
 func TestIsSupportedProtocolVersion(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -240,7 +316,10 @@ func TestGetOrCreateSession(t *testing.T) {
 			}
 
 			recorder := httptest.NewRecorder()
-			sessionID := getOrCreateSession(req, recorder)
+			sessionID, issued := getOrCreateSession(req, recorder)
+			if issued != tc.expectNewSession {
+				t.Errorf("Expected issued %v, got %v", tc.expectNewSession, issued)
+			}
 
 			if tc.expectNewSession {
 				if sessionID == "" {
@@ -339,9 +418,10 @@ func TestHandleCORS(t *testing.T) {
 
 			// Check other CORS headers
 			expectedHeaders := map[string]string{
-				"Access-Control-Allow-Methods": "POST, GET, OPTIONS",
-				"Access-Control-Allow-Headers": "Content-Type, MCP-Protocol-Version, MCP-Session-ID",
-				"Access-Control-Max-Age":       "86400",
+				"Access-Control-Allow-Methods":  "POST, GET, OPTIONS",
+				"Access-Control-Allow-Headers":  "Content-Type, Authorization, X-API-Key, MCP-Protocol-Version, MCP-Session-ID, X-Request-ID, traceparent",
+				"Access-Control-Max-Age":        "86400",
+				"Access-Control-Expose-Headers": "MCP-Session-ID, X-Request-ID",
 			}
 
 			for header, expectedValue := range expectedHeaders {
@@ -7,13 +7,19 @@ import (
 	"errors"
 	"flag"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/health"
 	"github.com/taihen/mcp-ripestat/internal/mcp"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
 )
 
 func TestManifestHandler(t *testing.T) {
@@ -141,7 +147,7 @@ func TestRun_ServerStartup(t *testing.T) {
 	// Start the server in a goroutine
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- run(ctx, port)
+		errCh <- run(ctx, options{Config: testConfig(port)})
 	}()
 
 	// Give the server a moment to start
@@ -171,7 +177,7 @@ func TestRun_ContextCancellation(t *testing.T) {
 	// Start the server in a goroutine
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- run(ctx, port)
+		errCh <- run(ctx, options{Config: testConfig(port)})
 	}()
 
 	// Give the server a moment to start
@@ -282,7 +288,7 @@ func TestRun(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
-	err := run(ctx, "0") // Use port 0 to let the OS choose a free port
+	err := run(ctx, options{Config: testConfig("0")}) // Use port 0 to let the OS choose a free port
 	if err != nil {
 		t.Fatalf("run() failed: %v", err)
 	}
@@ -325,7 +331,7 @@ func TestRun_ServerShutdownError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	err := run(ctx, "0")
+	err := run(ctx, options{Config: testConfig("0")})
 	// The function should complete without error even with cancelled context
 	if err != nil {
 		t.Fatalf("run() failed: %v", err)
@@ -338,7 +344,7 @@ func TestRun_InvalidPort(t *testing.T) {
 	defer cancel()
 
 	// Use a very high port number that might cause issues
-	err := run(ctx, "99999")
+	err := run(ctx, options{Config: testConfig("99999")})
 	// The function should complete without error
 	if err != nil {
 		t.Fatalf("run() failed: %v", err)
@@ -406,6 +412,52 @@ func TestMCPHandler_Notification(t *testing.T) {
 	}
 }
 
+func TestMCPHandler_Batch(t *testing.T) {
+	server := mcp.NewServer("test-server", version, false)
+
+	batch := []interface{}{
+		mcp.NewRequest("initialize", map[string]interface{}{"protocolVersion": "2025-06-18"}, 1),
+		mcp.NewNotification("notifications/initialized", nil),
+		mcp.NewRequest("tools/list", nil, 2),
+	}
+
+	reqBody, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatalf("Failed to marshal batch: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	mcpHandler(w, req, server)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", resp.StatusCode)
+	}
+
+	var responses []mcp.Response
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		t.Fatalf("Expected JSON array response: %v", err)
+	}
+
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(responses))
+	}
+
+	for i, response := range responses {
+		if response.Error != nil {
+			t.Errorf("Response %d: unexpected error %+v", i, response.Error)
+		}
+		if id, ok := response.ID.(float64); !ok || int(id) != i+1 {
+			t.Errorf("Response %d: expected ID %d, got %v", i, i+1, response.ID)
+		}
+	}
+}
+
 func TestMCPHandler_InvalidJSON(t *testing.T) {
 	server := mcp.NewServer("test-server", version, false)
 
@@ -524,6 +576,134 @@ func TestWarmupHandler(t *testing.T) {
 	}
 }
 
+func TestHealthzHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	healthzHandler(w, httptest.NewRequest("GET", "/healthz", nil), time.Now().Add(-time.Minute))
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if w.Code != http.StatusOK || response["status"] != health.StatusOK || response["uptime"] != "1m0s" {
+		t.Errorf("Unexpected liveness response %d %v", w.Code, response)
+	}
+}
+
+func TestProbeRIPEstat_BypassesCache(t *testing.T) {
+	restoreSettings(t)
+	t.Cleanup(cache.Shared().Clear)
+
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status": "ok", "data": {"ip": "192.0.2.1"}}`)
+	}))
+	defer ts.Close()
+
+	cfg := testConfig("0")
+	cfg.Upstream.BaseURL = ts.URL
+	cfg.Upstream.RetryCount = 0
+	if err := applyConfig(mcp.NewServer("test-server", version, false), cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	if err := probeRIPEstat(context.Background()); err != nil {
+		t.Fatalf("Expected the first probe to succeed, got %v", err)
+	}
+	// The first answer is cached, but the probe must still reach RIPEstat.
+	if err := probeRIPEstat(context.Background()); err == nil {
+		t.Error("Expected the probe to fail once RIPEstat does, not to be answered from cache")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 upstream requests, got %d", got)
+	}
+}
+
+func TestReadyzHandler(t *testing.T) {
+	restoreSettings(t)
+	previous := upstreamProbe
+	t.Cleanup(func() { upstreamProbe = previous })
+
+	readyz := func(t *testing.T) (int, health.Report) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		readyzHandler(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		var report health.Report
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return w.Code, report
+	}
+	checkStatus := func(report health.Report, name string) string {
+		for _, check := range report.Checks {
+			if check.Name == name {
+				return check.Status
+			}
+		}
+		return ""
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		upstreamProbe = health.NewProbe("upstream", func(context.Context) error { return nil }, time.Minute, time.Second)
+
+		// Other tests may have failed to reach RIPEstat, so do not judge the
+		// error rate here.
+		cfg := testConfig("0")
+		cfg.Health.MinRequests = math.MaxInt32
+		if err := applyConfig(mcp.NewServer("test-server", version, false), cfg); err != nil {
+			t.Fatalf("applyConfig failed: %v", err)
+		}
+
+		code, report := readyz(t)
+		if code != http.StatusOK || report.Status != health.StatusOK {
+			t.Errorf("Expected ready instance, got %d %+v", code, report)
+		}
+		for _, name := range []string{"config", "upstream_errors", "limiter", "upstream"} {
+			if got := checkStatus(report, name); got != health.StatusOK {
+				t.Errorf("Expected check %s to be ok, got %q", name, got)
+			}
+		}
+	})
+
+	t.Run("upstream unreachable", func(t *testing.T) {
+		upstreamProbe = health.NewProbe("upstream", func(context.Context) error { return errors.New("connection refused") }, time.Minute, time.Second)
+
+		code, report := readyz(t)
+		if code != http.StatusServiceUnavailable || checkStatus(report, "upstream") != health.StatusFail {
+			t.Errorf("Expected 503 with failed upstream probe, got %d %+v", code, report)
+		}
+	})
+
+	t.Run("probe disabled", func(t *testing.T) {
+		cfg := testConfig("0")
+		cfg.Health.Probe = false
+		if err := applyConfig(mcp.NewServer("test-server", version, false), cfg); err != nil {
+			t.Fatalf("applyConfig failed: %v", err)
+		}
+
+		code, report := readyz(t)
+		if code != http.StatusOK || checkStatus(report, "upstream") != "" {
+			t.Errorf("Expected no upstream probe, got %d %+v", code, report)
+		}
+	})
+
+	t.Run("reload failure", func(t *testing.T) {
+		current := currentConfig()
+		reloadConfig(mcp.NewServer("test-server", version, false), current, func() (*config.Config, error) {
+			return nil, errors.New("broken")
+		})
+
+		code, report := readyz(t)
+		if code != http.StatusOK || report.Status != health.StatusDegraded || checkStatus(report, "config") != health.StatusDegraded {
+			t.Errorf("Expected degraded but ready instance, got %d %+v", code, report)
+		}
+	})
+}
+
 func TestStatusHandler(t *testing.T) {
 	startTime := time.Now()
 	req := httptest.NewRequest("GET", "/status", nil)
@@ -756,3 +936,94 @@ func TestMCPEndpointIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricsHandler_ContentNegotiation(t *testing.T) {
+	server := mcp.NewServer("test", "1.0.0", false)
+
+	tests := []struct {
+		name       string
+		target     string
+		accept     string
+		prometheus bool
+	}{
+		{name: "default", target: "/metrics"},
+		{name: "query parameter", target: "/metrics?format=prometheus", prometheus: true},
+		{name: "json parameter wins", target: "/metrics?format=json", accept: "text/plain", prometheus: false},
+		{name: "text accept", target: "/metrics", accept: "text/plain;version=0.0.4", prometheus: true},
+		{name: "prometheus scraper", target: "/metrics", accept: "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5,*/*;q=0.1", prometheus: true},
+		{name: "json accept", target: "/metrics", accept: "application/json, text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			metricsHandler(w, req, server)
+
+			body := w.Body.String()
+			if tt.prometheus {
+				if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+					t.Errorf("Expected text/plain, got %s", w.Header().Get("Content-Type"))
+				}
+				if !strings.Contains(body, "# TYPE mcp_tool_calls_total counter") {
+					t.Errorf("Expected Prometheus text, got %s", body)
+				}
+				return
+			}
+
+			if w.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("Expected application/json, got %s", w.Header().Get("Content-Type"))
+			}
+			var summary map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+				t.Fatalf("Expected JSON, got %s", body)
+			}
+			if _, ok := summary["cache_hits"]; !ok {
+				t.Errorf("Expected cache_hits in %s", body)
+			}
+		})
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	var seen string
+	handler := withRequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen, _ = mcp.RequestIDFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "accepted", header: "gw-1234:abc/def=", expected: "gw-1234:abc/def="},
+		{name: "generated", header: ""},
+		{name: "invalid characters", header: "abc\ndef"},
+		{name: "too long", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/warmup", nil)
+			if tt.header != "" {
+				req.Header.Set(requestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(requestIDHeader)
+			if echoed == "" || echoed != seen {
+				t.Fatalf("Expected the context request ID %q to be echoed, got %q", seen, echoed)
+			}
+			if tt.expected != "" && echoed != tt.expected {
+				t.Errorf("Expected request ID %q, got %q", tt.expected, echoed)
+			}
+			if tt.expected == "" && (echoed == tt.header || len(echoed) != 32) {
+				t.Errorf("Expected a generated request ID, got %q", echoed)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/mcp"
+	"github.com/taihen/mcp-ripestat/internal/origin"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+	"github.com/taihen/mcp-ripestat/internal/tracing"
+	"github.com/taihen/mcp-ripestat/internal/warming"
+)
+
+// tracerShutdownTimeout bounds the final export of a replaced tracer.
+const tracerShutdownTimeout = 5 * time.Second
+
+// logLevel is shared by the default logger so that reloads can change it.
+var logLevel = new(slog.LevelVar)
+
+var (
+	// settings holds the active configuration read by request handlers.
+	settings atomic.Pointer[config.Config]
+
+	// origins holds the origin policy compiled from settings.
+	origins atomic.Pointer[origin.Policy]
+
+	// reloadFailure holds the error of the last reload, or nil when it
+	// succeeded.
+	reloadFailure atomic.Pointer[string]
+
+	defaultSettings     *config.Config
+	defaultOrigins      *origin.Policy
+	defaultSettingsOnce sync.Once
+)
+
+// currentConfig returns the active configuration, or the defaults before run
+// has applied one.
+func currentConfig() *config.Config {
+	if cfg := settings.Load(); cfg != nil {
+		return cfg
+	}
+
+	loadDefaultSettings()
+	return defaultSettings
+}
+
+// currentOriginPolicy returns the active origin policy.
+func currentOriginPolicy() *origin.Policy {
+	if policy := origins.Load(); policy != nil {
+		return policy
+	}
+
+	loadDefaultSettings()
+	return defaultOrigins
+}
+
+func loadDefaultSettings() {
+	defaultSettingsOnce.Do(func() {
+		defaultSettings = config.Default()
+		// The built-in patterns are valid; Validate tests cover them.
+		defaultOrigins, _ = defaultSettings.OriginPolicy()
+	})
+}
+
+// applyConfig applies the reloadable settings of cfg to the running process.
+// Everything that can fail is checked before any setting changes, so an error
+// leaves the current configuration fully in effect.
+func applyConfig(server *mcp.Server, cfg *config.Config) error {
+	policy, err := cfg.OriginPolicy()
+	if err != nil {
+		return fmt.Errorf("invalid origin policy: %w", err)
+	}
+
+	authPolicy, err := newAccessPolicy(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid auth configuration: %w", err)
+	}
+
+	warmingTargets, err := cfg.WarmingTargets()
+	if err != nil {
+		return fmt.Errorf("invalid warming configuration: %w", err)
+	}
+	for _, target := range warmingTargets {
+		if !mcp.IsTool(target.Tool) {
+			return fmt.Errorf("invalid warming configuration: unknown tool %q", target.Tool)
+		}
+	}
+
+	resolver, err := cfg.Resolver()
+	if err != nil {
+		return fmt.Errorf("invalid resolve configuration: %w", err)
+	}
+
+	toolFilter := mcp.ToolFilter{Allow: cfg.Tools.Allow, Deny: cfg.Tools.Deny}
+	if err := toolFilter.Validate(); err != nil {
+		return fmt.Errorf("invalid tool filter: %w", err)
+	}
+	if err := client.ValidateMaxConcurrentRequests(cfg.Limiter.MaxConcurrent); err != nil {
+		return fmt.Errorf("invalid limiter: %w", err)
+	}
+
+	// The audit log is reopened on every apply, so a reload picks up a file
+	// moved away by external rotation.
+	auditLogger, err := cfg.AuditLogger()
+	if err != nil {
+		return fmt.Errorf("invalid audit configuration: %w", err)
+	}
+
+	// The tool filter and limiter were validated above.
+	_ = server.SetToolFilter(toolFilter)
+	server.SetToolsPageSize(cfg.Tools.PageSize)
+	server.SetMaxResultBytes(cfg.Tools.MaxResultBytes)
+	server.SetQuotaPolicy(cfg.QuotaPolicy())
+	server.SetResolver(resolver)
+	_ = client.SetMaxConcurrentRequests(cfg.Limiter.MaxConcurrent)
+
+	if previous := server.SetAuditLogger(auditLogger); previous != nil {
+		if err := previous.Close(); err != nil {
+			slog.Warn("failed to close previous audit log", "err", err)
+		}
+	}
+
+	shutdownTracer(tracing.SetTracer(cfg.Tracer()))
+	upstreamProbe.SetTiming(cfg.Health.ProbeInterval.Std(), cfg.Health.ProbeTimeout.Std())
+
+	cache.SetDefaultTTLs(cfg.CacheTTLs(), cfg.Cache.DefaultTTL.Std())
+	cache.Shared().SetNegativeTTL(cfg.Cache.NegativeTTL.Std())
+	cacheWarmer.Configure(warmingTargets, warming.Options{
+		RefreshBefore: cfg.Warming.RefreshBefore.Std(),
+		MaxConcurrent: cfg.Warming.MaxConcurrent,
+	})
+	ripeconfig.SetDefault(cfg.UpstreamClientConfig())
+
+	if cfg.Server.Debug {
+		logLevel.Set(slog.LevelDebug)
+	} else {
+		logLevel.Set(slog.LevelInfo)
+	}
+
+	origins.Store(policy)
+	access.Store(authPolicy)
+	settings.Store(cfg)
+
+	return nil
+}
+
+// shutdownTracer flushes and stops a tracer that is no longer in use.
+func shutdownTracer(tracer *tracing.Tracer) {
+	if tracer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracerShutdownTimeout)
+	defer cancel()
+	if err := tracer.Shutdown(ctx); err != nil {
+		slog.Warn("failed to shut down tracer", "err", err)
+	}
+}
+
+// reloadConfig loads a new configuration and applies it, returning the
+// configuration now in effect. Settings bound to the listeners keep their
+// current values until restart; an invalid configuration is ignored.
+func reloadConfig(server *mcp.Server, current *config.Config, load func() (*config.Config, error)) *config.Config {
+	next, err := load()
+	if err != nil {
+		slog.Error("configuration reload failed, keeping current configuration", "err", err)
+		recordReloadFailure(err)
+		return current
+	}
+
+	if keys := keepRestartSettings(current, next); len(keys) > 0 {
+		slog.Warn("configuration changes require a restart, keeping current values", "keys", keys)
+
+		// Other settings may depend on the listeners, such as authentication
+		// by client certificates alone.
+		if err := next.Validate(); err != nil {
+			err = fmt.Errorf("with the current listener settings: %w", err)
+			slog.Error("configuration reload failed, keeping current configuration", "err", err)
+			recordReloadFailure(err)
+			return current
+		}
+	}
+
+	if err := applyConfig(server, next); err != nil {
+		slog.Error("configuration reload failed, keeping current configuration", "err", err)
+		recordReloadFailure(err)
+		return current
+	}
+
+	reloadFailure.Store(nil)
+	slog.Info("configuration reloaded")
+	return next
+}
+
+// keepRestartSettings copies the settings bound to the listeners from current
+// to next, as the open listeners cannot pick up new values, and returns the
+// keys of the settings next changed.
+func keepRestartSettings(current, next *config.Config) []string {
+	var keys []string
+
+	if !slices.Equal(next.Server.Listeners, current.Server.Listeners) {
+		keys = append(keys, "server.listeners")
+		next.Server.Listeners = slices.Clone(current.Server.Listeners)
+	}
+	if next.Server.Port != current.Server.Port {
+		keys = append(keys, "server.port")
+		next.Server.Port = current.Server.Port
+	}
+	if next.Server.ReadHeaderTimeout != current.Server.ReadHeaderTimeout {
+		keys = append(keys, "server.read_header_timeout")
+		next.Server.ReadHeaderTimeout = current.Server.ReadHeaderTimeout
+	}
+
+	// The TLS listener keeps its port, certificate files and client
+	// certificate policy. The grants of client certificate subjects are not
+	// bound to it and are reloaded.
+	if next.TLS.Port != current.TLS.Port {
+		keys = append(keys, "tls.port")
+		next.TLS.Port = current.TLS.Port
+	}
+	if next.TLS.CertFile != current.TLS.CertFile {
+		keys = append(keys, "tls.cert_file")
+		next.TLS.CertFile = current.TLS.CertFile
+	}
+	if next.TLS.KeyFile != current.TLS.KeyFile {
+		keys = append(keys, "tls.key_file")
+		next.TLS.KeyFile = current.TLS.KeyFile
+	}
+	if next.TLS.ClientCAFile != current.TLS.ClientCAFile {
+		keys = append(keys, "tls.client_ca_file")
+		next.TLS.ClientCAFile = current.TLS.ClientCAFile
+	}
+	if next.TLS.ClientAuth != current.TLS.ClientAuth {
+		keys = append(keys, "tls.client_auth")
+		next.TLS.ClientAuth = current.TLS.ClientAuth
+	}
+
+	if next.Unix.Path != current.Unix.Path {
+		keys = append(keys, "unix.path")
+		next.Unix.Path = current.Unix.Path
+	}
+	if next.Unix.Mode != current.Unix.Mode {
+		keys = append(keys, "unix.mode")
+		next.Unix.Mode = current.Unix.Mode
+	}
+
+	return keys
+}
+
+// recordReloadFailure remembers why the last reload failed, for readiness.
+func recordReloadFailure(err error) {
+	message := err.Error()
+	reloadFailure.Store(&message)
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/config"
+	"github.com/taihen/mcp-ripestat/internal/mcp"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/logging"
+)
+
+// testConfig returns the default configuration listening on port.
+func testConfig(port string) *config.Config {
+	cfg := config.Default()
+	cfg.Server.Port = port
+	return cfg
+}
+
+// restoreSettings resets process-wide settings changed by applyConfig.
+func restoreSettings(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		if err := applyConfig(mcp.NewServer("test-server", version, false), config.Default()); err != nil {
+			t.Errorf("failed to restore settings: %v", err)
+		}
+		ripeconfig.SetDefault(nil)
+		settings.Store(nil)
+		origins.Store(nil)
+		access.Store(nil)
+		reloadFailure.Store(nil)
+	})
+}
+
+func TestApplyConfig(t *testing.T) {
+	restoreSettings(t)
+
+	server := mcp.NewServer("test-server", version, false)
+
+	cfg := testConfig("0")
+	cfg.Server.Debug = true
+	cfg.Server.RequestTimeout = config.Duration(5 * time.Second)
+	cfg.Transport.AllowedOrigins = []string{"https://example.com"}
+	cfg.Limiter.MaxConcurrent = 3
+	cfg.Upstream.SourceApp = "test-app"
+	cfg.Tools.Deny = []string{"historical"}
+	cfg.Cache.NegativeTTL = config.Duration(30 * time.Second)
+
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	if logLevel.Level() != slog.LevelDebug {
+		t.Errorf("Expected debug log level, got %v", logLevel.Level())
+	}
+	if client.MaxConcurrentRequests() != 3 {
+		t.Errorf("Expected limiter size 3, got %d", client.MaxConcurrentRequests())
+	}
+	if got := ripeconfig.DefaultConfig().SourceApp; got != "test-app" {
+		t.Errorf("Expected upstream source app test-app, got %q", got)
+	}
+	if server.IsToolEnabled("getBGPlay") {
+		t.Error("Expected historical tools to be disabled")
+	}
+	if !isValidOrigin("https://example.com") || isValidOrigin("https://claude.ai") {
+		t.Error("Expected origin allowlist from configuration")
+	}
+	if currentConfig().Server.RequestTimeout.Std() != 5*time.Second {
+		t.Errorf("Expected request timeout 5s, got %v", currentConfig().Server.RequestTimeout.Std())
+	}
+	if ttl := cache.Shared().NegativeTTL(); ttl != 30*time.Second {
+		t.Errorf("Expected negative TTL 30s, got %v", ttl)
+	}
+}
+
+func TestApplyConfig_InvalidLeavesSettingsUnchanged(t *testing.T) {
+	restoreSettings(t)
+
+	server := mcp.NewServer("test-server", version, false)
+	if err := applyConfig(server, testConfig("0")); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	previous := currentConfig()
+
+	// Every setting before the limiter is valid, but none of them may apply.
+	cfg := testConfig("0")
+	cfg.Tools.Deny = []string{"historical"}
+	cfg.Limiter.MaxConcurrent = client.MaxConcurrentRequestsLimit + 1
+
+	if err := applyConfig(server, cfg); err == nil || !strings.Contains(err.Error(), "invalid limiter") {
+		t.Fatalf("Expected a limiter error, got %v", err)
+	}
+	if !server.IsToolEnabled("getBGPlay") || len(server.ToolFilter().Deny) != 0 {
+		t.Errorf("Expected the tool filter to be unchanged, got %+v", server.ToolFilter())
+	}
+	if client.MaxConcurrentRequests() != client.DefaultMaxConcurrentRequests {
+		t.Errorf("Expected limiter size %d, got %d", client.DefaultMaxConcurrentRequests, client.MaxConcurrentRequests())
+	}
+	if currentConfig() != previous {
+		t.Error("Expected the current configuration to stay in effect")
+	}
+}
+
+func TestApplyConfig_ClientLogLevel(t *testing.T) {
+	restoreSettings(t)
+
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	slog.SetDefault(slog.New(logging.NewHandler(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: logLevel}))))
+
+	server := mcp.NewServer("test-server", version, false)
+	ctx := context.Background()
+	for _, debug := range []bool{true, false} {
+		cfg := testConfig("0")
+		cfg.Server.Debug = debug
+		if err := applyConfig(server, cfg); err != nil {
+			t.Fatalf("applyConfig failed: %v", err)
+		}
+		if got := logging.DefaultLogger.Enabled(ctx, slog.LevelDebug); got != debug {
+			t.Errorf("Expected RIPEstat client debug logging %v, got %v", debug, got)
+		}
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	restoreSettings(t)
+
+	server := mcp.NewServer("test-server", version, false)
+	current := testConfig("8080")
+	if err := applyConfig(server, current); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	t.Run("applies reloadable settings", func(t *testing.T) {
+		next := testConfig("9090")
+		next.Server.ReadHeaderTimeout = config.Duration(time.Minute)
+		next.Tools.Deny = []string{"client"}
+		next.Limiter.MaxConcurrent = 2
+
+		got := reloadConfig(server, current, func() (*config.Config, error) { return next, nil })
+
+		if got.Server.Port != "8080" {
+			t.Errorf("Expected port to keep its value until restart, got %s", got.Server.Port)
+		}
+		if got.Server.ReadHeaderTimeout != current.Server.ReadHeaderTimeout {
+			t.Errorf("Expected read_header_timeout to keep its value until restart, got %v", got.Server.ReadHeaderTimeout.Std())
+		}
+		if server.IsToolEnabled("getWhatsMyIP") {
+			t.Error("Expected tool filter to be reloaded")
+		}
+		if client.MaxConcurrentRequests() != 2 {
+			t.Errorf("Expected limiter size 2, got %d", client.MaxConcurrentRequests())
+		}
+		current = got
+	})
+
+	t.Run("keeps configuration on load error", func(t *testing.T) {
+		got := reloadConfig(server, current, func() (*config.Config, error) { return nil, errors.New("broken") })
+		if got != current {
+			t.Error("Expected current configuration to be kept")
+		}
+	})
+
+	t.Run("keeps configuration on apply error", func(t *testing.T) {
+		next := testConfig("8080")
+		next.Tools.Allow = []string{"unknown"}
+
+		got := reloadConfig(server, current, func() (*config.Config, error) { return next, nil })
+		if got != current {
+			t.Error("Expected current configuration to be kept")
+		}
+		if server.IsToolEnabled("getWhatsMyIP") {
+			t.Error("Expected previous tool filter to stay in effect")
+		}
+	})
+}
+
+func TestReloadConfig_ToolFilter(t *testing.T) {
+	restoreSettings(t)
+
+	server := mcp.NewServer("test-server", version, false)
+	current := testConfig("8080")
+	current.Tools.Deny = []string{"client"}
+	if err := applyConfig(server, current); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	notifications, unsubscribe := server.SubscribeNotifications("session-1")
+	defer unsubscribe()
+
+	expectListChanged := func(want bool) {
+		t.Helper()
+		select {
+		case notif := <-notifications:
+			if !want || notif.Method != "notifications/tools/list_changed" {
+				t.Errorf("Unexpected notification %s", notif.Method)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if want {
+				t.Error("Expected list_changed notification")
+			}
+		}
+	}
+
+	// A runtime change through /admin/tools.
+	if err := server.SetToolFilter(mcp.ToolFilter{Deny: []string{"historical"}}); err != nil {
+		t.Fatalf("SetToolFilter failed: %v", err)
+	}
+	expectListChanged(true)
+
+	// The reload restores the configured filter and notifies sessions.
+	current = reloadConfig(server, current, func() (*config.Config, error) { return current, nil })
+	if !server.IsToolEnabled("getBGPlay") || server.IsToolEnabled("getWhatsMyIP") {
+		t.Errorf("Expected the configured filter after reload, got %v", server.ToolFilter())
+	}
+	expectListChanged(true)
+
+	// A reload that leaves the enabled tools unchanged does not notify.
+	reloadConfig(server, current, func() (*config.Config, error) { return current, nil })
+	expectListChanged(false)
+}
+
+// tlsTestConfig returns a configuration with TCP and mutual TLS listeners.
+func tlsTestConfig() *config.Config {
+	cfg := testConfig("8080")
+	cfg.Server.Listeners = []string{config.ListenerTCP, config.ListenerTLS}
+	cfg.TLS.Port = "8443"
+	cfg.TLS.CertFile = "server.crt"
+	cfg.TLS.KeyFile = "server.key"
+	cfg.TLS.ClientCAFile = "ca.crt"
+	cfg.TLS.ClientAuth = config.ClientAuthRequire
+	cfg.Auth.Enabled = true
+	return cfg
+}
+
+// acceptsClientCertificates reports whether the active access policy
+// authenticates TLS client certificates.
+func acceptsClientCertificates() bool {
+	chain, _ := access.Load().authenticator.(auth.Chain)
+	for _, authenticator := range chain {
+		if _, ok := authenticator.(*auth.ClientCertificates); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReloadConfig_Listeners(t *testing.T) {
+	restoreSettings(t)
+
+	server := mcp.NewServer("test-server", version, false)
+	current := tlsTestConfig()
+	current.Auth.APIKeys = map[string]config.APIKeyConfig{"ops": {Key: "ops-key"}}
+	if err := applyConfig(server, current); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	t.Run("keeps listener settings", func(t *testing.T) {
+		next := testConfig("8080")
+		next.Server.Listeners = []string{config.ListenerTCP, config.ListenerUnix}
+		next.TLS.Port = "9443"
+		next.TLS.CertFile = "other.crt"
+		next.TLS.KeyFile = "other.key"
+		next.TLS.ClientScopes = []string{auth.ScopeMCP, auth.ScopeMetrics}
+		next.Unix.Path = "/run/mcp-ripestat.sock"
+		next.Unix.Mode = "0600"
+		next.Auth.Enabled = true
+		next.Auth.APIKeys = current.Auth.APIKeys
+
+		got := reloadConfig(server, current, func() (*config.Config, error) { return next, nil })
+
+		if got != next {
+			t.Fatalf("Expected the reload to be applied, got failure %v", reloadFailure.Load())
+		}
+		if !slices.Equal(got.Server.Listeners, current.Server.Listeners) {
+			t.Errorf("Expected listeners to keep their value until restart, got %v", got.Server.Listeners)
+		}
+		if got.TLS.Port != "8443" || got.TLS.CertFile != "server.crt" || got.TLS.KeyFile != "server.key" ||
+			got.TLS.ClientCAFile != "ca.crt" || got.TLS.ClientAuth != config.ClientAuthRequire {
+			t.Errorf("Expected TLS listener settings to keep their values until restart, got %+v", got.TLS)
+		}
+		if got.Unix != current.Unix {
+			t.Errorf("Expected Unix listener settings to keep their values until restart, got %+v", got.Unix)
+		}
+		if !slices.Equal(got.TLS.ClientScopes, next.TLS.ClientScopes) {
+			t.Errorf("Expected client certificate grants to be reloaded, got %v", got.TLS.ClientScopes)
+		}
+		if !acceptsClientCertificates() {
+			t.Error("Expected client certificates to authenticate while the mutual TLS listener runs")
+		}
+		if currentConfig() != got {
+			t.Error("Expected the active configuration to match the listeners")
+		}
+		current = got
+	})
+
+	t.Run("rejects settings that rely on other listeners", func(t *testing.T) {
+		restarted := testConfig("8080")
+		restarted.Auth.Enabled = true
+		restarted.Auth.APIKeys = current.Auth.APIKeys
+		if err := applyConfig(server, restarted); err != nil {
+			t.Fatalf("applyConfig failed: %v", err)
+		}
+
+		// Client certificates would be the only credentials, but the running
+		// server has no TLS listener to verify them.
+		next := tlsTestConfig()
+
+		got := reloadConfig(server, restarted, func() (*config.Config, error) { return next, nil })
+
+		if got != restarted {
+			t.Error("Expected current configuration to be kept")
+		}
+		if reloadFailure.Load() == nil {
+			t.Error("Expected the reload failure to be recorded")
+		}
+		if acceptsClientCertificates() {
+			t.Error("Expected client certificates to stay disabled without a TLS listener")
+		}
+	})
+}
+
+func TestApplyConfig_AuditLog(t *testing.T) {
+	restoreSettings(t)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg := testConfig("0")
+	cfg.Audit.Enabled = true
+	cfg.Audit.Sinks = []string{config.AuditSinkFile}
+	cfg.Audit.File = path
+
+	server := mcp.NewServer("test-server", version, false)
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+
+	// getWhois without arguments fails validation without calling RIPEstat.
+	call := func() {
+		t.Helper()
+		batch := `[{"jsonrpc": "2.0", "method": "initialize", "params": {"protocolVersion": "2025-06-18"}, "id": 1},
+			{"jsonrpc": "2.0", "method": "tools/call", "params": {"name": "getWhois", "arguments": {}}, "id": 2}]`
+		if _, err := server.ProcessMessage(context.Background(), []byte(batch)); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	call()
+
+	disabled := testConfig("0")
+	if got := reloadConfig(server, cfg, func() (*config.Config, error) { return disabled, nil }); got != disabled {
+		t.Fatal("Expected reload to succeed")
+	}
+	call()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 || !strings.Contains(string(data), `"tool":"getWhois"`) {
+		t.Errorf("Expected one audit entry before the reload, got %s", data)
+	}
+
+	invalid := testConfig("0")
+	invalid.Audit.Enabled = true
+	invalid.Audit.Sinks = []string{config.AuditSinkFile}
+	invalid.Audit.File = filepath.Join(t.TempDir(), "missing", "audit.log")
+	if err := applyConfig(server, invalid); err == nil || !strings.Contains(err.Error(), "invalid audit configuration") {
+		t.Errorf("Expected audit configuration error, got %v", err)
+	}
+}
+
+func TestApplyConfig_Warming(t *testing.T) {
+	restoreSettings(t)
+
+	server := mcp.NewServer("test-server", version, false)
+	cfg := testConfig("0")
+	cfg.Warming.Enabled = true
+	cfg.Warming.Watchlist = []string{"getASOverview?resource=AS3333", "getAnnouncedPrefixes?resource=AS3333"}
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	if statuses := cacheWarmer.Status(); len(statuses) != 2 || statuses[0].Target != "getASOverview?resource=AS3333" {
+		t.Errorf("Expected the watchlist to be warmed, got %+v", statuses)
+	}
+
+	invalid := testConfig("0")
+	invalid.Warming.Enabled = true
+	invalid.Warming.Watchlist = []string{"getASOverveiw?resource=AS3333"}
+	if err := applyConfig(server, invalid); err == nil || !strings.Contains(err.Error(), "unknown tool") {
+		t.Errorf("Expected unknown tool error, got %v", err)
+	}
+	if statuses := cacheWarmer.Status(); len(statuses) != 2 {
+		t.Errorf("Expected a rejected configuration to keep the watchlist, got %+v", statuses)
+	}
+}
+
+func TestApplyConfig_Tracing(t *testing.T) {
+	restoreSettings(t)
+
+	var mu sync.Mutex
+	var received []string
+	collector := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, string(body))
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	cfg := testConfig("0")
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.Endpoint = collector.URL
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	server := mcp.NewServer("test-server", version, false)
+	if err := applyConfig(server, cfg); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	if _, err := server.ProcessMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "ping", "id": 1}`)); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	// Disabling tracing on reload flushes the spans of the previous tracer.
+	disabled := testConfig("0")
+	if got := reloadConfig(server, cfg, func() (*config.Config, error) { return disabled, nil }); got != disabled {
+		t.Fatal("Expected reload to succeed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || !strings.Contains(received[0], `"name":"ping"`) || !strings.Contains(received[0], `"stringValue":"mcp-ripestat"`) {
+		t.Errorf("Expected one export with the ping span, got %v", received)
+	}
+}
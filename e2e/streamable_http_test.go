@@ -168,7 +168,7 @@ func TestStreamableHTTP(t *testing.T) {
 		expectedHeaders := map[string]string{
 			"Access-Control-Allow-Origin":  "http://localhost:3000",
 			"Access-Control-Allow-Methods": "POST, GET, OPTIONS",
-			"Access-Control-Allow-Headers": "Content-Type, MCP-Protocol-Version, MCP-Session-ID",
+			"Access-Control-Allow-Headers": "Content-Type, Authorization, X-API-Key, MCP-Protocol-Version, MCP-Session-ID",
 			"Access-Control-Max-Age":       "86400",
 		}
 
@@ -0,0 +1,201 @@
+// Package audit writes an append-only stream of tool invocations, one JSON
+// object per line, recording who asked what about which network and when.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result statuses recorded in Entry.Status.
+const (
+	// StatusOK means the tool returned a result.
+	StatusOK = "ok"
+
+	// StatusToolError means the tool ran but reported an error, for example
+	// an invalid argument or an upstream failure.
+	StatusToolError = "tool_error"
+
+	// StatusError means the call was refused, for example a disabled tool.
+	StatusError = "error"
+
+	// StatusRateLimited means a rate limit or daily quota rejected the call.
+	StatusRateLimited = "rate_limited"
+)
+
+// Cache outcomes recorded in Entry.Cache.
+const (
+	CacheHit     = "hit"
+	CacheMiss    = "miss"
+	CachePartial = "partial"
+	CacheNone    = "none"
+)
+
+// RedactedValue replaces redacted argument values.
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedact redacts the caller's address recorded for getWhatsMyIP.
+var DefaultRedact = []string{"getWhatsMyIP.client_ip"}
+
+// ErrClosed is returned when logging to a closed Logger.
+var ErrClosed = errors.New("audit log closed")
+
+// Client identifies the MCP client, as reported in initialize.
+type Client struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Upstream is one RIPEstat request made for a tool call.
+type Upstream struct {
+	Endpoint  string  `json:"endpoint"`
+	Cache     string  `json:"cache"`
+	QueryID   string  `json:"query_id,omitempty"`
+	Failed    bool    `json:"failed,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// Entry is one audited tools/call.
+type Entry struct {
+	Time       time.Time              `json:"time"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	Principal  string                 `json:"principal,omitempty"`
+	AuthMethod string                 `json:"auth_method,omitempty"`
+	Client     *Client                `json:"client,omitempty"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments"`
+	Status     string                 `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	Cache      string                 `json:"cache"`
+	Upstream   []Upstream             `json:"upstream,omitempty"`
+	LatencyMS  float64                `json:"latency_ms"`
+}
+
+// CacheOutcome summarizes the cache outcome of upstream requests: "hit" when
+// all were served from cache, "miss" when none were, "partial" otherwise and
+// "none" when no request was made.
+func CacheOutcome(upstream []Upstream) string {
+	if len(upstream) == 0 {
+		return CacheNone
+	}
+
+	hits := 0
+	for _, call := range upstream {
+		if call.Cache == CacheHit {
+			hits++
+		}
+	}
+
+	switch hits {
+	case 0:
+		return CacheMiss
+	case len(upstream):
+		return CacheHit
+	default:
+		return CachePartial
+	}
+}
+
+// Redaction selects tool arguments whose values are never written. Rules are
+// "tool.argument", where tool may be "*" to match every tool.
+type Redaction struct {
+	rules map[string]map[string]bool
+}
+
+// NewRedaction compiles redaction rules.
+func NewRedaction(rules []string) (Redaction, error) {
+	r := Redaction{rules: make(map[string]map[string]bool)}
+	for _, rule := range rules {
+		tool, argument, ok := strings.Cut(rule, ".")
+		if !ok || tool == "" || argument == "" {
+			return Redaction{}, fmt.Errorf("invalid redaction rule %q: expected tool.argument", rule)
+		}
+		if r.rules[tool] == nil {
+			r.rules[tool] = make(map[string]bool)
+		}
+		r.rules[tool][argument] = true
+	}
+	return r, nil
+}
+
+// apply returns arguments with redacted values replaced. The input map is
+// not modified.
+func (r Redaction) apply(tool string, arguments map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(arguments))
+	for name, value := range arguments {
+		if r.rules[tool][name] || r.rules["*"][name] {
+			value = RedactedValue
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// Logger writes entries to one or more sinks. It is safe for concurrent use.
+type Logger struct {
+	mu     sync.Mutex
+	sinks  []io.Writer
+	redact Redaction
+	closed bool
+}
+
+// New returns a logger writing to sinks. Sinks that implement io.Closer are
+// closed by Close.
+func New(redact Redaction, sinks ...io.Writer) *Logger {
+	return &Logger{sinks: sinks, redact: redact}
+}
+
+// Log redacts and writes e as a single line to every sink. A failing sink
+// does not prevent writing to the others.
+func (l *Logger) Log(e Entry) error {
+	e.Arguments = l.redact.apply(e.Tool, e.Arguments)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	var errs []error
+	for _, sink := range l.sinks {
+		if _, err := sink.Write(line); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the sinks that implement io.Closer. Later calls to Log fail
+// with ErrClosed.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	var errs []error
+	for _, sink := range l.sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_Log(t *testing.T) {
+	redact, err := NewRedaction(DefaultRedact)
+	if err != nil {
+		t.Fatalf("NewRedaction failed: %v", err)
+	}
+
+	var first, second bytes.Buffer
+	logger := New(redact, &first, &second)
+
+	arguments := map[string]interface{}{"client_ip": "192.0.2.1"}
+	entry := Entry{
+		Time:      time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC),
+		SessionID: "s1",
+		Principal: "alice",
+		Client:    &Client{Name: "claude-ai", Version: "0.1.0"},
+		Tool:      "getWhatsMyIP",
+		Arguments: arguments,
+		Status:    StatusOK,
+		Cache:     CacheMiss,
+		Upstream:  []Upstream{{Endpoint: "whats-my-ip", Cache: CacheMiss, QueryID: "q1", LatencyMS: 12.5}},
+		LatencyMS: 13,
+	}
+	if err := logger.Log(entry); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	if first.String() != second.String() {
+		t.Error("Expected every sink to receive the entry")
+	}
+	if strings.Count(first.String(), "\n") != 1 {
+		t.Errorf("Expected exactly one line, got %q", first.String())
+	}
+	if strings.Contains(first.String(), "192.0.2.1") {
+		t.Errorf("Expected client IP to be redacted, got %s", first.String())
+	}
+	if arguments["client_ip"] != "192.0.2.1" {
+		t.Error("Expected redaction not to modify the caller's arguments")
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(first.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	for _, field := range []string{"time", "session_id", "principal", "client", "tool", "arguments", "status", "cache", "upstream", "latency_ms"} {
+		if _, ok := decoded[field]; !ok {
+			t.Errorf("Expected field %q in %s", field, first.String())
+		}
+	}
+	if decoded["arguments"].(map[string]interface{})["client_ip"] != RedactedValue {
+		t.Errorf("Expected redacted value, got %v", decoded["arguments"])
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := logger.Log(entry); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	redact, err := NewRedaction([]string{"*.resource", "getWhois.resource"})
+	if err != nil {
+		t.Fatalf("NewRedaction failed: %v", err)
+	}
+	got := redact.apply("getNetworkInfo", map[string]interface{}{"resource": "193.0.0.1", "lod": "1"})
+	if got["resource"] != RedactedValue || got["lod"] != "1" {
+		t.Errorf("Unexpected redaction result %v", got)
+	}
+
+	for _, rule := range []string{"getWhois", ".resource", "getWhois."} {
+		if _, err := NewRedaction([]string{rule}); err == nil {
+			t.Errorf("Expected error for rule %q", rule)
+		}
+	}
+}
+
+func TestCacheOutcome(t *testing.T) {
+	tests := []struct {
+		upstream []Upstream
+		want     string
+	}{
+		{nil, CacheNone},
+		{[]Upstream{{Cache: CacheHit}}, CacheHit},
+		{[]Upstream{{Cache: CacheMiss}, {Cache: CacheMiss}}, CacheMiss},
+		{[]Upstream{{Cache: CacheHit}, {Cache: CacheMiss}}, CachePartial},
+	}
+
+	for _, tt := range tests {
+		if got := CacheOutcome(tt.upstream); got != tt.want {
+			t.Errorf("CacheOutcome(%v) = %s, want %s", tt.upstream, got, tt.want)
+		}
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	f, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile failed: %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected backups beyond the limit to be removed")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestRotatingFile_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	f, err := OpenRotatingFile(path, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile failed: %v", err)
+	}
+	if _, err := f.Write([]byte("appended\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "existing\nappended\n" {
+		t.Errorf("Unexpected content %q", data)
+	}
+	if _, err := f.Write([]byte("late\n")); err == nil {
+		t.Error("Expected write after Close to fail")
+	}
+}
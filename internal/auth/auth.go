@@ -0,0 +1,121 @@
+// Package auth authenticates HTTP requests with static API keys or bearer
+// JWTs and describes the resulting principal.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Scopes granted to principals. Each HTTP endpoint requires one of them.
+const (
+	// ScopeMCP allows MCP calls on /mcp.
+	ScopeMCP = "mcp"
+
+	// ScopeMetrics allows reading /metrics, /status and /debug/vars.
+	ScopeMetrics = "metrics"
+
+	// ScopeAdmin allows the /admin endpoints.
+	ScopeAdmin = "admin"
+)
+
+// Scopes lists every known scope.
+var Scopes = []string{ScopeMCP, ScopeMetrics, ScopeAdmin}
+
+// Authentication methods recorded on a Principal.
+const (
+	MethodAPIKey     = "api_key"
+	MethodJWT        = "jwt"
+	MethodAdminToken = "admin_token"
+	MethodClientCert = "client_cert"
+)
+
+var (
+	// ErrNoCredentials means the request carries no credentials that an
+	// authenticator understands.
+	ErrNoCredentials = errors.New("no credentials")
+
+	// ErrInvalidCredentials means the credentials were presented but rejected.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject identifies the caller: the API key name or the JWT subject.
+	Subject string `json:"subject"`
+
+	// Method is the authentication method, for example MethodJWT.
+	Method string `json:"method"`
+
+	// Scopes are the granted scopes.
+	Scopes []string `json:"scopes"`
+
+	// Groups are the groups the caller belongs to, used to select limits.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+// ValidateScopes returns an error for scopes not listed in Scopes.
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return fmt.Errorf("unknown scope %q (known: %s)", scope, strings.Join(Scopes, ", "))
+		}
+	}
+	return nil
+}
+
+// Authenticator identifies the caller of an HTTP request. It returns
+// ErrNoCredentials when the request carries no credentials it handles, so
+// that authenticators can be chained.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain tries each authenticator in order and returns the first principal.
+// When none succeeds, the first error other than ErrNoCredentials is
+// returned, so a JWT rejected by one verifier can still be accepted by
+// another.
+type Chain []Authenticator
+
+// Authenticate implements Authenticator.
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	var firstErr error
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(r)
+		if err == nil {
+			return principal, nil
+		}
+		if firstErr == nil && !errors.Is(err, ErrNoCredentials) {
+			firstErr = err
+		}
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, ErrNoCredentials
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header.
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// looksLikeJWT reports whether token has the three dot-separated parts of a
+// compact JWS.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
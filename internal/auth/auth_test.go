@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	keys, err := NewAPIKeys([]APIKey{
+		{Subject: "ci", Key: "ci-secret", Scopes: []string{ScopeMCP}},
+		{Subject: "prometheus", Key: "scrape-secret", Scopes: []string{ScopeMetrics}},
+	})
+	if err != nil {
+		t.Fatalf("NewAPIKeys failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		subject string
+		err     error
+	}{
+		{name: "api key header", header: APIKeyHeader, value: "ci-secret", subject: "ci"},
+		{name: "bearer token", header: "Authorization", value: "Bearer scrape-secret", subject: "prometheus"},
+		{name: "lowercase bearer", header: "Authorization", value: "bearer ci-secret", subject: "ci"},
+		{name: "unknown key", header: APIKeyHeader, value: "guess", err: ErrInvalidCredentials},
+		{name: "jwt bearer", header: "Authorization", value: "Bearer a.b.c", err: ErrNoCredentials},
+		{name: "basic auth", header: "Authorization", value: "Basic Y2k6c2VjcmV0", err: ErrNoCredentials},
+		{name: "no credentials", err: ErrNoCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			principal, err := keys.Authenticate(req)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if principal.Subject != tt.subject || principal.Method != MethodAPIKey {
+				t.Errorf("Unexpected principal %+v", principal)
+			}
+		})
+	}
+}
+
+func TestNewAPIKeys_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []APIKey
+	}{
+		{name: "missing subject", keys: []APIKey{{Key: "k"}}},
+		{name: "empty key", keys: []APIKey{{Subject: "a"}}},
+		{name: "unknown scope", keys: []APIKey{{Subject: "a", Key: "k", Scopes: []string{"root"}}}},
+		{name: "duplicate key", keys: []APIKey{{Subject: "a", Key: "k"}, {Subject: "b", Key: "k"}}},
+		{name: "duplicate subject", keys: []APIKey{{Subject: "a", Key: "k1"}, {Subject: "a", Key: "k2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAPIKeys(tt.keys); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	keys, err := NewAPIKeys([]APIKey{{Subject: "ci", Key: "ci-secret", Scopes: []string{ScopeMCP}}})
+	if err != nil {
+		t.Fatalf("NewAPIKeys failed: %v", err)
+	}
+	signer := newTestSigner(t, "EdDSA", "k1")
+	chain := Chain{keys, newTestVerifier(t, signer)}
+
+	req := httptest.NewRequest("POST", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer ci-secret")
+	if principal, err := chain.Authenticate(req); err != nil || principal.Subject != "ci" {
+		t.Errorf("Expected API key principal, got %+v, %v", principal, err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+signer.sign(t, validClaims()))
+	if principal, err := chain.Authenticate(req); err != nil || principal.Method != MethodJWT {
+		t.Errorf("Expected JWT principal, got %+v, %v", principal, err)
+	}
+
+	other := newTestSigner(t, "EdDSA", "k1")
+	fallback := Chain{newTestVerifier(t, other), newTestVerifier(t, signer)}
+	if principal, err := fallback.Authenticate(req); err != nil || principal.Subject != "alice" {
+		t.Errorf("Expected second verifier to accept the token, got %+v, %v", principal, err)
+	}
+	if _, err := (Chain{newTestVerifier(t, other)}).Authenticate(req); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+
+	req.Header.Del("Authorization")
+	if _, err := chain.Authenticate(req); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	principal := &Principal{Subject: "a", Scopes: []string{ScopeMCP}}
+	if !principal.HasScope(ScopeMCP) || principal.HasScope(ScopeAdmin) {
+		t.Errorf("Unexpected scopes for %+v", principal)
+	}
+
+	var none *Principal
+	if none.HasScope(ScopeMCP) {
+		t.Error("Expected nil principal to have no scopes")
+	}
+}
+
+func TestClientCertificates_Authenticate(t *testing.T) {
+	certs, err := NewClientCertificates([]string{ScopeMCP}, map[string]ClientCertificate{
+		"ops": {Scopes: []string{ScopeMetrics}, Groups: []string{"operators"}},
+	})
+	if err != nil {
+		t.Fatalf("NewClientCertificates failed: %v", err)
+	}
+
+	withCert := func(subject pkix.Name) *tls.ConnectionState {
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: subject}}}}
+	}
+
+	tests := []struct {
+		name    string
+		state   *tls.ConnectionState
+		subject string
+		scopes  []string
+		groups  []string
+	}{
+		{name: "configured subject", state: withCert(pkix.Name{CommonName: "ops"}), subject: "ops", scopes: []string{ScopeMetrics}, groups: []string{"operators"}},
+		{name: "other subject", state: withCert(pkix.Name{CommonName: "ci"}), subject: "ci", scopes: []string{ScopeMCP}},
+		{name: "no common name", state: withCert(pkix.Name{Organization: []string{"Example"}}), subject: "O=Example", scopes: []string{ScopeMCP}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/mcp", nil)
+			r.TLS = tt.state
+
+			principal, err := certs.Authenticate(r)
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if principal.Subject != tt.subject || principal.Method != MethodClientCert ||
+				!slices.Equal(principal.Scopes, tt.scopes) || !slices.Equal(principal.Groups, tt.groups) {
+				t.Errorf("Unexpected principal %+v", principal)
+			}
+		})
+	}
+
+	// Unverified connections carry no credentials.
+	for _, state := range []*tls.ConnectionState{nil, {}} {
+		r := httptest.NewRequest("GET", "/mcp", nil)
+		r.TLS = state
+		if _, err := certs.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+			t.Errorf("Expected ErrNoCredentials, got %v", err)
+		}
+	}
+
+	if _, err := NewClientCertificates([]string{"root"}, nil); err == nil {
+		t.Error("Expected error for an unknown scope")
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// ClientCertificate grants scopes and groups to the holder of a client
+// certificate.
+type ClientCertificate struct {
+	Scopes []string
+	Groups []string
+}
+
+// ClientCertificates authenticates requests by the TLS client certificate
+// verified during the handshake. The principal is named by the certificate
+// subject common name, or by the full subject when it has none.
+type ClientCertificates struct {
+	// Subjects grants scopes and groups to specific subjects.
+	Subjects map[string]ClientCertificate
+
+	// Scopes are granted to other verified subjects.
+	Scopes []string
+}
+
+// NewClientCertificates returns an authenticator granting scopes to any
+// verified certificate and the Subjects entries to named ones. Scopes must be
+// known.
+func NewClientCertificates(scopes []string, subjects map[string]ClientCertificate) (*ClientCertificates, error) {
+	if err := ValidateScopes(scopes); err != nil {
+		return nil, err
+	}
+	for subject, grant := range subjects {
+		if err := ValidateScopes(grant.Scopes); err != nil {
+			return nil, fmt.Errorf("client %q: %w", subject, err)
+		}
+	}
+	return &ClientCertificates{Subjects: subjects, Scopes: scopes}, nil
+}
+
+// Authenticate implements Authenticator. Connections without a verified
+// client certificate carry no credentials.
+func (c *ClientCertificates) Authenticate(r *http.Request) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+	subject := leaf.Subject.CommonName
+	if subject == "" {
+		subject = leaf.Subject.String()
+	}
+
+	principal := &Principal{Subject: subject, Method: MethodClientCert, Scopes: slices.Clone(c.Scopes)}
+	if grant, ok := c.Subjects[subject]; ok {
+		principal.Scopes = slices.Clone(grant.Scopes)
+		principal.Groups = slices.Clone(grant.Groups)
+	}
+	return principal, nil
+}
@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking token lifetimes.
+const DefaultLeeway = time.Minute
+
+// KeySource provides the keys used to verify JWT signatures.
+type KeySource interface {
+	KeySet(ctx context.Context) (*KeySet, error)
+}
+
+// keyRefresher is implemented by key sources that can refetch their keys,
+// for example after the issuer rotated its signing key.
+type keyRefresher interface {
+	Refresh(ctx context.Context) (*KeySet, error)
+}
+
+// KeySet implements KeySource for a fixed set of keys.
+func (s *KeySet) KeySet(context.Context) (*KeySet, error) {
+	return s, nil
+}
+
+// JWTVerifier authenticates bearer JWTs signed by keys from a JWKS.
+type JWTVerifier struct {
+	// Keys verifies signatures.
+	Keys KeySource
+
+	// Issuer, when set, must equal the "iss" claim.
+	Issuer string
+
+	// Audience, when set, must be listed in the "aud" claim.
+	Audience string
+
+	// Leeway is the tolerated clock skew; zero uses DefaultLeeway.
+	Leeway time.Duration
+
+	// Now returns the current time; nil uses time.Now.
+	Now func() time.Time
+}
+
+// Claims are the registered and scope claims of an access token.
+type Claims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  StringList  `json:"aud"`
+	ExpiresAt NumericDate `json:"exp"`
+	NotBefore NumericDate `json:"nbf"`
+	IssuedAt  NumericDate `json:"iat"`
+
+	// Scope is the space-separated OAuth scope claim.
+	Scope string `json:"scope"`
+
+	// Scp is the list form of the scope claim used by some issuers.
+	Scp StringList `json:"scp"`
+
+	// Groups lists the groups the subject belongs to.
+	Groups StringList `json:"groups"`
+}
+
+// Scopes returns the scopes from the "scope" and "scp" claims.
+func (c *Claims) Scopes() []string {
+	scopes := strings.Fields(c.Scope)
+	for _, entry := range c.Scp {
+		for _, scope := range strings.Fields(entry) {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// StringList is a claim holding either a single string or a list of strings.
+type StringList []string
+
+// UnmarshalJSON accepts a string or an array of strings.
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = StringList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("must be a string or an array of strings")
+	}
+	*l = list
+	return nil
+}
+
+// NumericDate is a JWT time value in seconds since the epoch; zero means the
+// claim is absent.
+type NumericDate float64
+
+// Time returns d as a time.Time.
+func (d NumericDate) Time() time.Time {
+	sec, frac := int64(d), float64(d)-float64(int64(d))
+	return time.Unix(sec, int64(frac*1e9)).UTC()
+}
+
+// jwtHeader is the JOSE header of a compact JWS.
+type jwtHeader struct {
+	Alg  string          `json:"alg"`
+	Kid  string          `json:"kid"`
+	Typ  string          `json:"typ"`
+	Crit json.RawMessage `json:"crit"`
+}
+
+// Authenticate implements Authenticator for bearer tokens shaped like a JWT.
+func (v *JWTVerifier) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := BearerToken(r)
+	if !ok || !looksLikeJWT(token) {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := v.Verify(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Method:  MethodJWT,
+		Scopes:  claims.Scopes(),
+		Groups:  claims.Groups,
+	}, nil
+}
+
+// Verify checks the signature, lifetime, issuer and audience of a compact JWT
+// and returns its claims. Errors wrap ErrInvalidCredentials.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidCredentials, fmt.Sprintf(format, args...))
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalid("malformed header")
+	}
+	if len(header.Crit) > 0 {
+		return nil, invalid("unsupported critical header parameters")
+	}
+
+	hash, ok := signatureHashes[header.Alg]
+	if !ok {
+		return nil, invalid("unsupported algorithm %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+
+	keys, err := v.Keys.KeySet(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	candidates := keys.candidates(header.Kid, header.Alg)
+	if refresher, ok := v.Keys.(keyRefresher); ok && len(candidates) == 0 {
+		if keys, err := refresher.Refresh(ctx); err == nil {
+			candidates = keys.candidates(header.Kid, header.Alg)
+		}
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range candidates {
+		if verifySignature(header.Alg, hash, key, signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, invalid("signature verification failed")
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, invalid("malformed claims: %v", err)
+	}
+
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	return &claims, nil
+}
+
+// checkClaims validates the registered claims.
+func (v *JWTVerifier) checkClaims(claims *Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	leeway := v.Leeway
+	if leeway == 0 {
+		leeway = DefaultLeeway
+	}
+
+	if claims.Subject == "" {
+		return fmt.Errorf("missing subject")
+	}
+	if claims.ExpiresAt == 0 {
+		return fmt.Errorf("missing expiry")
+	}
+	if now.After(claims.ExpiresAt.Time().Add(leeway)) {
+		return fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != 0 && now.Add(leeway).Before(claims.NotBefore.Time()) {
+		return fmt.Errorf("token not yet valid")
+	}
+	if claims.IssuedAt != 0 && now.Add(leeway).Before(claims.IssuedAt.Time()) {
+		return fmt.Errorf("token issued in the future")
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if v.Audience != "" && !slices.Contains(claims.Audience, v.Audience) {
+		return fmt.Errorf("token is not intended for this resource")
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// signatureHashes maps supported JWS algorithms to their hash. Symmetric
+// algorithms and "none" are deliberately absent.
+var signatureHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+	"EdDSA": 0,
+}
+
+// ecdsaCurveSizes maps ECDSA algorithms to their curve size in bits.
+var ecdsaCurveSizes = map[string]int{"ES256": 256, "ES384": 384, "ES512": 521}
+
+// verifySignature checks a JWS signature made with alg by key.
+func verifySignature(alg string, hash crypto.Hash, key crypto.PublicKey, signed, signature []byte) bool {
+	digest := func() []byte {
+		h := hash.New()
+		h.Write(signed)
+		return h.Sum(nil)
+	}
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(pub, hash, digest(), signature) == nil
+		case "PS":
+			return rsa.VerifyPSS(pub, hash, digest(), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+	case *ecdsa.PublicKey:
+		bits, ok := ecdsaCurveSizes[alg]
+		if !ok || pub.Curve.Params().BitSize != bits {
+			return false
+		}
+		size := (bits + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(pub, digest(), r, s)
+	case ed25519.PublicKey:
+		return alg == "EdDSA" && ed25519.Verify(pub, signed, signature)
+	}
+
+	return false
+}
@@ -0,0 +1,337 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+
+// testSigner signs tokens with a generated key and publishes it as a JWK.
+type testSigner struct {
+	alg string
+	kid string
+	key crypto.Signer
+}
+
+func newTestSigner(t *testing.T, alg, kid string) *testSigner {
+	t.Helper()
+
+	var key crypto.Signer
+	var err error
+	switch alg {
+	case "RS256", "PS256":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("unsupported test algorithm %s", alg)
+	}
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	return &testSigner{alg: alg, kid: kid, key: key}
+}
+
+func (s *testSigner) jwk() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: s.kid, Alg: s.alg, Use: "sig"}
+
+	switch pub := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty, jwk.N, jwk.E = "RSA", b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.Kty, jwk.Crv = "EC", "P-256"
+		jwk.X, jwk.Y = b64(pub.X.FillBytes(make([]byte, 32))), b64(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv, jwk.X = "OKP", "Ed25519", b64(pub)
+	}
+
+	return jwk
+}
+
+func (s *testSigner) sign(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": s.alg, "kid": s.kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	var err error
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		if s.alg == "PS256" {
+			signature, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		}
+	case *ecdsa.PrivateKey:
+		var r, sig *big.Int
+		r, sig, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), sig.FillBytes(make([]byte, 32))...)
+		}
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signed))
+	}
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testJWKS(t *testing.T, signers ...*testSigner) []byte {
+	t.Helper()
+
+	doc := map[string][]JWK{"keys": {}}
+	for _, s := range signers {
+		doc["keys"] = append(doc["keys"], s.jwk())
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to encode JWKS: %v", err)
+	}
+	return data
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "alice",
+		"iss":   "https://issuer.example.com",
+		"aud":   "https://mcp.example.com",
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"iat":   testNow.Add(-time.Minute).Unix(),
+		"scope": "mcp metrics",
+	}
+}
+
+func newTestVerifier(t *testing.T, signers ...*testSigner) *JWTVerifier {
+	t.Helper()
+
+	keys, err := ParseJWKS(testJWKS(t, signers...))
+	if err != nil {
+		t.Fatalf("ParseJWKS failed: %v", err)
+	}
+	return &JWTVerifier{
+		Keys:     keys,
+		Issuer:   "https://issuer.example.com",
+		Audience: "https://mcp.example.com",
+		Now:      func() time.Time { return testNow },
+	}
+}
+
+func TestJWTVerifier_Algorithms(t *testing.T) {
+	for _, alg := range []string{"RS256", "PS256", "ES256", "EdDSA"} {
+		t.Run(alg, func(t *testing.T) {
+			signer := newTestSigner(t, alg, "key-"+alg)
+			verifier := newTestVerifier(t, signer)
+
+			claims, err := verifier.Verify(context.Background(), signer.sign(t, validClaims()))
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if claims.Subject != "alice" {
+				t.Errorf("Expected subject alice, got %q", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	signer := newTestSigner(t, "ES256", "current")
+	other := newTestSigner(t, "ES256", "current")
+	verifier := newTestVerifier(t, signer)
+
+	tests := []struct {
+		name   string
+		token  func() string
+		reason string
+	}{
+		{
+			name:   "expired",
+			token:  func() string { c := validClaims(); c["exp"] = testNow.Add(-time.Hour).Unix(); return signer.sign(t, c) },
+			reason: "expired",
+		},
+		{
+			name:   "not yet valid",
+			token:  func() string { c := validClaims(); c["nbf"] = testNow.Add(time.Hour).Unix(); return signer.sign(t, c) },
+			reason: "not yet valid",
+		},
+		{
+			name:   "missing expiry",
+			token:  func() string { c := validClaims(); delete(c, "exp"); return signer.sign(t, c) },
+			reason: "missing expiry",
+		},
+		{
+			name:   "missing subject",
+			token:  func() string { c := validClaims(); delete(c, "sub"); return signer.sign(t, c) },
+			reason: "missing subject",
+		},
+		{
+			name:   "wrong issuer",
+			token:  func() string { c := validClaims(); c["iss"] = "https://evil.example.com"; return signer.sign(t, c) },
+			reason: "unexpected issuer",
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := validClaims()
+				c["aud"] = []string{"https://other.example.com"}
+				return signer.sign(t, c)
+			},
+			reason: "not intended for this resource",
+		},
+		{
+			name:   "unknown signing key",
+			token:  func() string { return other.sign(t, validClaims()) },
+			reason: "signature verification failed",
+		},
+		{
+			name: "tampered claims",
+			token: func() string {
+				parts := strings.Split(signer.sign(t, validClaims()), ".")
+				c := validClaims()
+				c["sub"] = "mallory"
+				payload, _ := json.Marshal(c)
+				parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+				return strings.Join(parts, ".")
+			},
+			reason: "signature verification failed",
+		},
+		{
+			name: "alg none",
+			token: func() string {
+				header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+				payload, _ := json.Marshal(validClaims())
+				return header + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+			},
+			reason: "unsupported algorithm",
+		},
+		{
+			name:   "malformed",
+			token:  func() string { return "a.b.c" },
+			reason: "malformed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), tt.token())
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("Expected error containing %q, got %v", tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_KeyRotation(t *testing.T) {
+	oldKey := newTestSigner(t, "RS256", "old")
+	newKey := newTestSigner(t, "EdDSA", "new")
+	verifier := newTestVerifier(t, oldKey, newKey)
+
+	for _, signer := range []*testSigner{oldKey, newKey} {
+		if _, err := verifier.Verify(context.Background(), signer.sign(t, validClaims())); err != nil {
+			t.Errorf("Expected token signed by %s to verify, got %v", signer.kid, err)
+		}
+	}
+}
+
+func TestJWTVerifier_Authenticate(t *testing.T) {
+	signer := newTestSigner(t, "ES256", "k1")
+	verifier := newTestVerifier(t, signer)
+
+	claims := validClaims()
+	claims["scp"] = []string{"admin"}
+	claims["groups"] = []string{"research"}
+
+	req := httptest.NewRequest("POST", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer "+signer.sign(t, claims))
+
+	principal, err := verifier.Authenticate(req)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if principal.Subject != "alice" || principal.Method != MethodJWT || !slices.Equal(principal.Groups, []string{"research"}) {
+		t.Errorf("Unexpected principal %+v", principal)
+	}
+	for _, scope := range []string{ScopeMCP, ScopeMetrics, ScopeAdmin} {
+		if !principal.HasScope(scope) {
+			t.Errorf("Expected scope %s in %v", scope, principal.Scopes)
+		}
+	}
+
+	req.Header.Set("Authorization", "Bearer opaque-api-key")
+	if _, err := verifier.Authenticate(req); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials for a non-JWT token, got %v", err)
+	}
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, testJWKS(t, newTestSigner(t, "ES256", "k1")), 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+
+	keys, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatalf("LoadJWKSFile failed: %v", err)
+	}
+	if keys.Len() != 1 {
+		t.Errorf("Expected 1 key, got %d", keys.Len())
+	}
+
+	if _, err := LoadJWKSFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestParseJWKS_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "not json", data: "keys", want: "invalid JWKS"},
+		{name: "no keys", data: `{"keys":[]}`, want: "no signing keys"},
+		{name: "only encryption keys", data: `{"keys":[{"kty":"OKP","crv":"Ed25519","use":"enc","x":"AA"}]}`, want: "no signing keys"},
+		{name: "symmetric key", data: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`, want: "unsupported key type"},
+		{name: "small rsa key", data: `{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`, want: "2048 bits"},
+		{name: "point off curve", data: `{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`, want: "not on curve"},
+		{name: "bad ed25519", data: `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AA"}]}`, want: "invalid Ed25519"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJWKS([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for RemoteKeySet.
+const (
+	DefaultJWKSRefreshInterval = time.Hour
+	minJWKSRefreshInterval     = 30 * time.Second
+	maxJWKSResponseSize        = 1 << 20
+
+	// jwksFetchTimeout bounds a whole fetch, discovery included, as it no
+	// longer ends with the request that started it.
+	jwksFetchTimeout = 30 * time.Second
+)
+
+// RemoteKeySet fetches a JWKS over HTTP and caches it. When URL is empty the
+// JWKS location is discovered from the issuer's authorization server metadata
+// (RFC 8414), falling back to OpenID Connect discovery.
+type RemoteKeySet struct {
+	// Issuer is the authorization server issuer identifier.
+	Issuer string
+
+	// URL is the JWKS location; empty enables discovery from Issuer.
+	URL string
+
+	// Client performs the requests; nil uses a client with a 10s timeout.
+	Client *http.Client
+
+	// RefreshInterval is how long fetched keys are used before refetching;
+	// zero uses DefaultJWKSRefreshInterval.
+	RefreshInterval time.Duration
+
+	mu          sync.Mutex
+	keys        *KeySet
+	err         error // Error of the last fetch while no keys are known.
+	jwksURL     string
+	refreshedAt time.Time     // Last fetch attempt.
+	expiresAt   time.Time     // When the keys must be refetched.
+	fetching    chan struct{} // Closed when the fetch in progress ends.
+}
+
+// KeySet implements KeySource, fetching the keys when missing or stale. Stale
+// keys stay in use, and are retried every 30 seconds, when a fetch fails;
+// without keys, a failed fetch is likewise retried every 30 seconds.
+func (s *RemoteKeySet) KeySet(ctx context.Context) (*KeySet, error) {
+	s.mu.Lock()
+
+	if s.keys != nil && time.Now().Before(s.expiresAt) {
+		defer s.mu.Unlock()
+		return s.keys, nil
+	}
+
+	return s.fetchLocked(ctx)
+}
+
+// Refresh refetches the keys, for example after a token names an unknown key
+// ID. Refreshes are limited to one every 30 seconds.
+func (s *RemoteKeySet) Refresh(ctx context.Context) (*KeySet, error) {
+	s.mu.Lock()
+
+	if s.keys != nil && time.Since(s.refreshedAt) < minJWKSRefreshInterval {
+		defer s.mu.Unlock()
+		return s.keys, nil
+	}
+
+	return s.fetchLocked(ctx)
+}
+
+// fetchLocked waits for a fetch of the keys, starting one unless one is in
+// progress, and returns the resulting keys. It is called with s.mu held and
+// releases it. The fetch runs outside the lock with a context detached from
+// ctx, so concurrent callers share it and a canceled request does not abort
+// it for the others.
+func (s *RemoteKeySet) fetchLocked(ctx context.Context) (*KeySet, error) {
+	if s.keys == nil && s.err != nil && time.Since(s.refreshedAt) < minJWKSRefreshInterval {
+		defer s.mu.Unlock()
+		return nil, s.err
+	}
+
+	done := s.fetching
+	if done == nil {
+		done = make(chan struct{})
+		s.fetching = done
+		s.refreshedAt = time.Now()
+		go s.fetchAndStore(context.WithoutCancel(ctx), s.jwksURL, done)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys != nil {
+		return s.keys, nil
+	}
+	return nil, s.err
+}
+
+// fetchAndStore fetches the keys and stores the outcome, then closes done.
+func (s *RemoteKeySet) fetchAndStore(ctx context.Context, jwksURL string, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	keys, jwksURL, err := s.fetch(ctx, jwksURL)
+
+	s.mu.Lock()
+	defer close(done)
+	defer s.mu.Unlock()
+
+	s.fetching = nil
+	s.jwksURL = jwksURL
+	if err != nil {
+		if s.keys != nil {
+			s.expiresAt = time.Now().Add(minJWKSRefreshInterval)
+		} else {
+			s.err = err
+		}
+		return
+	}
+
+	interval := s.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultJWKSRefreshInterval
+	}
+
+	s.keys = keys
+	s.err = nil
+	s.expiresAt = time.Now().Add(interval)
+}
+
+// fetch fetches the keys from jwksURL, discovering it first when empty. It
+// returns the JWKS location used.
+func (s *RemoteKeySet) fetch(ctx context.Context, jwksURL string) (*KeySet, string, error) {
+	if jwksURL == "" {
+		jwksURL = s.URL
+	}
+	if jwksURL == "" {
+		discovered, err := s.discover(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		jwksURL = discovered
+	}
+
+	data, err := s.get(ctx, jwksURL)
+	if err != nil {
+		return nil, jwksURL, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	keys, err := ParseJWKS(data)
+	return keys, jwksURL, err
+}
+
+// discover reads jwks_uri from the issuer's metadata documents.
+func (s *RemoteKeySet) discover(ctx context.Context) (string, error) {
+	if s.Issuer == "" {
+		return "", fmt.Errorf("JWKS discovery requires an issuer")
+	}
+
+	issuer := strings.TrimSuffix(s.Issuer, "/")
+	var lastErr error
+	for _, path := range []string{"/.well-known/oauth-authorization-server", "/.well-known/openid-configuration"} {
+		data, err := s.get(ctx, issuer+path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		var metadata struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			lastErr = fmt.Errorf("invalid metadata: %w", err)
+			continue
+		}
+		if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+			return "", fmt.Errorf("authorization server metadata names issuer %q, expected %q", metadata.Issuer, s.Issuer)
+		}
+		if metadata.JWKSURI == "" {
+			return "", fmt.Errorf("authorization server metadata has no jwks_uri")
+		}
+		return metadata.JWKSURI, nil
+	}
+
+	return "", fmt.Errorf("failed to discover JWKS for issuer %s: %w", s.Issuer, lastErr)
+}
+
+func (s *RemoteKeySet) get(ctx context.Context, url string) ([]byte, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSResponseSize))
+}
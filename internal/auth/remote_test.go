@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// issuerStub is a local authorization server publishing metadata and a JWKS
+// that tests can rotate or break.
+type issuerStub struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	jwks      []byte
+	metaIss   string
+	failJWKS  bool
+	jwksGate  chan struct{} // When set, JWKS requests wait until it is closed.
+	jwksCalls atomic.Int32
+}
+
+func newIssuerStub(t *testing.T, jwks []byte) *issuerStub {
+	t.Helper()
+
+	stub := &issuerStub{jwks: jwks}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, _ *http.Request) {
+		stub.mu.Lock()
+		issuer := stub.metaIss
+		stub.mu.Unlock()
+		if issuer == "" {
+			issuer = stub.URL
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issuer":"` + issuer + `","jwks_uri":"` + stub.URL + `/jwks"}`))
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		stub.jwksCalls.Add(1)
+		stub.mu.Lock()
+		gate := stub.jwksGate
+		stub.mu.Unlock()
+		if gate != nil {
+			<-gate
+		}
+
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		if stub.failJWKS {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(stub.jwks)
+	})
+
+	stub.Server = httptest.NewServer(mux)
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *issuerStub) set(update func(*issuerStub)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(s)
+}
+
+func TestRemoteKeySet_Discovery(t *testing.T) {
+	signer := newTestSigner(t, "ES256", "k1")
+	stub := newIssuerStub(t, testJWKS(t, signer))
+
+	keys := &RemoteKeySet{Issuer: stub.URL}
+	set, err := keys.KeySet(context.Background())
+	if err != nil {
+		t.Fatalf("KeySet failed: %v", err)
+	}
+	if set.Len() != 1 {
+		t.Errorf("Expected 1 key, got %d", set.Len())
+	}
+
+	if _, err := keys.KeySet(context.Background()); err != nil {
+		t.Fatalf("KeySet failed: %v", err)
+	}
+	if calls := stub.jwksCalls.Load(); calls != 1 {
+		t.Errorf("Expected cached keys to be reused, got %d fetches", calls)
+	}
+}
+
+func TestRemoteKeySet_IssuerMismatch(t *testing.T) {
+	stub := newIssuerStub(t, testJWKS(t, newTestSigner(t, "ES256", "k1")))
+	stub.set(func(s *issuerStub) { s.metaIss = "https://evil.example.com" })
+
+	_, err := (&RemoteKeySet{Issuer: stub.URL}).KeySet(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "names issuer") {
+		t.Errorf("Expected issuer mismatch error, got %v", err)
+	}
+}
+
+func TestRemoteKeySet_RotationAndOutage(t *testing.T) {
+	oldKey := newTestSigner(t, "ES256", "old")
+	newKey := newTestSigner(t, "EdDSA", "new")
+	stub := newIssuerStub(t, testJWKS(t, oldKey))
+
+	keys := &RemoteKeySet{Issuer: stub.URL, URL: stub.URL + "/jwks"}
+	verifier := &JWTVerifier{Keys: keys, Issuer: "https://issuer.example.com", Audience: "https://mcp.example.com", Now: func() time.Time { return testNow }}
+
+	if _, err := verifier.Verify(context.Background(), oldKey.sign(t, validClaims())); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// A token naming an unknown key ID triggers a refetch.
+	stub.set(func(s *issuerStub) { s.jwks = testJWKS(t, newKey) })
+	keys.mu.Lock()
+	keys.refreshedAt = time.Time{}
+	keys.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), newKey.sign(t, validClaims())); err != nil {
+		t.Fatalf("Expected rotated key to be fetched, got %v", err)
+	}
+
+	// During an outage the last keys stay in use.
+	stub.set(func(s *issuerStub) { s.failJWKS = true })
+	keys.mu.Lock()
+	keys.expiresAt = time.Time{}
+	keys.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), newKey.sign(t, validClaims())); err != nil {
+		t.Errorf("Expected cached keys during outage, got %v", err)
+	}
+}
+
+func TestRemoteKeySet_SharedFetch(t *testing.T) {
+	stub := newIssuerStub(t, testJWKS(t, newTestSigner(t, "ES256", "k1")))
+	gate := make(chan struct{})
+	stub.set(func(s *issuerStub) { s.jwksGate = gate })
+
+	keys := &RemoteKeySet{URL: stub.URL + "/jwks"}
+
+	// The request starting the fetch gives up, but the fetch carries on for
+	// the others instead of being canceled with it.
+	canceled, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := keys.KeySet(canceled)
+		first <- err
+	}()
+	for stub.jwksCalls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled caller to stop waiting, got %v", err)
+	}
+
+	// The key set is not locked while the fetch is in progress.
+	results := make(chan error, 3)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			_, err := keys.KeySet(context.Background())
+			results <- err
+		}()
+	}
+	close(gate)
+	for i := 0; i < cap(results); i++ {
+		if err := <-results; err != nil {
+			t.Errorf("KeySet failed: %v", err)
+		}
+	}
+	if calls := stub.jwksCalls.Load(); calls != 1 {
+		t.Errorf("Expected concurrent callers to share one fetch, got %d fetches", calls)
+	}
+}
+
+func TestRemoteKeySet_BackoffWithoutKeys(t *testing.T) {
+	stub := newIssuerStub(t, nil)
+	stub.set(func(s *issuerStub) { s.failJWKS = true })
+
+	keys := &RemoteKeySet{URL: stub.URL + "/jwks"}
+	for i := 0; i < 3; i++ {
+		if _, err := keys.KeySet(context.Background()); err == nil {
+			t.Fatal("Expected an error without keys")
+		}
+	}
+	if _, err := keys.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected an error without keys")
+	}
+	if calls := stub.jwksCalls.Load(); calls != 1 {
+		t.Errorf("Expected failed fetches to be retried after a backoff, got %d fetches", calls)
+	}
+
+	// Once the backoff has passed, the keys are fetched again.
+	stub.set(func(s *issuerStub) {
+		s.failJWKS = false
+		s.jwks = testJWKS(t, newTestSigner(t, "ES256", "k1"))
+	})
+	keys.mu.Lock()
+	keys.refreshedAt = time.Time{}
+	keys.mu.Unlock()
+	if _, err := keys.KeySet(context.Background()); err != nil {
+		t.Errorf("Expected keys after the backoff, got %v", err)
+	}
+}
+
+func TestRemoteKeySet_Unavailable(t *testing.T) {
+	stub := newIssuerStub(t, nil)
+	stub.set(func(s *issuerStub) { s.failJWKS = true })
+
+	signer := newTestSigner(t, "ES256", "k1")
+	verifier := &JWTVerifier{Keys: &RemoteKeySet{URL: stub.URL + "/jwks"}, Now: func() time.Time { return testNow }}
+
+	_, err := verifier.Verify(context.Background(), signer.sign(t, validClaims()))
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected key loading error distinct from invalid credentials, got %v", err)
+	}
+}
+
+func TestResourceMetadataURL(t *testing.T) {
+	tests := []struct {
+		resource string
+		want     string
+	}{
+		{"https://mcp.example.com/mcp", "https://mcp.example.com/.well-known/oauth-protected-resource/mcp"},
+		{"https://mcp.example.com", "https://mcp.example.com/.well-known/oauth-protected-resource"},
+		{"https://mcp.example.com/", "https://mcp.example.com/.well-known/oauth-protected-resource"},
+		{"http://localhost:8080/mcp", "http://localhost:8080/.well-known/oauth-protected-resource/mcp"},
+	}
+
+	for _, tt := range tests {
+		got, err := ResourceMetadataURL(tt.resource)
+		if err != nil {
+			t.Errorf("ResourceMetadataURL(%q) failed: %v", tt.resource, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResourceMetadataURL(%q) = %q, want %q", tt.resource, got, tt.want)
+		}
+	}
+
+	for _, resource := range []string{"mcp.example.com", "https://mcp.example.com/mcp#frag", "https://mcp.example.com/?a=b", "ftp://x"} {
+		if _, err := ResourceMetadataURL(resource); err == nil {
+			t.Errorf("Expected error for %q", resource)
+		}
+	}
+}
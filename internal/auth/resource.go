@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ProtectedResourceMetadataPath is the well-known path of the OAuth protected
+// resource metadata document (RFC 9728).
+const ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadata describes this server as an OAuth 2.0 protected
+// resource, telling clients which authorization servers issue its tokens.
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+	ResourceName           string   `json:"resource_name,omitempty"`
+}
+
+// ValidateResource checks that resource is an absolute http or https URL
+// without a query or fragment, as required for resource identifiers.
+func ValidateResource(resource string) error {
+	u, err := url.Parse(resource)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("resource %q must be an absolute http or https URL", resource)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("resource %q must not have a query or fragment", resource)
+	}
+	return nil
+}
+
+// ResourceMetadataURL returns where the metadata for resource is published:
+// the well-known path is inserted between the host and the resource path, so
+// https://mcp.example.com/mcp is described at
+// https://mcp.example.com/.well-known/oauth-protected-resource/mcp.
+func ResourceMetadataURL(resource string) (string, error) {
+	if err := ValidateResource(resource); err != nil {
+		return "", err
+	}
+
+	u, _ := url.Parse(resource)
+	u.Path = ProtectedResourceMetadataPath + strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
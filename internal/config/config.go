@@ -0,0 +1,1021 @@
+// Package config loads the server configuration from a TOML file and
+// environment variables.
+//
+// Values are resolved in order: built-in defaults, the configuration file,
+// then MCP_RIPESTAT_<SECTION>_<KEY> environment variables. Command-line flags
+// are applied on top by the caller.
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/audit"
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/origin"
+	"github.com/taihen/mcp-ripestat/internal/quota"
+	"github.com/taihen/mcp-ripestat/internal/resolve"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+	"github.com/taihen/mcp-ripestat/internal/tracing"
+	"github.com/taihen/mcp-ripestat/internal/warming"
+)
+
+// Config is the complete server configuration.
+type Config struct {
+	Server    ServerConfig    `json:"server"`
+	Transport TransportConfig `json:"transport"`
+	Cache     CacheConfig     `json:"cache"`
+	Warming   WarmingConfig   `json:"warming"`
+	Resolve   ResolveConfig   `json:"resolve"`
+	Limiter   LimiterConfig   `json:"limiter"`
+	Upstream  UpstreamConfig  `json:"upstream"`
+	Tools     ToolsConfig     `json:"tools"`
+	Auth      AuthConfig      `json:"auth"`
+	OAuth     OAuthConfig     `json:"oauth"`
+	Quotas    QuotasConfig    `json:"quotas"`
+	Audit     AuditConfig     `json:"audit"`
+	Tracing   TracingConfig   `json:"tracing"`
+	Health    HealthConfig    `json:"health"`
+	TLS       TLSConfig       `json:"tls"`
+	Unix      UnixConfig      `json:"unix"`
+}
+
+// ServerConfig holds HTTP server settings. Listeners names the listeners to
+// open: "tcp" on Port, "tls" and "unix" as set in their sections. Listeners,
+// Port and ReadHeaderTimeout cannot be changed by a reload.
+type ServerConfig struct {
+	Listeners         []string `json:"listeners"`
+	Port              string   `json:"port"`
+	Debug             bool     `json:"debug"`
+	RequestTimeout    Duration `json:"request_timeout"`
+	ReadHeaderTimeout Duration `json:"read_header_timeout"`
+	ShutdownTimeout   Duration `json:"shutdown_timeout"`
+	AdminToken        string   `json:"admin_token"`
+}
+
+// TransportConfig holds MCP transport settings. OriginMode is "allowlist" to
+// accept the AllowedOrigins patterns or "deny-all" to reject every browser
+// origin.
+type TransportConfig struct {
+	OriginMode       string   `json:"origin_mode"`
+	AllowedOrigins   []string `json:"allowed_origins"`
+	ProtocolVersions []string `json:"protocol_versions"`
+}
+
+// CacheConfig holds response cache settings. TTLs are keyed by endpoint type,
+// for example "whois" or "routing-status". NegativeTTL is how long resources
+// RIPEstat has no data for are remembered; zero disables it.
+type CacheConfig struct {
+	Enabled     bool                `json:"enabled"`
+	DefaultTTL  Duration            `json:"default_ttl"`
+	NegativeTTL Duration            `json:"negative_ttl"`
+	TTLs        map[string]Duration `json:"ttls"`
+}
+
+// WarmingConfig holds cache warming. Watchlist entries are tool calls written
+// as "tool?argument=value&...", repeated RefreshBefore ahead of the expiry of
+// the responses they cache. Warming uses at most MaxConcurrent of the
+// limiter's concurrent requests.
+type WarmingConfig struct {
+	Enabled       bool     `json:"enabled"`
+	Watchlist     []string `json:"watchlist"`
+	RefreshBefore Duration `json:"refresh_before"`
+	MaxConcurrent int      `json:"max_concurrent"`
+}
+
+// ResolveConfig holds hostname resolution for the tools that accept
+// hostnames and URLs in place of IP addresses. Server is the "host:port" of
+// the DNS server to query; empty uses the system resolver. A lookup returns
+// at most MaxAddresses addresses, and the tool runs once for each.
+type ResolveConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Server       string   `json:"server"`
+	Timeout      Duration `json:"timeout"`
+	MaxAddresses int      `json:"max_addresses"`
+}
+
+// LimiterConfig holds upstream concurrency settings.
+type LimiterConfig struct {
+	MaxConcurrent int `json:"max_concurrent"`
+}
+
+// UpstreamConfig holds RIPEstat API client settings.
+type UpstreamConfig struct {
+	BaseURL          string   `json:"base_url"`
+	SourceApp        string   `json:"source_app"`
+	UserAgent        string   `json:"user_agent"`
+	Timeout          Duration `json:"timeout"`
+	RetryCount       int      `json:"retry_count"`
+	RetryWaitTime    Duration `json:"retry_wait_time"`
+	MaxRetryWaitTime Duration `json:"max_retry_wait_time"`
+}
+
+// ToolsConfig selects the exposed tools by name or category.
+type ToolsConfig struct {
+	Allow    []string `json:"allow"`
+	Deny     []string `json:"deny"`
+	PageSize int      `json:"page_size"`
+	// MaxResultBytes limits the JSON text of a tool result; longer results
+	// are paginated or truncated. Zero disables the limit.
+	MaxResultBytes int `json:"max_result_bytes"`
+}
+
+// AuthConfig holds authentication settings. When enabled, /mcp requires the
+// "mcp" scope and /metrics, /status and /debug/vars require "metrics". The
+// /admin endpoints always require "admin", which the server admin token
+// grants as well.
+type AuthConfig struct {
+	Enabled     bool                    `json:"enabled"`
+	APIKeys     map[string]APIKeyConfig `json:"api_keys"`
+	JWKSFile    string                  `json:"jwks_file"`
+	JWTIssuer   string                  `json:"jwt_issuer"`
+	JWTAudience string                  `json:"jwt_audience"`
+}
+
+// APIKeyConfig is a static API key, keyed by principal name in
+// AuthConfig.APIKeys. Keys without scopes are granted "mcp". Groups select
+// quota limits.
+type APIKeyConfig struct {
+	Key    string   `json:"key"`
+	Scopes []string `json:"scopes"`
+	Groups []string `json:"groups"`
+}
+
+// OAuthConfig makes the server an OAuth 2.0 protected resource. When enabled,
+// bearer JWTs from Issuer are accepted only if their audience is Resource,
+// and the metadata document naming AuthorizationServers is published.
+type OAuthConfig struct {
+	Enabled              bool     `json:"enabled"`
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	Issuer               string   `json:"issuer"`
+	JWKSURL              string   `json:"jwks_url"`
+	JWKSRefresh          Duration `json:"jwks_refresh"`
+}
+
+// QuotasConfig holds per-caller rate limits and daily quotas on tool calls.
+// Callers are authenticated principals, or sessions and client addresses when
+// unauthenticated. Zero disables a limit.
+type QuotasConfig struct {
+	Enabled       bool                   `json:"enabled"`
+	RatePerMinute int                    `json:"rate_per_minute"`
+	Burst         int                    `json:"burst"`
+	DailyQuota    int                    `json:"daily_quota"`
+	Groups        map[string]QuotaLimits `json:"groups"`
+	Principals    map[string]QuotaLimits `json:"principals"`
+}
+
+// QuotaLimits overrides the default limits for a group or principal. Unset
+// fields keep the defaults.
+type QuotaLimits struct {
+	RatePerMinute *int `json:"rate_per_minute"`
+	Burst         *int `json:"burst"`
+	DailyQuota    *int `json:"daily_quota"`
+}
+
+// Audit sinks.
+const (
+	AuditSinkStdout = "stdout"
+	AuditSinkFile   = "file"
+)
+
+// AuditConfig holds the tool call audit log. Sinks are "stdout" and "file";
+// the file is rotated at MaxSizeMB, keeping MaxBackups old files. Redact lists
+// "tool.argument" values that are never written.
+type AuditConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Sinks      []string `json:"sinks"`
+	File       string   `json:"file"`
+	MaxSizeMB  int      `json:"max_size_mb"`
+	MaxBackups int      `json:"max_backups"`
+	Redact     []string `json:"redact"`
+}
+
+// Trace exporters.
+const (
+	TraceExporterStdout = "stdout"
+	TraceExporterOTLP   = "otlp"
+)
+
+// TracingConfig holds OpenTelemetry tracing. Exporter is "stdout" or "otlp";
+// the OTLP exporter posts JSON to Endpoint with Headers added to every
+// request.
+type TracingConfig struct {
+	Enabled     bool              `json:"enabled"`
+	Exporter    string            `json:"exporter"`
+	Endpoint    string            `json:"endpoint"`
+	Headers     map[string]string `json:"headers"`
+	ServiceName string            `json:"service_name"`
+}
+
+// HealthConfig holds the readiness checks. The upstream probe runs at most
+// once per ProbeInterval; readiness fails when more than MaxErrorRate of at
+// least MinRequests upstream requests in ErrorWindow failed.
+type HealthConfig struct {
+	Probe         bool     `json:"probe"`
+	ProbeInterval Duration `json:"probe_interval"`
+	ProbeTimeout  Duration `json:"probe_timeout"`
+	ErrorWindow   Duration `json:"error_window"`
+	MaxErrorRate  float64  `json:"max_error_rate"`
+	MinRequests   int      `json:"min_requests"`
+}
+
+// Listeners.
+const (
+	ListenerTCP  = "tcp"
+	ListenerTLS  = "tls"
+	ListenerUnix = "unix"
+)
+
+// TLS client authentication modes.
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// TLSConfig holds the TLS listener. The certificate files are reloaded when
+// they change. ClientAuth is "none", "optional" or "require"; client
+// certificates are verified against ClientCAFile and, when authentication is
+// required, authenticate as the principal named by their subject common name
+// with the grants of the matching Clients entry, or ClientScopes. Only
+// ClientScopes and Clients can be changed by a reload.
+type TLSConfig struct {
+	Port         string                      `json:"port"`
+	CertFile     string                      `json:"cert_file"`
+	KeyFile      string                      `json:"key_file"`
+	ClientCAFile string                      `json:"client_ca_file"`
+	ClientAuth   string                      `json:"client_auth"`
+	ClientScopes []string                    `json:"client_scopes"`
+	Clients      map[string]ClientCertConfig `json:"clients"`
+}
+
+// ClientCertConfig grants scopes and groups to a client certificate subject,
+// keyed by common name in TLSConfig.Clients. Subjects without scopes are
+// granted "mcp".
+type ClientCertConfig struct {
+	Scopes []string `json:"scopes"`
+	Groups []string `json:"groups"`
+}
+
+// ClientAuthType returns the crypto/tls client authentication policy.
+func (c TLSConfig) ClientAuthType() tls.ClientAuthType {
+	switch c.ClientAuth {
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert
+	}
+}
+
+// UnixConfig holds the Unix domain socket listener. Mode is the octal file
+// mode of the socket, such as "0660". It cannot be changed by a reload.
+type UnixConfig struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+}
+
+// FileMode parses Mode.
+func (c UnixConfig) FileMode() (fs.FileMode, error) {
+	mode, err := strconv.ParseUint(c.Mode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid file mode %q", c.Mode)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// Duration is a time.Duration written as a Go duration string such as "90s".
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\"")
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON formats the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Std returns d as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	sourceApp := os.Getenv("RIPE_SOURCE_APP")
+	if sourceApp == "" {
+		sourceApp = ripeconfig.DefaultSourceApp
+	}
+
+	ttls := make(map[string]Duration, len(cache.DefaultTTLs))
+	for endpoint, ttl := range cache.DefaultTTLs {
+		ttls[endpoint] = Duration(ttl)
+	}
+
+	return &Config{
+		Server: ServerConfig{
+			Listeners:         []string{ListenerTCP},
+			Port:              "8080",
+			RequestTimeout:    Duration(60 * time.Second),
+			ReadHeaderTimeout: Duration(10 * time.Second),
+			ShutdownTimeout:   Duration(10 * time.Second),
+			AdminToken:        os.Getenv("MCP_ADMIN_TOKEN"),
+		},
+		Transport: TransportConfig{
+			OriginMode: origin.ModeAllowlist,
+			AllowedOrigins: []string{
+				"http://localhost",
+				"https://localhost",
+				"http://127.0.0.1",
+				"https://127.0.0.1",
+				"https://cursor.sh",
+				"https://claude.ai",
+			},
+			ProtocolVersions: []string{
+				"2025-06-18",
+				"2025-03-26", // Backward compatibility.
+			},
+		},
+		Cache: CacheConfig{
+			Enabled:     true,
+			DefaultTTL:  Duration(cache.DefaultFallbackTTL),
+			NegativeTTL: Duration(cache.DefaultNegativeTTL),
+			TTLs:        ttls,
+		},
+		Warming: WarmingConfig{
+			Watchlist:     []string{},
+			RefreshBefore: Duration(time.Minute),
+			MaxConcurrent: 1,
+		},
+		Resolve: ResolveConfig{
+			Timeout:      Duration(resolve.DefaultTimeout),
+			MaxAddresses: resolve.DefaultMaxAddresses,
+		},
+		Limiter: LimiterConfig{
+			MaxConcurrent: client.DefaultMaxConcurrentRequests,
+		},
+		Upstream: UpstreamConfig{
+			BaseURL:          ripeconfig.DefaultBaseURL,
+			SourceApp:        sourceApp,
+			UserAgent:        ripeconfig.DefaultUserAgent,
+			Timeout:          Duration(ripeconfig.DefaultTimeout),
+			RetryCount:       ripeconfig.DefaultRetryCount,
+			RetryWaitTime:    Duration(ripeconfig.DefaultRetryWaitTime),
+			MaxRetryWaitTime: Duration(ripeconfig.DefaultMaxRetryWaitTime),
+		},
+		Tools: ToolsConfig{
+			PageSize:       50,
+			MaxResultBytes: 256 << 10,
+		},
+		OAuth: OAuthConfig{
+			JWKSRefresh: Duration(auth.DefaultJWKSRefreshInterval),
+		},
+		Quotas: QuotasConfig{
+			RatePerMinute: 60,
+			Burst:         10,
+			DailyQuota:    10000,
+		},
+		Audit: AuditConfig{
+			Sinks:      []string{AuditSinkStdout},
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			Redact:     slices.Clone(audit.DefaultRedact),
+		},
+		Tracing: TracingConfig{
+			Exporter:    TraceExporterOTLP,
+			Endpoint:    tracing.DefaultOTLPEndpoint,
+			ServiceName: "mcp-ripestat",
+		},
+		Health: HealthConfig{
+			Probe:         true,
+			ProbeInterval: Duration(30 * time.Second),
+			ProbeTimeout:  Duration(5 * time.Second),
+			ErrorWindow:   Duration(5 * time.Minute),
+			MaxErrorRate:  0.5,
+			MinRequests:   10,
+		},
+		TLS: TLSConfig{
+			Port:         "8443",
+			ClientAuth:   ClientAuthNone,
+			ClientScopes: []string{auth.ScopeMCP},
+		},
+		Unix: UnixConfig{
+			Mode: "0660",
+		},
+	}
+}
+
+// Load reads the configuration file at path on top of the defaults and
+// applies environment overrides. An empty path skips the file.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := cfg.decodeTOML(data); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// decodeTOML merges TOML data into cfg, rejecting unknown keys.
+func (c *Config) decodeTOML(data []byte) error {
+	tree, err := parseTOML(data)
+	if err != nil {
+		return err
+	}
+
+	// Round-trip through JSON so that struct tags, unknown key detection and
+	// Duration parsing are shared with the rest of the code.
+	encoded, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// protocolVersionPattern matches MCP protocol version dates.
+var protocolVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// Validate checks the configuration for values the server cannot run with.
+func (c *Config) Validate() error {
+	port, err := strconv.Atoi(c.Server.Port)
+	if err != nil || port < 0 || port > 65535 {
+		return fmt.Errorf("server.port: invalid port %q", c.Server.Port)
+	}
+
+	if err := c.validateListeners(); err != nil {
+		return err
+	}
+
+	durations := map[string]Duration{
+		"server.request_timeout":       c.Server.RequestTimeout,
+		"server.read_header_timeout":   c.Server.ReadHeaderTimeout,
+		"server.shutdown_timeout":      c.Server.ShutdownTimeout,
+		"cache.default_ttl":            c.Cache.DefaultTTL,
+		"warming.refresh_before":       c.Warming.RefreshBefore,
+		"resolve.timeout":              c.Resolve.Timeout,
+		"upstream.timeout":             c.Upstream.Timeout,
+		"upstream.retry_wait_time":     c.Upstream.RetryWaitTime,
+		"upstream.max_retry_wait_time": c.Upstream.MaxRetryWaitTime,
+		"oauth.jwks_refresh":           c.OAuth.JWKSRefresh,
+		"health.probe_interval":        c.Health.ProbeInterval,
+		"health.probe_timeout":         c.Health.ProbeTimeout,
+		"health.error_window":          c.Health.ErrorWindow,
+	}
+	for endpoint, ttl := range c.Cache.TTLs {
+		durations["cache.ttls."+endpoint] = ttl
+	}
+	for name, d := range durations {
+		if d <= 0 {
+			return fmt.Errorf("%s: must be positive", name)
+		}
+	}
+	if c.Cache.NegativeTTL < 0 {
+		return fmt.Errorf("cache.negative_ttl: must not be negative")
+	}
+
+	switch c.Transport.OriginMode {
+	case origin.ModeAllowlist, origin.ModeDenyAll:
+	default:
+		return fmt.Errorf("transport.origin_mode: must be %q or %q", origin.ModeAllowlist, origin.ModeDenyAll)
+	}
+	if _, err := c.OriginPolicy(); err != nil {
+		return fmt.Errorf("transport.allowed_origins: %w", err)
+	}
+
+	if len(c.Transport.ProtocolVersions) == 0 {
+		return fmt.Errorf("transport.protocol_versions: at least one version is required")
+	}
+	for _, version := range c.Transport.ProtocolVersions {
+		if !protocolVersionPattern.MatchString(version) {
+			return fmt.Errorf("transport.protocol_versions: invalid version %q", version)
+		}
+	}
+
+	if c.Limiter.MaxConcurrent < 1 || c.Limiter.MaxConcurrent > client.MaxConcurrentRequestsLimit {
+		return fmt.Errorf("limiter.max_concurrent: must be between 1 and %d", client.MaxConcurrentRequestsLimit)
+	}
+
+	if err := c.validateWarming(); err != nil {
+		return err
+	}
+
+	if c.Resolve.Server != "" {
+		if err := resolve.ValidateServer(c.Resolve.Server); err != nil {
+			return fmt.Errorf("resolve.server: %w", err)
+		}
+	}
+	if c.Resolve.MaxAddresses < 1 {
+		return fmt.Errorf("resolve.max_addresses: must be positive")
+	}
+
+	u, err := url.Parse(c.Upstream.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("upstream.base_url: invalid URL %q", c.Upstream.BaseURL)
+	}
+	if c.Upstream.RetryCount < 0 {
+		return fmt.Errorf("upstream.retry_count: must not be negative")
+	}
+
+	if c.Tools.PageSize < 1 {
+		return fmt.Errorf("tools.page_size: must be positive")
+	}
+	if c.Tools.MaxResultBytes < 0 {
+		return fmt.Errorf("tools.max_result_bytes: must not be negative")
+	}
+
+	clientCerts := slices.Contains(c.Server.Listeners, ListenerTLS) && c.TLS.ClientAuth != ClientAuthNone
+	if c.Auth.Enabled && len(c.Auth.APIKeys) == 0 && c.Auth.JWKSFile == "" && !c.OAuth.Enabled && !clientCerts {
+		return fmt.Errorf("auth: enabled without api_keys, jwks_file, oauth or tls client certificates")
+	}
+	if _, ok := c.Auth.APIKeys[adminSubject]; ok && c.Server.AdminToken != "" {
+		return fmt.Errorf("auth.api_keys: %q is reserved for server.admin_token", adminSubject)
+	}
+	if _, err := auth.NewAPIKeys(c.apiKeys()); err != nil {
+		return fmt.Errorf("auth.api_keys: %w", err)
+	}
+	// JWTs are tried against every verifier, so a token rejected for its
+	// audience by one must not be accepted by another that checks none.
+	if c.Auth.JWKSFile != "" && c.Auth.JWTAudience == "" {
+		return fmt.Errorf("auth.jwt_audience: required by jwks_file")
+	}
+
+	if c.OAuth.Enabled {
+		if err := auth.ValidateResource(c.OAuth.Resource); err != nil {
+			return fmt.Errorf("oauth.resource: %w", err)
+		}
+		if len(c.OAuth.AuthorizationServers) == 0 {
+			return fmt.Errorf("oauth.authorization_servers: at least one server is required")
+		}
+		for _, server := range append(slices.Clone(c.OAuth.AuthorizationServers), c.OAuth.Issuer, c.OAuth.JWKSURL) {
+			if server == "" {
+				continue
+			}
+			if u, err := url.Parse(server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("oauth: invalid URL %q", server)
+			}
+		}
+	}
+
+	if err := c.validateQuotas(); err != nil {
+		return err
+	}
+
+	if err := c.validateAudit(); err != nil {
+		return err
+	}
+
+	switch c.Tracing.Exporter {
+	case TraceExporterStdout:
+	case TraceExporterOTLP:
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing.endpoint: invalid URL %q", c.Tracing.Endpoint)
+		}
+	default:
+		return fmt.Errorf("tracing.exporter: unknown exporter %q (known: %s, %s)", c.Tracing.Exporter, TraceExporterStdout, TraceExporterOTLP)
+	}
+	if c.Tracing.ServiceName == "" {
+		return fmt.Errorf("tracing.service_name: must not be empty")
+	}
+
+	if c.Health.ErrorWindow.Std() > client.MaxOutcomeWindow {
+		return fmt.Errorf("health.error_window: must not exceed %s", client.MaxOutcomeWindow)
+	}
+	if c.Health.MaxErrorRate < 0 || c.Health.MaxErrorRate > 1 {
+		return fmt.Errorf("health.max_error_rate: must be between 0 and 1")
+	}
+	if c.Health.MinRequests < 1 {
+		return fmt.Errorf("health.min_requests: must be positive")
+	}
+
+	return nil
+}
+
+// validateListeners checks the listeners and the settings they require.
+func (c *Config) validateListeners() error {
+	if len(c.Server.Listeners) == 0 {
+		return fmt.Errorf("server.listeners: at least one listener is required")
+	}
+	seen := make(map[string]bool, len(c.Server.Listeners))
+	for _, listener := range c.Server.Listeners {
+		if seen[listener] {
+			return fmt.Errorf("server.listeners: duplicate listener %q", listener)
+		}
+		seen[listener] = true
+
+		switch listener {
+		case ListenerTCP, ListenerTLS, ListenerUnix:
+		default:
+			return fmt.Errorf("server.listeners: unknown listener %q (known: %s, %s, %s)", listener, ListenerTCP, ListenerTLS, ListenerUnix)
+		}
+	}
+
+	if seen[ListenerTLS] {
+		port, err := strconv.Atoi(c.TLS.Port)
+		if err != nil || port < 0 || port > 65535 {
+			return fmt.Errorf("tls.port: invalid port %q", c.TLS.Port)
+		}
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			return fmt.Errorf("tls: cert_file and key_file are required by the %q listener", ListenerTLS)
+		}
+	}
+	switch c.TLS.ClientAuth {
+	case ClientAuthNone:
+	case ClientAuthOptional, ClientAuthRequire:
+		if c.TLS.ClientCAFile == "" {
+			return fmt.Errorf("tls.client_ca_file: required by client_auth %q", c.TLS.ClientAuth)
+		}
+	default:
+		return fmt.Errorf("tls.client_auth: must be %q, %q or %q", ClientAuthNone, ClientAuthOptional, ClientAuthRequire)
+	}
+	if _, err := c.clientCertificates(); err != nil {
+		return fmt.Errorf("tls.clients: %w", err)
+	}
+
+	if seen[ListenerUnix] && c.Unix.Path == "" {
+		return fmt.Errorf("unix.path: required by the %q listener", ListenerUnix)
+	}
+	if _, err := c.Unix.FileMode(); err != nil {
+		return fmt.Errorf("unix.mode: %w", err)
+	}
+	return nil
+}
+
+// validateAudit checks the audit sinks, rotation and redaction rules.
+func (c *Config) validateAudit() error {
+	for _, sink := range c.Audit.Sinks {
+		switch sink {
+		case AuditSinkStdout:
+		case AuditSinkFile:
+			if c.Audit.File == "" {
+				return fmt.Errorf("audit.file: required by the %q sink", AuditSinkFile)
+			}
+		default:
+			return fmt.Errorf("audit.sinks: unknown sink %q (known: %s, %s)", sink, AuditSinkStdout, AuditSinkFile)
+		}
+	}
+	if c.Audit.Enabled && len(c.Audit.Sinks) == 0 {
+		return fmt.Errorf("audit.sinks: at least one sink is required")
+	}
+	if c.Audit.MaxSizeMB < 0 {
+		return fmt.Errorf("audit.max_size_mb: must not be negative")
+	}
+	if c.Audit.MaxBackups < 0 {
+		return fmt.Errorf("audit.max_backups: must not be negative")
+	}
+	if _, err := audit.NewRedaction(c.Audit.Redact); err != nil {
+		return fmt.Errorf("audit.redact: %w", err)
+	}
+	return nil
+}
+
+// AuditLogger opens the configured audit sinks. It returns nil when auditing
+// is disabled; the caller closes the logger.
+func (c *Config) AuditLogger() (*audit.Logger, error) {
+	if !c.Audit.Enabled {
+		return nil, nil
+	}
+
+	redact, err := audit.NewRedaction(c.Audit.Redact)
+	if err != nil {
+		return nil, fmt.Errorf("audit.redact: %w", err)
+	}
+
+	var sinks []io.Writer
+	for _, sink := range c.Audit.Sinks {
+		switch sink {
+		case AuditSinkStdout:
+			// Wrapped so that closing the logger leaves stdout open.
+			sinks = append(sinks, struct{ io.Writer }{os.Stdout})
+		case AuditSinkFile:
+			file, err := audit.OpenRotatingFile(c.Audit.File, int64(c.Audit.MaxSizeMB)<<20, c.Audit.MaxBackups)
+			if err != nil {
+				_ = audit.New(redact, sinks...).Close()
+				return nil, fmt.Errorf("audit.file: %w", err)
+			}
+			sinks = append(sinks, file)
+		}
+	}
+
+	return audit.New(redact, sinks...), nil
+}
+
+// Tracer returns a tracer for the configured exporter, or nil when tracing
+// is disabled. The caller shuts the tracer down.
+func (c *Config) Tracer() *tracing.Tracer {
+	if !c.Tracing.Enabled {
+		return nil
+	}
+
+	if c.Tracing.Exporter == TraceExporterStdout {
+		return tracing.NewTracer(tracing.NewWriterExporter(os.Stdout))
+	}
+	return tracing.NewTracer(tracing.NewOTLPExporter(c.Tracing.Endpoint, c.Tracing.ServiceName, maps.Clone(c.Tracing.Headers)))
+}
+
+// validateWarming checks the watchlist and that warming leaves part of the
+// limiter to tool calls.
+func (c *Config) validateWarming() error {
+	for _, entry := range c.Warming.Watchlist {
+		if _, err := warming.ParseTarget(entry); err != nil {
+			return fmt.Errorf("warming.watchlist: %w", err)
+		}
+	}
+	if c.Warming.MaxConcurrent < 1 {
+		return fmt.Errorf("warming.max_concurrent: must be positive")
+	}
+	if !c.Warming.Enabled {
+		return nil
+	}
+	if !c.Cache.Enabled {
+		return fmt.Errorf("warming: requires cache.enabled")
+	}
+	if c.Warming.MaxConcurrent >= c.Limiter.MaxConcurrent {
+		return fmt.Errorf("warming.max_concurrent: must be below limiter.max_concurrent (%d)", c.Limiter.MaxConcurrent)
+	}
+	return nil
+}
+
+// validateQuotas rejects negative limits.
+func (c *Config) validateQuotas() error {
+	limits := map[string]*int{
+		"quotas.rate_per_minute": &c.Quotas.RatePerMinute,
+		"quotas.burst":           &c.Quotas.Burst,
+		"quotas.daily_quota":     &c.Quotas.DailyQuota,
+	}
+	for section, overrides := range map[string]map[string]QuotaLimits{"groups": c.Quotas.Groups, "principals": c.Quotas.Principals} {
+		for name, override := range overrides {
+			prefix := "quotas." + section + "." + name + "."
+			limits[prefix+"rate_per_minute"] = override.RatePerMinute
+			limits[prefix+"burst"] = override.Burst
+			limits[prefix+"daily_quota"] = override.DailyQuota
+		}
+	}
+
+	for name, limit := range limits {
+		if limit != nil && *limit < 0 {
+			return fmt.Errorf("%s: must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// WarmingTargets returns the parsed watchlist, or nil when warming is
+// disabled.
+func (c *Config) WarmingTargets() ([]warming.Target, error) {
+	if !c.Warming.Enabled {
+		return nil, nil
+	}
+
+	targets := make([]warming.Target, 0, len(c.Warming.Watchlist))
+	for _, entry := range c.Warming.Watchlist {
+		target, err := warming.ParseTarget(entry)
+		if err != nil {
+			return nil, fmt.Errorf("warming.watchlist: %w", err)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// Resolver returns the hostname resolver, or nil when resolution is
+// disabled.
+func (c *Config) Resolver() (*resolve.Resolver, error) {
+	if !c.Resolve.Enabled {
+		return nil, nil
+	}
+
+	resolver, err := resolve.New(resolve.Options{
+		Server:       c.Resolve.Server,
+		Timeout:      c.Resolve.Timeout.Std(),
+		MaxAddresses: c.Resolve.MaxAddresses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resolve.server: %w", err)
+	}
+	return resolver, nil
+}
+
+// QuotaPolicy returns the tool call limits, with group and principal
+// overrides merged onto the defaults.
+func (c *Config) QuotaPolicy() quota.Policy {
+	defaults := quota.Limits{
+		RatePerMinute: c.Quotas.RatePerMinute,
+		Burst:         c.Quotas.Burst,
+		Daily:         c.Quotas.DailyQuota,
+	}
+
+	merge := func(overrides map[string]QuotaLimits) map[string]quota.Limits {
+		merged := make(map[string]quota.Limits, len(overrides))
+		for name, override := range overrides {
+			limits := defaults
+			if override.RatePerMinute != nil {
+				limits.RatePerMinute = *override.RatePerMinute
+			}
+			if override.Burst != nil {
+				limits.Burst = *override.Burst
+			}
+			if override.DailyQuota != nil {
+				limits.Daily = *override.DailyQuota
+			}
+			merged[name] = limits
+		}
+		return merged
+	}
+
+	return quota.Policy{
+		Enabled:    c.Quotas.Enabled,
+		Default:    defaults,
+		Groups:     merge(c.Quotas.Groups),
+		Principals: merge(c.Quotas.Principals),
+	}
+}
+
+// OriginPolicy compiles the transport origin policy.
+func (c *Config) OriginPolicy() (*origin.Policy, error) {
+	return origin.NewPolicy(c.Transport.OriginMode, c.Transport.AllowedOrigins)
+}
+
+// Authenticator builds the authenticator for the configured API keys, JWKS
+// file and admin token. The JWKS file is read on every call.
+func (c *Config) Authenticator() (auth.Chain, error) {
+	keys, err := auth.NewAPIKeys(c.apiKeys())
+	if err != nil {
+		return nil, fmt.Errorf("auth.api_keys: %w", err)
+	}
+	chain := auth.Chain{keys}
+
+	if c.OAuth.Enabled {
+		chain = append(chain, &auth.JWTVerifier{
+			Keys: &auth.RemoteKeySet{
+				Issuer:          c.OAuthIssuer(),
+				URL:             c.OAuth.JWKSURL,
+				RefreshInterval: c.OAuth.JWKSRefresh.Std(),
+			},
+			Issuer:   c.OAuthIssuer(),
+			Audience: c.OAuth.Resource,
+		})
+	}
+
+	if c.Auth.JWKSFile != "" {
+		keySet, err := auth.LoadJWKSFile(c.Auth.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth.jwks_file: %w", err)
+		}
+		chain = append(chain, &auth.JWTVerifier{
+			Keys:     keySet,
+			Issuer:   c.Auth.JWTIssuer,
+			Audience: c.Auth.JWTAudience,
+		})
+	}
+
+	if slices.Contains(c.Server.Listeners, ListenerTLS) && c.TLS.ClientAuth != ClientAuthNone {
+		certs, err := c.clientCertificates()
+		if err != nil {
+			return nil, fmt.Errorf("tls.clients: %w", err)
+		}
+		chain = append(chain, certs)
+	}
+
+	return chain, nil
+}
+
+// clientCertificates returns the authenticator for TLS client certificates.
+func (c *Config) clientCertificates() (*auth.ClientCertificates, error) {
+	subjects := make(map[string]auth.ClientCertificate, len(c.TLS.Clients))
+	for subject, client := range c.TLS.Clients {
+		scopes := client.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{auth.ScopeMCP}
+		}
+		subjects[subject] = auth.ClientCertificate{Scopes: scopes, Groups: client.Groups}
+	}
+	return auth.NewClientCertificates(c.TLS.ClientScopes, subjects)
+}
+
+// OAuthIssuer returns the expected token issuer: oauth.issuer, or the first
+// authorization server when unset.
+func (c *Config) OAuthIssuer() string {
+	if c.OAuth.Issuer != "" || len(c.OAuth.AuthorizationServers) == 0 {
+		return c.OAuth.Issuer
+	}
+	return c.OAuth.AuthorizationServers[0]
+}
+
+// ResourceMetadata returns the protected resource metadata document, or nil
+// when OAuth is disabled.
+func (c *Config) ResourceMetadata() *auth.ProtectedResourceMetadata {
+	if !c.OAuth.Enabled {
+		return nil
+	}
+	return &auth.ProtectedResourceMetadata{
+		Resource:               c.OAuth.Resource,
+		AuthorizationServers:   slices.Clone(c.OAuth.AuthorizationServers),
+		ScopesSupported:        slices.Clone(auth.Scopes),
+		BearerMethodsSupported: []string{"header"},
+		ResourceName:           "mcp-ripestat",
+	}
+}
+
+// adminSubject is the principal authenticated by server.admin_token.
+const adminSubject = "admin"
+
+// apiKeys returns the configured API keys, sorted by name, followed by the
+// admin token.
+func (c *Config) apiKeys() []auth.APIKey {
+	names := make([]string, 0, len(c.Auth.APIKeys))
+	for name := range c.Auth.APIKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keys := make([]auth.APIKey, 0, len(names)+1)
+	for _, name := range names {
+		key := c.Auth.APIKeys[name]
+		scopes := key.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{auth.ScopeMCP}
+		}
+		keys = append(keys, auth.APIKey{Subject: name, Key: key.Key, Scopes: scopes, Groups: key.Groups})
+	}
+
+	if c.Server.AdminToken != "" {
+		keys = append(keys, auth.APIKey{
+			Subject: adminSubject,
+			Key:     c.Server.AdminToken,
+			Scopes:  []string{auth.ScopeAdmin},
+			Method:  auth.MethodAdminToken,
+		})
+	}
+
+	return keys
+}
+
+// UpstreamClientConfig returns the RIPEstat client configuration.
+func (c *Config) UpstreamClientConfig() *ripeconfig.Config {
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = c.Upstream.BaseURL
+	cfg.SourceApp = c.Upstream.SourceApp
+	cfg.UserAgent = c.Upstream.UserAgent
+	cfg.Timeout = c.Upstream.Timeout.Std()
+	cfg.RetryCount = c.Upstream.RetryCount
+	cfg.RetryWaitTime = c.Upstream.RetryWaitTime.Std()
+	cfg.MaxRetryWaitTime = c.Upstream.MaxRetryWaitTime.Std()
+	cfg.DisableCache = !c.Cache.Enabled
+	return cfg
+}
+
+// CacheTTLs returns the per-endpoint cache TTLs.
+func (c *Config) CacheTTLs() map[string]time.Duration {
+	ttls := make(map[string]time.Duration, len(c.Cache.TTLs))
+	for endpoint, ttl := range c.Cache.TTLs {
+		ttls[endpoint] = ttl.Std()
+	}
+	return ttls
+}
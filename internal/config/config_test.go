@@ -0,0 +1,687 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/audit"
+	"github.com/taihen/mcp-ripestat/internal/quota"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Default configuration is invalid: %v", err)
+	}
+
+	if cfg.Cache.TTLs["whois"].Std() != cache.DefaultTTLs["whois"] {
+		t.Errorf("Expected default whois TTL %v, got %v", cache.DefaultTTLs["whois"], cfg.Cache.TTLs["whois"].Std())
+	}
+}
+
+func TestLoad_File(t *testing.T) {
+	path := writeConfig(t, `
+[server]
+port = "9090"
+request_timeout = "30s"
+
+[transport]
+allowed_origins = ["https://example.com"]
+
+[cache]
+default_ttl = "1m"
+negative_ttl = "0s"
+
+[cache.ttls]
+whois = "12h"
+
+[limiter]
+max_concurrent = 4
+
+[upstream]
+source_app = "my-app"
+
+[tools]
+deny = ["historical"]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if cfg.Server.Port != "9090" {
+		t.Errorf("Expected port 9090, got %s", cfg.Server.Port)
+	}
+	if cfg.Server.RequestTimeout.Std() != 30*time.Second {
+		t.Errorf("Expected request timeout 30s, got %v", cfg.Server.RequestTimeout.Std())
+	}
+	if cfg.Server.ShutdownTimeout.Std() != 10*time.Second {
+		t.Errorf("Expected default shutdown timeout to be kept, got %v", cfg.Server.ShutdownTimeout.Std())
+	}
+	if strings.Join(cfg.Transport.AllowedOrigins, ",") != "https://example.com" {
+		t.Errorf("Expected origins to be replaced, got %v", cfg.Transport.AllowedOrigins)
+	}
+	if cfg.Cache.TTLs["whois"].Std() != 12*time.Hour {
+		t.Errorf("Expected whois TTL 12h, got %v", cfg.Cache.TTLs["whois"].Std())
+	}
+	if cfg.Cache.TTLs["bgplay"].Std() != cache.DefaultTTLs["bgplay"] {
+		t.Errorf("Expected other TTLs to keep defaults, got %v", cfg.Cache.TTLs["bgplay"].Std())
+	}
+	if cfg.Cache.NegativeTTL != 0 {
+		t.Errorf("Expected negative caching to be disabled, got %v", cfg.Cache.NegativeTTL.Std())
+	}
+	if cfg.Limiter.MaxConcurrent != 4 {
+		t.Errorf("Expected limiter 4, got %d", cfg.Limiter.MaxConcurrent)
+	}
+	if cfg.Upstream.SourceApp != "my-app" {
+		t.Errorf("Expected source app my-app, got %s", cfg.Upstream.SourceApp)
+	}
+	if strings.Join(cfg.Tools.Deny, ",") != "historical" {
+		t.Errorf("Expected deny list, got %v", cfg.Tools.Deny)
+	}
+
+	upstream := cfg.UpstreamClientConfig()
+	if upstream.SourceApp != "my-app" || upstream.DisableCache {
+		t.Errorf("Unexpected upstream client config: %+v", upstream)
+	}
+}
+
+func TestLoad_Auth(t *testing.T) {
+	path := writeConfig(t, `
+[server]
+admin_token = "admin-secret"
+
+[auth]
+enabled = true
+jwt_issuer = "https://issuer.example.com"
+
+[auth.api_keys.agent]
+key = "agent-secret"
+
+[auth.api_keys.prometheus]
+key = "scrape-secret"
+scopes = ["metrics"]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	chain, err := cfg.Authenticator()
+	if err != nil {
+		t.Fatalf("Authenticator failed: %v", err)
+	}
+
+	tests := []struct {
+		key     string
+		subject string
+		scope   string
+	}{
+		{key: "agent-secret", subject: "agent", scope: "mcp"},
+		{key: "scrape-secret", subject: "prometheus", scope: "metrics"},
+		{key: "admin-secret", subject: "admin", scope: "admin"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", tt.key)
+
+		principal, err := chain.Authenticate(req)
+		if err != nil {
+			t.Fatalf("Authenticate(%s) failed: %v", tt.subject, err)
+		}
+		if principal.Subject != tt.subject || !principal.HasScope(tt.scope) {
+			t.Errorf("Expected %s with scope %s, got %+v", tt.subject, tt.scope, principal)
+		}
+	}
+}
+
+func TestOAuthConfig(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.Enabled = true
+	cfg.OAuth.Enabled = true
+	cfg.OAuth.Resource = "https://mcp.example.com/mcp"
+	cfg.OAuth.AuthorizationServers = []string{"https://issuer.example.com"}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.OAuthIssuer() != "https://issuer.example.com" {
+		t.Errorf("Expected issuer to default to the first authorization server, got %q", cfg.OAuthIssuer())
+	}
+
+	metadata := cfg.ResourceMetadata()
+	if metadata == nil || metadata.Resource != cfg.OAuth.Resource || len(metadata.ScopesSupported) == 0 {
+		t.Errorf("Unexpected metadata %+v", metadata)
+	}
+
+	cfg.OAuth.Enabled = false
+	if cfg.ResourceMetadata() != nil {
+		t.Error("Expected no metadata when OAuth is disabled")
+	}
+}
+
+func TestLoad_Quotas(t *testing.T) {
+	path := writeConfig(t, `
+[auth.api_keys.ci]
+key = "ci-secret"
+groups = ["research"]
+
+[quotas]
+enabled = true
+rate_per_minute = 30
+daily_quota = 1000
+
+[quotas.groups.research]
+daily_quota = 5000
+
+[quotas.principals.ci]
+rate_per_minute = 0
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	policy := cfg.QuotaPolicy()
+	if !policy.Enabled {
+		t.Error("Expected quotas to be enabled")
+	}
+	if want := (quota.Limits{RatePerMinute: 30, Burst: 10, Daily: 1000}); policy.Default != want {
+		t.Errorf("Default limits = %+v, want %+v", policy.Default, want)
+	}
+	if want := (quota.Limits{RatePerMinute: 30, Burst: 10, Daily: 5000}); policy.Groups["research"] != want {
+		t.Errorf("Group limits = %+v, want %+v", policy.Groups["research"], want)
+	}
+	if want := (quota.Limits{RatePerMinute: 0, Burst: 10, Daily: 1000}); policy.Principals["ci"] != want {
+		t.Errorf("Principal limits = %+v, want %+v", policy.Principals["ci"], want)
+	}
+
+	chain, err := cfg.Authenticator()
+	if err != nil {
+		t.Fatalf("Authenticator failed: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-API-Key", "ci-secret")
+	if principal, err := chain.Authenticate(req); err != nil || len(principal.Groups) != 1 || principal.Groups[0] != "research" {
+		t.Errorf("Expected API key groups on the principal, got %+v, %v", principal, err)
+	}
+}
+
+func TestAuditLogger(t *testing.T) {
+	cfg := Default()
+	if logger, err := cfg.AuditLogger(); err != nil || logger != nil {
+		t.Fatalf("Expected no logger when disabled, got %v, %v", logger, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg.Audit.Enabled = true
+	cfg.Audit.Sinks = []string{AuditSinkFile}
+	cfg.Audit.File = path
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	logger, err := cfg.AuditLogger()
+	if err != nil {
+		t.Fatalf("AuditLogger failed: %v", err)
+	}
+	if err := logger.Log(audit.Entry{Tool: "getWhatsMyIP", Arguments: map[string]interface{}{"client_ip": "192.0.2.1"}}); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if !strings.Contains(string(data), `"tool":"getWhatsMyIP"`) || strings.Contains(string(data), "192.0.2.1") {
+		t.Errorf("Expected a redacted entry, got %s", data)
+	}
+
+	cfg.Audit.File = filepath.Join(t.TempDir(), "missing", "audit.log")
+	if _, err := cfg.AuditLogger(); err == nil || !strings.Contains(err.Error(), "audit.file") {
+		t.Errorf("Expected error for an unwritable file, got %v", err)
+	}
+}
+
+func TestLoad_Tracing(t *testing.T) {
+	path := writeConfig(t, `
+[tracing]
+enabled = true
+exporter = "otlp"
+endpoint = "http://collector:4318/v1/traces"
+
+[tracing.headers]
+x-honeycomb-team = "secret"
+`)
+	t.Setenv("MCP_RIPESTAT_TRACING_SERVICE_NAME", "ripestat-edge")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.Tracing.Endpoint != "http://collector:4318/v1/traces" || cfg.Tracing.Headers["x-honeycomb-team"] != "secret" {
+		t.Errorf("Unexpected tracing config %+v", cfg.Tracing)
+	}
+	if cfg.Tracing.ServiceName != "ripestat-edge" {
+		t.Errorf("Expected service name from environment, got %s", cfg.Tracing.ServiceName)
+	}
+
+	tracer := cfg.Tracer()
+	if tracer == nil {
+		t.Fatal("Expected a tracer when tracing is enabled")
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+
+	if Default().Tracer() != nil {
+		t.Error("Expected no tracer by default")
+	}
+}
+
+func TestLoad_Warming(t *testing.T) {
+	path := writeConfig(t, `
+[warming]
+enabled = true
+watchlist = [
+  "getASOverview?resource=AS3333",
+  "getRPKIValidation?resource=AS3333&prefix=193.0.0.0/21",
+]
+refresh_before = "2m"
+`)
+	t.Setenv("MCP_RIPESTAT_WARMING_MAX_CONCURRENT", "2")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.Warming.RefreshBefore.Std() != 2*time.Minute || cfg.Warming.MaxConcurrent != 2 {
+		t.Errorf("Unexpected warming config %+v", cfg.Warming)
+	}
+
+	targets, err := cfg.WarmingTargets()
+	if err != nil {
+		t.Fatalf("WarmingTargets failed: %v", err)
+	}
+	if len(targets) != 2 || targets[1].Tool != "getRPKIValidation" || targets[1].Arguments["prefix"] != "193.0.0.0/21" {
+		t.Errorf("Unexpected targets %+v", targets)
+	}
+
+	cfg.Warming.Enabled = false
+	if targets, _ := cfg.WarmingTargets(); targets != nil {
+		t.Errorf("Expected no targets when warming is disabled, got %+v", targets)
+	}
+}
+
+func TestLoad_Resolve(t *testing.T) {
+	path := writeConfig(t, `
+[resolve]
+enabled = true
+server = "192.0.2.53:53"
+timeout = "2s"
+`)
+	t.Setenv("MCP_RIPESTAT_RESOLVE_MAX_ADDRESSES", "2")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	resolver, err := cfg.Resolver()
+	if err != nil {
+		t.Fatalf("Resolver failed: %v", err)
+	}
+	if resolver == nil || resolver.Server() != "192.0.2.53:53" {
+		t.Errorf("Unexpected resolver %+v", resolver)
+	}
+	if cfg.Resolve.Timeout.Std() != 2*time.Second || cfg.Resolve.MaxAddresses != 2 {
+		t.Errorf("Unexpected resolve config %+v", cfg.Resolve)
+	}
+
+	if resolver, _ := Default().Resolver(); resolver != nil {
+		t.Errorf("Expected no resolver by default, got %+v", resolver)
+	}
+}
+
+func TestLoad_MaxResultBytes(t *testing.T) {
+	if got := Default().Tools.MaxResultBytes; got != 256<<10 {
+		t.Errorf("Expected a default of 256 KiB, got %d", got)
+	}
+
+	path := writeConfig(t, `
+[tools]
+max_result_bytes = 65536
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Tools.MaxResultBytes != 65536 {
+		t.Errorf("Expected 65536, got %d", cfg.Tools.MaxResultBytes)
+	}
+
+	t.Setenv("MCP_RIPESTAT_TOOLS_MAX_RESULT_BYTES", "0")
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Tools.MaxResultBytes != 0 || cfg.Validate() != nil {
+		t.Errorf("Expected the environment to disable the limit, got %d", cfg.Tools.MaxResultBytes)
+	}
+}
+
+func TestLoad_Listeners(t *testing.T) {
+	path := writeConfig(t, `
+[server]
+listeners = ["tls", "unix"]
+
+[tls]
+cert_file = "/etc/mcp/tls.crt"
+key_file = "/etc/mcp/tls.key"
+client_ca_file = "/etc/mcp/ca.crt"
+client_auth = "require"
+
+[tls.clients.ops]
+scopes = ["metrics"]
+groups = ["operators"]
+
+[unix]
+path = "/run/mcp-ripestat.sock"
+mode = "0600"
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.TLS.ClientAuthType() != tls.RequireAndVerifyClientCert {
+		t.Errorf("Expected client certificates to be required, got %v", cfg.TLS.ClientAuthType())
+	}
+	if mode, err := cfg.Unix.FileMode(); err != nil || mode != 0o600 {
+		t.Errorf("Expected socket mode 0600, got %v (%v)", mode, err)
+	}
+
+	// Client certificates are enough to enable authentication.
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected client certificates to satisfy auth, got %v", err)
+	}
+	chain, err := cfg.Authenticator()
+	if err != nil {
+		t.Fatalf("Authenticator failed: %v", err)
+	}
+	if len(chain) != 2 {
+		t.Errorf("Expected API keys and client certificates, got %d authenticators", len(chain))
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "unknown section", content: "[nope]\na = 1", want: "unknown field"},
+		{name: "unknown key", content: "[server]\nprot = \"80\"", want: "unknown field"},
+		{name: "wrong type", content: "[limiter]\nmax_concurrent = \"four\"", want: "max_concurrent"},
+		{name: "invalid duration", content: "[server]\nrequest_timeout = \"soon\"", want: "invalid duration"},
+		{name: "numeric duration", content: "[server]\nrequest_timeout = 30", want: "duration must be a string"},
+		{name: "syntax error", content: "[server\nport = 1", want: "invalid table header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	path := writeConfig(t, "[server]\nport = \"9090\"\n")
+
+	t.Setenv("MCP_RIPESTAT_SERVER_PORT", "7070")
+	t.Setenv("MCP_RIPESTAT_SERVER_DEBUG", "true")
+	t.Setenv("MCP_RIPESTAT_SERVER_REQUEST_TIMEOUT", "15s")
+	t.Setenv("MCP_RIPESTAT_LIMITER_MAX_CONCURRENT", "2")
+	t.Setenv("MCP_RIPESTAT_TOOLS_DENY", "historical, client")
+	t.Setenv("MCP_RIPESTAT_CACHE_TTLS", "whois=1h, bgplay=30s")
+	t.Setenv("MCP_RIPESTAT_CACHE_ENABLED", "false")
+	t.Setenv("MCP_RIPESTAT_HEALTH_MAX_ERROR_RATE", "0.25")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.Port != "7070" {
+		t.Errorf("Expected environment to override file, got port %s", cfg.Server.Port)
+	}
+	if !cfg.Server.Debug {
+		t.Error("Expected debug from environment")
+	}
+	if cfg.Server.RequestTimeout.Std() != 15*time.Second {
+		t.Errorf("Expected request timeout 15s, got %v", cfg.Server.RequestTimeout.Std())
+	}
+	if cfg.Limiter.MaxConcurrent != 2 {
+		t.Errorf("Expected limiter 2, got %d", cfg.Limiter.MaxConcurrent)
+	}
+	if strings.Join(cfg.Tools.Deny, ",") != "historical,client" {
+		t.Errorf("Expected deny list from environment, got %v", cfg.Tools.Deny)
+	}
+	if cfg.Cache.TTLs["whois"].Std() != time.Hour || cfg.Cache.TTLs["bgplay"].Std() != 30*time.Second {
+		t.Errorf("Expected TTLs from environment, got %v", cfg.Cache.TTLs)
+	}
+	if cfg.Cache.TTLs["looking-glass"].Std() != cache.DefaultTTLs["looking-glass"] {
+		t.Error("Expected other TTLs to keep defaults")
+	}
+	if !cfg.UpstreamClientConfig().DisableCache {
+		t.Error("Expected cache to be disabled")
+	}
+	if cfg.Health.MaxErrorRate != 0.25 {
+		t.Errorf("Expected max error rate 0.25, got %v", cfg.Health.MaxErrorRate)
+	}
+}
+
+func TestLoad_EnvErrors(t *testing.T) {
+	tests := map[string]string{
+		"MCP_RIPESTAT_SERVER_DEBUG":           "maybe",
+		"MCP_RIPESTAT_LIMITER_MAX_CONCURRENT": "many",
+		"MCP_RIPESTAT_SERVER_REQUEST_TIMEOUT": "soon",
+		"MCP_RIPESTAT_CACHE_TTLS":             "whois",
+		"MCP_RIPESTAT_HEALTH_MAX_ERROR_RATE":  "half",
+	}
+
+	for env, value := range tests {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, value)
+			_, err := Load("")
+			if err == nil || !strings.Contains(err.Error(), env) {
+				t.Errorf("Load() error = %v, want error naming %s", err, env)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{name: "invalid port", modify: func(c *Config) { c.Server.Port = "http" }, want: "server.port"},
+		{name: "port out of range", modify: func(c *Config) { c.Server.Port = "70000" }, want: "server.port"},
+		{name: "zero timeout", modify: func(c *Config) { c.Server.RequestTimeout = 0 }, want: "server.request_timeout"},
+		{name: "negative ttl", modify: func(c *Config) { c.Cache.TTLs["whois"] = Duration(-time.Second) }, want: "cache.ttls.whois"},
+		{name: "negative negative ttl", modify: func(c *Config) { c.Cache.NegativeTTL = Duration(-time.Second) }, want: "cache.negative_ttl"},
+		{name: "invalid origin", modify: func(c *Config) { c.Transport.AllowedOrigins = []string{"localhost"} }, want: "transport.allowed_origins"},
+		{name: "origin with path", modify: func(c *Config) { c.Transport.AllowedOrigins = []string{"https://example.com/app"} }, want: "transport.allowed_origins"},
+		{name: "unknown origin mode", modify: func(c *Config) { c.Transport.OriginMode = "open" }, want: "transport.origin_mode"},
+		{name: "no protocol versions", modify: func(c *Config) { c.Transport.ProtocolVersions = nil }, want: "transport.protocol_versions"},
+		{name: "invalid protocol version", modify: func(c *Config) { c.Transport.ProtocolVersions = []string{"latest"} }, want: "transport.protocol_versions"},
+		{name: "limiter too large", modify: func(c *Config) { c.Limiter.MaxConcurrent = 9 }, want: "limiter.max_concurrent"},
+		{name: "limiter zero", modify: func(c *Config) { c.Limiter.MaxConcurrent = 0 }, want: "limiter.max_concurrent"},
+		{name: "invalid base url", modify: func(c *Config) { c.Upstream.BaseURL = "stat.ripe.net" }, want: "upstream.base_url"},
+		{name: "negative retries", modify: func(c *Config) { c.Upstream.RetryCount = -1 }, want: "upstream.retry_count"},
+		{name: "auth without credentials", modify: func(c *Config) { c.Auth.Enabled = true }, want: "auth: enabled without"},
+		{name: "jwks file without audience", modify: func(c *Config) { c.Auth.JWKSFile = "jwks.json" }, want: "auth.jwt_audience"},
+		{name: "empty api key", modify: func(c *Config) { c.Auth.APIKeys = map[string]APIKeyConfig{"a": {}} }, want: "auth.api_keys"},
+		{
+			name: "api key named admin with admin token",
+			modify: func(c *Config) {
+				c.Server.AdminToken = "secret"
+				c.Auth.APIKeys = map[string]APIKeyConfig{"admin": {Key: "other"}}
+			},
+			want: "reserved for server.admin_token",
+		},
+		{name: "unknown scope", modify: func(c *Config) {
+			c.Auth.APIKeys = map[string]APIKeyConfig{"a": {Key: "k", Scopes: []string{"root"}}}
+		}, want: "unknown scope"},
+		{name: "oauth without resource", modify: func(c *Config) {
+			c.OAuth.Enabled = true
+			c.OAuth.AuthorizationServers = []string{"https://issuer.example.com"}
+		}, want: "oauth.resource"},
+		{name: "oauth resource with fragment", modify: func(c *Config) {
+			c.OAuth.Enabled = true
+			c.OAuth.Resource = "https://mcp.example.com/mcp#x"
+			c.OAuth.AuthorizationServers = []string{"https://issuer.example.com"}
+		}, want: "oauth.resource"},
+		{name: "oauth without authorization server", modify: func(c *Config) {
+			c.OAuth.Enabled = true
+			c.OAuth.Resource = "https://mcp.example.com/mcp"
+		}, want: "oauth.authorization_servers"},
+		{name: "oauth invalid jwks url", modify: func(c *Config) {
+			c.OAuth.Enabled = true
+			c.OAuth.Resource = "https://mcp.example.com/mcp"
+			c.OAuth.AuthorizationServers = []string{"https://issuer.example.com"}
+			c.OAuth.JWKSURL = "jwks.json"
+		}, want: "oauth: invalid URL"},
+		{name: "zero page size", modify: func(c *Config) { c.Tools.PageSize = 0 }, want: "tools.page_size"},
+		{name: "negative max result bytes", modify: func(c *Config) { c.Tools.MaxResultBytes = -1 }, want: "tools.max_result_bytes"},
+		{name: "unknown audit sink", modify: func(c *Config) { c.Audit.Sinks = []string{"syslog"} }, want: "audit.sinks"},
+		{name: "audit file sink without file", modify: func(c *Config) { c.Audit.Sinks = []string{"file"} }, want: "audit.file"},
+		{name: "audit without sinks", modify: func(c *Config) {
+			c.Audit.Enabled = true
+			c.Audit.Sinks = nil
+		}, want: "audit.sinks"},
+		{name: "invalid redaction", modify: func(c *Config) { c.Audit.Redact = []string{"getWhatsMyIP"} }, want: "audit.redact"},
+		{name: "unknown trace exporter", modify: func(c *Config) { c.Tracing.Exporter = "jaeger" }, want: "tracing.exporter"},
+		{name: "invalid trace endpoint", modify: func(c *Config) { c.Tracing.Endpoint = "localhost:4318" }, want: "tracing.endpoint"},
+		{name: "empty service name", modify: func(c *Config) { c.Tracing.ServiceName = "" }, want: "tracing.service_name"},
+		{name: "no listeners", modify: func(c *Config) { c.Server.Listeners = nil }, want: "server.listeners"},
+		{name: "unknown listener", modify: func(c *Config) { c.Server.Listeners = []string{"quic"} }, want: "server.listeners"},
+		{name: "duplicate listener", modify: func(c *Config) { c.Server.Listeners = []string{"tcp", "tcp"} }, want: "server.listeners"},
+		{name: "tls without certificate", modify: func(c *Config) { c.Server.Listeners = []string{"tls"} }, want: "cert_file and key_file"},
+		{name: "invalid tls port", modify: func(c *Config) {
+			c.Server.Listeners = []string{"tls"}
+			c.TLS.Port = "https"
+		}, want: "tls.port"},
+		{name: "unknown client auth", modify: func(c *Config) { c.TLS.ClientAuth = "verify" }, want: "tls.client_auth"},
+		{name: "client auth without ca", modify: func(c *Config) { c.TLS.ClientAuth = "require" }, want: "tls.client_ca_file"},
+		{name: "unknown client scope", modify: func(c *Config) {
+			c.TLS.Clients = map[string]ClientCertConfig{"ops": {Scopes: []string{"root"}}}
+		}, want: "tls.clients"},
+		{name: "unix without path", modify: func(c *Config) { c.Server.Listeners = []string{"unix"} }, want: "unix.path"},
+		{name: "invalid socket mode", modify: func(c *Config) { c.Unix.Mode = "rw" }, want: "unix.mode"},
+		{name: "error window too long", modify: func(c *Config) { c.Health.ErrorWindow = Duration(time.Hour) }, want: "health.error_window"},
+		{name: "error rate above one", modify: func(c *Config) { c.Health.MaxErrorRate = 1.5 }, want: "health.max_error_rate"},
+		{name: "zero min requests", modify: func(c *Config) { c.Health.MinRequests = 0 }, want: "health.min_requests"},
+		{name: "zero probe interval", modify: func(c *Config) { c.Health.ProbeInterval = 0 }, want: "health.probe_interval"},
+		{name: "invalid watchlist entry", modify: func(c *Config) { c.Warming.Watchlist = []string{"?resource=AS3333"} }, want: "warming.watchlist"},
+		{name: "zero refresh before", modify: func(c *Config) { c.Warming.RefreshBefore = 0 }, want: "warming.refresh_before"},
+		{name: "warming takes the limiter", modify: func(c *Config) {
+			c.Warming.Enabled = true
+			c.Warming.MaxConcurrent = c.Limiter.MaxConcurrent
+		}, want: "warming.max_concurrent"},
+		{name: "warming without cache", modify: func(c *Config) {
+			c.Warming.Enabled = true
+			c.Cache.Enabled = false
+		}, want: "warming: requires cache.enabled"},
+		{name: "invalid resolver address", modify: func(c *Config) { c.Resolve.Server = "192.0.2.53" }, want: "resolve.server"},
+		{name: "zero resolve timeout", modify: func(c *Config) { c.Resolve.Timeout = 0 }, want: "resolve.timeout"},
+		{name: "zero max addresses", modify: func(c *Config) { c.Resolve.MaxAddresses = 0 }, want: "resolve.max_addresses"},
+		{name: "negative daily quota", modify: func(c *Config) { c.Quotas.DailyQuota = -1 }, want: "quotas.daily_quota"},
+		{name: "negative group rate", modify: func(c *Config) {
+			rate := -5
+			c.Quotas.Groups = map[string]QuotaLimits{"research": {RatePerMinute: &rate}}
+		}, want: "quotas.groups.research.rate_per_minute"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("limiter.max_concurrent"); got != "MCP_RIPESTAT_LIMITER_MAX_CONCURRENT" {
+		t.Errorf("EnvName() = %s", got)
+	}
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "config.example.toml"))
+	if err != nil {
+		t.Fatalf("Failed to load example configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Example configuration is invalid: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix prefixes every environment override, for example
+// MCP_RIPESTAT_SERVER_PORT or MCP_RIPESTAT_LIMITER_MAX_CONCURRENT.
+const EnvPrefix = "MCP_RIPESTAT_"
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// applyEnv overrides fields from environment variables named after their
+// section and key. Lists are comma-separated and cache TTLs are written as
+// "endpoint=duration" pairs, for example "whois=12h,bgplay=1m".
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	sections := reflect.ValueOf(c).Elem()
+
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionName := jsonName(sections.Type().Field(i))
+
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Field(j)
+			key := sectionName + "." + jsonName(section.Type().Field(j))
+			env := EnvName(key)
+
+			value, ok := lookup(env)
+			if !ok {
+				continue
+			}
+
+			if err := setFromEnv(field, value); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// EnvName returns the environment variable overriding a "section.key" setting.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+// setFromEnv parses value into field according to the field's type.
+func setFromEnv(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(f)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.ValueOf(splitList(value)))
+	case field.Kind() == reflect.Map && field.Type().Elem() == durationType:
+		ttls := reflect.MakeMap(field.Type())
+		if !field.IsNil() {
+			for _, k := range field.MapKeys() {
+				ttls.SetMapIndex(k, field.MapIndex(k))
+			}
+		}
+		for _, pair := range splitList(value) {
+			endpoint, raw, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected endpoint=duration, got %q", pair)
+			}
+			d, err := time.ParseDuration(strings.TrimSpace(raw))
+			if err != nil {
+				return err
+			}
+			ttls.SetMapIndex(reflect.ValueOf(strings.TrimSpace(endpoint)), reflect.ValueOf(Duration(d)))
+		}
+		field.Set(ttls)
+	case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.String:
+		values := make(map[string]string)
+		for _, pair := range splitList(value) {
+			name, raw, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected name=value, got %q", pair)
+			}
+			values[strings.TrimSpace(name)] = strings.TrimSpace(raw)
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML used by configuration files: tables,
+// dotted keys, strings, integers, floats, booleans and arrays of those values.
+// Inline tables and arrays of tables are not supported.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		startLine := lineNo
+		line := stripComment(scanner.Text())
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Multi-line arrays continue until their brackets balance.
+		isHeader := strings.HasPrefix(strings.TrimSpace(line), "[")
+		for !isHeader && !bracketsBalanced(line) {
+			if !scanner.Scan() {
+				return nil, fmt.Errorf("line %d: unterminated array", startLine)
+			}
+			lineNo++
+			line += "\n" + stripComment(scanner.Text())
+		}
+
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "[[") {
+			return nil, fmt.Errorf("line %d: arrays of tables are not supported", startLine)
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", startLine, line)
+			}
+			path, err := parseKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", startLine, err)
+			}
+			table, err := tableAt(root, path)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", startLine, err)
+			}
+			current = table
+			continue
+		}
+
+		rawKey, rawValue, ok := splitKeyValue(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", startLine)
+		}
+
+		path, err := parseKey(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", startLine, err)
+		}
+
+		value, err := parseValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", startLine, strings.Join(path, "."), err)
+		}
+
+		table, err := tableAt(current, path[:len(path)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", startLine, err)
+		}
+
+		name := path[len(path)-1]
+		if _, exists := table[name]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", startLine, strings.Join(path, "."))
+		}
+		table[name] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// tableAt returns the table at path below root, creating missing tables.
+func tableAt(root map[string]interface{}, path []string) (map[string]interface{}, error) {
+	table := root
+	for _, name := range path {
+		next, exists := table[name]
+		if !exists {
+			child := make(map[string]interface{})
+			table[name] = child
+			table = child
+			continue
+		}
+
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("key %q is not a table", name)
+		}
+		table = child
+	}
+	return table, nil
+}
+
+// parseKey splits a possibly dotted and quoted key into its parts.
+func parseKey(raw string) ([]string, error) {
+	var parts []string
+	rest := strings.TrimSpace(raw)
+
+	for {
+		var part string
+		switch {
+		case strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, `'`):
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted key %q", raw)
+			}
+			part = rest[1 : end+1]
+			rest = strings.TrimSpace(rest[end+2:])
+		default:
+			end := strings.IndexByte(rest, '.')
+			if end < 0 {
+				end = len(rest)
+			}
+			part = strings.TrimSpace(rest[:end])
+			rest = strings.TrimSpace(rest[end:])
+			if !isBareKey(part) {
+				return nil, fmt.Errorf("invalid key %q", raw)
+			}
+		}
+
+		parts = append(parts, part)
+
+		if rest == "" {
+			return parts, nil
+		}
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("invalid key %q", raw)
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+}
+
+func isBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseValue parses a single TOML value.
+func parseValue(raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, `'''`):
+		return nil, fmt.Errorf("multi-line strings are not supported")
+	case raw[0] == '"':
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' || strings.ContainsRune(raw[1:len(raw)-1], '\'') {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw[0] == '[':
+		return parseArray(raw)
+	case raw[0] == '{':
+		return nil, fmt.Errorf("inline tables are not supported")
+	}
+
+	number := strings.ReplaceAll(raw, "_", "")
+	if i, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil {
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("invalid value %s", raw)
+}
+
+// parseArray parses an array of values, allowing a trailing comma.
+func parseArray(raw string) ([]interface{}, error) {
+	if !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("invalid array %s", raw)
+	}
+
+	values := []interface{}{}
+	for _, item := range splitArray(raw[1 : len(raw)-1]) {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		value, err := parseValue(item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// splitArray splits array contents on commas outside strings and nested arrays.
+func splitArray(s string) []string {
+	var items []string
+	depth, start := 0, 0
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// splitKeyValue splits a line on the first '=' outside a quoted key.
+func splitKeyValue(line string) (string, string, bool) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return line[:i], line[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// stripComment removes a trailing comment outside strings.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// bracketsBalanced reports whether every '[' outside strings is closed.
+func bracketsBalanced(line string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	data := `
+# Top-level comment
+title = "mcp # not a comment"
+
+[server]
+port = "8080" # trailing comment
+debug = true
+count = 1_000
+ratio = 0.5
+
+[cache.ttls]
+whois = '24h'
+"routing-status" = "30m"
+
+[tools]
+deny = [
+  "historical",
+  "getWhatsMyIP", # comment inside array
+]
+allow = []
+nested.key = 3
+`
+
+	got, err := parseTOML([]byte(data))
+	if err != nil {
+		t.Fatalf("parseTOML failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"title": "mcp # not a comment",
+		"server": map[string]interface{}{
+			"port":  "8080",
+			"debug": true,
+			"count": int64(1000),
+			"ratio": 0.5,
+		},
+		"cache": map[string]interface{}{
+			"ttls": map[string]interface{}{
+				"whois":          "24h",
+				"routing-status": "30m",
+			},
+		},
+		"tools": map[string]interface{}{
+			"deny":  []interface{}{"historical", "getWhatsMyIP"},
+			"allow": []interface{}{},
+			"nested": map[string]interface{}{
+				"key": int64(3),
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTOML() =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "missing value", data: "port =", want: "line 1"},
+		{name: "no equals", data: "\n\nport", want: "line 3: expected key = value"},
+		{name: "invalid value", data: "port = 80 80", want: "invalid value"},
+		{name: "unterminated string", data: `port = "8080`, want: "invalid string"},
+		{name: "unterminated array", data: "deny = [\n\"a\",", want: "unterminated array"},
+		{name: "duplicate key", data: "a = 1\na = 2", want: "duplicate key"},
+		{name: "key is not a table", data: "a = 1\n[a]", want: "not a table"},
+		{name: "inline table", data: "a = {b = 1}", want: "inline tables"},
+		{name: "array of tables", data: "[[a]]", want: "arrays of tables"},
+		{name: "invalid key", data: "a b = 1", want: "invalid key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseTOML() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
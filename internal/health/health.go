@@ -0,0 +1,178 @@
+// Package health runs the dependency checks behind the readiness endpoint
+// and combines them into a single report.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Check statuses, from best to worst.
+const (
+	// StatusOK means the dependency works.
+	StatusOK = "ok"
+
+	// StatusDegraded means the dependency works with reduced capacity; the
+	// instance still accepts traffic.
+	StatusDegraded = "degraded"
+
+	// StatusFail means the instance cannot serve requests.
+	StatusFail = "fail"
+)
+
+var severity = map[string]int{StatusOK: 0, StatusDegraded: 1, StatusFail: 2}
+
+// Check is the result of checking one dependency.
+type Check struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Checker checks one dependency.
+type Checker func(ctx context.Context) Check
+
+// Report combines the results of several checks. Status is the worst status
+// of any check.
+type Report struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+	Checks []Check   `json:"checks"`
+}
+
+// Ready reports whether the instance should receive traffic.
+func (r Report) Ready() bool {
+	return r.Status != StatusFail
+}
+
+// Run runs the checkers concurrently and reports their results in order.
+func Run(ctx context.Context, checkers ...Checker) Report {
+	report := Report{Status: StatusOK, Time: time.Now().UTC(), Checks: make([]Check, len(checkers))}
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = checker(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		if severity[check.Status] > severity[report.Status] {
+			report.Status = check.Status
+		}
+	}
+	return report
+}
+
+// Probe checks that an upstream dependency answers. Results are reused for
+// the probe interval so that frequent readiness checks do not turn into
+// upstream traffic. It is safe for concurrent use.
+type Probe struct {
+	name  string
+	probe func(ctx context.Context) error
+	now   func() time.Time
+
+	mu       sync.Mutex
+	interval time.Duration
+	timeout  time.Duration
+	checked  time.Time
+	latency  time.Duration
+	err      error
+}
+
+// NewProbe returns a probe named name that calls probe at most once per
+// interval, allowing it timeout to answer.
+func NewProbe(name string, probe func(ctx context.Context) error, interval, timeout time.Duration) *Probe {
+	return &Probe{name: name, probe: probe, now: time.Now, interval: interval, timeout: timeout}
+}
+
+// SetTiming changes the probe interval and timeout.
+func (p *Probe) SetTiming(interval, timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.interval = interval
+	p.timeout = timeout
+}
+
+// Check returns the latest probe result, probing first if it is older than
+// the interval. Concurrent callers wait for a single probe.
+func (p *Probe) Check(ctx context.Context) Check {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cached := true
+	if p.checked.IsZero() || p.now().Sub(p.checked) >= p.interval {
+		cached = false
+
+		// The result is shared, so it must not depend on one caller going away.
+		probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.timeout)
+		start := p.now()
+		p.err = p.probe(probeCtx)
+		cancel()
+		p.checked = p.now()
+		p.latency = p.checked.Sub(start)
+	}
+
+	check := Check{
+		Name:   p.name,
+		Status: StatusOK,
+		Details: map[string]interface{}{
+			"checked_at": p.checked.UTC().Format(time.RFC3339),
+			"latency_ms": float64(p.latency.Microseconds()) / 1000,
+			"cached":     cached,
+		},
+	}
+	if p.err != nil {
+		check.Status = StatusFail
+		check.Message = p.err.Error()
+	}
+	return check
+}
+
+// ErrorRate fails when more than maxRate of at least minRequests recent
+// requests failed.
+func ErrorRate(name string, requests, failures int, maxRate float64, minRequests int) Check {
+	check := Check{
+		Name:   name,
+		Status: StatusOK,
+		Details: map[string]interface{}{
+			"requests": requests,
+			"failures": failures,
+		},
+	}
+	if requests == 0 {
+		return check
+	}
+
+	rate := float64(failures) / float64(requests)
+	check.Details["error_rate"] = rate
+	if requests >= minRequests && rate > maxRate {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("%d of %d recent requests failed", failures, requests)
+	}
+	return check
+}
+
+// Saturation reports a degraded status when every slot of a limiter is in
+// use, so new requests queue.
+func Saturation(name string, inUse, capacity int) Check {
+	check := Check{
+		Name:   name,
+		Status: StatusOK,
+		Details: map[string]interface{}{
+			"in_use":   inUse,
+			"capacity": capacity,
+		},
+	}
+	if inUse >= capacity {
+		check.Status = StatusDegraded
+		check.Message = "all slots in use, requests are queueing"
+	}
+	return check
+}
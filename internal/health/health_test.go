@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	check := func(name, status string) Checker {
+		return func(context.Context) Check { return Check{Name: name, Status: status} }
+	}
+
+	report := Run(context.Background(), check("a", StatusOK), check("b", StatusDegraded))
+	if report.Status != StatusDegraded || !report.Ready() {
+		t.Errorf("Expected degraded but ready, got %+v", report)
+	}
+	if report.Checks[0].Name != "a" || report.Checks[1].Name != "b" {
+		t.Errorf("Expected checks in order, got %+v", report.Checks)
+	}
+
+	report = Run(context.Background(), check("a", StatusFail), check("b", StatusDegraded))
+	if report.Status != StatusFail || report.Ready() {
+		t.Errorf("Expected fail, got %+v", report)
+	}
+
+	if report := Run(context.Background()); report.Status != StatusOK {
+		t.Errorf("Expected ok without checks, got %+v", report)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	var failure error
+	probe := NewProbe("upstream", func(ctx context.Context) error {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected the probe to have a deadline")
+		}
+		return failure
+	}, 30*time.Second, time.Second)
+	probe.now = func() time.Time { return now }
+
+	// A cancelled caller context does not cancel the shared probe.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if check := probe.Check(ctx); check.Status != StatusOK || check.Details["cached"] != false {
+		t.Errorf("Expected fresh ok result, got %+v", check)
+	}
+	now = now.Add(10 * time.Second)
+	if check := probe.Check(ctx); check.Status != StatusOK || check.Details["cached"] != true {
+		t.Errorf("Expected cached result, got %+v", check)
+	}
+	if calls != 1 {
+		t.Errorf("Expected one probe within the interval, got %d", calls)
+	}
+
+	failure = errors.New("connection refused")
+	now = now.Add(30 * time.Second)
+	if check := probe.Check(ctx); check.Status != StatusFail || check.Message != "connection refused" {
+		t.Errorf("Expected failed probe, got %+v", check)
+	}
+
+	probe.SetTiming(time.Hour, time.Second)
+	now = now.Add(time.Minute)
+	failure = nil
+	if check := probe.Check(ctx); check.Status != StatusFail || calls != 2 {
+		t.Errorf("Expected the failure to be reused with the longer interval, got %+v after %d calls", check, calls)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	tests := []struct {
+		requests, failures int
+		want               string
+	}{
+		{0, 0, StatusOK},
+		{4, 4, StatusOK}, // Too few requests to judge.
+		{10, 5, StatusOK},
+		{10, 6, StatusFail},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorRate("upstream_errors", tt.requests, tt.failures, 0.5, 10); got.Status != tt.want {
+			t.Errorf("ErrorRate(%d, %d) = %s, want %s", tt.requests, tt.failures, got.Status, tt.want)
+		}
+	}
+}
+
+func TestSaturation(t *testing.T) {
+	if got := Saturation("limiter", 6, 7); got.Status != StatusOK {
+		t.Errorf("Expected ok below capacity, got %+v", got)
+	}
+	if got := Saturation("limiter", 7, 7); got.Status != StatusDegraded || got.Message == "" {
+		t.Errorf("Expected degraded at capacity, got %+v", got)
+	}
+}
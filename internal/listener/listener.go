@@ -0,0 +1,38 @@
+// Package listener opens the network listeners the HTTP server accepts
+// connections on: Unix domain sockets and TLS with certificate reload.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+)
+
+// Unix listens on the Unix domain socket at path and sets its file mode. A
+// socket left behind by a previous run is removed first; any other file at
+// path is an error. The socket file is removed when the listener is closed.
+func Unix(path string, mode fs.FileMode) (net.Listener, error) {
+	info, err := os.Lstat(path)
+	switch {
+	case err == nil && info.Mode().Type() != fs.ModeSocket:
+		return nil, fmt.Errorf("%s exists and is not a socket", path)
+	case err == nil:
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		_ = l.Close()
+		return nil, fmt.Errorf("failed to set socket mode: %w", err)
+	}
+	return l, nil
+}
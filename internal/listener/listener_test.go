@@ -0,0 +1,194 @@
+package listener
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate for commonName and its key to
+// dir, returning the file paths.
+func writeCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// serverName returns the common name of the certificate served by l.
+func serverName(t *testing.T, l net.Listener) string {
+	t.Helper()
+
+	errCh := make(chan error, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer conn.Close()
+		errCh <- conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{InsecureSkipVerify: true}) // #nosec G402 -- test certificates are self-signed
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "first")
+
+	certs, err := NewCertReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("NewCertReloader failed: %v", err)
+	}
+	certs.CheckInterval = 0
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := tls.NewListener(inner, certs.TLSConfig(tls.NoClientCert))
+	defer l.Close()
+
+	if got := serverName(t, l); got != "first" {
+		t.Errorf("Expected certificate first, got %s", got)
+	}
+
+	// A rotated certificate is picked up without a restart.
+	writeCert(t, dir, "second")
+	later := time.Now().Add(time.Minute)
+	for _, path := range []string{certFile, keyFile} {
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := serverName(t, l); got != "second" {
+		t.Errorf("Expected rotated certificate second, got %s", got)
+	}
+
+	// A broken file keeps the previous certificate in use.
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := certs.Reload(); err == nil {
+		t.Error("Expected reload of a broken certificate to fail")
+	}
+	if got := serverName(t, l); got != "second" {
+		t.Errorf("Expected previous certificate second, got %s", got)
+	}
+}
+
+func TestNewCertReloader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "server")
+
+	if _, err := NewCertReloader(filepath.Join(dir, "missing.pem"), keyFile, ""); err == nil {
+		t.Error("Expected error for a missing certificate")
+	}
+	if _, err := NewCertReloader(certFile, keyFile, keyFile); err == nil {
+		t.Error("Expected error for a client CA file without certificates")
+	}
+	if _, err := NewCertReloader(certFile, keyFile, certFile); err != nil {
+		t.Errorf("Expected client CA file to load, got %v", err)
+	}
+}
+
+func TestUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.sock")
+
+	l, err := Unix(path, 0o600)
+	if err != nil {
+		t.Fatalf("Unix failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			_ = conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	_ = conn.Close()
+
+	// A stale socket is replaced.
+	stale, err := net.Listen("unix", path+".stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+	if l, err := Unix(path+".stale", 0o660); err != nil {
+		t.Errorf("Expected stale socket to be replaced, got %v", err)
+	} else {
+		_ = l.Close()
+	}
+
+	_ = l.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected socket to be removed on close, got %v", err)
+	}
+
+	// Other files are never removed.
+	file := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unix(file, 0o660); err == nil {
+		t.Error("Expected error for a regular file")
+	}
+}
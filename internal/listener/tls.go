@@ -0,0 +1,134 @@
+package listener
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCheckInterval is how often a CertReloader looks for changed files.
+const DefaultCheckInterval = 10 * time.Second
+
+// CertReloader serves a certificate and client CA pool loaded from files and
+// reloads them when the files change, so that rotated certificates are used
+// without a restart. It is safe for concurrent use.
+type CertReloader struct {
+	certFile, keyFile, clientCAFile string
+
+	// CheckInterval limits how often the files are checked for changes
+	// during handshakes.
+	CheckInterval time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime time.Time
+	checked time.Time
+}
+
+// NewCertReloader loads the key pair and, when clientCAFile is set, the PEM
+// bundle of CAs that client certificates are verified against.
+func NewCertReloader(certFile, keyFile, clientCAFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile, CheckInterval: DefaultCheckInterval}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again. On error the previous certificate stays in
+// use.
+func (r *CertReloader) Reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.clientCAFile != "" {
+		data, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in client CA file %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.pool, r.modTime, r.checked = &cert, pool, modTime, time.Now()
+	return nil
+}
+
+// latestModTime returns the newest modification time of the files.
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// current returns the certificate and pool, reloading them first when the
+// files changed since the last check.
+func (r *CertReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	due := time.Since(r.checked) >= r.CheckInterval
+	if due {
+		r.checked = time.Now()
+	}
+	loaded := r.modTime
+	r.mu.Unlock()
+
+	if due {
+		if modTime, err := r.latestModTime(); err != nil {
+			slog.Warn("failed to check TLS certificate files", "err", err)
+		} else if !modTime.Equal(loaded) {
+			if err := r.Reload(); err != nil {
+				slog.Warn("failed to reload TLS certificate, keeping the previous one", "err", err)
+			} else {
+				slog.Info("reloaded TLS certificate", "cert_file", r.certFile)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.pool
+}
+
+// TLSConfig returns a server configuration that uses the current certificate
+// for every handshake and verifies client certificates as clientAuth
+// requires.
+func (r *CertReloader) TLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	base := &tls.Config{MinVersion: tls.VersionTLS12, ClientAuth: clientAuth}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, pool := r.current()
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.Certificates = []tls.Certificate{*cert}
+		cfg.ClientCAs = pool
+		return cfg, nil
+	}
+	return base
+}
@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/audit"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/whatsmyip"
+)
+
+// sessionClientsLimit is the number of sessions whose client info is kept for
+// audit entries.
+const sessionClientsLimit = 4096
+
+// SetAuditLogger replaces the audit logger and returns the previous one, which
+// the caller should close. A nil logger disables auditing.
+func (s *Server) SetAuditLogger(logger *audit.Logger) *audit.Logger {
+	return s.auditLogger.Swap(logger)
+}
+
+// sessionClients remembers the client info sent in initialize, per session,
+// and whether the server issued the session's ID. The oldest sessions are
+// forgotten first.
+type sessionClients struct {
+	mu       sync.Mutex
+	sessions map[string]sessionEntry
+	order    []string
+	limit    int
+}
+
+// sessionEntry is what sessionClients keeps for one session.
+type sessionEntry struct {
+	info   ClientInfo
+	issued bool
+}
+
+// newSessionClients creates a sessionClients store holding up to limit sessions.
+func newSessionClients(limit int) *sessionClients {
+	return &sessionClients{sessions: make(map[string]sessionEntry), limit: limit}
+}
+
+// Set records the client info for a session. A session stays issued once
+// the server has issued its ID.
+func (c *sessionClients) Set(sessionID string, info ClientInfo, issued bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.sessions[sessionID]
+	if !ok {
+		c.order = append(c.order, sessionID)
+		if len(c.order) > c.limit {
+			delete(c.sessions, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.sessions[sessionID] = sessionEntry{info: info, issued: entry.issued || issued}
+}
+
+// Get returns the client info for a session.
+func (c *sessionClients) Get(sessionID string) (ClientInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.sessions[sessionID]
+	return entry.info, ok
+}
+
+// Issued reports whether the server issued the session's ID in response to
+// its initialize request.
+func (c *sessionClients) Issued(sessionID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessions[sessionID].issued
+}
+
+// auditToolCall writes the audit entry for a tools/call answered by response.
+func (s *Server) auditToolCall(ctx context.Context, logger *audit.Logger, params *CallToolParams, response *Response, calls []client.UpstreamCall, latency time.Duration) {
+	entry := audit.Entry{
+		Time:      time.Now().UTC().Add(-latency),
+		Tool:      params.Name,
+		Arguments: executedArguments(params.Name, params.Arguments),
+		LatencyMS: milliseconds(latency),
+	}
+
+	entry.RequestID, _ = RequestIDFromContext(ctx)
+	if sessionID, ok := SessionIDFromContext(ctx); ok {
+		entry.SessionID = sessionID
+		if info, ok := s.sessionClients.Get(sessionID); ok && info.Name != "" {
+			entry.Client = &audit.Client{Name: info.Name, Version: info.Version}
+		}
+	}
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		entry.Principal = principal.Subject
+		entry.AuthMethod = principal.Method
+	}
+
+	// getWhatsMyIP has no arguments; the address it looks up is the caller's.
+	if params.Name == "getWhatsMyIP" {
+		if r, ok := HTTPRequestFromContext(ctx); ok {
+			entry.Arguments["client_ip"] = whatsmyip.ExtractClientIP(r)
+		}
+	}
+
+	entry.Status, entry.Error = toolCallStatus(response)
+
+	for _, call := range calls {
+		entry.Upstream = append(entry.Upstream, audit.Upstream{
+			Endpoint:  call.Endpoint,
+			Cache:     call.Cache,
+			QueryID:   call.QueryID,
+			Failed:    call.Failed,
+			LatencyMS: milliseconds(call.Latency),
+		})
+	}
+	entry.Cache = audit.CacheOutcome(entry.Upstream)
+
+	if err := logger.Log(entry); err != nil {
+		slog.ErrorContext(ctx, "failed to write audit entry", "tool", params.Name, "err", err)
+	}
+}
+
+// toolCallStatus returns the audit status of a tools/call response and the
+// error message, if any.
+func toolCallStatus(response *Response) (status, message string) {
+	switch {
+	case response.Error != nil && response.Error.Code == RateLimitError:
+		return audit.StatusRateLimited, response.Error.Message
+	case response.Error != nil:
+		message = response.Error.Message
+		if data, ok := response.Error.Data.(string); ok && data != "" {
+			message += ": " + data
+		}
+		return audit.StatusError, message
+	}
+
+	if result, ok := response.Result.(*ToolResult); ok && result.IsError {
+		if len(result.Content) > 0 {
+			message = result.Content[0].Text
+		}
+		return audit.StatusToolError, message
+	}
+	return audit.StatusOK, ""
+}
+
+// executedArguments returns the arguments of a call to tool as a JSON object,
+// in the form the tool ran with: string values are trimmed and resource
+// arguments are canonicalized, so "as 3333" is recorded as "AS3333". Values the
+// tool rejected are recorded as sent.
+func executedArguments(tool string, arguments interface{}) map[string]interface{} {
+	executed := make(map[string]interface{})
+	if arguments == nil {
+		return executed
+	}
+
+	data, err := json.Marshal(arguments)
+	if err != nil || json.Unmarshal(data, &executed) != nil {
+		return map[string]interface{}{}
+	}
+
+	for name, value := range executed {
+		if s, ok := value.(string); ok {
+			executed[name] = strings.TrimSpace(s)
+		}
+	}
+	_, _ = normalizeResourceArgs(tool, executed)
+	return executed
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
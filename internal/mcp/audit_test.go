@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/audit"
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/quota"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+)
+
+// useStubRIPEstat points the RIPEstat clients at a local server returning
+// an empty result with a fixed query ID.
+func useStubRIPEstat(t *testing.T) {
+	t.Helper()
+
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"status": "ok", "query_id": "20250618120000-stub", "data": {"prefix": "193.0.0.0/21", "asns": ["3333"]}}`)
+	}))
+	t.Cleanup(stub.Close)
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	t.Cleanup(func() { ripeconfig.SetDefault(nil) })
+
+	// Responses from other stubs must not be served from the shared cache.
+	cache.Shared().Clear()
+	t.Cleanup(cache.Shared().Clear)
+}
+
+// readAuditEntries decodes the audit lines written to buf.
+func readAuditEntries(t *testing.T, buf *bytes.Buffer) []audit.Entry {
+	t.Helper()
+
+	var entries []audit.Entry
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry audit.Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid audit line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestHandleToolsCall_Audit(t *testing.T) {
+	useStubRIPEstat(t)
+
+	redact, err := audit.NewRedaction(audit.DefaultRedact)
+	if err != nil {
+		t.Fatalf("NewRedaction failed: %v", err)
+	}
+	var buf bytes.Buffer
+	server := NewServer("test", "1.0.0", false)
+	if previous := server.SetAuditLogger(audit.New(redact, &buf)); previous != nil {
+		t.Error("Expected no previous audit logger")
+	}
+	server.SetQuotaPolicy(quota.Policy{Enabled: true, Default: quota.Limits{Daily: 3}})
+
+	httpReq := httptest.NewRequest("POST", "/mcp", nil)
+	httpReq.RemoteAddr = "192.0.2.10:4711"
+	ctx := WithHTTPRequest(context.Background(), httpReq)
+	ctx = WithSessionID(ctx, "s1")
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithPrincipal(ctx, &auth.Principal{Subject: "alice", Method: auth.MethodAPIKey})
+
+	call := func(name string, arguments map[string]interface{}) {
+		t.Helper()
+		req := NewRequest("tools/call", map[string]interface{}{"name": name, "arguments": arguments}, 1)
+		if _, err := server.handleToolsCall(ctx, req); err != nil {
+			t.Fatalf("handleToolsCall failed: %v", err)
+		}
+	}
+
+	initialize := NewRequest("initialize", map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"clientInfo":      map[string]interface{}{"name": "inspector", "version": "0.9.0"},
+	}, 0)
+	if _, err := server.handleInitialize(ctx, initialize); err != nil {
+		t.Fatalf("handleInitialize failed: %v", err)
+	}
+
+	call("getNetworkInfo", map[string]interface{}{"resource": " 193.0.0.1 "})
+	call("getNetworkInfo", map[string]interface{}{})
+	call("getWhatsMyIP", nil)
+	call("getWhois", map[string]interface{}{"resource": "193.0.0.1"})
+
+	entries := readAuditEntries(t, &buf)
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 audit entries, got %d: %s", len(entries), buf.String())
+	}
+
+	ok := entries[0]
+	if ok.Tool != "getNetworkInfo" || ok.Status != audit.StatusOK || ok.SessionID != "s1" || ok.RequestID != "req-1" ||
+		ok.Principal != "alice" || ok.AuthMethod != auth.MethodAPIKey || ok.Time.IsZero() {
+		t.Errorf("Unexpected entry %+v", ok)
+	}
+	if ok.Client == nil || ok.Client.Name != "inspector" || ok.Client.Version != "0.9.0" {
+		t.Errorf("Expected client info from initialize, got %+v", ok.Client)
+	}
+	if ok.Arguments["resource"] != "193.0.0.1" {
+		t.Errorf("Expected trimmed arguments, got %v", ok.Arguments)
+	}
+	if ok.Cache != audit.CacheMiss || len(ok.Upstream) != 1 ||
+		ok.Upstream[0].Endpoint != "network-info" || ok.Upstream[0].QueryID != "20250618120000-stub" {
+		t.Errorf("Unexpected upstream record %+v (cache %s)", ok.Upstream, ok.Cache)
+	}
+
+	invalid := entries[1]
+	if invalid.Status != audit.StatusToolError || invalid.Error == "" || invalid.Cache != audit.CacheNone {
+		t.Errorf("Expected tool error without upstream calls, got %+v", invalid)
+	}
+
+	whatsMyIP := entries[2]
+	if whatsMyIP.Arguments["client_ip"] != audit.RedactedValue {
+		t.Errorf("Expected redacted client IP, got %v", whatsMyIP.Arguments)
+	}
+	if strings.Contains(buf.String(), "192.0.2.10") {
+		t.Error("Expected the client address not to appear in the audit log")
+	}
+
+	limited := entries[3]
+	if limited.Status != audit.StatusRateLimited || !strings.Contains(limited.Error, "daily_quota") || len(limited.Upstream) != 0 {
+		t.Errorf("Expected rate limited entry, got %+v", limited)
+	}
+
+	// Disabling the logger stops auditing.
+	server.SetAuditLogger(nil)
+	buf.Reset()
+	call("getNetworkInfo", map[string]interface{}{})
+	if buf.Len() != 0 {
+		t.Errorf("Expected no audit output when disabled, got %s", buf.String())
+	}
+}
+
+func TestExecutedArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		tool      string
+		arguments interface{}
+		want      map[string]interface{}
+	}{
+		{name: "none", tool: "getWhatsMyIP", arguments: nil, want: map[string]interface{}{}},
+		{name: "asn", tool: "getASOverview", arguments: map[string]interface{}{"resource": " as 3333 "}, want: map[string]interface{}{"resource": "AS3333"}},
+		{
+			name:      "prefix and other arguments",
+			tool:      "getRPKIValidation",
+			arguments: map[string]interface{}{"resource": "as3333", "prefix": "193.0.0.0/21 ", "max_items": float64(5)},
+			want:      map[string]interface{}{"resource": "AS3333", "prefix": "193.0.0.0/21", "max_items": float64(5)},
+		},
+		{name: "rejected", tool: "getASOverview", arguments: map[string]interface{}{"resource": " not-an-asn "}, want: map[string]interface{}{"resource": "not-an-asn"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := executedArguments(tt.tool, tt.arguments)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleToolsCall_SupportReference(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, `{"status": "error", "query_id": "20250618120000-failed", "messages": []}`)
+	}))
+	defer stub.Close()
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	defer ripeconfig.SetDefault(nil)
+
+	var buf bytes.Buffer
+	server := NewServer("test", "1.0.0", false)
+	server.SetAuditLogger(audit.New(audit.Redaction{}, &buf))
+
+	ctx := WithRequestID(context.Background(), "req-42")
+	req := NewRequest("tools/call", map[string]interface{}{
+		"name":      "getNetworkInfo",
+		"arguments": map[string]interface{}{"resource": "192.0.2.42"},
+	}, 1)
+	resp, err := server.handleToolsCall(ctx, req)
+	if err != nil {
+		t.Fatalf("handleToolsCall failed: %v", err)
+	}
+
+	result, ok := resp.(*Response).Result.(*ToolResult)
+	if !ok || !result.IsError || len(result.Content) != 2 {
+		t.Fatalf("Expected tool error with a support reference, got %+v", resp.(*Response).Result)
+	}
+	reference := result.Content[1].Text
+	if !strings.Contains(reference, "Request ID: req-42") || !strings.Contains(reference, "20250618120000-failed") {
+		t.Errorf("Expected request and query IDs in %q", reference)
+	}
+
+	entries := readAuditEntries(t, &buf)
+	if len(entries) != 1 || entries[0].RequestID != "req-42" || entries[0].Error != result.Content[0].Text ||
+		len(entries[0].Upstream) != 1 || entries[0].Upstream[0].QueryID != "20250618120000-failed" {
+		t.Errorf("Unexpected audit entries %+v", entries)
+	}
+
+	// Without a request ID or upstream calls there is nothing to add.
+	req = NewRequest("tools/call", map[string]interface{}{"name": "getNetworkInfo", "arguments": map[string]interface{}{}}, 2)
+	resp, _ = server.handleToolsCall(context.Background(), req)
+	if result := resp.(*Response).Result.(*ToolResult); len(result.Content) != 1 {
+		t.Errorf("Expected no support reference, got %+v", result.Content)
+	}
+}
+
+func TestSessionClients_Evicts(t *testing.T) {
+	clients := newSessionClients(2)
+	clients.Set("a", ClientInfo{Name: "a"}, true)
+	clients.Set("b", ClientInfo{Name: "b"}, false)
+	clients.Set("a", ClientInfo{Name: "a2"}, false)
+	clients.Set("c", ClientInfo{Name: "c"}, true)
+
+	if _, ok := clients.Get("a"); ok {
+		t.Error("Expected oldest session to be evicted")
+	}
+	if info, ok := clients.Get("c"); !ok || info.Name != "c" {
+		t.Errorf("Expected newest session to be kept, got %+v", info)
+	}
+	if clients.Issued("a") || clients.Issued("b") || !clients.Issued("c") {
+		t.Error("Expected only the kept session issued by the server to be issued")
+	}
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+const (
+	// MaxBatchSize is the maximum number of messages accepted in a single JSON-RPC batch.
+	MaxBatchSize = 100
+
+	// batchConcurrency bounds how many batch members are processed at the same time.
+	// Upstream requests are additionally bounded by the RIPEstat client rate limiter.
+	batchConcurrency = 8
+)
+
+// handleBatch processes a JSON-RPC batch. Members run concurrently and every
+// member is answered independently, so one failing member does not fail the
+// batch. Notifications produce no response; a batch of only notifications
+// returns nil.
+func (s *Server) handleBatch(ctx context.Context, batch Batch) (interface{}, error) {
+	if len(batch) == 0 {
+		return NewErrorResponse(InvalidRequest, "Invalid request", "empty batch", nil), nil
+	}
+
+	if len(batch) > MaxBatchSize {
+		return NewErrorResponse(InvalidRequest, "Invalid request",
+			fmt.Sprintf("batch exceeds maximum size of %d messages", MaxBatchSize), nil), nil
+	}
+
+	slog.DebugContext(ctx, "processing batch", "size", len(batch))
+
+	results := make([]interface{}, len(batch))
+	messages := make([]interface{}, len(batch))
+
+	for i, raw := range batch {
+		msg, err := ParseMessage(raw)
+		if err != nil {
+			results[i] = NewErrorResponse(InvalidRequest, "Invalid request", err.Error(), nil)
+			continue
+		}
+		if _, nested := msg.(Batch); nested {
+			results[i] = NewErrorResponse(InvalidRequest, "Invalid request", "nested batches are not allowed", nil)
+			continue
+		}
+		messages[i] = msg
+	}
+
+	// Lifecycle messages run first and in order, so that the remaining
+	// members of a batch starting with initialize see an initialized server.
+	for i, msg := range messages {
+		if isLifecycleMessage(msg) {
+			results[i] = s.processBatchMember(ctx, msg)
+		}
+	}
+
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, msg := range messages {
+		if msg == nil || isLifecycleMessage(msg) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, msg interface{}) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = batchMemberError(msg, InternalError, "Internal error", ctx.Err().Error())
+				return
+			}
+
+			results[i] = s.processBatchMember(ctx, msg)
+		}(i, msg)
+	}
+
+	wg.Wait()
+
+	responses := make([]interface{}, 0, len(results))
+	for _, result := range results {
+		if result != nil {
+			responses = append(responses, result)
+		}
+	}
+
+	if len(responses) == 0 {
+		return nil, nil
+	}
+
+	return responses, nil
+}
+
+// processBatchMember handles a single batch member, converting handler errors
+// into an error response for that member only.
+func (s *Server) processBatchMember(ctx context.Context, msg interface{}) interface{} {
+	result, err := s.dispatchMessage(ctx, msg)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to process batch member", "err", err)
+		return batchMemberError(msg, InternalError, "Internal error", err.Error())
+	}
+	return result
+}
+
+// batchMemberError creates an error response for a batch member. Notifications
+// never receive a response, even on error.
+func batchMemberError(msg interface{}, code int, message, data string) interface{} {
+	req, ok := msg.(*Request)
+	if !ok {
+		return nil
+	}
+	return NewErrorResponse(code, message, data, req.ID)
+}
+
+// isLifecycleMessage reports whether msg initializes the session.
+func isLifecycleMessage(msg interface{}) bool {
+	switch m := msg.(type) {
+	case *Request:
+		return m.Method == "initialize"
+	case *Notification:
+		return m.Method == "initialized" || m.Method == "notifications/initialized"
+	default:
+		return false
+	}
+}
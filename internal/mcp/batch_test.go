@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// processBatch sends a raw batch and returns the responses keyed by their ID.
+func processBatch(t *testing.T, server *Server, batch string) ([]*Response, map[string]*Response) {
+	t.Helper()
+
+	result, err := server.ProcessMessage(context.Background(), []byte(batch))
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	members, ok := result.([]interface{})
+	if !ok {
+		t.Fatalf("Expected batch response, got %T", result)
+	}
+
+	responses := make([]*Response, 0, len(members))
+	byID := make(map[string]*Response)
+	for _, member := range members {
+		response, ok := member.(*Response)
+		if !ok {
+			t.Fatalf("Expected *Response batch member, got %T", member)
+		}
+		responses = append(responses, response)
+		byID[fmt.Sprint(response.ID)] = response
+	}
+	return responses, byID
+}
+
+func TestProcessMessage_Batch(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+
+	batch := `[
+		{"jsonrpc": "2.0", "method": "initialize", "params": {"protocolVersion": "2025-06-18"}, "id": "init"},
+		{"jsonrpc": "2.0", "method": "notifications/initialized"},
+		{"jsonrpc": "2.0", "method": "tools/list", "id": 2},
+		{"jsonrpc": "2.0", "method": "ping", "id": 3},
+		{"jsonrpc": "2.0", "method": "nonexistent", "id": 4},
+		{"jsonrpc": "2.0", "method": "tools/call", "params": {"name": "getWhois", "arguments": {}}, "id": 5}
+	]`
+
+	responses, byID := processBatch(t, server, batch)
+
+	if len(responses) != 5 {
+		t.Fatalf("Expected 5 responses (notification excluded), got %d", len(responses))
+	}
+
+	if byID["init"] == nil || byID["init"].Error != nil {
+		t.Errorf("Expected successful initialize response, got %+v", byID["init"])
+	}
+	if byID["2"] == nil || byID["2"].Error != nil {
+		t.Errorf("Expected tools/list to succeed after initialize in the same batch, got %+v", byID["2"])
+	}
+	if byID["3"] == nil || byID["3"].Error != nil {
+		t.Errorf("Expected successful ping response, got %+v", byID["3"])
+	}
+	if byID["4"] == nil || byID["4"].Error == nil || byID["4"].Error.Code != MethodNotFound {
+		t.Errorf("Expected method not found for member 4, got %+v", byID["4"])
+	}
+
+	toolResult, ok := byID["5"].Result.(*ToolResult)
+	if !ok || !toolResult.IsError {
+		t.Errorf("Expected tool error result for member 5, got %+v", byID["5"])
+	}
+
+	// Responses keep the input order.
+	wantOrder := []string{"init", "2", "3", "4", "5"}
+	for i, response := range responses {
+		if got := fmt.Sprint(response.ID); got != wantOrder[i] {
+			t.Errorf("Response %d: expected ID %s, got %s", i, wantOrder[i], got)
+		}
+	}
+}
+
+func TestProcessMessage_BatchOnlyNotifications(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+
+	result, err := server.ProcessMessage(context.Background(), []byte(`[
+		{"jsonrpc": "2.0", "method": "notifications/initialized"},
+		{"jsonrpc": "2.0", "method": "notifications/cancelled"}
+	]`))
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected no response for a batch of notifications, got %v", result)
+	}
+	if !server.initialized {
+		t.Error("Expected initialized notification inside the batch to be handled")
+	}
+}
+
+func TestProcessMessage_BatchInvalid(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+
+	tests := []struct {
+		name  string
+		batch string
+		want  string
+	}{
+		{name: "empty batch", batch: `[]`, want: "empty batch"},
+		{name: "oversized batch", batch: "[" + strings.Repeat(`{"jsonrpc":"2.0","method":"ping","id":1},`, MaxBatchSize) + `{"jsonrpc":"2.0","method":"ping","id":1}]`, want: "maximum size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := server.ProcessMessage(context.Background(), []byte(tt.batch))
+			if err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+
+			response, ok := result.(*Response)
+			if !ok {
+				t.Fatalf("Expected single error response, got %T", result)
+			}
+			if response.Error == nil || response.Error.Code != InvalidRequest {
+				t.Fatalf("Expected InvalidRequest, got %+v", response.Error)
+			}
+			if !strings.Contains(fmt.Sprint(response.Error.Data), tt.want) {
+				t.Errorf("Expected error data to contain %q, got %v", tt.want, response.Error.Data)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_BatchInvalidMembers(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+
+	responses, byID := processBatch(t, server, `[1, [], {"jsonrpc": "2.0", "method": "ping", "id": 7}]`)
+
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses, got %d", len(responses))
+	}
+	for _, response := range responses[:2] {
+		if response.Error == nil || response.Error.Code != InvalidRequest || response.ID != nil {
+			t.Errorf("Expected InvalidRequest with null ID, got %+v", response)
+		}
+	}
+	if byID["7"] == nil || byID["7"].Error != nil {
+		t.Errorf("Expected valid member to succeed, got %+v", byID["7"])
+	}
+}
+
+func TestProcessMessage_BatchRunsConcurrently(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+	server.initialized = true
+
+	const members = 4
+	var active, peak int32
+	release := make(chan struct{})
+
+	server.searchComplete = func(ctx context.Context, _ string) ([]string, error) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		if n == members {
+			close(release)
+		}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil, nil
+	}
+
+	requests := make([]interface{}, 0, members)
+	for i := 0; i < members; i++ {
+		requests = append(requests, NewRequest("completion/complete", toolRef("getWhois", "resource", "AS33"), i))
+	}
+	data, err := json.Marshal(requests)
+	if err != nil {
+		t.Fatalf("Failed to marshal batch: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		responses, _ := processBatch(t, server, string(data))
+		if len(responses) != members {
+			t.Errorf("Expected %d responses, got %d", members, len(responses))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(completionUpstreamTimeout + time.Second):
+		t.Fatal("batch did not complete")
+	}
+
+	if atomic.LoadInt32(&peak) != members {
+		t.Errorf("Expected %d members to run concurrently, peak was %d", members, peak)
+	}
+}
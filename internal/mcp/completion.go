@@ -0,0 +1,349 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/resource"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/searchcomplete"
+)
+
+const (
+	// completionMaxValues is the maximum number of values in a completion result (MCP spec limit).
+	completionMaxValues = 100
+
+	// completionMinUpstreamQuery is the minimum input length before RIPEstat searchcomplete is asked.
+	completionMinUpstreamQuery = 2
+
+	// completionUpstreamTimeout bounds the searchcomplete call so completion stays interactive.
+	completionUpstreamTimeout = 5 * time.Second
+
+	// recentResourcesLimit is the number of recently queried resources kept for completion.
+	recentResourcesLimit = 256
+)
+
+// Completion reference types. "ref/prompt" and "ref/resource" are defined by
+// the MCP specification; "ref/tool" is an extension of this server for
+// completing tool arguments.
+const (
+	RefTypeTool     = "ref/tool"
+	RefTypePrompt   = "ref/prompt"
+	RefTypeResource = "ref/resource"
+)
+
+// CompleteParams represents parameters for a completion/complete request.
+type CompleteParams struct {
+	Ref      CompletionReference `json:"ref"`
+	Argument CompletionArgument  `json:"argument"`
+	Context  *CompletionContext  `json:"context,omitempty"`
+}
+
+// CompletionReference identifies the tool, prompt or resource whose argument is
+// being completed. Besides the standard "ref/prompt" and "ref/resource" types,
+// "ref/tool" is accepted as an extension for completing tool arguments.
+type CompletionReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument being completed and its partial value.
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionContext carries already resolved arguments of the same call.
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompleteResult represents the result of a completion/complete request.
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+// Completion holds the suggested values for an argument.
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// completionKind describes which values a tool argument accepts.
+type completionKind int
+
+const (
+	completeNone     completionKind = iota
+	completeCountry                 // ISO 3166-1 alpha-2 country code
+	completeASN                     // Autonomous System Number
+	completePrefix                  // IP address or prefix
+	completeResource                // ASN, IP address or prefix
+	completeLOD                     // Level of detail (0 or 1)
+)
+
+// toolArgumentCompletions maps every tool to the completable kinds of its arguments.
+var toolArgumentCompletions = map[string]map[string]completionKind{
+	"getNetworkInfo":              {"resource": completePrefix},
+	"getASOverview":               {"resource": completeASN},
+	"getAnnouncedPrefixes":        {"resource": completeASN},
+	"getRelatedPrefixes":          {"resource": completePrefix},
+	"getRoutingStatus":            {"resource": completePrefix},
+	"getRoutingHistory":           {"resource": completeResource},
+	"getWhois":                    {"resource": completeResource},
+	"getAbuseContactFinder":       {"resource": completePrefix},
+	"getRPKIValidation":           {"resource": completeASN, "prefix": completePrefix},
+	"getRPKIHistory":              {"resource": completePrefix},
+	"getASNNeighbours":            {"resource": completeASN, "lod": completeLOD},
+	"getLookingGlass":             {"resource": completePrefix},
+	"getCountryASNs":              {"resource": completeCountry, "lod": completeLOD},
+	"getBGPlay":                   {"resource": completePrefix},
+	"getBGPUpdates":               {"resource": completePrefix},
+	"getPrefixRoutingConsistency": {"resource": completePrefix},
+	"getPrefixOverview":           {"resource": completePrefix},
+	"getAddressSpaceHierarchy":    {"resource": completePrefix},
+	"getAllocationHistory":        {"resource": completePrefix},
+	"getASPathLength":             {"resource": completeASN},
+	"getASRoutingConsistency":     {"resource": completeASN},
+	"getWhatsMyIP":                {},
+}
+
+// searchCompleteFunc returns RIPEstat resource suggestions for a partial search term.
+type searchCompleteFunc func(ctx context.Context, query string) ([]string, error)
+
+// defaultSearchComplete queries the RIPEstat searchcomplete data call.
+func defaultSearchComplete(ctx context.Context, query string) ([]string, error) {
+	response, err := searchcomplete.GetSearchComplete(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data.Values(), nil
+}
+
+// recentResources remembers resources of successful tool calls, most recent first.
+type recentResources struct {
+	mu    sync.Mutex
+	items []string
+	limit int
+}
+
+// newRecentResources creates a recentResources list holding up to limit entries.
+func newRecentResources(limit int) *recentResources {
+	return &recentResources{limit: limit}
+}
+
+// Add records a resource, moving it to the front if it is already known.
+func (r *recentResources) Add(resource string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, item := range r.items {
+		if item == resource {
+			r.items = append(r.items[:i], r.items[i+1:]...)
+			break
+		}
+	}
+
+	r.items = append([]string{resource}, r.items...)
+	if len(r.items) > r.limit {
+		r.items = r.items[:r.limit]
+	}
+}
+
+// List returns a copy of the recorded resources, most recent first.
+func (r *recentResources) List() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := make([]string, len(r.items))
+	copy(items, r.items)
+	return items
+}
+
+// recordRecentResources remembers the resource arguments of a successful tool call.
+func (s *Server) recordRecentResources(args map[string]interface{}) {
+	for _, key := range []string{"resource", "prefix"} {
+		value, ok := args[key].(string)
+		if !ok {
+			continue
+		}
+		if normalized, ok := completionCandidate(completeResource, value); ok {
+			s.recent.Add(normalized)
+		}
+	}
+}
+
+// handleComplete handles completion/complete requests.
+func (s *Server) handleComplete(ctx context.Context, req *Request) (interface{}, error) {
+	slog.DebugContext(ctx, "handling completion/complete request")
+
+	var params CompleteParams
+	if req.Params != nil {
+		jsonData, err := json.Marshal(req.Params)
+		if err != nil {
+			return NewErrorResponse(InvalidParams, "Invalid params", err.Error(), req.ID), nil
+		}
+		if err := json.Unmarshal(jsonData, &params); err != nil {
+			return NewErrorResponse(InvalidParams, "Invalid params", err.Error(), req.ID), nil
+		}
+	}
+
+	var arguments map[string]completionKind
+	switch params.Ref.Type {
+	case RefTypeTool:
+		var ok bool
+		arguments, ok = toolArgumentCompletions[params.Ref.Name]
+		if !ok || !s.IsToolEnabled(params.Ref.Name) {
+			return NewErrorResponse(InvalidParams, "Invalid params", "unknown tool: "+params.Ref.Name, req.ID), nil
+		}
+	case RefTypePrompt, RefTypeResource:
+		// The server has no prompts or resource templates, so there is nothing
+		// to complete, which is not an error.
+	default:
+		return NewErrorResponse(InvalidParams, "Invalid params", "unsupported reference type: "+params.Ref.Type, req.ID), nil
+	}
+
+	if params.Argument.Name == "" {
+		return NewErrorResponse(InvalidParams, "Invalid params", "argument name is required", req.ID), nil
+	}
+
+	values := s.completeValues(ctx, arguments[params.Argument.Name], params.Argument.Value)
+
+	completion := Completion{Values: values, Total: len(values)}
+	if len(values) > completionMaxValues {
+		completion.Values = values[:completionMaxValues]
+		completion.HasMore = true
+	}
+
+	return NewResponse(&CompleteResult{Completion: completion}, req.ID), nil
+}
+
+// completeValues returns all candidate values of the given kind matching the partial value.
+func (s *Server) completeValues(ctx context.Context, kind completionKind, value string) []string {
+	switch kind {
+	case completeCountry:
+		return completeCountryCodes(value)
+	case completeLOD:
+		return filterByPrefix([]string{"0", "1"}, value)
+	case completeASN, completePrefix, completeResource:
+		return s.completeResources(ctx, kind, value)
+	default:
+		return []string{}
+	}
+}
+
+// completeCountryCodes matches the input against country codes first and country names second.
+func completeCountryCodes(value string) []string {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	values := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, c := range resource.Countries {
+		if strings.HasPrefix(c.Code, value) {
+			values = append(values, c.Code)
+			seen[c.Code] = true
+		}
+	}
+
+	for _, c := range resource.Countries {
+		if !seen[c.Code] && strings.Contains(strings.ToLower(c.Name), value) {
+			values = append(values, c.Code)
+		}
+	}
+
+	return values
+}
+
+// completeResources completes ASNs and IP resources from recent tool calls and RIPEstat searchcomplete.
+func (s *Server) completeResources(ctx context.Context, kind completionKind, value string) []string {
+	value = strings.TrimSpace(value)
+
+	// Candidates are matched case-insensitively against the raw input and,
+	// for ASN arguments, against its normalized "AS" form.
+	prefixes := []string{strings.ToLower(value)}
+	if normalized, ok := normalizeCompletionASN(value); ok && kind != completePrefix {
+		prefixes = append(prefixes, strings.ToLower(normalized))
+	}
+
+	values := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(candidates []string) {
+		for _, candidate := range candidates {
+			normalized, ok := completionCandidate(kind, candidate)
+			if !ok || seen[normalized] || !hasAnyPrefix(strings.ToLower(normalized), prefixes) {
+				continue
+			}
+			seen[normalized] = true
+			values = append(values, normalized)
+		}
+	}
+
+	add(s.recent.List())
+
+	if len(value) >= completionMinUpstreamQuery && len(values) < completionMaxValues && s.searchComplete != nil {
+		upstreamCtx, cancel := context.WithTimeout(ctx, completionUpstreamTimeout)
+		defer cancel()
+
+		suggestions, err := s.searchComplete(upstreamCtx, value)
+		if err != nil {
+			slog.DebugContext(ctx, "searchcomplete lookup failed", "query", value, "err", err)
+		} else {
+			add(suggestions)
+		}
+	}
+
+	return values
+}
+
+// completionCandidate normalizes a candidate value and reports whether it fits the completion kind.
+func completionCandidate(kind completionKind, candidate string) (string, bool) {
+	r, err := resource.Parse(candidate)
+	if err != nil {
+		return "", false
+	}
+
+	switch r.Kind {
+	case resource.KindASN:
+		return r.Value, kind == completeASN || kind == completeResource
+	case resource.KindIPv4, resource.KindIPv6, resource.KindPrefix:
+		return r.Value, kind == completePrefix || kind == completeResource
+	default:
+		return "", false
+	}
+}
+
+// normalizeCompletionASN converts "AS 3333", "as3333" and "3333" into "AS3333".
+func normalizeCompletionASN(value string) (string, bool) {
+	r, err := resource.Parse(value)
+	if err != nil || r.Kind != resource.KindASN {
+		return "", false
+	}
+	return r.Value, true
+}
+
+// hasAnyPrefix reports whether s starts with any of the given prefixes.
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByPrefix returns the candidates starting with the given prefix.
+func filterByPrefix(candidates []string, prefix string) []string {
+	prefix = strings.TrimSpace(prefix)
+	values := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			values = append(values, candidate)
+		}
+	}
+	return values
+}
@@ -0,0 +1,313 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/resource"
+)
+
+// completeRequest sends a completion/complete request and returns the decoded completion.
+func completeRequest(t *testing.T, server *Server, params map[string]interface{}) (*Completion, *Error) {
+	t.Helper()
+
+	req := NewRequest("completion/complete", params, 1)
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	result, err := server.ProcessMessage(context.Background(), data)
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	response, ok := result.(*Response)
+	if !ok {
+		t.Fatalf("Expected Response, got %T", result)
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+
+	completeResult, ok := response.Result.(*CompleteResult)
+	if !ok {
+		t.Fatalf("Expected *CompleteResult, got %T", response.Result)
+	}
+	return &completeResult.Completion, nil
+}
+
+func toolRef(name, argument, value string) map[string]interface{} {
+	return map[string]interface{}{
+		"ref":      map[string]interface{}{"type": RefTypeTool, "name": name},
+		"argument": map[string]interface{}{"name": argument, "value": value},
+	}
+}
+
+func newCompletionTestServer(suggestions []string, upstreamErr error) (*Server, *[]string) {
+	server := NewServer("test-server", "1.0.0", false)
+	server.initialized = true
+
+	var queries []string
+	server.searchComplete = func(_ context.Context, query string) ([]string, error) {
+		queries = append(queries, query)
+		return suggestions, upstreamErr
+	}
+	return server, &queries
+}
+
+func TestComplete_RequiresInitialization(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+
+	_, rpcErr := completeRequest(t, server, toolRef("getCountryASNs", "resource", "n"))
+	if rpcErr == nil || rpcErr.Code != InitializationError {
+		t.Fatalf("Expected initialization error, got %v", rpcErr)
+	}
+}
+
+func TestComplete_CountryCodes(t *testing.T) {
+	server, _ := newCompletionTestServer(nil, nil)
+
+	tests := []struct {
+		value string
+		want  string
+		first bool
+	}{
+		{value: "n", want: "nl"},
+		{value: "NL", want: "nl", first: true},
+		{value: "nether", want: "nl", first: true},
+		{value: "germ", want: "de", first: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			completion, rpcErr := completeRequest(t, server, toolRef("getCountryASNs", "resource", tt.value))
+			if rpcErr != nil {
+				t.Fatalf("Unexpected error: %v", rpcErr)
+			}
+			if tt.first {
+				if len(completion.Values) == 0 || completion.Values[0] != tt.want {
+					t.Errorf("Expected %q first, got %v", tt.want, completion.Values)
+				}
+				return
+			}
+			found := false
+			for _, v := range completion.Values {
+				if v == tt.want {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected %q in %v", tt.want, completion.Values)
+			}
+		})
+	}
+}
+
+func TestComplete_TruncatesToMaxValues(t *testing.T) {
+	server, _ := newCompletionTestServer(nil, nil)
+
+	completion, rpcErr := completeRequest(t, server, toolRef("getCountryASNs", "resource", ""))
+	if rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr)
+	}
+
+	if len(completion.Values) != completionMaxValues {
+		t.Errorf("Expected %d values, got %d", completionMaxValues, len(completion.Values))
+	}
+	if !completion.HasMore {
+		t.Error("Expected hasMore to be true")
+	}
+	if completion.Total != len(resource.Countries) {
+		t.Errorf("Expected total %d, got %d", len(resource.Countries), completion.Total)
+	}
+}
+
+func TestComplete_LOD(t *testing.T) {
+	server, _ := newCompletionTestServer(nil, nil)
+
+	completion, rpcErr := completeRequest(t, server, toolRef("getASNNeighbours", "lod", ""))
+	if rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr)
+	}
+	if strings.Join(completion.Values, ",") != "0,1" {
+		t.Errorf("Expected [0 1], got %v", completion.Values)
+	}
+
+	completion, _ = completeRequest(t, server, toolRef("getCountryASNs", "lod", "1"))
+	if strings.Join(completion.Values, ",") != "1" {
+		t.Errorf("Expected [1], got %v", completion.Values)
+	}
+}
+
+func TestComplete_ASNsFromRecentAndUpstream(t *testing.T) {
+	server, queries := newCompletionTestServer([]string{"AS33333", "193.0.0.0/21", "AS3333"}, nil)
+	server.recordRecentResources(map[string]interface{}{"resource": "as 3333"})
+	server.recordRecentResources(map[string]interface{}{"resource": "193.0.0.0/21"})
+
+	completion, rpcErr := completeRequest(t, server, toolRef("getASOverview", "resource", "333"))
+	if rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr)
+	}
+
+	if strings.Join(completion.Values, ",") != "AS3333,AS33333" {
+		t.Errorf("Expected [AS3333 AS33333], got %v", completion.Values)
+	}
+	if len(*queries) != 1 || (*queries)[0] != "333" {
+		t.Errorf("Expected one upstream query for '333', got %v", *queries)
+	}
+}
+
+func TestComplete_PrefixesFromRecent(t *testing.T) {
+	server, queries := newCompletionTestServer(nil, nil)
+	server.recordRecentResources(map[string]interface{}{"resource": "AS3333", "prefix": "193.0.0.0/21"})
+	server.recordRecentResources(map[string]interface{}{"resource": "2001:67C:2E8::/48"})
+
+	completion, rpcErr := completeRequest(t, server, toolRef("getRPKIValidation", "prefix", "1"))
+	if rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr)
+	}
+	if strings.Join(completion.Values, ",") != "193.0.0.0/21" {
+		t.Errorf("Expected [193.0.0.0/21], got %v", completion.Values)
+	}
+	if len(*queries) != 0 {
+		t.Errorf("Expected no upstream query for single character input, got %v", *queries)
+	}
+
+	completion, _ = completeRequest(t, server, toolRef("getWhois", "resource", ""))
+	if len(completion.Values) != 3 || completion.Values[0] != "2001:67c:2e8::/48" {
+		t.Errorf("Expected all recent resources most recent first, got %v", completion.Values)
+	}
+}
+
+func TestComplete_UpstreamErrorIgnored(t *testing.T) {
+	server, _ := newCompletionTestServer(nil, errors.New("upstream down"))
+	server.recordRecentResources(map[string]interface{}{"resource": "AS3333"})
+
+	completion, rpcErr := completeRequest(t, server, toolRef("getASOverview", "resource", "AS3"))
+	if rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr)
+	}
+	if strings.Join(completion.Values, ",") != "AS3333" {
+		t.Errorf("Expected recent values despite upstream error, got %v", completion.Values)
+	}
+}
+
+func TestComplete_InvalidReferences(t *testing.T) {
+	server, _ := newCompletionTestServer(nil, nil)
+	disabled := NewServer("test-server", "1.0.0", true)
+	disabled.initialized = true
+
+	tests := []struct {
+		name   string
+		server *Server
+		params map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "unknown tool",
+			server: server,
+			params: toolRef("getNothing", "resource", ""),
+			want:   "unknown tool",
+		},
+		{
+			name:   "disabled tool",
+			server: disabled,
+			params: toolRef("getWhatsMyIP", "resource", ""),
+			want:   "unknown tool",
+		},
+		{
+			name:   "unsupported reference type",
+			server: server,
+			params: map[string]interface{}{"ref": map[string]interface{}{"type": "ref/other"}},
+			want:   "unsupported reference type",
+		},
+		{
+			name:   "missing argument name",
+			server: server,
+			params: toolRef("getWhois", "", "AS"),
+			want:   "argument name is required",
+		},
+		{
+			name:   "malformed params",
+			server: server,
+			params: map[string]interface{}{"ref": "not-an-object"},
+			want:   "cannot unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, rpcErr := completeRequest(t, tt.server, tt.params)
+			if rpcErr == nil {
+				t.Fatal("Expected error")
+			}
+			if rpcErr.Code != InvalidParams {
+				t.Errorf("Expected InvalidParams, got %d", rpcErr.Code)
+			}
+			if !strings.Contains(fmt.Sprint(rpcErr.Data), tt.want) {
+				t.Errorf("Expected error data to contain %q, got %v", tt.want, rpcErr.Data)
+			}
+		})
+	}
+}
+
+func TestComplete_UnknownArgumentReturnsEmpty(t *testing.T) {
+	server, _ := newCompletionTestServer(nil, nil)
+
+	completion, rpcErr := completeRequest(t, server, toolRef("getWhois", "nothing", "x"))
+	if rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr)
+	}
+	if len(completion.Values) != 0 {
+		t.Errorf("Expected no values, got %v", completion.Values)
+	}
+}
+
+func TestComplete_SpecReferencesReturnEmpty(t *testing.T) {
+	server, queries := newCompletionTestServer([]string{"AS3333"}, nil)
+
+	refs := []map[string]interface{}{
+		{"type": RefTypePrompt, "name": "investigate"},
+		{"type": RefTypeResource, "uri": "file:///x"},
+	}
+	for _, ref := range refs {
+		completion, rpcErr := completeRequest(t, server, map[string]interface{}{
+			"ref":      ref,
+			"argument": map[string]interface{}{"name": "resource", "value": "AS3"},
+		})
+		if rpcErr != nil {
+			t.Fatalf("Expected an empty completion for %v, got error %v", ref["type"], rpcErr)
+		}
+		if len(completion.Values) != 0 || completion.HasMore {
+			t.Errorf("Expected no values for %v, got %+v", ref["type"], completion)
+		}
+	}
+	if len(*queries) != 0 {
+		t.Errorf("Expected no upstream lookups, got %v", *queries)
+	}
+}
+
+func TestRecentResources_Limit(t *testing.T) {
+	recent := newRecentResources(2)
+	recent.Add("a")
+	recent.Add("b")
+	recent.Add("a")
+	recent.Add("c")
+
+	if got := strings.Join(recent.List(), ","); got != "c,a" {
+		t.Errorf("Expected [c a], got %v", got)
+	}
+}
+
+func TestToolArgumentCompletions_CoverAllTools(t *testing.T) {
+	for _, tool := range CreateToolsList().Tools {
+		if _, ok := toolArgumentCompletions[tool.Name]; !ok {
+			t.Errorf("Tool %s has no completion entry", tool.Name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 )
@@ -30,6 +31,10 @@ type Notification struct {
 	Params  interface{} `json:"params,omitempty"`
 }
 
+// Batch represents a JSON-RPC 2.0 batch: an array of messages, kept raw so
+// that every member can be parsed and answered independently.
+type Batch []json.RawMessage
+
 // Error represents a JSON-RPC 2.0 error.
 type Error struct {
 	Code    int         `json:"code"`
@@ -52,6 +57,10 @@ const (
 	ProtocolError       = -32001
 	ResourceError       = -32002
 	ToolError           = -32003
+	RateLimitError      = -32004
+	NotFoundError       = -32005
+	UnavailableError    = -32006
+	TimeoutError        = -32007
 )
 
 // NewRequest creates a new JSON-RPC request.
@@ -115,7 +124,16 @@ func (r *Request) IsNotification() bool {
 }
 
 // ParseMessage parses a JSON message into appropriate JSON-RPC type.
+// A JSON array is returned as a Batch.
 func ParseMessage(data []byte) (interface{}, error) {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch Batch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return batch, nil
+	}
+
 	var raw map[string]interface{}
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("invalid JSON: %w", err)
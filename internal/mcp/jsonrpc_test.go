@@ -155,6 +155,18 @@ func TestParseMessage(t *testing.T) {
 			wantType: "",
 			wantErr:  true,
 		},
+		{
+			name:     "parse batch",
+			data:     `  [{"jsonrpc": "2.0", "method": "test", "id": 1}, {"jsonrpc": "2.0", "method": "notification"}]`,
+			wantType: "mcp.Batch",
+			wantErr:  false,
+		},
+		{
+			name:     "invalid batch json",
+			data:     `[{"jsonrpc": "2.0",]`,
+			wantType: "",
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -173,6 +185,8 @@ func TestParseMessage(t *testing.T) {
 					msgType = "*mcp.Response"
 				case *Notification:
 					msgType = "*mcp.Notification"
+				case Batch:
+					msgType = "mcp.Batch"
 				}
 				if msgType != tt.wantType {
 					t.Errorf("ParseMessage() got type %s, want %s", msgType, tt.wantType)
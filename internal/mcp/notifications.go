@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// notificationBuffer is the number of notifications queued per subscriber
+// before further notifications are dropped for that subscriber.
+const notificationBuffer = 16
+
+// notificationHub fans out server-initiated notifications to subscribed
+// sessions.
+type notificationHub struct {
+	mu          sync.Mutex
+	subscribers map[chan *Notification]string
+}
+
+func newNotificationHub() *notificationHub {
+	return &notificationHub{subscribers: make(map[chan *Notification]string)}
+}
+
+// subscribe registers a subscriber for sessionID. The returned function
+// unsubscribes and closes the channel.
+func (h *notificationHub) subscribe(sessionID string) (<-chan *Notification, func()) {
+	ch := make(chan *Notification, notificationBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = sessionID
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// broadcast queues notif for every subscriber without blocking. Slow
+// subscribers miss the notification rather than stalling the server.
+func (h *notificationHub) broadcast(notif *Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch, sessionID := range h.subscribers {
+		select {
+		case ch <- notif:
+		default:
+			slog.Warn("dropping notification for slow session", "method", notif.Method, "session_id", sessionID)
+		}
+	}
+}
+
+// count returns the number of subscribers.
+func (h *notificationHub) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// SubscribeNotifications returns a channel of server-initiated notifications
+// for a session, such as notifications/tools/list_changed. Call the returned
+// function when the session's stream closes.
+func (s *Server) SubscribeNotifications(sessionID string) (<-chan *Notification, func()) {
+	return s.notifications.subscribe(sessionID)
+}
+
+// notify sends a notification to every subscribed session.
+func (s *Server) notify(notif *Notification) {
+	slog.Debug("broadcasting notification", "method", notif.Method, "sessions", s.notifications.count())
+	s.notifications.broadcast(notif)
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// DefaultToolsPageSize is the number of tools returned per tools/list page.
+const DefaultToolsPageSize = 50
+
+// toolsCursorPrefix namespaces tools/list cursors so that cursors issued for
+// other lists are rejected.
+const toolsCursorPrefix = "tools:"
+
+// errInvalidCursor is returned for cursors that were not issued by the server.
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor creates an opaque pagination cursor for the given offset.
+func encodeCursor(prefix string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(prefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset stored in cursor. An empty cursor is the
+// first page.
+func decodeCursor(prefix, cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+
+	value, ok := strings.CutPrefix(string(raw), prefix)
+	if !ok {
+		return 0, errInvalidCursor
+	}
+
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, errInvalidCursor
+	}
+
+	return offset, nil
+}
+
+// paginateTools returns the page of tools starting at cursor and the cursor of
+// the next page, which is empty on the last page.
+func paginateTools(tools []Tool, cursor string, pageSize int) ([]Tool, string, error) {
+	offset, err := decodeCursor(toolsCursorPrefix, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The list can shrink between pages when tools are disabled; a cursor
+	// pointing exactly at the end still yields an empty last page.
+	if offset > len(tools) {
+		return nil, "", errInvalidCursor
+	}
+
+	if pageSize <= 0 {
+		pageSize = DefaultToolsPageSize
+	}
+
+	end := offset + pageSize
+	if end >= len(tools) {
+		return tools[offset:], "", nil
+	}
+
+	return tools[offset:end], encodeCursor(toolsCursorPrefix, end), nil
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+func listToolsPage(t *testing.T, server *Server, cursor string) *Response {
+	t.Helper()
+
+	var params interface{}
+	if cursor != "" {
+		params = map[string]interface{}{"cursor": cursor}
+	}
+
+	result, err := server.handleToolsList(context.Background(), NewRequest("tools/list", params, 1))
+	if err != nil {
+		t.Fatalf("handleToolsList failed: %v", err)
+	}
+
+	response, ok := result.(*Response)
+	if !ok {
+		t.Fatalf("Expected *Response, got %T", result)
+	}
+	return response
+}
+
+func TestHandleToolsList_SinglePageByDefault(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+
+	response := listToolsPage(t, server, "")
+	result := response.Result.(*ToolsListResult)
+
+	if len(result.Tools) != len(CreateToolsList().Tools) {
+		t.Errorf("Expected all %d tools, got %d", len(CreateToolsList().Tools), len(result.Tools))
+	}
+	if result.NextCursor != "" {
+		t.Errorf("Expected no next cursor, got %q", result.NextCursor)
+	}
+}
+
+func TestHandleToolsList_Pagination(t *testing.T) {
+	for _, disableWhatsMyIP := range []bool{false, true} {
+		server := NewServer("test-server", "1.0.0", disableWhatsMyIP)
+		server.SetToolsPageSize(5)
+
+		seen := make(map[string]bool)
+		cursor := ""
+		pages := 0
+
+		for {
+			response := listToolsPage(t, server, cursor)
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %+v", response.Error)
+			}
+
+			result := response.Result.(*ToolsListResult)
+			if len(result.Tools) > 5 {
+				t.Errorf("Expected at most 5 tools per page, got %d", len(result.Tools))
+			}
+			for _, tool := range result.Tools {
+				if seen[tool.Name] {
+					t.Errorf("Tool %s returned twice", tool.Name)
+				}
+				seen[tool.Name] = true
+			}
+
+			pages++
+			cursor = result.NextCursor
+			if cursor == "" {
+				break
+			}
+			if pages > 100 {
+				t.Fatal("pagination did not terminate")
+			}
+		}
+
+		want := len(CreateToolsList().Tools)
+		if disableWhatsMyIP {
+			want--
+		}
+		if len(seen) != want {
+			t.Errorf("Expected %d tools across pages, got %d", want, len(seen))
+		}
+		if seen["getWhatsMyIP"] == disableWhatsMyIP {
+			t.Errorf("getWhatsMyIP listed = %v with disableWhatsMyIP = %v", seen["getWhatsMyIP"], disableWhatsMyIP)
+		}
+		if wantPages := (want + 4) / 5; pages != wantPages {
+			t.Errorf("Expected %d pages, got %d", wantPages, pages)
+		}
+	}
+}
+
+func TestHandleToolsList_InvalidCursor(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+
+	cursors := []string{
+		"not base64!",
+		encodeCursor("other:", 5),
+		encodeCursor(toolsCursorPrefix, -1),
+		encodeCursor(toolsCursorPrefix, 1000),
+	}
+
+	for _, cursor := range cursors {
+		response := listToolsPage(t, server, cursor)
+		if response.Error == nil || response.Error.Code != InvalidParams {
+			t.Errorf("cursor %q: expected InvalidParams, got %+v", cursor, response.Error)
+		}
+	}
+}
+
+func TestDecodeCursor_RoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 1, 50, 12345} {
+		got, err := decodeCursor(toolsCursorPrefix, encodeCursor(toolsCursorPrefix, offset))
+		if err != nil {
+			t.Fatalf("decodeCursor(%d) failed: %v", offset, err)
+		}
+		if got != offset {
+			t.Errorf("decodeCursor() = %d, want %d", got, offset)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result shaping arguments accepted by every tool.
+const (
+	fieldsArgument = "fields"
+	filterArgument = "filter"
+)
+
+// filterMetaKey is the _meta key reporting how many items a filter kept.
+const filterMetaKey = "filter"
+
+// resultPath is a parsed field path such as "data.prefixes[].prefix". Lists
+// along the path are traversed element by element, so "[]" is optional.
+type resultPath []string
+
+// String formats the path in dotted form.
+func (p resultPath) String() string {
+	if len(p) == 0 {
+		return "(root)"
+	}
+	return strings.Join(p, ".")
+}
+
+// parseResultPath parses a dotted path. A leading "$", "." or "$." as used
+// by JSONPath and jq is accepted, as are "[]" and "[*]" after a key.
+func parseResultPath(expr string) (resultPath, error) {
+	value := strings.TrimSpace(expr)
+	value = strings.TrimPrefix(value, "$")
+	value = strings.TrimPrefix(value, ".")
+	if value == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var path resultPath
+	for _, segment := range strings.Split(value, ".") {
+		segment = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(segment, "[]"), "[*]"))
+		if segment == "" || strings.ContainsAny(segment, "[]*\"'") {
+			return nil, fmt.Errorf("invalid path %q: use dotted keys such as data.prefixes.prefix", expr)
+		}
+		path = append(path, segment)
+	}
+	return path, nil
+}
+
+// resultFilter keeps the items of a list whose value at a path compares true
+// against a literal.
+type resultFilter struct {
+	path  resultPath
+	op    string
+	value interface{}
+}
+
+// filterPattern matches "path op value" filter expressions.
+var filterPattern = regexp.MustCompile(`^([^\s=!<>]+)\s*(==|!=|<=|>=|<|>|\s+contains\s+)\s*(.+)$`)
+
+// parseResultFilter parses a filter such as `data.asns.asn == 3333` or
+// `data.prefixes.prefix contains "193.0."`. The value is a JSON literal or a
+// bare word, which is read as a string.
+func parseResultFilter(expr string) (*resultFilter, error) {
+	match := filterPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if match == nil {
+		return nil, fmt.Errorf("invalid filter %q: use \"<path> <op> <value>\" with ==, !=, <, <=, >, >= or contains", expr)
+	}
+
+	path, err := parseResultPath(match[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(match[3]), &value); err != nil {
+		value = strings.TrimSpace(match[3])
+	}
+	return &resultFilter{path: path, op: strings.TrimSpace(match[2]), value: value}, nil
+}
+
+// resultShape holds the projection and filter arguments of a tool call.
+type resultShape struct {
+	fields []resultPath
+	filter *resultFilter
+}
+
+// addShapeProperties adds the fields and filter arguments to a tool's schema.
+func addShapeProperties(schema interface{}) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties, ok := object["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties[fieldsArgument] = map[string]interface{}{
+		"type":        "string",
+		"description": "Comma-separated field paths to return, for example 'data.asns,data.prefix'. Lists along a path are traversed item by item ('data.prefixes.prefix' or 'data.prefixes[].prefix').",
+	}
+	properties[filterArgument] = map[string]interface{}{
+		"type":        "string",
+		"description": "Keep only the items of a list matching '<path> <op> <value>', where the path leads through the list to a field of its items, for example 'data.prefixes.prefix contains 193.0.' or 'neighbours.asn == 3333'. Operators: ==, !=, <, <=, >, >=, contains.",
+	}
+}
+
+// shapeParams removes the fields and filter arguments from args and parses
+// them, so syntax errors are reported before RIPEstat is queried.
+func shapeParams(args map[string]interface{}) (resultShape, *ToolResult) {
+	var shape resultShape
+
+	if value, ok := args[fieldsArgument]; ok {
+		delete(args, fieldsArgument)
+		fields, ok := value.(string)
+		if !ok {
+			return resultShape{}, invalidInputResult("Error: fields parameter must be a string")
+		}
+		for _, expr := range strings.Split(fields, ",") {
+			path, err := parseResultPath(expr)
+			if err != nil {
+				return resultShape{}, invalidInputResult(fmt.Sprintf("Error: fields parameter is invalid: %v", err))
+			}
+			shape.fields = append(shape.fields, path)
+		}
+	}
+
+	if value, ok := args[filterArgument]; ok {
+		delete(args, filterArgument)
+		expr, ok := value.(string)
+		if !ok {
+			return resultShape{}, invalidInputResult("Error: filter parameter must be a string")
+		}
+		filter, err := parseResultFilter(expr)
+		if err != nil {
+			return resultShape{}, invalidInputResult(fmt.Sprintf("Error: filter parameter is invalid: %v", err))
+		}
+		shape.filter = filter
+	}
+
+	return shape, nil
+}
+
+// shapeResult applies the filter and then the projection to the JSON text of
+// a successful result. Paths missing from the result are reported as invalid
+// input, naming the keys that do exist.
+func shapeResult(result *ToolResult, shape resultShape) *ToolResult {
+	if result == nil || result.IsError || len(result.Content) == 0 || (shape.fields == nil && shape.filter == nil) {
+		return result
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(result.Content[0].Text))
+	decoder.UseNumber()
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return invalidInputResult("Error: fields and filter parameters require a JSON result")
+	}
+
+	if shape.filter != nil {
+		filtered, kept, total, err := applyFilter(root, shape.filter)
+		if err != nil {
+			return invalidInputResult(fmt.Sprintf("Error: filter parameter is invalid: %v", err))
+		}
+		root = filtered
+		if result.Meta == nil {
+			result.Meta = map[string]interface{}{}
+		}
+		result.Meta[filterMetaKey] = map[string]interface{}{"matched": kept, "total": total}
+	}
+
+	if shape.fields != nil {
+		var projected interface{}
+		for _, path := range shape.fields {
+			value, err := projectPath(root, path, nil)
+			if err != nil {
+				return invalidInputResult(fmt.Sprintf("Error: fields parameter is invalid: %v", err))
+			}
+			projected = mergeProjection(projected, value)
+		}
+		root = projected
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return CreateToolErrorResult(err)
+	}
+	result.Content[0].Text = string(data)
+	return result
+}
+
+// errMissingPath reports a path that does not exist, listing the keys of the
+// object where it ends.
+func errMissingPath(path, at resultPath, object map[string]interface{}) error {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("path %q not found: %s has keys %s", path.String(), at.String(), strings.Join(keys, ", "))
+}
+
+// projectPath returns the parts of value along path, keeping the enclosing
+// objects and lists. at is the part of the path already traversed.
+func projectPath(value interface{}, path, at resultPath) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	full := append(at[:len(at):len(at)], path...)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil, errMissingPath(full, at, v)
+		}
+		projected, err := projectPath(child, path[1:], append(at[:len(at):len(at)], path[0]))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{path[0]: projected}, nil
+	case []interface{}:
+		// Items lacking the path are kept as empty objects, so the items
+		// of several projected paths line up; the path must exist in at
+		// least one item.
+		items := make([]interface{}, len(v))
+		var firstErr error
+		found := len(v) == 0
+		for i, item := range v {
+			projected, err := projectPath(item, path, at)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				items[i] = map[string]interface{}{}
+				continue
+			}
+			items[i] = projected
+			found = true
+		}
+		if !found {
+			return nil, firstErr
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("path %q not found: %s is not an object", full.String(), at.String())
+	}
+}
+
+// mergeProjection merges the projections of two paths.
+func mergeProjection(a, b interface{}) interface{} {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			return b
+		}
+		for key, value := range bv {
+			if existing, ok := av[key]; ok {
+				av[key] = mergeProjection(existing, value)
+			} else {
+				av[key] = value
+			}
+		}
+		return av
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return b
+		}
+		for i := range av {
+			av[i] = mergeProjection(av[i], bv[i])
+		}
+		return av
+	}
+	return b
+}
+
+// applyFilter keeps the items of the first list along the filter's path that
+// match it, and returns the result with the number of kept and total items.
+func applyFilter(root interface{}, filter *resultFilter) (interface{}, int, int, error) {
+	var at resultPath
+	value := root
+	for i := 0; ; i++ {
+		switch v := value.(type) {
+		case []interface{}:
+			rest := filter.path[i:]
+			if len(v) > 0 && !anyHasPath(v, rest) {
+				return nil, 0, 0, fmt.Errorf("path %q not found: no item of %s has %s", filter.path.String(), at.String(), rest.String())
+			}
+			kept := make([]interface{}, 0, len(v))
+			for _, item := range v {
+				if filter.matches(collectValues(item, rest)) {
+					kept = append(kept, item)
+				}
+			}
+			return replaceAt(root, at, kept), len(kept), len(v), nil
+		case map[string]interface{}:
+			if i == len(filter.path) {
+				return nil, 0, 0, fmt.Errorf("path %q does not lead through a list", filter.path.String())
+			}
+			child, ok := v[filter.path[i]]
+			if !ok {
+				return nil, 0, 0, errMissingPath(filter.path, at, v)
+			}
+			at = append(at, filter.path[i])
+			value = child
+		default:
+			return nil, 0, 0, fmt.Errorf("path %q does not lead through a list", filter.path.String())
+		}
+	}
+}
+
+// replaceAt returns root with the value at path replaced, copying the
+// objects along the path.
+func replaceAt(root interface{}, path resultPath, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	object := root.(map[string]interface{})
+	copied := make(map[string]interface{}, len(object))
+	for key, v := range object {
+		copied[key] = v
+	}
+	copied[path[0]] = replaceAt(object[path[0]], path[1:], value)
+	return copied
+}
+
+// anyHasPath reports whether the path exists in any of the items.
+func anyHasPath(items []interface{}, path resultPath) bool {
+	for _, item := range items {
+		if _, err := projectPath(item, path, nil); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// collectValues returns the values at path below value, traversing lists.
+func collectValues(value interface{}, path resultPath) []interface{} {
+	if len(path) == 0 {
+		if items, ok := value.([]interface{}); ok {
+			return items
+		}
+		return []interface{}{value}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+		return collectValues(child, path[1:])
+	case []interface{}:
+		var values []interface{}
+		for _, item := range v {
+			values = append(values, collectValues(item, path)...)
+		}
+		return values
+	}
+	return nil
+}
+
+// matches reports whether any of an item's values compares true. "!=" holds
+// when no value equals the literal, including when there are none.
+func (f *resultFilter) matches(values []interface{}) bool {
+	if f.op == "!=" {
+		for _, v := range values {
+			if compareValues(v, "==", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compareValues(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareValues compares a result value with a filter literal. Numbers and
+// numeric strings compare numerically, everything else as case-insensitive
+// text, so "AS3333", "as3333" and 3333 can all be matched.
+func compareValues(v interface{}, op string, literal interface{}) bool {
+	a, b := valueText(v), valueText(literal)
+	if op == "contains" {
+		return strings.Contains(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	var cmp int
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			cmp = -1
+		case x > y:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// valueText returns the text form of a decoded JSON value.
+func valueText(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
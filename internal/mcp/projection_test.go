@@ -0,0 +1,287 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+)
+
+// useEndpointStubRIPEstat points the RIPEstat client at a stub answering each
+// data call with the data object in bodies, keyed by data call name.
+func useEndpointStubRIPEstat(t *testing.T, bodies map[string]string) {
+	t.Helper()
+
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/data/"), "/data.json")
+		data, ok := bodies[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "ok", "status_code": 200, "data": ` + data + `}`))
+	}))
+	t.Cleanup(stub.Close)
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	t.Cleanup(func() { ripeconfig.SetDefault(nil) })
+	cache.Shared().Clear()
+	t.Cleanup(cache.Shared().Clear)
+}
+
+// assertJSON fails unless text holds the same JSON value as want.
+func assertJSON(t *testing.T, text, want string) {
+	t.Helper()
+	var got, expected interface{}
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatalf("Result is not valid JSON: %v\n%s", err, text)
+	}
+	if err := json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatalf("Invalid expectation: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %s, want %s", text, want)
+	}
+}
+
+func TestExecuteToolCall_FieldsEveryTool(t *testing.T) {
+	useEndpointStubRIPEstat(t, map[string]string{
+		"network-info":               `{"asns": ["3333"], "prefix": "193.0.0.0/21"}`,
+		"as-overview":                `{"type": "as", "resource": "3333", "holder": "RIPE-NCC-AS", "announced": true, "block": {"resource": "3154-3353", "desc": "RIPE NCC ASN block", "name": "IANA-ASN-BLOCK"}}`,
+		"announced-prefixes":         `{"resource": "3333", "prefixes": [{"prefix": "193.0.0.0/21", "timelines": []}, {"prefix": "2001:67c:2e8::/48", "timelines": []}]}`,
+		"related-prefixes":           `{"resource": "193.0.0.0/21", "prefixes": [{"prefix": "193.0.10.0/23", "origin_asn": "3333", "asn_name": "RIPE-NCC-AS", "relationship": "Overlap - More Specific"}]}`,
+		"routing-status":             `{"resource": "193.0.0.0/21", "announced": true, "asns": ["3333"]}`,
+		"routing-history":            `{"resource": "3333", "by_origin": [{"origin": "3333", "prefixes": [{"prefix": "193.0.0.0/21", "timelines": []}]}]}`,
+		"whois":                      `{"resource": "193.0.0.0/21", "authorities": ["ripe"], "irr_records": [], "records": [[{"key": "inetnum", "value": "193.0.0.0 - 193.0.7.255", "details_link": null}, {"key": "abuse-mailbox", "value": "abuse@ripe.net", "details_link": null}]]}`,
+		"abuse-contact-finder":       `{"abuse_contacts": ["abuse@ripe.net"], "authoritative_rir": "ripe"}`,
+		"rpki-validation":            `{"status": "valid", "validator": "routinator", "resource": "3333", "prefix": "193.0.0.0/21", "validating_roas": [{"origin": "3333", "prefix": "193.0.0.0/21", "max_length": 21, "validity": "valid"}]}`,
+		"asn-neighbours":             `{"resource": "3333", "neighbour_counts": {"left": 1, "right": 0, "unique": 1, "uncertain": 0}, "neighbours": [{"asn": 1299, "type": "left"}]}`,
+		"looking-glass":              `{"rrcs": [{"rrc": "RRC00", "location": "Amsterdam, Netherlands", "peers": [{"prefix": "193.0.0.0/21", "as_path": "1299 3333"}]}]}`,
+		"country-asns":               `{"resource": ["nl"], "countries": [{"resource": "nl", "stats": {"registered": 10, "routed": 8}}]}`,
+		"rpki-history":               `{"timeseries": [{"prefix": "193.0.0.0/21", "time": "2024-01-01T00:00:00Z", "count": 1, "family": 4}]}`,
+		"bgplay":                     `{"resource": "193.0.0.0/21", "initial_state": [], "events": [{"type": "A", "timestamp": "2024-01-01T00:00:00", "attrs": {"target_prefix": "193.0.0.0/21"}}]}`,
+		"bgp-updates":                `{"resource": "193.0.0.0/21", "nr_updates": 1, "updates": [{"seq": 1, "timestamp": "2024-01-01T00:00:00", "type": "A", "attrs": {"source_id": "rrc00"}}]}`,
+		"prefix-routing-consistency": `{"resource": "193.0.0.0/21", "routes": [{"in_bgp": true, "in_whois": true, "prefix": "193.0.0.0/21", "origin": 3333, "irr_sources": ["RIPE"], "asn_name": "RIPE-NCC-AS"}]}`,
+		"prefix-overview":            `{"announced": true, "resource": "193.0.0.0/21", "asns": [{"asn": 3333, "holder": "RIPE-NCC-AS"}]}`,
+		"address-space-hierarchy":    `{"rir": "ripe", "resource": "193.0.0.0/21", "exact": [{"inetnum": "193.0.0.0 - 193.0.7.255", "netname": "RIPE-NCC"}], "less_specific": [], "more_specific": []}`,
+		"allocation-history":         `{"resource": "193.0.0.0/21", "results": {"RIPE NCC": [{"resource": "193.0.0.0/21", "status": "ALLOCATED PA", "timelines": []}]}}`,
+		"as-path-length":             `{"resource": "3333", "stats": [{"number": 1, "count": 5, "location": "rrc00"}]}`,
+		"as-routing-consistency":     `{"prefixes": [{"in_bgp": true, "in_whois": false, "irr_sources": [], "prefix": "193.0.0.0/21"}], "imports": [{"in_bgp": true, "in_whois": true, "peer": 1299}]}`,
+		"whats-my-ip":                `{"ip": "198.51.100.7"}`,
+	})
+	server := NewServer("test", "1.0.0", false)
+
+	tests := []struct {
+		tool   string
+		args   map[string]interface{}
+		fields string
+		want   string
+	}{
+		{tool: "getNetworkInfo", args: map[string]interface{}{"resource": "193.0.0.1"}, fields: "data.prefix", want: `{"data": {"prefix": "193.0.0.0/21"}}`},
+		{tool: "getASOverview", args: map[string]interface{}{"resource": "AS3333"}, fields: "data.holder,data.block.name", want: `{"data": {"holder": "RIPE-NCC-AS", "block": {"name": "IANA-ASN-BLOCK"}}}`},
+		{tool: "getAnnouncedPrefixes", args: map[string]interface{}{"resource": "AS3333"}, fields: "data.prefixes[].prefix", want: `{"data": {"prefixes": [{"prefix": "193.0.0.0/21"}, {"prefix": "2001:67c:2e8::/48"}]}}`},
+		{tool: "getRelatedPrefixes", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.prefixes.origin_asn", want: `{"data": {"prefixes": [{"origin_asn": "3333"}]}}`},
+		{tool: "getRoutingStatus", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.asns", want: `{"data": {"asns": ["3333"]}}`},
+		{tool: "getRoutingHistory", args: map[string]interface{}{"resource": "AS3333"}, fields: "data.by_origin.prefixes.prefix", want: `{"data": {"by_origin": [{"prefixes": [{"prefix": "193.0.0.0/21"}]}]}}`},
+		{tool: "getWhois", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.authorities", want: `{"data": {"authorities": ["ripe"]}}`},
+		{tool: "getAbuseContactFinder", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "contacts", want: `{"contacts": ["abuse@ripe.net"]}`},
+		{tool: "getRPKIValidation", args: map[string]interface{}{"resource": "AS3333", "prefix": "193.0.0.0/21"}, fields: "$.status", want: `{"status": "valid"}`},
+		{tool: "getASNNeighbours", args: map[string]interface{}{"resource": "AS3333"}, fields: "neighbours.asn", want: `{"neighbours": [{"asn": 1299}]}`},
+		{tool: "getLookingGlass", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "rrcs.location", want: `{"rrcs": [{"location": "Amsterdam, Netherlands"}]}`},
+		{tool: "getCountryASNs", args: map[string]interface{}{"resource": "nl"}, fields: "data.countries.stats.routed", want: `{"data": {"countries": [{"stats": {"routed": 8}}]}}`},
+		{tool: "getRPKIHistory", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.timeseries.count", want: `{"data": {"timeseries": [{"count": 1}]}}`},
+		{tool: "getBGPlay", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.events.type", want: `{"data": {"events": [{"type": "A"}]}}`},
+		{tool: "getBGPUpdates", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: ".data.nr_updates", want: `{"data": {"nr_updates": 1}}`},
+		{tool: "getPrefixRoutingConsistency", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.routes.origin", want: `{"data": {"routes": [{"origin": 3333}]}}`},
+		{tool: "getPrefixOverview", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.asns.holder", want: `{"data": {"asns": [{"holder": "RIPE-NCC-AS"}]}}`},
+		{tool: "getAddressSpaceHierarchy", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.exact.netname", want: `{"data": {"exact": [{"netname": "RIPE-NCC"}]}}`},
+		{tool: "getAllocationHistory", args: map[string]interface{}{"resource": "193.0.0.0/21"}, fields: "data.results.RIPE NCC.status", want: `{"data": {"results": {"RIPE NCC": [{"status": "ALLOCATED PA"}]}}}`},
+		{tool: "getASPathLength", args: map[string]interface{}{"resource": "AS3333"}, fields: "data.stats.location", want: `{"data": {"stats": [{"location": "rrc00"}]}}`},
+		{tool: "getASRoutingConsistency", args: map[string]interface{}{"resource": "AS3333"}, fields: "data.imports.peer", want: `{"data": {"imports": [{"peer": 1299}]}}`},
+		{tool: "getWhatsMyIP", args: map[string]interface{}{}, fields: "ip", want: `{"ip": "198.51.100.7"}`},
+	}
+
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		covered[tt.tool] = true
+		t.Run(tt.tool, func(t *testing.T) {
+			tt.args["fields"] = tt.fields
+			result, err := server.executeToolCall(context.Background(), &CallToolParams{Name: tt.tool, Arguments: tt.args})
+			if err != nil || result.IsError {
+				t.Fatalf("Unexpected failure: %v %+v", err, result)
+			}
+			assertJSON(t, result.Content[0].Text, tt.want)
+		})
+	}
+
+	for _, tool := range CreateToolsList().Tools {
+		if !covered[tool.Name] {
+			t.Errorf("Expected a projection test for %s", tool.Name)
+		}
+	}
+}
+
+func TestExecuteToolCall_Filter(t *testing.T) {
+	useEndpointStubRIPEstat(t, map[string]string{
+		"announced-prefixes": `{"resource": "3333", "prefixes": [{"prefix": "193.0.0.0/21", "timelines": []}, {"prefix": "193.0.10.0/23", "timelines": []}, {"prefix": "2001:67c:2e8::/48", "timelines": []}]}`,
+		"whois":              `{"resource": "193.0.0.0/21", "authorities": ["ripe"], "records": [[{"key": "inetnum", "value": "193.0.0.0 - 193.0.7.255"}], [{"key": "abuse-mailbox", "value": "abuse@ripe.net"}]]}`,
+		"asn-neighbours":     `{"resource": "3333", "neighbours": [{"asn": 1299, "type": "left"}, {"asn": 3356, "type": "left"}, {"asn": 64496, "type": "right"}]}`,
+	})
+	server := NewServer("test", "1.0.0", false)
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]interface{}
+		want    string
+		matched int
+	}{
+		{
+			name:    "contains",
+			tool:    "getAnnouncedPrefixes",
+			args:    map[string]interface{}{"resource": "AS3333", "filter": "data.prefixes.prefix contains 193.0.", "fields": "data.prefixes.prefix"},
+			want:    `{"data": {"prefixes": [{"prefix": "193.0.0.0/21"}, {"prefix": "193.0.10.0/23"}]}}`,
+			matched: 2,
+		},
+		{
+			name:    "nested lists",
+			tool:    "getWhois",
+			args:    map[string]interface{}{"resource": "193.0.0.0/21", "filter": `data.records.key == "abuse-mailbox"`, "fields": "data.records.value"},
+			want:    `{"data": {"records": [[{"value": "abuse@ripe.net"}]]}}`,
+			matched: 1,
+		},
+		{
+			name:    "numeric",
+			tool:    "getASNNeighbours",
+			args:    map[string]interface{}{"resource": "AS3333", "filter": "neighbours.asn < 4000", "fields": "neighbours.asn"},
+			want:    `{"neighbours": [{"asn": 1299}, {"asn": 3356}]}`,
+			matched: 2,
+		},
+		{
+			name:    "not equal",
+			tool:    "getASNNeighbours",
+			args:    map[string]interface{}{"resource": "AS3333", "filter": "neighbours.type != LEFT", "fields": "neighbours.asn"},
+			want:    `{"neighbours": [{"asn": 64496}]}`,
+			matched: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := server.executeToolCall(context.Background(), &CallToolParams{Name: tt.tool, Arguments: tt.args})
+			if err != nil || result.IsError {
+				t.Fatalf("Unexpected failure: %v %+v", err, result)
+			}
+			assertJSON(t, result.Content[0].Text, tt.want)
+			if filter, ok := result.Meta[filterMetaKey].(map[string]interface{}); !ok || filter["matched"] != tt.matched {
+				t.Errorf("Expected %d matched items in _meta, got %+v", tt.matched, result.Meta)
+			}
+		})
+	}
+}
+
+func TestExecuteToolCall_ShapeErrors(t *testing.T) {
+	requested := useRecordingStubRIPEstat(t, func(w http.ResponseWriter, _ string) {
+		_, _ = w.Write([]byte(`{"status": "ok", "data": {"resource": "193.0.0.0/21", "announced": true, "asns": ["3333"]}}`))
+	})
+	server := NewServer("test", "1.0.0", false)
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "missing field", args: map[string]interface{}{"fields": "data.origins"}, want: `path "data.origins" not found: data has keys announced, asns, query_time, resource`},
+		{name: "missing top level", args: map[string]interface{}{"fields": "result"}, want: `path "result" not found: (root) has keys`},
+		{name: "through a scalar", args: map[string]interface{}{"fields": "data.resource.prefix"}, want: `path "data.resource.prefix" not found: data.resource is not an object`},
+		{name: "filter without list", args: map[string]interface{}{"filter": "data.resource == x"}, want: `path "data.resource" does not lead through a list`},
+		{name: "filter missing list", args: map[string]interface{}{"filter": "data.prefixes.prefix == x"}, want: `path "data.prefixes.prefix" not found: data has keys`},
+		{name: "fields syntax", args: map[string]interface{}{"fields": "data..asns"}, want: "fields parameter is invalid: invalid path"},
+		{name: "filter syntax", args: map[string]interface{}{"filter": "data.asns"}, want: "filter parameter is invalid: invalid filter"},
+		{name: "fields type", args: map[string]interface{}{"fields": []interface{}{"data"}}, want: "fields parameter must be a string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["resource"] = "193.0.0.0/21"
+			result, err := server.executeToolCall(context.Background(), &CallToolParams{Name: "getRoutingStatus", Arguments: tt.args})
+			if err != nil {
+				t.Fatalf("executeToolCall failed: %v", err)
+			}
+			if !result.IsError || !strings.Contains(result.Content[0].Text, tt.want) {
+				t.Errorf("Expected an error containing %q, got %+v", tt.want, result)
+			}
+			if classified, ok := result.Meta[toolErrorMetaKey].(*Error); !ok || classified.Code != InvalidParams {
+				t.Errorf("Expected an invalid params error, got %+v", result.Meta)
+			}
+		})
+	}
+
+	// Syntax errors are reported before RIPEstat is queried; the path
+	// errors share one cached upstream response.
+	if got := requested(); len(got) != 1 {
+		t.Errorf("Expected a single upstream request, got %v", got)
+	}
+}
+
+func TestParseResultPath(t *testing.T) {
+	for expr, want := range map[string]string{
+		"data.prefixes.prefix":    "data.prefixes.prefix",
+		"$.data.prefixes[*].asn":  "data.prefixes.asn",
+		".data.prefixes[].prefix": "data.prefixes.prefix",
+		" data . asns ":           "data.asns",
+	} {
+		path, err := parseResultPath(expr)
+		if err != nil || path.String() != want {
+			t.Errorf("parseResultPath(%q) = %v, %v; want %s", expr, path, err, want)
+		}
+	}
+
+	for _, expr := range []string{"", "$", "data.", "data[0]", `data."x"`} {
+		if _, err := parseResultPath(expr); err == nil {
+			t.Errorf("parseResultPath(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestParseResultFilter(t *testing.T) {
+	tests := []struct {
+		expr  string
+		op    string
+		value interface{}
+	}{
+		{expr: "data.asns.asn == 3333", op: "==", value: float64(3333)},
+		{expr: `data.prefixes.prefix contains "193.0."`, op: "contains", value: "193.0."},
+		{expr: "data.neighbours.type!=left", op: "!=", value: "left"},
+		{expr: "data.stats.count >= 10", op: ">=", value: float64(10)},
+		{expr: "data.routes.in_bgp == true", op: "==", value: true},
+	}
+	for _, tt := range tests {
+		filter, err := parseResultFilter(tt.expr)
+		if err != nil {
+			t.Errorf("parseResultFilter(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if filter.op != tt.op || filter.value != tt.value {
+			t.Errorf("parseResultFilter(%q) = %s %v, want %s %v", tt.expr, filter.op, filter.value, tt.op, tt.value)
+		}
+	}
+}
+
+func TestCreateToolsList_ShapeArguments(t *testing.T) {
+	for _, tool := range CreateToolsList().Tools {
+		properties := tool.InputSchema.(map[string]interface{})["properties"].(map[string]interface{})
+		for _, arg := range []string{fieldsArgument, filterArgument} {
+			if _, ok := properties[arg]; !ok {
+				t.Errorf("Expected %s to accept %s", tool.Name, arg)
+			}
+		}
+	}
+}
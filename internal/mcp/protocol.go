@@ -3,6 +3,9 @@ package mcp
 import (
 	"encoding/json"
 	"fmt"
+
+	ripestaterrors "github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/resource"
 )
 
 // MCP Protocol Version.
@@ -36,12 +39,13 @@ type InitializeResult struct {
 
 // Server capabilities.
 type Capabilities struct {
-	Tools     *ToolsCapability     `json:"tools,omitempty"`
-	Resources *ResourcesCapability `json:"resources,omitempty"`
-	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
-	Logging   *LoggingCapability   `json:"logging,omitempty"`
-	Roots     *RootsCapability     `json:"roots,omitempty"`
-	Transport *TransportCapability `json:"transport,omitempty"`
+	Tools       *ToolsCapability       `json:"tools,omitempty"`
+	Resources   *ResourcesCapability   `json:"resources,omitempty"`
+	Prompts     *PromptsCapability     `json:"prompts,omitempty"`
+	Logging     *LoggingCapability     `json:"logging,omitempty"`
+	Roots       *RootsCapability       `json:"roots,omitempty"`
+	Completions *CompletionsCapability `json:"completions,omitempty"`
+	Transport   *TransportCapability   `json:"transport,omitempty"`
 }
 
 // ToolsCapability represents tools capability.
@@ -68,6 +72,9 @@ type RootsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// CompletionsCapability represents argument completion capability.
+type CompletionsCapability struct{}
+
 // TransportCapability represents transport capability.
 type TransportCapability struct {
 	HTTP *HTTPTransportCapability `json:"http,omitempty"`
@@ -81,14 +88,31 @@ type HTTPTransportCapability struct {
 
 // Tool represents a tool that can be called.
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema interface{} `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	InputSchema interface{}      `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations describes tool behavior to clients. Hints are advisory and
+// let clients skip confirmation prompts for tools without side effects.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint"`
+	DestructiveHint bool   `json:"destructiveHint"`
+	IdempotentHint  bool   `json:"idempotentHint"`
+	OpenWorldHint   bool   `json:"openWorldHint"`
+}
+
+// ListToolsParams represents parameters for listing tools.
+type ListToolsParams struct {
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // ToolsListResult represents the result of listing tools.
 type ToolsListResult struct {
-	Tools []Tool `json:"tools"`
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // CallToolParams represents parameters for calling a tool.
@@ -100,8 +124,9 @@ type CallToolParams struct {
 
 // ToolResult represents the result of calling a tool.
 type ToolResult struct {
-	Content []ToolContent `json:"content"`
-	IsError bool          `json:"isError,omitempty"`
+	Content []ToolContent          `json:"content"`
+	IsError bool                   `json:"isError,omitempty"`
+	Meta    map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // ToolContent represents content returned by a tool.
@@ -115,11 +140,12 @@ func CreateInitializeResult(serverName, serverVersion string) *InitializeResult
 	return &InitializeResult{
 		ProtocolVersion: ProtocolVersion,
 		Capabilities: &Capabilities{
-			Tools:     &ToolsCapability{ListChanged: false},
-			Resources: &ResourcesCapability{Subscribe: false, ListChanged: false},
-			Prompts:   &PromptsCapability{ListChanged: false},
-			Logging:   &LoggingCapability{},
-			Roots:     &RootsCapability{ListChanged: false},
+			Tools:       &ToolsCapability{ListChanged: true},
+			Resources:   &ResourcesCapability{Subscribe: false, ListChanged: false},
+			Prompts:     &PromptsCapability{ListChanged: false},
+			Logging:     &LoggingCapability{},
+			Roots:       &RootsCapability{ListChanged: false},
+			Completions: &CompletionsCapability{},
 			Transport: &TransportCapability{
 				HTTP: &HTTPTransportCapability{
 					Streamable: true,
@@ -139,11 +165,12 @@ func CreateLegacyInitializeResult(serverName, serverVersion string) *InitializeR
 	return &InitializeResult{
 		ProtocolVersion: "2025-03-26", // Use older protocol version
 		Capabilities: &Capabilities{
-			Tools:     &ToolsCapability{ListChanged: false},
-			Resources: &ResourcesCapability{Subscribe: false, ListChanged: false},
-			Prompts:   &PromptsCapability{ListChanged: false},
-			Logging:   &LoggingCapability{},
-			Roots:     &RootsCapability{ListChanged: false},
+			Tools:       &ToolsCapability{ListChanged: true},
+			Resources:   &ResourcesCapability{Subscribe: false, ListChanged: false},
+			Prompts:     &PromptsCapability{ListChanged: false},
+			Logging:     &LoggingCapability{},
+			Roots:       &RootsCapability{ListChanged: false},
+			Completions: &CompletionsCapability{},
 			// No transport capabilities for legacy clients to avoid confusion
 		},
 		ServerInfo: ServerInfo{
@@ -153,19 +180,30 @@ func CreateLegacyInitializeResult(serverName, serverVersion string) *InitializeR
 	}
 }
 
+// readOnlyAnnotations returns the annotations shared by all RIPEstat tools:
+// they only read public data from an external service, so repeated calls
+// have no side effects.
+func readOnlyAnnotations(title string) *ToolAnnotations {
+	return &ToolAnnotations{
+		Title:           title,
+		ReadOnlyHint:    true,
+		DestructiveHint: false,
+		IdempotentHint:  true,
+		OpenWorldHint:   true,
+	}
+}
+
 // CreateToolsList creates a list of available tools.
 func CreateToolsList() *ToolsListResult {
 	tools := []Tool{
 		{
 			Name:        "getNetworkInfo",
 			Description: "Get network information for an IP address or prefix.",
+			Annotations: readOnlyAnnotations("Network Info"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address or prefix to query.",
-					},
+					"resource": resourceProperty("The IP address or prefix to query. Hostnames and URLs are resolved to their addresses when the server enables resolution.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix, resource.KindHostname),
 				},
 				"required":             []string{"resource"},
 				"additionalProperties": false,
@@ -174,13 +212,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getASOverview",
 			Description: "Get an overview of an Autonomous System (AS).",
+			Annotations: readOnlyAnnotations("AS Overview"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The AS number to query.",
-					},
+					"resource": resourceProperty("The AS number to query.", resource.KindASN),
 				},
 				"required": []string{"resource"},
 			},
@@ -188,13 +224,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getAnnouncedPrefixes",
 			Description: "Get a list of prefixes announced by an Autonomous System (AS).",
+			Annotations: readOnlyAnnotations("Announced Prefixes"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The AS number to query.",
-					},
+					"resource": resourceProperty("The AS number to query.", resource.KindASN),
 				},
 				"required": []string{"resource"},
 			},
@@ -202,13 +236,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getRelatedPrefixes",
 			Description: "Get related prefixes that are connected or associated with the given prefix.",
+			Annotations: readOnlyAnnotations("Related Prefixes"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP prefix in CIDR notation to query.",
-					},
+					"resource": resourceProperty("The IP prefix in CIDR notation to query.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
 				},
 				"required": []string{"resource"},
 			},
@@ -216,13 +248,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getRoutingStatus",
 			Description: "Get the routing status for an IP prefix.",
+			Annotations: readOnlyAnnotations("Routing Status"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP prefix to query.",
-					},
+					"resource": resourceProperty("The IP prefix to query.", resource.KindASN, resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
 				},
 				"required": []string{"resource"},
 			},
@@ -230,21 +260,13 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getRoutingHistory",
 			Description: "Get routing history information for an IP address, prefix, or ASN.",
+			Annotations: readOnlyAnnotations("Routing History"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address, prefix, or ASN to query for routing history.",
-					},
-					"start_time": map[string]interface{}{
-						"type":        "string",
-						"description": "Start time for the query in ISO8601 format (e.g., '2024-01-01T00:00:00Z'). If omitted, uses default historical range.",
-					},
-					"end_time": map[string]interface{}{
-						"type":        "string",
-						"description": "End time for the query in ISO8601 format (e.g., '2024-12-31T23:59:59Z'). If omitted, uses current time.",
-					},
+					"resource":   resourceProperty("The IP address, prefix, or ASN to query for routing history.", resource.KindASN, resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
+					"start_time": timeProperty("Start of the time window. If omitted, uses the default historical range."),
+					"end_time":   timeProperty("End of the time window. If omitted, uses the current time."),
 					"max_results": map[string]interface{}{
 						"type":        "string",
 						"description": "Maximum number of routing events to return. Helps limit response size for large datasets.",
@@ -256,13 +278,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getWhois",
 			Description: "Get whois information for an IP address, prefix, or ASN.",
+			Annotations: readOnlyAnnotations("Whois"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address, prefix, or ASN to query.",
-					},
+					"resource": resourceProperty("The IP address, prefix, or ASN to query.", resource.KindASN, resource.KindIPv4, resource.KindIPv6, resource.KindPrefix, resource.KindRange),
 				},
 				"required": []string{"resource"},
 			},
@@ -270,13 +290,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getAbuseContactFinder",
 			Description: "Get abuse contact information for an IP address or prefix.",
+			Annotations: readOnlyAnnotations("Abuse Contact Finder"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address or prefix to query for abuse contacts.",
-					},
+					"resource": resourceProperty("The IP address or prefix to query for abuse contacts. Hostnames and URLs are resolved to their addresses when the server enables resolution.", resource.KindASN, resource.KindIPv4, resource.KindIPv6, resource.KindPrefix, resource.KindHostname),
 				},
 				"required": []string{"resource"},
 			},
@@ -284,17 +302,12 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getRPKIValidation",
 			Description: "Get RPKI validation status for a resource (ASN) and prefix combination.",
+			Annotations: readOnlyAnnotations("RPKI Validation"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The ASN to validate against the prefix.",
-					},
-					"prefix": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP prefix to validate.",
-					},
+					"resource": resourceProperty("The ASN to validate against the prefix.", resource.KindASN),
+					"prefix":   resourceProperty("The IP prefix to validate.", resource.KindPrefix),
 				},
 				"required": []string{"resource", "prefix"},
 			},
@@ -302,13 +315,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getASNNeighbours",
 			Description: "Get ASN neighbours for an Autonomous System. Left neighbours are downstream providers, right neighbours are upstream providers.",
+			Annotations: readOnlyAnnotations("ASN Neighbours"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The AS number to query for neighbours.",
-					},
+					"resource": resourceProperty("The AS number to query for neighbours.", resource.KindASN),
 					"lod": map[string]interface{}{
 						"type":        "string",
 						"description": "Level of detail: 0 (basic) or 1 (detailed with power, v4_peers, v6_peers). Default is 0.",
@@ -324,13 +335,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getLookingGlass",
 			Description: "Get looking glass information for an IP prefix, showing BGP routing data from RIPE NCC's Route Reflection Collectors (RRCs).",
+			Annotations: readOnlyAnnotations("Looking Glass"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP prefix to query for looking glass information.",
-					},
+					"resource": resourceProperty("The IP prefix to query for looking glass information.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
 					"look_back_limit": map[string]interface{}{
 						"type":        "string",
 						"description": "Time limit in seconds to look back for BGP data. Maximum is 172800 seconds (48 hours). Default is 0.",
@@ -342,13 +351,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getCountryASNs",
 			Description: "Get Autonomous System Numbers (ASNs) for a given country code.",
+			Annotations: readOnlyAnnotations("Country ASNs"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "Two-letter ISO country code (e.g., 'nl', 'us', 'de').",
-					},
+					"resource": resourceProperty("Two-letter ISO country code (e.g., 'nl', 'us', 'de').", resource.KindCountry),
 					"lod": map[string]interface{}{
 						"type":        "string",
 						"description": "Level of detail: 0 (basic stats) or 1 (includes lists of routed/non-routed ASNs). Default is 0.",
@@ -360,13 +367,13 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getRPKIHistory",
 			Description: "Get RPKI history information for an IP prefix, showing the historical RPKI validation status.",
+			Annotations: readOnlyAnnotations("RPKI History"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP prefix to query for RPKI history.",
-					},
+					"resource":   resourceProperty("The IP prefix to query for RPKI history.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
+					"start_time": timeProperty("Start of the time window. If omitted, the history starts at its first entry."),
+					"end_time":   timeProperty("End of the time window. If omitted, uses the current time."),
 				},
 				"required": []string{"resource"},
 			},
@@ -374,13 +381,13 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getBGPlay",
 			Description: "Get BGP play data for an IP address or prefix, showing BGP routing events and timeline.",
+			Annotations: readOnlyAnnotations("BGPlay"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address or prefix to query for BGP play data.",
-					},
+					"resource":   resourceProperty("The IP address or prefix to query for BGP play data.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
+					"start_time": timeProperty("Start of the replay, at most 7 days before end_time. If omitted, the replay covers the 24 hours before end_time."),
+					"end_time":   timeProperty("End of the replay. If omitted, uses the current time."),
 				},
 				"required": []string{"resource"},
 			},
@@ -388,13 +395,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getPrefixRoutingConsistency",
 			Description: "Get prefix routing consistency information for an IP prefix, showing BGP routing consistency data.",
+			Annotations: readOnlyAnnotations("Prefix Routing Consistency"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP prefix to query for routing consistency.",
-					},
+					"resource": resourceProperty("The IP prefix to query for routing consistency.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
 				},
 				"required": []string{"resource"},
 			},
@@ -402,13 +407,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getPrefixOverview",
 			Description: "Get prefix overview information for an IP prefix.",
+			Annotations: readOnlyAnnotations("Prefix Overview"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP prefix to query.",
-					},
+					"resource": resourceProperty("The IP prefix to query. Hostnames and URLs are resolved to their addresses when the server enables resolution.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix, resource.KindHostname),
 				},
 				"required": []string{"resource"},
 			},
@@ -416,13 +419,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getAddressSpaceHierarchy",
 			Description: "Get address space hierarchy information for an IP address or prefix.",
+			Annotations: readOnlyAnnotations("Address Space Hierarchy"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address or prefix to query.",
-					},
+					"resource": resourceProperty("The IP address or prefix to query.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix, resource.KindRange),
 				},
 				"required": []string{"resource"},
 			},
@@ -430,13 +431,13 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getAllocationHistory",
 			Description: "Get allocation history information for an IP address or prefix.",
+			Annotations: readOnlyAnnotations("Allocation History"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address or prefix to query for allocation history.",
-					},
+					"resource":   resourceProperty("The IP address or prefix to query for allocation history.", resource.KindIPv4, resource.KindIPv6, resource.KindPrefix, resource.KindRange),
+					"start_time": timeProperty("Start of the time window. If omitted, uses the default historical range."),
+					"end_time":   timeProperty("End of the time window. If omitted, uses the current time."),
 				},
 				"required": []string{"resource"},
 			},
@@ -444,13 +445,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getASPathLength",
 			Description: "Get AS path length statistics and distribution data for an Autonomous System (AS).",
+			Annotations: readOnlyAnnotations("AS Path Length"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The AS number to query (e.g., AS3333).",
-					},
+					"resource": resourceProperty("The AS number to query (e.g., AS3333).", resource.KindASN),
 				},
 				"required": []string{"resource"},
 			},
@@ -458,13 +457,11 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getASRoutingConsistency",
 			Description: "Get AS routing consistency information for an Autonomous System (AS).",
+			Annotations: readOnlyAnnotations("AS Routing Consistency"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The AS number to query (e.g., AS3333).",
-					},
+					"resource": resourceProperty("The AS number to query (e.g., AS3333).", resource.KindASN),
 				},
 				"required": []string{"resource"},
 			},
@@ -472,13 +469,13 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getBGPUpdates",
 			Description: "Get BGP update activity and routing changes for an IP address or prefix.",
+			Annotations: readOnlyAnnotations("BGP Updates"),
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"resource": map[string]interface{}{
-						"type":        "string",
-						"description": "The IP address or prefix to query for BGP updates.",
-					},
+					"resource":   resourceProperty("The IP address or prefix to query for BGP updates.", resource.KindASN, resource.KindIPv4, resource.KindIPv6, resource.KindPrefix),
+					"start_time": timeProperty("Start of the time window, at most 7 days before end_time. If omitted, the window covers the 24 hours before end_time."),
+					"end_time":   timeProperty("End of the time window. If omitted, uses the current time."),
 				},
 				"required": []string{"resource"},
 			},
@@ -486,6 +483,7 @@ func CreateToolsList() *ToolsListResult {
 		{
 			Name:        "getWhatsMyIP",
 			Description: "Get the caller's public IP address. Respects X-Forwarded-For headers when behind a proxy.",
+			Annotations: readOnlyAnnotations("What's My IP"),
 			InputSchema: map[string]interface{}{
 				"type":       "object",
 				"properties": map[string]interface{}{},
@@ -493,9 +491,66 @@ func CreateToolsList() *ToolsListResult {
 		},
 	}
 
+	for _, tool := range tools {
+		addBypassCacheProperty(tool.InputSchema)
+		addPaginationProperties(tool.InputSchema)
+		addShapeProperties(tool.InputSchema)
+	}
+
 	return &ToolsListResult{Tools: tools}
 }
 
+// bypassCacheArgument is accepted by every tool to skip cached RIPEstat
+// responses.
+const bypassCacheArgument = "bypass_cache"
+
+// addBypassCacheProperty adds the bypass_cache argument to a tool's schema.
+func addBypassCacheProperty(schema interface{}) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties, ok := object["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties[bypassCacheArgument] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "Fetch fresh data from RIPEstat instead of using a cached response.",
+	}
+}
+
+// resourceTypesKeyword is the schema keyword listing the resource kinds a
+// string argument accepts. Arguments carrying it are validated and
+// canonicalized before the tool runs.
+const resourceTypesKeyword = "x-resource-types"
+
+// resourceProperty returns the schema of a string argument holding a
+// resource of one of the given kinds.
+func resourceProperty(description string, kinds ...resource.Kind) map[string]interface{} {
+	types := make([]string, len(kinds))
+	for i, kind := range kinds {
+		types[i] = string(kind)
+	}
+	return map[string]interface{}{
+		"type":               "string",
+		"description":        description,
+		resourceTypesKeyword: types,
+	}
+}
+
+// timeFormats describes the time expressions accepted by time arguments.
+const timeFormats = "Accepts RFC 3339 (e.g., '2024-01-31T12:00:00Z'), a date ('2024-01-31'), a Unix timestamp, 'now', 'today', 'yesterday' or a relative time ('2 hours ago', '-7d'). Times without a zone are UTC."
+
+// timeProperty returns the schema of a string argument holding a time
+// expression parsed by timerange.
+func timeProperty(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": description + " " + timeFormats,
+	}
+}
+
 // ParseCallToolParams parses tool call parameters from JSON.
 func ParseCallToolParams(params interface{}) (*CallToolParams, error) {
 	jsonData, err := json.Marshal(params)
@@ -511,6 +566,26 @@ func ParseCallToolParams(params interface{}) (*CallToolParams, error) {
 	return &callParams, nil
 }
 
+// ParseListToolsParams parses tools/list parameters from JSON. Missing params
+// are valid and request the first page.
+func ParseListToolsParams(params interface{}) (*ListToolsParams, error) {
+	var listParams ListToolsParams
+	if params == nil {
+		return &listParams, nil
+	}
+
+	jsonData, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	if err := json.Unmarshal(jsonData, &listParams); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal list tools params: %w", err)
+	}
+
+	return &listParams, nil
+}
+
 // CreateToolResult creates a tool result with text content.
 func CreateToolResult(text string, isError bool) *ToolResult {
 	return &ToolResult{
@@ -528,7 +603,7 @@ func CreateToolResult(text string, isError bool) *ToolResult {
 func CreateToolResultFromJSON(data interface{}) *ToolResult {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		return CreateToolResult(fmt.Sprintf("Error marshaling result: %v", err), true)
+		return classifyToolError(CreateToolResult(fmt.Sprintf("Error marshaling result: %v", err), true), ripestaterrors.KindInternal)
 	}
 
 	return CreateToolResult(string(jsonData), false)
@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -93,6 +94,67 @@ func TestCreateToolsList(t *testing.T) {
 	}
 }
 
+func TestCreateToolsList_Annotations(t *testing.T) {
+	titles := make(map[string]string)
+
+	for _, tool := range CreateToolsList().Tools {
+		a := tool.Annotations
+		if a == nil {
+			t.Errorf("%s: expected annotations", tool.Name)
+			continue
+		}
+		if a.Title == "" {
+			t.Errorf("%s: expected annotation title", tool.Name)
+		}
+		if other, ok := titles[a.Title]; ok {
+			t.Errorf("%s: title %q already used by %s", tool.Name, a.Title, other)
+		}
+		titles[a.Title] = tool.Name
+
+		if !a.ReadOnlyHint || a.DestructiveHint || !a.IdempotentHint || !a.OpenWorldHint {
+			t.Errorf("%s: expected read-only, non-destructive, idempotent, open-world hints, got %+v", tool.Name, a)
+		}
+	}
+}
+
+func TestTool_AnnotationsJSON(t *testing.T) {
+	data, err := json.Marshal(Tool{Name: "getWhois", Annotations: readOnlyAnnotations("Whois")})
+	if err != nil {
+		t.Fatalf("Failed to marshal tool: %v", err)
+	}
+
+	want := `"annotations":{"title":"Whois","readOnlyHint":true,"destructiveHint":false,"idempotentHint":true,"openWorldHint":true}`
+	if !strings.Contains(string(data), want) {
+		t.Errorf("Expected %s in %s", want, data)
+	}
+}
+
+func TestParseListToolsParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "nil params", params: nil, want: ""},
+		{name: "empty params", params: map[string]interface{}{}, want: ""},
+		{name: "cursor", params: map[string]interface{}{"cursor": "abc"}, want: "abc"},
+		{name: "invalid cursor type", params: map[string]interface{}{"cursor": 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseListToolsParams(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseListToolsParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Cursor != tt.want {
+				t.Errorf("ParseListToolsParams() cursor = %q, want %q", got.Cursor, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseCallToolParams(t *testing.T) {
 	tests := []struct {
 		name    string
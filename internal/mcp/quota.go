@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/taihen/mcp-ripestat/internal/quota"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/metrics"
+)
+
+// SetQuotaPolicy replaces the rate limits and daily quotas applied to tool
+// calls. Usage recorded under the previous policy is kept.
+func (s *Server) SetQuotaPolicy(policy quota.Policy) {
+	s.quotas.SetPolicy(policy)
+}
+
+// QuotaUsage returns the tool call usage recorded for each principal, with
+// session and client callers summed per kind.
+func (s *Server) QuotaUsage() []quota.Usage {
+	return quota.Aggregate(s.quotas.Usage())
+}
+
+// QuotaFamilies returns the usage reported by QuotaUsage as Prometheus metric
+// families. Principals are labeled by caller and kind, and the aggregated
+// session and client callers by kind only. Limits are only reported for
+// principals that have them.
+func (s *Server) QuotaFamilies() []metrics.Family {
+	used := metrics.Family{Name: "mcp_quota_used", Help: "Tool calls made today by caller.", Type: metrics.TypeGauge}
+	limit := metrics.Family{Name: "mcp_quota_limit", Help: "Daily tool call quota by caller.", Type: metrics.TypeGauge}
+	rate := metrics.Family{Name: "mcp_quota_rate_per_minute", Help: "Tool call rate limit per minute by caller.", Type: metrics.TypeGauge}
+	calls := metrics.Family{Name: "mcp_quota_calls_total", Help: "Tool calls by caller.", Type: metrics.TypeCounter}
+	rejected := metrics.Family{Name: "mcp_quota_rejected_total", Help: "Tool calls rejected by caller and limit.", Type: metrics.TypeCounter}
+
+	for _, usage := range s.QuotaUsage() {
+		labels := []metrics.Label{{Name: "kind", Value: usage.Kind}}
+		if usage.Kind == quota.KindPrincipal {
+			labels = []metrics.Label{
+				{Name: "caller", Value: strings.TrimPrefix(usage.Caller, usage.Kind+":")},
+				{Name: "kind", Value: usage.Kind},
+			}
+		}
+		withLimit := func(name string) []metrics.Label {
+			return append(labels[:len(labels):len(labels)], metrics.Label{Name: "limit", Value: name})
+		}
+
+		used.Samples = append(used.Samples, metrics.Sample{Labels: labels, Value: float64(usage.CallsToday)})
+		if usage.DailyQuota > 0 {
+			limit.Samples = append(limit.Samples, metrics.Sample{Labels: labels, Value: float64(usage.DailyQuota)})
+		}
+		if usage.RatePerMinute > 0 {
+			rate.Samples = append(rate.Samples, metrics.Sample{Labels: labels, Value: float64(usage.RatePerMinute)})
+		}
+		calls.Samples = append(calls.Samples, metrics.Sample{Labels: labels, Value: float64(usage.Calls)})
+		rejected.Samples = append(rejected.Samples,
+			metrics.Sample{Labels: withLimit(quota.LimitRate), Value: float64(usage.RateLimited)},
+			metrics.Sample{Labels: withLimit(quota.LimitDaily), Value: float64(usage.QuotaExceeded)},
+		)
+	}
+
+	return []metrics.Family{used, limit, rate, calls, rejected}
+}
+
+// callerFromContext identifies who is making a call: the authenticated
+// principal when there is one, otherwise the session if the server issued its
+// ID through initialize, otherwise the client address. Session IDs the
+// client made up, and the X-Forwarded-For header, are ignored because callers
+// could rotate them to escape their limits.
+func (s *Server) callerFromContext(ctx context.Context) quota.Caller {
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		return quota.Caller{Kind: quota.KindPrincipal, ID: principal.Subject, Groups: principal.Groups}
+	}
+
+	if sessionID, ok := SessionIDFromContext(ctx); ok && sessionID != "" && s.sessionClients.Issued(sessionID) {
+		return quota.Caller{Kind: quota.KindSession, ID: sessionID}
+	}
+
+	if r, ok := HTTPRequestFromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if host != "" {
+			return quota.Caller{Kind: quota.KindClient, ID: host}
+		}
+	}
+
+	return quota.Caller{Kind: quota.KindClient, ID: "anonymous"}
+}
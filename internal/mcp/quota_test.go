@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/quota"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/metrics"
+)
+
+// issueSession initializes a session whose ID the server issued.
+func issueSession(t *testing.T, server *Server, sessionID string) {
+	t.Helper()
+
+	ctx := WithIssuedSession(WithSessionID(context.Background(), sessionID))
+	req := NewRequest("initialize", map[string]interface{}{"protocolVersion": ProtocolVersion}, 0)
+	if _, err := server.handleInitialize(ctx, req); err != nil {
+		t.Fatalf("handleInitialize failed: %v", err)
+	}
+}
+
+// callMissingResource calls getWhois without arguments from an issued
+// session, which fails validation before any RIPEstat request is made.
+func callMissingResource(t *testing.T, server *Server, sessionID string) *Response {
+	t.Helper()
+
+	issueSession(t, server, sessionID)
+	ctx := WithSessionID(context.Background(), sessionID)
+	req := NewRequest("tools/call", map[string]interface{}{"name": "getWhois", "arguments": map[string]interface{}{}}, 1)
+	result, err := server.handleToolsCall(ctx, req)
+	if err != nil {
+		t.Fatalf("handleToolsCall failed: %v", err)
+	}
+	response, ok := result.(*Response)
+	if !ok {
+		t.Fatalf("Expected *Response, got %T", result)
+	}
+	return response
+}
+
+func TestHandleToolsCall_DailyQuota(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+	server.SetQuotaPolicy(quota.Policy{Enabled: true, Default: quota.Limits{Daily: 1}})
+
+	if response := callMissingResource(t, server, "s1"); response.Error != nil {
+		t.Fatalf("Expected first call to be allowed, got %+v", response.Error)
+	}
+
+	response := callMissingResource(t, server, "s1")
+	if response.Error == nil || response.Error.Code != RateLimitError {
+		t.Fatalf("Expected rate limit error, got %+v", response)
+	}
+	if !strings.Contains(response.Error.Message, "daily_quota") || !strings.Contains(response.Error.Message, "resets at") {
+		t.Errorf("Expected message naming the limit and reset time, got %q", response.Error.Message)
+	}
+	limitErr, ok := response.Error.Data.(*quota.LimitError)
+	if !ok || limitErr.Limit != quota.LimitDaily || limitErr.Caller != "session:s1" || limitErr.ResetAt.IsZero() {
+		t.Errorf("Unexpected error data %+v", response.Error.Data)
+	}
+
+	// Another session has its own quota.
+	if response := callMissingResource(t, server, "s2"); response.Error != nil {
+		t.Errorf("Expected other session to be allowed, got %+v", response.Error)
+	}
+}
+
+func TestQuotaFamilies(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+	server.SetQuotaPolicy(quota.Policy{
+		Enabled:    true,
+		Default:    quota.Limits{Daily: 1},
+		Principals: map[string]quota.Limits{"alice": {Daily: 5, RatePerMinute: 60}},
+	})
+	callMissingResource(t, server, "s1")
+	callMissingResource(t, server, "s1")
+	callMissingResource(t, server, "s2")
+
+	ctx := WithPrincipal(context.Background(), &auth.Principal{Subject: "alice"})
+	req := NewRequest("tools/call", map[string]interface{}{"name": "getWhois", "arguments": map[string]interface{}{}}, 1)
+	if _, err := server.handleToolsCall(ctx, req); err != nil {
+		t.Fatalf("handleToolsCall failed: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := metrics.WritePrometheus(&buf, server.QuotaFamilies()); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	exposition := buf.String()
+	for _, want := range []string{
+		`mcp_quota_used{caller="alice",kind="principal"} 1`,
+		`mcp_quota_limit{caller="alice",kind="principal"} 5`,
+		`mcp_quota_rate_per_minute{caller="alice",kind="principal"} 60`,
+		`mcp_quota_used{kind="session"} 2`,
+		`mcp_quota_calls_total{kind="session"} 2`,
+		`mcp_quota_rejected_total{kind="session",limit="daily_quota"} 1`,
+		`mcp_quota_rejected_total{kind="session",limit="rate"} 0`,
+	} {
+		if !strings.Contains(exposition, want) {
+			t.Errorf("Expected %q in:\n%s", want, exposition)
+		}
+	}
+	if strings.Contains(exposition, "s1") || strings.Contains(exposition, `mcp_quota_limit{kind="session"}`) {
+		t.Errorf("Expected session callers to be aggregated without IDs or limits:\n%s", exposition)
+	}
+}
+
+func TestHandleToolsCall_DisabledToolNotCounted(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+	server.SetQuotaPolicy(quota.Policy{Enabled: true, Default: quota.Limits{Daily: 1}})
+	if err := server.SetToolFilter(ToolFilter{Deny: []string{"getWhois"}}); err != nil {
+		t.Fatalf("SetToolFilter failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if response := callMissingResource(t, server, "s1"); response.Error == nil || response.Error.Code != ToolError {
+			t.Errorf("Expected disabled tool error, got %+v", response)
+		}
+	}
+	if usage := server.QuotaUsage(); len(usage) != 0 {
+		t.Errorf("Expected no usage for disabled tools, got %+v", usage)
+	}
+}
+
+func TestCallerFromContext(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+	issueSession(t, server, "s1")
+
+	req := httptest.NewRequest("POST", "/mcp", nil)
+	req.RemoteAddr = "192.0.2.10:4711"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	base := WithHTTPRequest(context.Background(), req)
+
+	principal := &auth.Principal{Subject: "alice", Groups: []string{"research"}}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want quota.Caller
+	}{
+		{name: "principal", ctx: WithPrincipal(WithSessionID(base, "s1"), principal), want: quota.Caller{Kind: quota.KindPrincipal, ID: "alice"}},
+		{name: "issued session", ctx: WithSessionID(base, "s1"), want: quota.Caller{Kind: quota.KindSession, ID: "s1"}},
+		{name: "session chosen by the client", ctx: WithSessionID(base, "rotated"), want: quota.Caller{Kind: quota.KindClient, ID: "192.0.2.10"}},
+		{name: "client address", ctx: base, want: quota.Caller{Kind: quota.KindClient, ID: "192.0.2.10"}},
+		{name: "anonymous", ctx: context.Background(), want: quota.Caller{Kind: quota.KindClient, ID: "anonymous"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := server.callerFromContext(tt.ctx)
+			if got.Kind != tt.want.Kind || got.ID != tt.want.ID {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if got := server.callerFromContext(WithPrincipal(base, principal)); len(got.Groups) != 1 || got.Groups[0] != "research" {
+		t.Errorf("Expected principal groups, got %+v", got.Groups)
+	}
+}
+
+func TestCallerFromContext_InitializeWithClientSession(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+
+	// Initializing with a session ID the client made up does not give it a quota of its own.
+	ctx := WithSessionID(context.Background(), "made-up")
+	req := NewRequest("initialize", map[string]interface{}{"protocolVersion": ProtocolVersion}, 0)
+	if _, err := server.handleInitialize(ctx, req); err != nil {
+		t.Fatalf("handleInitialize failed: %v", err)
+	}
+	if got := server.callerFromContext(ctx); got.Kind != quota.KindClient {
+		t.Errorf("Expected the client address to identify the caller, got %+v", got)
+	}
+}
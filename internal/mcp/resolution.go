@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/netip"
+
+	"github.com/taihen/mcp-ripestat/internal/resolve"
+)
+
+// hostResolver looks up the addresses of a hostname.
+type hostResolver interface {
+	LookupAddrs(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// SetResolver enables the hostname and URL arguments of tools whose schema
+// accepts hostnames, resolving them with r. A nil resolver disables them.
+func (s *Server) SetResolver(r *resolve.Resolver) {
+	s.resolverMu.Lock()
+	defer s.resolverMu.Unlock()
+	if r == nil {
+		s.resolver = nil
+		return
+	}
+	s.resolver = r
+}
+
+// currentResolver returns the hostname resolver, or nil when resolution is
+// disabled.
+func (s *Server) currentResolver() hostResolver {
+	s.resolverMu.RLock()
+	defer s.resolverMu.RUnlock()
+	return s.resolver
+}
+
+// resolvedResult is the result of a tool call for a hostname: the call is
+// made once per address the hostname resolved to.
+type resolvedResult struct {
+	Hostname string            `json:"hostname"`
+	Results  []resolvedAddress `json:"results"`
+}
+
+// resolvedAddress is the tool result for one address of a hostname.
+type resolvedAddress struct {
+	Address string          `json:"address"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// callResolved resolves the hostname in args[arg] and runs the tool for each
+// of its addresses. The call fails only when every address fails.
+func (s *Server) callResolved(ctx context.Context, name string, args map[string]interface{}, arg string) (*ToolResult, error) {
+	host, _ := args[arg].(string)
+
+	resolver := s.currentResolver()
+	if resolver == nil {
+		return invalidInputResult(fmt.Sprintf("Error: %s parameter is invalid: %q is a hostname and hostname resolution is disabled", arg, host)), nil
+	}
+
+	addrs, err := resolver.LookupAddrs(ctx, host)
+	if err != nil {
+		return CreateToolErrorResult(err), nil
+	}
+
+	resolved := resolvedResult{Hostname: host, Results: make([]resolvedAddress, 0, len(addrs))}
+	var failure *ToolResult
+	for _, addr := range addrs {
+		addrArgs := maps.Clone(args)
+		addrArgs[arg] = addr.String()
+
+		result, err := s.callTool(ctx, name, addrArgs)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := resolvedAddress{Address: addr.String()}
+		text := ""
+		if len(result.Content) > 0 {
+			text = result.Content[0].Text
+		}
+		switch {
+		case result.IsError:
+			entry.Error = text
+			if failure == nil {
+				failure = result
+			}
+		case json.Valid([]byte(text)):
+			entry.Result = json.RawMessage(text)
+		default:
+			entry.Result, _ = json.Marshal(text)
+		}
+		resolved.Results = append(resolved.Results, entry)
+	}
+
+	result := CreateToolResultFromJSON(resolved)
+	if failure != nil && allFailed(resolved.Results) {
+		result.IsError = true
+		result.Meta = failure.Meta
+	}
+	return result, nil
+}
+
+// allFailed reports whether the tool call failed for every address.
+func allFailed(results []resolvedAddress) bool {
+	for _, r := range results {
+		if r.Error == "" {
+			return false
+		}
+	}
+	return true
+}
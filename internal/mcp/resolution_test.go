@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/resolve"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+)
+
+// fakeResolver resolves hostnames from a fixed table.
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupAddrs(_ context.Context, host string) ([]netip.Addr, error) {
+	addrs, ok := f[host]
+	if !ok {
+		return nil, resolve.ErrNoAddresses
+	}
+	parsed := make([]netip.Addr, len(addrs))
+	for i, addr := range addrs {
+		parsed[i] = netip.MustParseAddr(addr)
+	}
+	return parsed, nil
+}
+
+// useRecordingStubRIPEstat points the RIPEstat client at a stub answering
+// with handler and returns the resources it was asked for.
+func useRecordingStubRIPEstat(t *testing.T, handler func(w http.ResponseWriter, resource string)) func() []string {
+	t.Helper()
+
+	var (
+		mu        sync.Mutex
+		resources []string
+	)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource := r.URL.Query().Get("resource")
+		mu.Lock()
+		resources = append(resources, resource)
+		mu.Unlock()
+		handler(w, resource)
+	}))
+	t.Cleanup(stub.Close)
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	t.Cleanup(func() { ripeconfig.SetDefault(nil) })
+	cache.Shared().Clear()
+	t.Cleanup(cache.Shared().Clear)
+
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), resources...)
+	}
+}
+
+func TestCallTool_HostnameResolution(t *testing.T) {
+	requested := useRecordingStubRIPEstat(t, func(w http.ResponseWriter, resource string) {
+		_, _ = w.Write([]byte(`{"status": "ok", "data": {"prefix": "` + resource + `/32", "asns": ["64496"]}}`))
+	})
+
+	server := NewServer("test", "1.0.0", false)
+	server.resolver = fakeResolver{"www.example.test": {"192.0.2.80", "2001:db8::80"}}
+
+	for _, input := range []string{"www.example.test", "https://WWW.example.test/path?q=1"} {
+		result, err := server.callTool(context.Background(), "getNetworkInfo", map[string]interface{}{"resource": input})
+		if err != nil || result.IsError {
+			t.Fatalf("%q: unexpected failure: %v %+v", input, err, result)
+		}
+
+		var resolved resolvedResult
+		if err := json.Unmarshal([]byte(result.Content[0].Text), &resolved); err != nil {
+			t.Fatalf("%q: invalid result: %v", input, err)
+		}
+		if resolved.Hostname != "www.example.test" || len(resolved.Results) != 2 {
+			t.Fatalf("%q: unexpected result %+v", input, resolved)
+		}
+		for i, want := range []string{"192.0.2.80", "2001:db8::80"} {
+			entry := resolved.Results[i]
+			if entry.Address != want || entry.Error != "" || !strings.Contains(string(entry.Result), want) {
+				t.Errorf("%q: result %d = %+v, want data for %s", input, i, entry, want)
+			}
+		}
+	}
+
+	// The second call is served from the cache.
+	if got := requested(); strings.Join(got, ",") != "192.0.2.80,2001:db8::80" {
+		t.Errorf("Expected upstream requests for the resolved addresses, got %v", got)
+	}
+}
+
+func TestCallTool_HostnameResolutionFailures(t *testing.T) {
+	useRecordingStubRIPEstat(t, func(w http.ResponseWriter, resource string) {
+		if resource == "192.0.2.80" {
+			_, _ = w.Write([]byte(`{"status": "ok", "data": {"prefix": "192.0.2.0/24", "asns": ["64496"]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	server := NewServer("test", "1.0.0", false)
+
+	result, err := server.callTool(context.Background(), "getNetworkInfo", map[string]interface{}{"resource": "www.example.test"})
+	if err != nil {
+		t.Fatalf("callTool failed: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "hostname resolution is disabled") {
+		t.Errorf("Expected hostnames to be rejected while resolution is disabled, got %+v", result)
+	}
+
+	server.resolver = fakeResolver{
+		"partial.example.test": {"192.0.2.80", "192.0.2.81"},
+		"missing.example.test": {"192.0.2.81"},
+	}
+
+	result, _ = server.callTool(context.Background(), "getNetworkInfo", map[string]interface{}{"resource": "partial.example.test"})
+	if result.IsError {
+		t.Errorf("Expected a partial failure to succeed, got %+v", result)
+	}
+	var resolved resolvedResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &resolved); err != nil {
+		t.Fatalf("Invalid result: %v", err)
+	}
+	if len(resolved.Results) != 2 || resolved.Results[0].Error != "" || resolved.Results[1].Error == "" {
+		t.Errorf("Expected the second address to fail, got %+v", resolved.Results)
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		code     int
+	}{
+		{name: "every address fails", resource: "missing.example.test", code: NotFoundError},
+		{name: "no addresses", resource: "unknown.example.test", code: NotFoundError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := server.callTool(context.Background(), "getAbuseContactFinder", map[string]interface{}{"resource": tt.resource})
+			if err != nil {
+				t.Fatalf("callTool failed: %v", err)
+			}
+			if !result.IsError {
+				t.Fatalf("Expected a failed result, got %+v", result)
+			}
+			if classified, ok := result.Meta[toolErrorMetaKey].(*Error); !ok || classified.Code != tt.code {
+				t.Errorf("Expected code %d, got %+v", tt.code, result.Meta)
+			}
+		})
+	}
+
+	result, _ = server.callTool(context.Background(), "getASOverview", map[string]interface{}{"resource": "www.example.test"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "is a hostname; expected an AS number") {
+		t.Errorf("Expected tools without hostname support to reject hostnames, got %+v", result)
+	}
+}
+
+func TestSetResolver(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+
+	resolver, err := resolve.New(resolve.Options{})
+	if err != nil {
+		t.Fatalf("resolve.New failed: %v", err)
+	}
+	server.SetResolver(resolver)
+	if server.currentResolver() == nil {
+		t.Error("Expected resolution to be enabled")
+	}
+
+	server.SetResolver(nil)
+	if server.currentResolver() != nil {
+		t.Error("Expected a nil resolver to disable resolution")
+	}
+}
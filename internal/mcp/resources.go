@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/resource"
+)
+
+// toolResourceKinds maps each tool to its resource arguments and the kinds
+// they accept, as declared by resourceTypesKeyword in the tools list.
+var toolResourceKinds = sync.OnceValue(func() map[string]map[string][]resource.Kind {
+	tools := make(map[string]map[string][]resource.Kind)
+	for _, tool := range CreateToolsList().Tools {
+		schema, _ := tool.InputSchema.(map[string]interface{})
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range properties {
+			prop, _ := property.(map[string]interface{})
+			types, ok := prop[resourceTypesKeyword].([]string)
+			if !ok {
+				continue
+			}
+			kinds := make([]resource.Kind, len(types))
+			for i, t := range types {
+				kinds[i] = resource.Kind(t)
+			}
+			if tools[tool.Name] == nil {
+				tools[tool.Name] = make(map[string][]resource.Kind)
+			}
+			tools[tool.Name][name] = kinds
+		}
+	}
+	return tools
+})
+
+// normalizeResourceArgs validates the resource arguments of a tool call
+// against the kinds its schema accepts and replaces them with their canonical
+// form, so "as3333" and "AS 3333" reach RIPEstat and the cache as "AS3333".
+// Missing or non-string arguments are left to the tool. It returns the name
+// of an argument holding a hostname, which must be resolved before the call,
+// or an invalid input result for a rejected argument.
+func normalizeResourceArgs(tool string, args map[string]interface{}) (string, *ToolResult) {
+	argKinds := toolResourceKinds()[tool]
+	names := make([]string, 0, len(argKinds))
+	for name := range argKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var hostnameArg string
+	for _, name := range names {
+		value, ok := args[name].(string)
+		if !ok || value == "" {
+			continue
+		}
+		r, err := resource.ParseAs(value, argKinds[name]...)
+		if err != nil {
+			return "", invalidInputResult(fmt.Sprintf("Error: %s parameter is invalid: %v", name, err))
+		}
+		args[name] = r.Value
+		if r.Kind == resource.KindHostname {
+			hostnameArg = name
+		}
+	}
+	return hostnameArg, nil
+}
@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/resource"
+)
+
+func TestCreateToolsList_ResourceTypes(t *testing.T) {
+	for _, tool := range CreateToolsList().Tools {
+		schema := tool.InputSchema.(map[string]interface{})
+		properties := schema["properties"].(map[string]interface{})
+		prop, ok := properties["resource"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		types, ok := prop[resourceTypesKeyword].([]string)
+		if !ok || len(types) == 0 {
+			t.Errorf("%s: resource argument has no %s", tool.Name, resourceTypesKeyword)
+			continue
+		}
+		for _, kind := range types {
+			if resource.Kind(kind).Description() == kind {
+				t.Errorf("%s: unknown resource kind %q", tool.Name, kind)
+			}
+		}
+	}
+
+	if kinds := toolResourceKinds()["getRPKIValidation"]["prefix"]; len(kinds) != 1 || kinds[0] != resource.KindPrefix {
+		t.Errorf("getRPKIValidation prefix kinds = %v, want [prefix]", kinds)
+	}
+}
+
+func TestNormalizeResourceArgs(t *testing.T) {
+	tests := []struct {
+		tool string
+		args map[string]interface{}
+		want map[string]interface{}
+	}{
+		{"getASOverview", map[string]interface{}{"resource": "as 3333"}, map[string]interface{}{"resource": "AS3333"}},
+		{"getNetworkInfo", map[string]interface{}{"resource": "193.0.0.1/21"}, map[string]interface{}{"resource": "193.0.0.0/21"}},
+		{"getCountryASNs", map[string]interface{}{"resource": "NL", "lod": "1"}, map[string]interface{}{"resource": "nl", "lod": "1"}},
+		{"getRPKIValidation", map[string]interface{}{"resource": "3333", "prefix": "193.0.0.0/21"}, map[string]interface{}{"resource": "AS3333", "prefix": "193.0.0.0/21"}},
+		{"getWhatsMyIP", map[string]interface{}{}, map[string]interface{}{}},
+		{"getASOverview", map[string]interface{}{}, map[string]interface{}{}},
+		{"getASOverview", map[string]interface{}{"resource": 3333}, map[string]interface{}{"resource": 3333}},
+	}
+
+	for _, tt := range tests {
+		if _, result := normalizeResourceArgs(tt.tool, tt.args); result != nil {
+			t.Errorf("%s %v: unexpected rejection: %s", tt.tool, tt.args, result.Content[0].Text)
+			continue
+		}
+		for key, want := range tt.want {
+			if tt.args[key] != want {
+				t.Errorf("%s: %s = %v, want %v", tt.tool, key, tt.args[key], want)
+			}
+		}
+	}
+}
+
+func TestNormalizeResourceArgs_Rejected(t *testing.T) {
+	tests := []struct {
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"getASOverview", map[string]interface{}{"resource": "AS0"}, "resource parameter is invalid: AS0 is reserved"},
+		{"getASOverview", map[string]interface{}{"resource": "193.0.0.0/21"}, `"193.0.0.0/21" is a prefix; expected an AS number`},
+		{"getNetworkInfo", map[string]interface{}{"resource": "193.0.0.0/40"}, "prefix length 40 exceeds 32"},
+		{"getCountryASNs", map[string]interface{}{"resource": "zz"}, "not an ISO 3166-1 country code"},
+		{"getRPKIValidation", map[string]interface{}{"resource": "AS3333", "prefix": "AS3333"}, "prefix parameter is invalid"},
+	}
+
+	for _, tt := range tests {
+		_, result := normalizeResourceArgs(tt.tool, tt.args)
+		if result == nil {
+			t.Errorf("%s %v: expected rejection", tt.tool, tt.args)
+			continue
+		}
+		if !strings.Contains(result.Content[0].Text, tt.want) {
+			t.Errorf("%s: error %q does not contain %q", tt.tool, result.Content[0].Text, tt.want)
+		}
+		if classified, ok := result.Meta[toolErrorMetaKey].(*Error); !ok || classified.Code != InvalidParams {
+			t.Errorf("%s: expected invalid input classification, got %+v", tt.tool, result.Meta)
+		}
+	}
+}
+
+func TestExecuteToolCall_CanonicalResource(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		resources []string
+	)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		resources = append(resources, r.URL.Query().Get("resource"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status": "ok", "data": {"holder": "RIPE-NCC-AS", "announced": true}}`))
+	}))
+	defer stub.Close()
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	defer ripeconfig.SetDefault(nil)
+	cache.Shared().Clear()
+	defer cache.Shared().Clear()
+
+	server := NewServer("test", "1.0.0", false)
+	for _, input := range []string{"AS3333", "as3333", "3333", "AS 3333"} {
+		result, err := server.executeToolCall(context.Background(), &CallToolParams{
+			Name:      "getASOverview",
+			Arguments: map[string]interface{}{"resource": input},
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("%q: unexpected failure: %v %+v", input, err, result)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(resources) != 1 || resources[0] != "AS3333" {
+		t.Errorf("Expected a single upstream request for AS3333, got %v", resources)
+	}
+}
@@ -0,0 +1,286 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxResultBytes is the default size limit of a tool result's JSON
+// text, sized to leave room in an agent's context window.
+const DefaultMaxResultBytes = 256 << 10
+
+// Pagination arguments accepted by every tool.
+const (
+	maxItemsArgument = "max_items"
+	cursorArgument   = "cursor"
+)
+
+// _meta keys describing a paginated or truncated result.
+const (
+	pageMetaKey      = "page"
+	truncatedMetaKey = "truncated"
+)
+
+// resultCursorPrefix namespaces tool result cursors. The cursor also carries
+// a fingerprint of the query and its full result, so a cursor is rejected
+// when reused with other arguments or after the data has changed.
+const resultCursorPrefix = "items:"
+
+// errStaleCursor explains why a result cursor was rejected.
+const errStaleCursor = "Error: cursor parameter is invalid: it belongs to another query or the results changed since it was issued; request the first page again"
+
+// pageRequest holds the pagination arguments of a tool call.
+type pageRequest struct {
+	maxItems int
+	cursor   string
+}
+
+// addPaginationProperties adds the max_items and cursor arguments to a tool's
+// schema.
+func addPaginationProperties(schema interface{}) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties, ok := object["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties[maxItemsArgument] = map[string]interface{}{
+		"type":        "integer",
+		"description": "Maximum number of items of the result's largest list to return. The rest is available through the returned cursor.",
+	}
+	properties[cursorArgument] = map[string]interface{}{
+		"type":        "string",
+		"description": "Cursor returned by a previous call with the same arguments, to fetch the next page of items.",
+	}
+}
+
+// pageParams removes the pagination arguments from args and validates them.
+func pageParams(args map[string]interface{}) (pageRequest, *ToolResult) {
+	var req pageRequest
+
+	if value, ok := args[maxItemsArgument]; ok {
+		delete(args, maxItemsArgument)
+		maxItems, ok := intArgument(value)
+		if !ok || maxItems < 1 {
+			return pageRequest{}, invalidInputResult("Error: max_items parameter must be a positive integer")
+		}
+		req.maxItems = maxItems
+	}
+
+	if value, ok := args[cursorArgument]; ok {
+		delete(args, cursorArgument)
+		cursor, ok := value.(string)
+		if !ok {
+			return pageRequest{}, invalidInputResult("Error: cursor parameter must be a string")
+		}
+		req.cursor = cursor
+	}
+
+	return req, nil
+}
+
+// intArgument converts a JSON number or numeric string to an int.
+func intArgument(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// resultFingerprint identifies a query and its full result text. It seeds
+// the cursor prefix of the result's pages.
+func resultFingerprint(name string, args map[string]interface{}, text string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	canonical, _ := json.Marshal(args) // map keys are sorted
+	_, _ = h.Write(canonical)
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(text))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// resultList is the list of a result that is paginated.
+type resultList struct {
+	path  []string
+	items []interface{}
+}
+
+// name returns the dotted path of the list, for example "data.prefixes".
+func (l resultList) name() string {
+	if len(l.path) == 0 {
+		return "(root)"
+	}
+	return strings.Join(l.path, ".")
+}
+
+// findResultList returns the longest list in a decoded result, looking
+// through objects but not into lists. Ties go to the first path in sorted
+// order, so every page of a result picks the same list.
+func findResultList(value interface{}, path []string) (resultList, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return resultList{path: path, items: v}, true
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var best resultList
+		found := false
+		for _, key := range keys {
+			list, ok := findResultList(v[key], append(path[:len(path):len(path)], key))
+			if ok && (!found || len(list.items) > len(best.items)) {
+				best, found = list, true
+			}
+		}
+		return best, found
+	}
+	return resultList{}, false
+}
+
+// withList returns root with the list at path replaced by items.
+func withList(root interface{}, path []string, items []interface{}) interface{} {
+	if len(path) == 0 {
+		return items
+	}
+	object := root.(map[string]interface{})
+	copied := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		copied[key] = value
+	}
+	copied[path[0]] = withList(object[path[0]], path[1:], items)
+	return copied
+}
+
+// paginateResult replaces the JSON text of a successful result with the page
+// of its longest list selected by req, and keeps the text within maxBytes
+// (zero disables the limit). Pages are cut from the full result on every
+// call, so the cached upstream response backs all pages of a query. It
+// returns an invalid input result for cursors it did not issue.
+func paginateResult(result *ToolResult, name string, args map[string]interface{}, req pageRequest, maxBytes int) *ToolResult {
+	if result == nil || result.IsError || len(result.Content) == 0 {
+		return result
+	}
+	text := result.Content[0].Text
+	if req == (pageRequest{}) && (maxBytes == 0 || len(text) <= maxBytes) {
+		return result
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var root interface{}
+	list, ok := resultList{}, false
+	if decoder.Decode(&root) == nil {
+		list, ok = findResultList(root, nil)
+	}
+	if !ok {
+		if req.cursor != "" {
+			return invalidInputResult(errStaleCursor)
+		}
+		truncateResult(result, maxBytes)
+		return result
+	}
+
+	prefix := resultCursorPrefix + resultFingerprint(name, args, text) + ":"
+	offset, err := decodeCursor(prefix, req.cursor)
+	if err != nil || offset > len(list.items) {
+		return invalidInputResult(errStaleCursor)
+	}
+
+	end := len(list.items)
+	if req.maxItems > 0 && offset+req.maxItems < end {
+		end = offset + req.maxItems
+	}
+
+	page := func(end int) string {
+		data, err := json.MarshalIndent(withList(root, list.path, list.items[offset:end]), "", "  ")
+		if err != nil {
+			return text
+		}
+		return string(data)
+	}
+
+	pageText := page(end)
+	if maxBytes > 0 && len(pageText) > maxBytes && end-offset > 1 {
+		// Find the most items that fit; a page always holds at least one.
+		n := sort.Search(end-offset, func(n int) bool {
+			return len(page(offset+n+1)) > maxBytes
+		})
+		end = offset + max(n, 1)
+		pageText = page(end)
+	}
+
+	if offset == 0 && end == len(list.items) {
+		// The whole list fits, so the text is left as the tool produced it.
+		truncateResult(result, maxBytes)
+		return result
+	}
+
+	result.Content[0].Text = pageText
+	reported := map[string]interface{}{
+		"list":     list.name(),
+		"total":    len(list.items),
+		"offset":   offset,
+		"returned": end - offset,
+		"omitted":  len(list.items) - (end - offset),
+	}
+	note := fmt.Sprintf("Showing items %d-%d of %d in %s; %d omitted.", offset+1, end, len(list.items), list.name(), len(list.items)-(end-offset))
+	if offset == end {
+		note = fmt.Sprintf("No items left of %d in %s.", len(list.items), list.name())
+	}
+	if end < len(list.items) {
+		nextCursor := encodeCursor(prefix, end)
+		reported["nextCursor"] = nextCursor
+		note += fmt.Sprintf(" Call again with cursor %q for the next page.", nextCursor)
+	}
+	if result.Meta == nil {
+		result.Meta = map[string]interface{}{}
+	}
+	result.Meta[pageMetaKey] = reported
+	result.Content = append(result.Content, ToolContent{Type: "text", Text: note})
+
+	truncateResult(result, maxBytes)
+	return result
+}
+
+// truncateResult cuts the JSON text of result to maxBytes when it is longer,
+// which only happens when no list can be paginated or a single item exceeds
+// the limit. The cut text is no longer valid JSON, so the result says so.
+func truncateResult(result *ToolResult, maxBytes int) {
+	text := result.Content[0].Text
+	if maxBytes == 0 || len(text) <= maxBytes {
+		return
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	result.Content[0].Text = text[:cut]
+
+	if result.Meta == nil {
+		result.Meta = map[string]interface{}{}
+	}
+	result.Meta[truncatedMetaKey] = map[string]int{"bytes": len(text), "limit": maxBytes}
+	result.Content = append(result.Content, ToolContent{
+		Type: "text",
+		Text: fmt.Sprintf("Result truncated to %d of %d bytes and is not valid JSON; narrow the query or lower max_items.", maxBytes, len(text)),
+	})
+}
@@ -0,0 +1,254 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// listResult returns a tool result holding n prefixes under data.prefixes.
+func listResult(n int) *ToolResult {
+	prefixes := make([]map[string]interface{}, n)
+	for i := range prefixes {
+		prefixes[i] = map[string]interface{}{"prefix": fmt.Sprintf("192.0.2.%d/32", i), "peers": i}
+	}
+	return CreateToolResultFromJSON(map[string]interface{}{
+		"data": map[string]interface{}{"resource": "AS64496", "prefixes": prefixes, "tags": []string{"a"}},
+	})
+}
+
+// pagePrefixes decodes the prefixes of a paginated result.
+func pagePrefixes(t *testing.T, result *ToolResult) []string {
+	t.Helper()
+	var decoded struct {
+		Data struct {
+			Prefixes []struct {
+				Prefix string `json:"prefix"`
+			} `json:"prefixes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &decoded); err != nil {
+		t.Fatalf("Page is not valid JSON: %v", err)
+	}
+	prefixes := make([]string, len(decoded.Data.Prefixes))
+	for i, p := range decoded.Data.Prefixes {
+		prefixes[i] = p.Prefix
+	}
+	return prefixes
+}
+
+func TestPaginateResult(t *testing.T) {
+	args := map[string]interface{}{"resource": "AS64496"}
+
+	var (
+		seen   []string
+		cursor string
+		pages  int
+	)
+	for {
+		result := paginateResult(listResult(10), "getAnnouncedPrefixes", args, pageRequest{maxItems: 4, cursor: cursor}, 0)
+		if result.IsError {
+			t.Fatalf("Unexpected failure: %+v", result)
+		}
+		seen = append(seen, pagePrefixes(t, result)...)
+		pages++
+
+		page, ok := result.Meta[pageMetaKey].(map[string]interface{})
+		if !ok || page["list"] != "data.prefixes" || page["total"] != 10 {
+			t.Fatalf("Expected page metadata, got %+v", result.Meta)
+		}
+		note := result.Content[len(result.Content)-1].Text
+		if page["omitted"] != 10-page["returned"].(int) {
+			t.Errorf("Expected the items not on the page to be counted as omitted, got %+v", page)
+		}
+		if !strings.HasPrefix(note, "Showing items ") {
+			t.Errorf("Expected a note on the page, got %q", note)
+		}
+
+		next, _ := page["nextCursor"].(string)
+		if next == "" {
+			if strings.Contains(note, "cursor") {
+				t.Errorf("Expected no cursor on the last page, got %q", note)
+			}
+			break
+		}
+		if !strings.Contains(note, next) {
+			t.Errorf("Expected the note to carry the cursor, got %q", note)
+		}
+		cursor = next
+	}
+
+	if pages != 3 || len(seen) != 10 || seen[0] != "192.0.2.0/32" || seen[9] != "192.0.2.9/32" {
+		t.Errorf("Expected 10 prefixes in order over 3 pages, got %d pages: %v", pages, seen)
+	}
+}
+
+func TestPaginateResult_Unchanged(t *testing.T) {
+	want := listResult(3).Content[0].Text
+
+	result := paginateResult(listResult(3), "getAnnouncedPrefixes", nil, pageRequest{}, 0)
+	if result.Content[0].Text != want || len(result.Content) != 1 || result.Meta != nil {
+		t.Errorf("Expected a result without pagination arguments to be unchanged, got %+v", result)
+	}
+
+	result = paginateResult(listResult(3), "getAnnouncedPrefixes", nil, pageRequest{maxItems: 3}, 0)
+	if result.Content[0].Text != want || result.Meta != nil {
+		t.Errorf("Expected a list that fits max_items to be unchanged, got %+v", result)
+	}
+}
+
+func TestPaginateResult_InvalidCursor(t *testing.T) {
+	args := map[string]interface{}{"resource": "AS64496"}
+	first := paginateResult(listResult(10), "getAnnouncedPrefixes", args, pageRequest{maxItems: 4}, 0)
+	cursor := first.Meta[pageMetaKey].(map[string]interface{})["nextCursor"].(string)
+
+	tests := []struct {
+		name   string
+		result *ToolResult
+		args   map[string]interface{}
+		cursor string
+	}{
+		{name: "other arguments", result: listResult(10), args: map[string]interface{}{"resource": "AS64497"}, cursor: cursor},
+		{name: "changed results", result: listResult(11), args: args, cursor: cursor},
+		{name: "tools/list cursor", result: listResult(10), args: args, cursor: encodeCursor(toolsCursorPrefix, 4)},
+		{name: "garbage", result: listResult(10), args: args, cursor: "not-a-cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := paginateResult(tt.result, "getAnnouncedPrefixes", tt.args, pageRequest{maxItems: 4, cursor: tt.cursor}, 0)
+			if !result.IsError || !strings.Contains(result.Content[0].Text, "cursor parameter is invalid") {
+				t.Errorf("Expected the cursor to be rejected, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestPaginateResult_ByteBudget(t *testing.T) {
+	full := listResult(200)
+	const maxBytes = 2048
+	if len(full.Content[0].Text) <= maxBytes {
+		t.Fatalf("Test result of %d bytes does not exceed the budget", len(full.Content[0].Text))
+	}
+
+	var (
+		seen   int
+		cursor string
+	)
+	for {
+		result := paginateResult(listResult(200), "getAnnouncedPrefixes", nil, pageRequest{cursor: cursor}, maxBytes)
+		if result.IsError {
+			t.Fatalf("Unexpected failure: %+v", result)
+		}
+		if size := len(result.Content[0].Text); size > maxBytes {
+			t.Fatalf("Page of %d bytes exceeds the budget of %d", size, maxBytes)
+		}
+		seen += len(pagePrefixes(t, result))
+
+		next, _ := result.Meta[pageMetaKey].(map[string]interface{})["nextCursor"].(string)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if seen != 200 {
+		t.Errorf("Expected all 200 prefixes across the pages, got %d", seen)
+	}
+}
+
+func TestPaginateResult_Truncated(t *testing.T) {
+	result := CreateToolResultFromJSON(map[string]string{"whois": strings.Repeat("é", 100)})
+
+	result = paginateResult(result, "getWhois", nil, pageRequest{}, 64)
+	if result.IsError || len(result.Content[0].Text) > 64 {
+		t.Fatalf("Expected the text to be cut to 64 bytes, got %+v", result)
+	}
+	if !strings.HasSuffix(result.Content[0].Text, "é") {
+		t.Errorf("Expected the cut on a character boundary, got %q", result.Content[0].Text)
+	}
+	if truncated, ok := result.Meta[truncatedMetaKey].(map[string]int); !ok || truncated["limit"] != 64 {
+		t.Errorf("Expected truncation metadata, got %+v", result.Meta)
+	}
+	if note := result.Content[len(result.Content)-1].Text; !strings.HasPrefix(note, "Result truncated to 64 of ") {
+		t.Errorf("Expected a truncation note, got %q", note)
+	}
+}
+
+func TestPageParams_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "zero", args: map[string]interface{}{"max_items": float64(0)}, want: "max_items parameter must be a positive integer"},
+		{name: "fraction", args: map[string]interface{}{"max_items": 1.5}, want: "max_items parameter must be a positive integer"},
+		{name: "text", args: map[string]interface{}{"max_items": "many"}, want: "max_items parameter must be a positive integer"},
+		{name: "cursor number", args: map[string]interface{}{"cursor": float64(3)}, want: "cursor parameter must be a string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, result := pageParams(tt.args)
+			if result == nil || !strings.Contains(result.Content[0].Text, tt.want) {
+				t.Errorf("Expected %q, got %+v", tt.want, result)
+			}
+		})
+	}
+
+	args := map[string]interface{}{"resource": "AS3333", "max_items": "25", "cursor": "abc"}
+	req, result := pageParams(args)
+	if result != nil || req.maxItems != 25 || req.cursor != "abc" {
+		t.Errorf("Expected max_items 25 and the cursor, got %+v %+v", req, result)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected the pagination arguments to be removed, got %v", args)
+	}
+}
+
+func TestExecuteToolCall_Pagination(t *testing.T) {
+	requested := useRecordingStubRIPEstat(t, func(w http.ResponseWriter, _ string) {
+		var prefixes []string
+		for i := range 5 {
+			prefixes = append(prefixes, fmt.Sprintf(`{"prefix": "192.0.2.%d/32", "timelines": []}`, i))
+		}
+		_, _ = w.Write([]byte(`{"status": "ok", "data": {"resource": "64496", "prefixes": [` + strings.Join(prefixes, ",") + `]}}`))
+	})
+	server := NewServer("test", "1.0.0", false)
+
+	call := func(args map[string]interface{}) *ToolResult {
+		t.Helper()
+		result, err := server.executeToolCall(context.Background(), &CallToolParams{Name: "getAnnouncedPrefixes", Arguments: args})
+		if err != nil || result.IsError {
+			t.Fatalf("Unexpected failure: %v %+v", err, result)
+		}
+		return result
+	}
+
+	first := call(map[string]interface{}{"resource": "AS64496", "max_items": 2})
+	page := first.Meta[pageMetaKey].(map[string]interface{})
+	if page["returned"] != 2 || page["omitted"] != 3 || !strings.HasSuffix(page["list"].(string), "prefixes") {
+		t.Fatalf("Unexpected first page %+v", page)
+	}
+
+	// Equivalent spellings of the resource share the cursor.
+	second := call(map[string]interface{}{"resource": "as64496", "max_items": 2, "cursor": page["nextCursor"]})
+	if page := second.Meta[pageMetaKey].(map[string]interface{}); page["offset"] != 2 || page["returned"] != 2 {
+		t.Errorf("Unexpected second page %+v", page)
+	}
+
+	if got := requested(); len(got) != 1 {
+		t.Errorf("Expected the pages to share one upstream request, got %v", got)
+	}
+}
+
+func TestCreateToolsList_PaginationArguments(t *testing.T) {
+	for _, tool := range CreateToolsList().Tools {
+		properties := tool.InputSchema.(map[string]interface{})["properties"].(map[string]interface{})
+		for _, arg := range []string{maxItemsArgument, cursorArgument} {
+			if _, ok := properties[arg]; !ok {
+				t.Errorf("Expected %s to accept %s", tool.Name, arg)
+			}
+		}
+	}
+}
@@ -3,13 +3,20 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/taihen/mcp-ripestat/internal/audit"
+	"github.com/taihen/mcp-ripestat/internal/auth"
+	"github.com/taihen/mcp-ripestat/internal/quota"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/abusecontactfinder"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/addressspacehierarchy"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/allocationhistory"
@@ -20,8 +27,11 @@ import (
 	"github.com/taihen/mcp-ripestat/internal/ripestat/asroutingconsistency"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/bgplay"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/bgpupdates"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/countryasns"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/logging"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/lookingglass"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/metrics"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/networkinfo"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/prefixoverview"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/prefixroutingconsistency"
@@ -30,8 +40,10 @@ import (
 	"github.com/taihen/mcp-ripestat/internal/ripestat/routingstatus"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/rpkihistory"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/rpkivalidation"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/whatsmyip"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/whois"
+	"github.com/taihen/mcp-ripestat/internal/tracing"
 )
 
 // contextKey is a custom type for context keys to avoid collisions.
@@ -43,6 +55,15 @@ const httpRequestKey contextKey = "http_request"
 // sessionIDKey is the context key for storing session ID information.
 const sessionIDKey contextKey = "session_id"
 
+// sessionIssuedKey is the context key marking a session ID issued by the server.
+const sessionIssuedKey contextKey = "session_issued"
+
+// requestIDKey is the context key for storing the request ID.
+const requestIDKey contextKey = "request_id"
+
+// principalKey is the context key for storing the authenticated principal.
+const principalKey contextKey = "principal"
+
 // WithHTTPRequest stores an HTTP request in the context.
 func WithHTTPRequest(ctx context.Context, r *http.Request) context.Context {
 	return context.WithValue(ctx, httpRequestKey, r)
@@ -54,8 +75,12 @@ func HTTPRequestFromContext(ctx context.Context) (*http.Request, bool) {
 	return r, ok
 }
 
-// WithSessionID stores a session ID in the context.
+// WithSessionID stores a session ID in the context. Log records of the
+// context carry it.
 func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	if sessionID != "" {
+		ctx = logging.WithAttrs(ctx, slog.String(logging.KeySessionID, sessionID))
+	}
 	return context.WithValue(ctx, sessionIDKey, sessionID)
 }
 
@@ -65,6 +90,46 @@ func SessionIDFromContext(ctx context.Context) (string, bool) {
 	return sessionID, ok
 }
 
+// WithIssuedSession marks the context's session ID as issued by the server
+// for this request rather than sent by the client. Only sessions whose ID was
+// issued in response to initialize have their own quota.
+func WithIssuedSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionIssuedKey, true)
+}
+
+// sessionIssued reports whether the context's session ID was issued by the
+// server for this request.
+func sessionIssued(ctx context.Context) bool {
+	issued, _ := ctx.Value(sessionIssuedKey).(bool)
+	return issued
+}
+
+// WithRequestID stores the ID correlating an HTTP request with its log lines,
+// audit entry and upstream calls. Log records of the context carry it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID != "" {
+		ctx = logging.WithAttrs(ctx, slog.String(logging.KeyRequestID, requestID))
+	}
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext retrieves the request ID from the context.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey).(string)
+	return requestID, ok && requestID != ""
+}
+
+// WithPrincipal stores the authenticated principal in the context.
+func WithPrincipal(ctx context.Context, principal *auth.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext retrieves the authenticated principal from the context.
+func PrincipalFromContext(ctx context.Context) (*auth.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*auth.Principal)
+	return principal, ok && principal != nil
+}
+
 // Error message constants for parameter validation.
 const (
 	ErrResourceRequired     = "Error: resource parameter is required"
@@ -87,7 +152,7 @@ func formatErrorMessage(err error) string {
 func getRequiredStringParam(args map[string]interface{}, key, errorMsg string) (string, *ToolResult) {
 	value, ok := args[key].(string)
 	if !ok {
-		return "", CreateToolResult(errorMsg, true)
+		return "", invalidInputResult(errorMsg)
 	}
 	return value, nil
 }
@@ -109,7 +174,7 @@ func validateLODParam(args map[string]interface{}) (int, *ToolResult) {
 
 	lod, err := strconv.Atoi(lodStr)
 	if err != nil || (lod != 0 && lod != 1) {
-		return 0, CreateToolResult(ErrLODParameterInvalid, true)
+		return 0, invalidInputResult(ErrLODParameterInvalid)
 	}
 	return lod, nil
 }
@@ -123,7 +188,7 @@ func validateLookBackLimitParam(args map[string]interface{}) (int, *ToolResult)
 
 	lookBackLimit, err := strconv.Atoi(lblStr)
 	if err != nil {
-		return 0, CreateToolResult(ErrLookBackLimitInvalid, true)
+		return 0, invalidInputResult(ErrLookBackLimitInvalid)
 	}
 	return lookBackLimit, nil
 }
@@ -133,29 +198,93 @@ type Server struct {
 	serverName          string
 	serverVersion       string
 	initialized         bool
-	disableWhatsMyIP    bool
 	globallyInitialized bool // For compatibility with older protocol versions
+	toolsPageSize       int
+	maxResultBytes      int
+
+	// Runtime tool selection
+	toolsMu       sync.RWMutex
+	toolFilter    ToolFilter
+	notifications *notificationHub
+
+	// Argument completion sources
+	recent         *recentResources
+	searchComplete searchCompleteFunc
+
+	// Per-caller rate limits and daily quotas
+	quotas *quota.Manager
+
+	// Audit log of tool calls, and the client info it records per session
+	auditLogger    atomic.Pointer[audit.Logger]
+	sessionClients *sessionClients
+
+	// Hostname resolution for tools accepting hostnames, nil when disabled
+	resolverMu sync.RWMutex
+	resolver   hostResolver
 }
 
-// NewServer creates a new MCP server.
+// NewServer creates a new MCP server. Setting disableWhatsMyIP denies the
+// getWhatsMyIP tool; use SetToolFilter for other tools and categories.
 func NewServer(serverName, serverVersion string, disableWhatsMyIP bool) *Server {
+	var filter ToolFilter
+	if disableWhatsMyIP {
+		filter.Deny = []string{"getWhatsMyIP"}
+	}
+
 	return &Server{
-		serverName:       serverName,
-		serverVersion:    serverVersion,
-		disableWhatsMyIP: disableWhatsMyIP,
+		serverName:     serverName,
+		serverVersion:  serverVersion,
+		toolsPageSize:  DefaultToolsPageSize,
+		maxResultBytes: DefaultMaxResultBytes,
+		toolFilter:     filter,
+		notifications:  newNotificationHub(),
+		recent:         newRecentResources(recentResourcesLimit),
+		searchComplete: defaultSearchComplete,
+		quotas:         quota.NewManager(quota.Policy{}),
+		sessionClients: newSessionClients(sessionClientsLimit),
 	}
 }
 
+// SetToolsPageSize sets the number of tools returned per tools/list page.
+// Values below one restore the default.
+func (s *Server) SetToolsPageSize(size int) {
+	if size < 1 {
+		size = DefaultToolsPageSize
+	}
+
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	s.toolsPageSize = size
+}
+
+// SetMaxResultBytes sets the size limit of a tool result's JSON text. Longer
+// results are paginated, or truncated when they cannot be. Zero disables the
+// limit.
+func (s *Server) SetMaxResultBytes(size int) {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	s.maxResultBytes = max(size, 0)
+}
+
 // ProcessMessage processes an incoming MCP message.
 func (s *Server) ProcessMessage(ctx context.Context, data []byte) (interface{}, error) {
-	slog.Debug("processing MCP message", "data", string(data))
+	slog.DebugContext(ctx, "processing MCP message", "data", string(data))
 
 	msg, err := ParseMessage(data)
 	if err != nil {
-		slog.Error("failed to parse message", "err", err)
+		slog.ErrorContext(ctx, "failed to parse message", "err", err)
 		return NewErrorResponse(ParseError, "Parse error", err.Error(), nil), nil
 	}
 
+	if batch, ok := msg.(Batch); ok {
+		return s.handleBatch(ctx, batch)
+	}
+
+	return s.dispatchMessage(ctx, msg)
+}
+
+// dispatchMessage routes a single parsed message to its handler.
+func (s *Server) dispatchMessage(ctx context.Context, msg interface{}) (interface{}, error) {
 	switch m := msg.(type) {
 	case *Request:
 		return s.handleRequest(ctx, m)
@@ -172,47 +301,61 @@ func (s *Server) handleRequest(ctx context.Context, req *Request) (interface{},
 		return NewErrorResponse(InvalidRequest, "Invalid request", err.Error(), req.ID), nil
 	}
 
-	slog.Debug("handling request", "method", req.Method, "id", req.ID)
+	ctx = logging.WithAttrs(ctx, slog.Any(logging.KeyRPCID, req.ID))
+	slog.DebugContext(ctx, "handling request", "method", req.Method)
+
+	ctx, span := startRequestSpan(ctx, req)
+	result, err := s.routeRequest(ctx, req)
+	endRequestSpan(span, result)
+	return result, err
+}
 
+// routeRequest calls the handler for a validated request's method.
+func (s *Server) routeRequest(ctx context.Context, req *Request) (interface{}, error) {
 	switch req.Method {
 	case "initialize":
-		return s.handleInitialize(req)
+		return s.handleInitialize(ctx, req)
 	case "tools/list":
 		if !s.initialized && !s.globallyInitialized {
 			return NewErrorResponse(InitializationError, "Server not initialized", "Initialize first", req.ID), nil
 		}
-		return s.handleToolsList(req)
+		return s.handleToolsList(ctx, req)
 	case "tools/call":
 		if !s.initialized && !s.globallyInitialized {
 			return NewErrorResponse(InitializationError, "Server not initialized", "Initialize first", req.ID), nil
 		}
 		return s.handleToolsCall(ctx, req)
+	case "completion/complete":
+		if !s.initialized && !s.globallyInitialized {
+			return NewErrorResponse(InitializationError, "Server not initialized", "Initialize first", req.ID), nil
+		}
+		return s.handleComplete(ctx, req)
 	case "ping":
-		return s.handlePing(req)
+		return s.handlePing(ctx, req)
 	default:
 		return NewErrorResponse(MethodNotFound, "Method not found", req.Method, req.ID), nil
 	}
 }
 
 // handleNotification handles JSON-RPC notifications.
-func (s *Server) handleNotification(_ context.Context, notif *Notification) (interface{}, error) {
-	slog.Debug("handling notification", "method", notif.Method)
+func (s *Server) handleNotification(ctx context.Context, notif *Notification) (interface{}, error) {
+	slog.DebugContext(ctx, "handling notification", "method", notif.Method)
 
 	switch notif.Method {
 	case "initialized", "notifications/initialized":
-		return s.handleInitialized(notif)
+		return s.handleInitialized(ctx, notif)
 	case "notifications/cancelled":
 		// Handle cancellation notifications
-		slog.Debug("received cancellation notification")
+		slog.DebugContext(ctx, "received cancellation notification")
 		return nil, nil
 	default:
-		slog.Warn("unknown notification method", "method", notif.Method)
+		slog.WarnContext(ctx, "unknown notification method", "method", notif.Method)
 		return nil, nil
 	}
 }
 
 // handleInitialize handles the initialize request.
-func (s *Server) handleInitialize(req *Request) (interface{}, error) {
+func (s *Server) handleInitialize(ctx context.Context, req *Request) (interface{}, error) {
 	var params InitializeParams
 	if req.Params != nil {
 		jsonData, err := json.Marshal(req.Params)
@@ -224,11 +367,15 @@ func (s *Server) handleInitialize(req *Request) (interface{}, error) {
 		}
 	}
 
+	if sessionID, ok := SessionIDFromContext(ctx); ok && sessionID != "" {
+		s.sessionClients.Set(sessionID, params.ClientInfo, sessionIssued(ctx))
+	}
+
 	// Determine if this is a legacy client based on protocol version
 	isLegacyClient := params.ProtocolVersion != "" && params.ProtocolVersion < "2025-06-18"
 
 	// Log server readiness for debugging cold starts
-	slog.Info("MCP server responding to initialize request",
+	slog.InfoContext(ctx, "MCP server responding to initialize request",
 		"server_name", s.serverName,
 		"version", s.serverVersion,
 		"client_protocol", params.ProtocolVersion,
@@ -238,7 +385,7 @@ func (s *Server) handleInitialize(req *Request) (interface{}, error) {
 	if isLegacyClient {
 		s.initialized = true
 		s.globallyInitialized = true // Global initialization for compatibility
-		slog.Info("auto-initialized server for legacy protocol version", "version", params.ProtocolVersion)
+		slog.InfoContext(ctx, "auto-initialized server for legacy protocol version", "version", params.ProtocolVersion)
 
 		result := CreateLegacyInitializeResult(s.serverName, s.serverVersion)
 		return NewResponse(result, req.ID), nil
@@ -246,72 +393,143 @@ func (s *Server) handleInitialize(req *Request) (interface{}, error) {
 
 	// For current protocol versions, validate and use full capabilities
 	if params.ProtocolVersion != ProtocolVersion {
-		slog.Warn("protocol version mismatch", "client", params.ProtocolVersion, "server", ProtocolVersion)
+		slog.WarnContext(ctx, "protocol version mismatch", "client", params.ProtocolVersion, "server", ProtocolVersion)
 	}
 
 	// Auto-initialize for better client compatibility
 	s.initialized = true
-	slog.Info("auto-initialized server for protocol version", "version", params.ProtocolVersion)
+	slog.InfoContext(ctx, "auto-initialized server for protocol version", "version", params.ProtocolVersion)
 
 	result := CreateInitializeResult(s.serverName, s.serverVersion)
 	return NewResponse(result, req.ID), nil
 }
 
 // handleInitialized handles the initialized notification.
-func (s *Server) handleInitialized(_ *Notification) (interface{}, error) {
-	slog.Debug("handling initialized notification")
+func (s *Server) handleInitialized(ctx context.Context, _ *Notification) (interface{}, error) {
+	slog.DebugContext(ctx, "handling initialized notification")
 	s.initialized = true
-	slog.Info("MCP server initialized successfully")
+	slog.InfoContext(ctx, "MCP server initialized successfully")
 	return nil, nil
 }
 
 // handlePing handles ping requests.
-func (s *Server) handlePing(req *Request) (interface{}, error) {
-	slog.Debug("handling ping request")
+func (s *Server) handlePing(ctx context.Context, req *Request) (interface{}, error) {
+	slog.DebugContext(ctx, "handling ping request")
 	return NewResponse(map[string]string{}, req.ID), nil
 }
 
 // handleToolsList handles tools/list requests.
-func (s *Server) handleToolsList(req *Request) (interface{}, error) {
-	slog.Debug("handling tools/list request")
+func (s *Server) handleToolsList(ctx context.Context, req *Request) (interface{}, error) {
+	slog.DebugContext(ctx, "handling tools/list request")
 
 	toolsList := CreateToolsList()
 
-	// Remove whats-my-ip tool if disabled
-	if s.disableWhatsMyIP {
-		tools := make([]Tool, 0, len(toolsList.Tools)-1)
-		for _, tool := range toolsList.Tools {
-			if tool.Name != "getWhatsMyIP" {
-				tools = append(tools, tool)
-			}
+	// Remove tools disabled by the tool filter
+	filter := s.ToolFilter()
+	tools := make([]Tool, 0, len(toolsList.Tools))
+	for _, tool := range toolsList.Tools {
+		if filter.Allows(tool.Name) {
+			tools = append(tools, tool)
 		}
-		toolsList.Tools = tools
 	}
+	toolsList.Tools = tools
+
+	params, err := ParseListToolsParams(req.Params)
+	if err != nil {
+		return NewErrorResponse(InvalidParams, "Invalid params", err.Error(), req.ID), nil
+	}
+
+	s.toolsMu.RLock()
+	pageSize := s.toolsPageSize
+	s.toolsMu.RUnlock()
+
+	page, nextCursor, err := paginateTools(toolsList.Tools, params.Cursor, pageSize)
+	if err != nil {
+		return NewErrorResponse(InvalidParams, "Invalid params", err.Error(), req.ID), nil
+	}
+	toolsList.Tools = page
+	toolsList.NextCursor = nextCursor
 
 	return NewResponse(toolsList, req.ID), nil
 }
 
 // handleToolsCall handles tools/call requests.
 func (s *Server) handleToolsCall(ctx context.Context, req *Request) (interface{}, error) {
-	slog.Debug("handling tools/call request")
+	slog.DebugContext(ctx, "handling tools/call request")
 
 	params, err := ParseCallToolParams(req.Params)
 	if err != nil {
 		return NewErrorResponse(InvalidParams, "Invalid params", err.Error(), req.ID), nil
 	}
 
+	start := time.Now()
+	metrics.StartToolCall()
+
+	recorder := &client.CallRecorder{}
+	response := s.callToolWithLimits(client.WithCallRecorder(ctx, recorder), req, params)
+	calls := recorder.Calls()
+	if result, ok := response.Result.(*ToolResult); ok && result.IsError {
+		addSupportReference(ctx, result, calls)
+	}
+	if logger := s.auditLogger.Load(); logger != nil {
+		s.auditToolCall(ctx, logger, params, response, calls, time.Since(start))
+	}
+
+	status, _ := toolCallStatus(response)
+	metrics.EndToolCall(toolMetricName(params.Name), status, time.Since(start))
+	tracing.SpanFromContext(ctx).SetAttributes(
+		tracing.String("mcp.tool.name", toolMetricName(params.Name)),
+		tracing.String("mcp.tool.status", status),
+	)
+
+	return response, nil
+}
+
+// addSupportReference appends the request ID and the RIPEstat query IDs of
+// calls to a failed tool result, so callers can quote them when escalating.
+func addSupportReference(ctx context.Context, result *ToolResult, calls []client.UpstreamCall) {
+	var refs []string
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		refs = append(refs, "Request ID: "+requestID)
+	}
+	for _, call := range calls {
+		if call.QueryID != "" {
+			refs = append(refs, fmt.Sprintf("RIPEstat query_id (%s): %s", call.Endpoint, call.QueryID))
+		}
+	}
+	if len(refs) == 0 {
+		return
+	}
+	result.Content = append(result.Content, ToolContent{Type: "text", Text: strings.Join(refs, "\n")})
+}
+
+// callToolWithLimits checks the caller's limits and runs the tool call.
+func (s *Server) callToolWithLimits(ctx context.Context, req *Request, params *CallToolParams) *Response {
+	// Limits are checked before any RIPEstat request is made. Calls to
+	// disabled tools fail without upstream traffic and are not counted.
+	if s.IsToolEnabled(params.Name) {
+		if err := s.quotas.Allow(s.callerFromContext(ctx)); err != nil {
+			var limitErr *quota.LimitError
+			if errors.As(err, &limitErr) {
+				slog.WarnContext(ctx, "tool call rejected by limit", "tool", params.Name, "limit", limitErr.Limit, "caller", limitErr.Caller)
+				return NewErrorResponse(RateLimitError, "Rate limit exceeded: "+limitErr.Error(), limitErr, req.ID)
+			}
+			return NewErrorResponse(InternalError, "Internal error", err.Error(), req.ID)
+		}
+	}
+
 	result, err := s.executeToolCall(ctx, params)
 	if err != nil {
-		slog.Error("tool execution failed", "tool", params.Name, "err", err)
-		return NewErrorResponse(ToolError, "Tool execution failed", err.Error(), req.ID), nil
+		slog.ErrorContext(ctx, "tool execution failed", "tool", params.Name, "err", err)
+		return NewErrorResponse(ToolError, "Tool execution failed", err.Error(), req.ID)
 	}
 
-	return NewResponse(result, req.ID), nil
+	return NewResponse(result, req.ID)
 }
 
 // executeToolCall executes a tool call.
 func (s *Server) executeToolCall(ctx context.Context, params *CallToolParams) (*ToolResult, error) {
-	slog.Debug("executing tool call", "tool", params.Name)
+	slog.DebugContext(ctx, "executing tool call", "tool", params.Name)
 
 	// Parse arguments
 	args := make(map[string]interface{})
@@ -325,7 +543,50 @@ func (s *Server) executeToolCall(ctx context.Context, params *CallToolParams) (*
 		}
 	}
 
-	switch params.Name {
+	if bypass, ok := args[bypassCacheArgument]; ok {
+		delete(args, bypassCacheArgument)
+		if bypass == true || bypass == "true" {
+			ctx = client.WithCacheBypass(ctx)
+		}
+	}
+
+	page, errResult := pageParams(args)
+	if errResult != nil {
+		return errResult, nil
+	}
+	shape, errResult := shapeParams(args)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	result, err := s.callTool(ctx, params.Name, args)
+	if err == nil && result != nil && !result.IsError {
+		s.recordRecentResources(args)
+
+		s.toolsMu.RLock()
+		maxBytes := s.maxResultBytes
+		s.toolsMu.RUnlock()
+		result = shapeResult(result, shape)
+		result = paginateResult(result, params.Name, args, page, maxBytes)
+	}
+
+	return result, err
+}
+
+// callTool dispatches a tool call to its implementation.
+func (s *Server) callTool(ctx context.Context, name string, args map[string]interface{}) (*ToolResult, error) {
+	if !s.IsToolEnabled(name) {
+		return nil, fmt.Errorf("tool is disabled: %s", name)
+	}
+	hostnameArg, result := normalizeResourceArgs(name, args)
+	if result != nil {
+		return result, nil
+	}
+	if hostnameArg != "" {
+		return s.callResolved(ctx, name, args, hostnameArg)
+	}
+
+	switch name {
 	case "getNetworkInfo":
 		return s.callNetworkInfo(ctx, args)
 	case "getASOverview":
@@ -369,12 +630,9 @@ func (s *Server) executeToolCall(ctx context.Context, params *CallToolParams) (*
 	case "getASRoutingConsistency":
 		return s.callASRoutingConsistency(ctx, args)
 	case "getWhatsMyIP":
-		if s.disableWhatsMyIP {
-			return nil, fmt.Errorf("whats-my-ip tool is disabled")
-		}
 		return s.callWhatsMyIP(ctx, args)
 	default:
-		return nil, fmt.Errorf("unknown tool: %s", params.Name)
+		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
 }
 
@@ -388,7 +646,7 @@ func (s *Server) callNetworkInfo(ctx context.Context, args map[string]interface{
 
 	result, err := networkinfo.GetNetworkInfo(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -402,7 +660,7 @@ func (s *Server) callASOverview(ctx context.Context, args map[string]interface{}
 
 	result, err := asoverview.GetASOverview(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -416,7 +674,7 @@ func (s *Server) callAnnouncedPrefixes(ctx context.Context, args map[string]inte
 
 	result, err := announcedprefixes.GetAnnouncedPrefixes(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -430,7 +688,7 @@ func (s *Server) callRelatedPrefixes(ctx context.Context, args map[string]interf
 
 	result, err := relatedprefixes.GetRelatedPrefixes(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -444,7 +702,7 @@ func (s *Server) callRoutingStatus(ctx context.Context, args map[string]interfac
 
 	result, err := routingstatus.GetRoutingStatus(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -456,35 +714,45 @@ func (s *Server) callRoutingHistory(ctx context.Context, args map[string]interfa
 		return errResult, nil
 	}
 
-	// Get optional pagination parameters
-	startTime := getOptionalStringParam(args, "start_time")
-	endTime := getOptionalStringParam(args, "end_time")
+	window, errResult := timeWindowParam(args, timerange.Limits{})
+	if errResult != nil {
+		return errResult, nil
+	}
 
 	var maxResults int
 	if maxResultsStr := getOptionalStringParam(args, "max_results"); maxResultsStr != "" {
 		var err error
 		maxResults, err = strconv.Atoi(maxResultsStr)
 		if err != nil {
-			return CreateToolResult("Error: max_results parameter must be a valid integer", true), nil
+			return invalidInputResult("Error: max_results parameter must be a valid integer"), nil
 		}
 		if maxResults < 0 {
-			return CreateToolResult("Error: max_results parameter must be non-negative", true), nil
+			return invalidInputResult("Error: max_results parameter must be non-negative"), nil
 		}
 	}
 
 	// Use paginated version if any optional parameters are provided
-	if startTime != "" || endTime != "" || maxResults > 0 {
+	if !window.IsZero() || maxResults > 0 {
+		var startTime, endTime string
+		if !window.Start.IsZero() {
+			startTime = timerange.FormatParam(window.Start)
+		}
+		if !window.End.IsZero() {
+			endTime = timerange.FormatParam(window.End)
+		}
 		result, err := routinghistory.GetRoutingHistoryWithOptions(ctx, resource, startTime, endTime, maxResults)
 		if err != nil {
-			return CreateToolResult(formatErrorMessage(err), true), nil
+			return CreateToolErrorResult(err), nil
 		}
-		return CreateToolResultFromJSON(result), nil
+		toolResult := CreateToolResultFromJSON(result)
+		addTimeWindow(toolResult, window)
+		return toolResult, nil
 	}
 
 	// Default behavior - use original function for backward compatibility
 	result, err := routinghistory.GetRoutingHistory(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -498,7 +766,7 @@ func (s *Server) callWhois(ctx context.Context, args map[string]interface{}) (*T
 
 	result, err := whois.GetWhois(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -512,7 +780,7 @@ func (s *Server) callAbuseContactFinder(ctx context.Context, args map[string]int
 
 	result, err := abusecontactfinder.GetAbuseContactFinder(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -531,7 +799,7 @@ func (s *Server) callRPKIValidation(ctx context.Context, args map[string]interfa
 
 	result, err := rpkivalidation.GetRPKIValidation(ctx, resource, prefix)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -543,12 +811,19 @@ func (s *Server) callRPKIHistory(ctx context.Context, args map[string]interface{
 		return errResult, nil
 	}
 
-	result, err := rpkihistory.GetRPKIHistory(ctx, resource)
+	window, errResult := timeWindowParam(args, rpkihistory.TimeLimits)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	result, err := rpkihistory.GetRPKIHistoryWithOptions(ctx, resource, &rpkihistory.GetOptions{Window: window})
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
-	return CreateToolResultFromJSON(result), nil
+	toolResult := CreateToolResultFromJSON(result)
+	addTimeWindow(toolResult, window)
+	return toolResult, nil
 }
 
 func (s *Server) callASNNeighbours(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
@@ -566,7 +841,7 @@ func (s *Server) callASNNeighbours(ctx context.Context, args map[string]interfac
 
 	result, err := asnneighbours.GetASNNeighbours(ctx, resource, lod, queryTime)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -585,7 +860,7 @@ func (s *Server) callLookingGlass(ctx context.Context, args map[string]interface
 
 	result, err := lookingglass.GetLookingGlass(ctx, resource, lookBackLimit)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -605,7 +880,7 @@ func (s *Server) callCountryASNs(ctx context.Context, args map[string]interface{
 	opts := &countryasns.GetOptions{LOD: lod}
 	result, err := countryasns.GetCountryASNs(ctx, resource, opts)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -617,12 +892,19 @@ func (s *Server) callBGPlay(ctx context.Context, args map[string]interface{}) (*
 		return errResult, nil
 	}
 
-	result, err := bgplay.GetBGPlay(ctx, resource)
+	window, errResult := timeWindowParam(args, bgplay.TimeLimits)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	result, err := bgplay.GetBGPlayWithOptions(ctx, resource, &bgplay.GetOptions{Window: window})
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
-	return CreateToolResultFromJSON(result), nil
+	toolResult := CreateToolResultFromJSON(result)
+	addTimeWindow(toolResult, window)
+	return toolResult, nil
 }
 
 func (s *Server) callBGPUpdates(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
@@ -631,12 +913,19 @@ func (s *Server) callBGPUpdates(ctx context.Context, args map[string]interface{}
 		return errResult, nil
 	}
 
-	result, err := bgpupdates.GetBGPUpdates(ctx, resource)
+	window, errResult := timeWindowParam(args, bgpupdates.TimeLimits)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	result, err := bgpupdates.GetBGPUpdatesWithOptions(ctx, resource, &bgpupdates.GetOptions{Window: window})
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
-	return CreateToolResultFromJSON(result), nil
+	toolResult := CreateToolResultFromJSON(result)
+	addTimeWindow(toolResult, window)
+	return toolResult, nil
 }
 
 func (s *Server) callPrefixRoutingConsistency(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
@@ -647,7 +936,7 @@ func (s *Server) callPrefixRoutingConsistency(ctx context.Context, args map[stri
 
 	result, err := prefixroutingconsistency.GetPrefixRoutingConsistency(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -661,7 +950,7 @@ func (s *Server) callPrefixOverview(ctx context.Context, args map[string]interfa
 
 	result, err := prefixoverview.GetPrefixOverview(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -675,7 +964,7 @@ func (s *Server) callAddressSpaceHierarchy(ctx context.Context, args map[string]
 
 	result, err := addressspacehierarchy.GetAddressSpaceHierarchy(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -687,12 +976,19 @@ func (s *Server) callAllocationHistory(ctx context.Context, args map[string]inte
 		return errResult, nil
 	}
 
-	result, err := allocationhistory.GetAllocationHistory(ctx, resource)
+	window, errResult := timeWindowParam(args, allocationhistory.TimeLimits)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	result, err := allocationhistory.GetAllocationHistoryWithOptions(ctx, resource, &allocationhistory.GetOptions{Window: window})
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
-	return CreateToolResultFromJSON(result), nil
+	toolResult := CreateToolResultFromJSON(result)
+	addTimeWindow(toolResult, window)
+	return toolResult, nil
 }
 
 func (s *Server) callASPathLength(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
@@ -703,7 +999,7 @@ func (s *Server) callASPathLength(ctx context.Context, args map[string]interface
 
 	result, err := aspathlength.GetASPathLength(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -717,7 +1013,7 @@ func (s *Server) callASRoutingConsistency(ctx context.Context, args map[string]i
 
 	result, err := asroutingconsistency.GetASRoutingConsistency(ctx, resource)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -728,12 +1024,12 @@ func (s *Server) callWhatsMyIP(ctx context.Context, _ map[string]interface{}) (*
 	if httpReq, ok := HTTPRequestFromContext(ctx); ok {
 		// Extract client IP from HTTP headers for proxy scenarios
 		clientIP := whatsmyip.ExtractClientIP(httpReq)
-		slog.Debug("extracted client IP from HTTP request", "client_ip", clientIP, "remote_addr", httpReq.RemoteAddr)
+		slog.DebugContext(ctx, "extracted client IP from HTTP request", "client_ip", clientIP, "remote_addr", httpReq.RemoteAddr)
 
 		// Use the extracted client IP for whats-my-ip query
 		result, err := whatsmyip.GetWhatsMyIPWithClientIP(ctx, clientIP)
 		if err != nil {
-			return CreateToolResult(formatErrorMessage(err), true), nil
+			return CreateToolErrorResult(err), nil
 		}
 		return CreateToolResultFromJSON(result), nil
 	}
@@ -741,7 +1037,7 @@ func (s *Server) callWhatsMyIP(ctx context.Context, _ map[string]interface{}) (*
 	// Fallback to standard behavior if no HTTP context available
 	result, err := whatsmyip.GetWhatsMyIP(ctx)
 	if err != nil {
-		return CreateToolResult(formatErrorMessage(err), true), nil
+		return CreateToolErrorResult(err), nil
 	}
 
 	return CreateToolResultFromJSON(result), nil
@@ -1,13 +1,24 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/audit"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/logging"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/metrics"
 )
 
 func TestNewServer(t *testing.T) {
@@ -19,8 +30,8 @@ func TestNewServer(t *testing.T) {
 	if server.serverVersion != "1.0.0" {
 		t.Errorf("Expected serverVersion to be '1.0.0', got %s", server.serverVersion)
 	}
-	if server.disableWhatsMyIP != false {
-		t.Errorf("Expected disableWhatsMyIP to be false, got %v", server.disableWhatsMyIP)
+	if !server.IsToolEnabled("getWhatsMyIP") {
+		t.Error("Expected getWhatsMyIP to be enabled")
 	}
 	if server.initialized != false {
 		t.Errorf("Expected initialized to be false, got %v", server.initialized)
@@ -1202,7 +1213,7 @@ func TestHandleInitialize_EdgeCases(t *testing.T) {
 		Params:  nil,
 	}
 
-	result, err := server.handleInitialize(req)
+	result, err := server.handleInitialize(context.Background(), req)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -1212,7 +1223,7 @@ func TestHandleInitialize_EdgeCases(t *testing.T) {
 
 	// Test with invalid params that can't be marshaled
 	req.Params = make(chan int) // Invalid JSON type
-	result, err = server.handleInitialize(req)
+	result, err = server.handleInitialize(context.Background(), req)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -2476,3 +2487,115 @@ func TestExecuteToolCall_UncoveredFunctions(t *testing.T) {
 		testBasicResourceTool(t, "getBGPlay", "8.8.8.8")
 	})
 }
+
+func TestHandleToolsCall_Metrics(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+
+	callsFor := func(tool, status string) float64 {
+		t.Helper()
+		for _, family := range metrics.PrometheusFamilies() {
+			if family.Name != "mcp_tool_calls_total" {
+				continue
+			}
+			for _, sample := range family.Samples {
+				if sample.Labels[0].Value == tool && sample.Labels[1].Value == status {
+					return sample.Value
+				}
+			}
+		}
+		return 0
+	}
+
+	before := callsFor("getWhois", audit.StatusToolError)
+	beforeUnknown := callsFor("unknown", audit.StatusError)
+
+	callMissingResource(t, server, "s1")
+	req := NewRequest("tools/call", map[string]interface{}{"name": "noSuchTool"}, 1)
+	if _, err := server.handleToolsCall(context.Background(), req); err != nil {
+		t.Fatalf("handleToolsCall failed: %v", err)
+	}
+
+	if got := callsFor("getWhois", audit.StatusToolError); got != before+1 {
+		t.Errorf("Expected getWhois tool_error count %v, got %v", before+1, got)
+	}
+	if got := callsFor("unknown", audit.StatusError); got != beforeUnknown+1 {
+		t.Errorf("Expected unknown error count %v, got %v", beforeUnknown+1, got)
+	}
+}
+
+func TestProcessMessage_LogContext(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	slog.SetDefault(slog.New(logging.NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+
+	server := NewServer("test-server", "1.0.0", false)
+	ctx := WithSessionID(context.Background(), "session-1")
+	ctx = WithRequestID(ctx, "request-1")
+	if _, err := server.ProcessMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "ping", "id": 42}`)); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		if entry[logging.KeySessionID] != "session-1" || entry[logging.KeyRequestID] != "request-1" {
+			t.Errorf("Expected session and request IDs on every line, got %s", line)
+		}
+		if entry["msg"] == "handling ping request" {
+			found = true
+			if entry[logging.KeyRPCID] != float64(42) {
+				t.Errorf("Expected JSON-RPC ID on request lines, got %s", line)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected ping to be logged, got %s", buf.String())
+	}
+}
+
+func TestExecuteToolCall_BypassCache(t *testing.T) {
+	var requests atomic.Int32
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, fmt.Sprintf(`{"status": "ok", "query_id": "q%d", "data": {"prefix": "198.51.100.0/24", "asns": ["64496"]}}`, requests.Add(1)))
+	}))
+	defer stub.Close()
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	defer ripeconfig.SetDefault(nil)
+
+	server := NewServer("test-server", "1.0.0", false)
+	call := func(arguments map[string]interface{}) {
+		t.Helper()
+		result, err := server.executeToolCall(context.Background(), &CallToolParams{Name: "getNetworkInfo", Arguments: arguments})
+		if err != nil || result.IsError {
+			t.Fatalf("executeToolCall failed: %v %+v", err, result)
+		}
+	}
+
+	// The resource is unique to this test, so the shared cache starts cold.
+	call(map[string]interface{}{"resource": "198.51.100.7"})
+	call(map[string]interface{}{"resource": "198.51.100.7"})
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("Expected the second call to be served from the cache, got %d requests", got)
+	}
+
+	call(map[string]interface{}{"resource": "198.51.100.7", "bypass_cache": true})
+	call(map[string]interface{}{"resource": "198.51.100.7", "bypass_cache": false})
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected only bypass_cache: true to reach RIPEstat, got %d requests", got)
+	}
+
+	for _, tool := range CreateToolsList().Tools {
+		properties := tool.InputSchema.(map[string]interface{})["properties"].(map[string]interface{})
+		if _, ok := properties["bypass_cache"]; !ok {
+			t.Errorf("Expected %s to accept bypass_cache", tool.Name)
+		}
+	}
+}
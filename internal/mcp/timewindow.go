@@ -0,0 +1,41 @@
+package mcp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
+)
+
+// timeWindowMetaKey is the _meta key reporting the effective time window of
+// a historical tool result.
+const timeWindowMetaKey = "window"
+
+// timeWindowParam resolves the start_time and end_time arguments against the
+// limits of the tool's data call. Without either it returns the zero window.
+func timeWindowParam(args map[string]interface{}, limits timerange.Limits) (timerange.Window, *ToolResult) {
+	window, err := timerange.Resolve(getOptionalStringParam(args, "start_time"), getOptionalStringParam(args, "end_time"), time.Now(), limits)
+	if err != nil {
+		return timerange.Window{}, invalidInputResult(fmt.Sprintf("Error: %v", err))
+	}
+	return window, nil
+}
+
+// addTimeWindow reports the time window a successful result covers, both in
+// _meta and as a text line, so callers see how their arguments were
+// interpreted and capped.
+func addTimeWindow(result *ToolResult, window timerange.Window) {
+	if result.IsError || window.IsZero() {
+		return
+	}
+
+	reported := map[string]string{"end": window.End.Format(time.RFC3339)}
+	if !window.Start.IsZero() {
+		reported["start"] = window.Start.Format(time.RFC3339)
+	}
+	if result.Meta == nil {
+		result.Meta = map[string]interface{}{}
+	}
+	result.Meta[timeWindowMetaKey] = reported
+	result.Content = append(result.Content, ToolContent{Type: "text", Text: "Time window: " + window.String() + " (UTC)"})
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
+)
+
+// useQueryRecordingStubRIPEstat points the RIPEstat client at a stub answering
+// body and returns the query of the last request.
+func useQueryRecordingStubRIPEstat(t *testing.T, body string) func() url.Values {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		query url.Values
+	)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		query = r.URL.Query()
+		mu.Unlock()
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(stub.Close)
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	t.Cleanup(func() { ripeconfig.SetDefault(nil) })
+	cache.Shared().Clear()
+	t.Cleanup(cache.Shared().Clear)
+
+	return func() url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return query
+	}
+}
+
+func TestCallTool_TimeWindow(t *testing.T) {
+	lastQuery := useQueryRecordingStubRIPEstat(t, `{"status": "ok", "data": {"resource": "193.0.0.0/21"}}`)
+	server := NewServer("test", "1.0.0", false)
+
+	tools := []string{"getBGPlay", "getBGPUpdates", "getAllocationHistory", "getRoutingHistory"}
+	for _, tool := range tools {
+		t.Run(tool, func(t *testing.T) {
+			args := map[string]interface{}{
+				"resource":   "193.0.0.0/21",
+				"start_time": "2024-03-01",
+				"end_time":   "1709294400",
+			}
+			result, err := server.callTool(context.Background(), tool, args)
+			if err != nil || result.IsError {
+				t.Fatalf("Unexpected failure: %v %+v", err, result)
+			}
+
+			query := lastQuery()
+			if query.Get("starttime") != "2024-03-01T00:00:00" || query.Get("endtime") != "2024-03-01T12:00:00" {
+				t.Errorf("Expected the normalized window upstream, got %q", query.Encode())
+			}
+
+			window, ok := result.Meta[timeWindowMetaKey].(map[string]string)
+			if !ok || window["start"] != "2024-03-01T00:00:00Z" || window["end"] != "2024-03-01T12:00:00Z" {
+				t.Errorf("Expected the effective window in _meta, got %+v", result.Meta)
+			}
+			last := result.Content[len(result.Content)-1].Text
+			if last != "Time window: 2024-03-01T00:00:00Z to 2024-03-01T12:00:00Z (UTC)" {
+				t.Errorf("Expected the effective window in the content, got %q", last)
+			}
+		})
+	}
+}
+
+func TestCallTool_TimeWindowDefaults(t *testing.T) {
+	lastQuery := useQueryRecordingStubRIPEstat(t, `{"status": "ok", "data": {"resource": "193.0.0.0/21"}}`)
+	server := NewServer("test", "1.0.0", false)
+
+	result, err := server.callTool(context.Background(), "getBGPUpdates", map[string]interface{}{"resource": "193.0.0.0/21"})
+	if err != nil || result.IsError {
+		t.Fatalf("Unexpected failure: %v %+v", err, result)
+	}
+	if query := lastQuery(); query.Has("starttime") || query.Has("endtime") {
+		t.Errorf("Expected RIPEstat's default window without time arguments, got %q", query.Encode())
+	}
+	if _, ok := result.Meta[timeWindowMetaKey]; ok {
+		t.Errorf("Expected no window to be reported without time arguments, got %+v", result.Meta)
+	}
+
+	result, _ = server.callTool(context.Background(), "getBGPlay", map[string]interface{}{"resource": "193.0.0.0/21", "end_time": "yesterday"})
+	if result.IsError {
+		t.Fatalf("Unexpected failure: %+v", result)
+	}
+	start, err := time.Parse(timerange.ParamLayout, lastQuery().Get("starttime"))
+	if err != nil {
+		t.Fatalf("Expected a starttime, got %q", lastQuery().Encode())
+	}
+	end, _ := time.Parse(timerange.ParamLayout, lastQuery().Get("endtime"))
+	if span := end.Sub(start); span != 24*time.Hour {
+		t.Errorf("Expected BGPlay's default span of 24h, got %s", span)
+	}
+}
+
+func TestCallTool_TimeWindowRPKIHistory(t *testing.T) {
+	lastQuery := useQueryRecordingStubRIPEstat(t, `{"status": "ok", "data": {"timeseries": [
+		{"prefix": "193.0.22.0/23", "time": "2015-02-11T00:00:00Z", "count": 1},
+		{"prefix": "193.0.22.0/23", "time": "2024-01-01T00:00:00Z", "count": 3}
+	]}}`)
+	server := NewServer("test", "1.0.0", false)
+
+	result, err := server.callTool(context.Background(), "getRPKIHistory", map[string]interface{}{"resource": "193.0.22.0/23", "start_time": "2020-01-01"})
+	if err != nil || result.IsError {
+		t.Fatalf("Unexpected failure: %v %+v", err, result)
+	}
+	if query := lastQuery(); query.Has("starttime") {
+		t.Errorf("Expected the window to be applied locally, got %q", query.Encode())
+	}
+	if text := result.Content[0].Text; strings.Contains(text, "2015-02-11") || !strings.Contains(text, "2024-01-01") {
+		t.Errorf("Expected only the entries within the window, got %s", text)
+	}
+}
+
+func TestCallTool_TimeWindowInvalid(t *testing.T) {
+	useQueryRecordingStubRIPEstat(t, `{"status": "ok", "data": {}}`)
+	server := NewServer("test", "1.0.0", false)
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "unparseable", tool: "getRoutingHistory", args: map[string]interface{}{"start_time": "last tuesday"}, want: "start_time: unrecognized time"},
+		{name: "reversed", tool: "getAllocationHistory", args: map[string]interface{}{"start_time": "2024-03-02", "end_time": "2024-03-01"}, want: "is after end_time"},
+		{name: "too long", tool: "getBGPUpdates", args: map[string]interface{}{"start_time": "30 days ago"}, want: "window of 30d exceeds the maximum of 7d"},
+		{name: "future", tool: "getRPKIHistory", args: map[string]interface{}{"start_time": "9999-01-01"}, want: "is in the future"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["resource"] = "193.0.0.0/21"
+			result, err := server.callTool(context.Background(), tt.tool, tt.args)
+			if err != nil {
+				t.Fatalf("callTool failed: %v", err)
+			}
+			if !result.IsError || !strings.Contains(result.Content[0].Text, tt.want) {
+				t.Errorf("Expected an invalid input error containing %q, got %+v", tt.want, result)
+			}
+			if classified, ok := result.Meta[toolErrorMetaKey].(*Error); !ok || classified.Code != InvalidParams {
+				t.Errorf("Expected an invalid params error, got %+v", result.Meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	ripestaterrors "github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+)
+
+// toolErrorMetaKey is the _meta key of failed tool results holding the
+// JSON-RPC error code and kind of the failure, so agents can tell a resource
+// without data from an upstream outage without parsing the message.
+const toolErrorMetaKey = "error"
+
+// toolErrorCode returns the JSON-RPC error code reported for an error kind.
+func toolErrorCode(kind ripestaterrors.Kind) int {
+	switch kind {
+	case ripestaterrors.KindInvalidInput:
+		return InvalidParams
+	case ripestaterrors.KindNotFound:
+		return NotFoundError
+	case ripestaterrors.KindRateLimited:
+		return RateLimitError
+	case ripestaterrors.KindUnavailable:
+		return UnavailableError
+	case ripestaterrors.KindTimeout:
+		return TimeoutError
+	default:
+		return ToolError
+	}
+}
+
+// CreateToolErrorResult creates a failed tool result for err, classified by
+// its kind.
+func CreateToolErrorResult(err error) *ToolResult {
+	return classifyToolError(CreateToolResult(formatErrorMessage(err), true), ripestaterrors.KindOf(err))
+}
+
+// invalidInputResult creates a failed tool result for arguments rejected
+// before any RIPEstat request.
+func invalidInputResult(text string) *ToolResult {
+	return classifyToolError(CreateToolResult(text, true), ripestaterrors.KindInvalidInput)
+}
+
+// classifyToolError records the code and kind of a failed tool result in its
+// _meta.
+func classifyToolError(result *ToolResult, kind ripestaterrors.Kind) *ToolResult {
+	result.Meta = map[string]interface{}{
+		toolErrorMetaKey: &Error{Code: toolErrorCode(kind), Message: string(kind)},
+	}
+	return result
+}
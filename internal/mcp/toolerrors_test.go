@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	ripestaterrors "github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+)
+
+func TestCreateToolErrorResult(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+		kind string
+	}{
+		{"invalid input", ripestaterrors.ErrInvalidParameter.WithError(errors.New("HTTP status: 400")), InvalidParams, "invalid_input"},
+		{"not found", ripestaterrors.Wrap(ripestaterrors.ErrNotFound, "failed to get AS overview"), NotFoundError, "not_found"},
+		{"rate limited", ripestaterrors.ErrRateLimited, RateLimitError, "rate_limited"},
+		{"unavailable", ripestaterrors.ErrServerError.WithError(errors.New("HTTP status: 502")), UnavailableError, "upstream_unavailable"},
+		{"timeout", fmt.Errorf("waiting for a slot: %w", context.DeadlineExceeded), TimeoutError, "timeout"},
+		{"internal", errors.New("boom"), ToolError, "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CreateToolErrorResult(tt.err)
+			if !result.IsError || result.Content[0].Text != "Error: "+tt.err.Error() {
+				t.Errorf("Unexpected result %+v", result)
+			}
+
+			data, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("Failed to marshal result: %v", err)
+			}
+			var decoded struct {
+				Meta struct {
+					Error Error `json:"error"`
+				} `json:"_meta"`
+			}
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if decoded.Meta.Error.Code != tt.code || decoded.Meta.Error.Message != tt.kind {
+				t.Errorf("_meta.error = %+v, want code %d and kind %q", decoded.Meta.Error, tt.code, tt.kind)
+			}
+		})
+	}
+}
+
+func TestExecuteToolCall_InvalidInputClassified(t *testing.T) {
+	server := NewServer("test", "1.0.0", false)
+
+	result, err := server.executeToolCall(context.Background(), &CallToolParams{Name: "getASOverview", Arguments: map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("executeToolCall failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatalf("Expected a failed result, got %+v", result)
+	}
+	if classified, ok := result.Meta[toolErrorMetaKey].(*Error); !ok || classified.Code != InvalidParams {
+		t.Errorf("Expected the missing resource to be classified as invalid input, got %+v", result.Meta)
+	}
+}
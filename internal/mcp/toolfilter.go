@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// toolCategories groups tools so that deployments can enable or disable related
+// tools together. A tool may belong to several categories.
+var toolCategories = map[string][]string{
+	"network": {
+		"getNetworkInfo",
+		"getPrefixOverview",
+		"getRelatedPrefixes",
+		"getAddressSpaceHierarchy",
+	},
+	"asn": {
+		"getASOverview",
+		"getAnnouncedPrefixes",
+		"getASNNeighbours",
+		"getASPathLength",
+		"getASRoutingConsistency",
+		"getCountryASNs",
+	},
+	"routing": {
+		"getRoutingStatus",
+		"getLookingGlass",
+		"getPrefixRoutingConsistency",
+		"getASRoutingConsistency",
+		"getASPathLength",
+	},
+	"registry": {
+		"getWhois",
+		"getAbuseContactFinder",
+		"getAddressSpaceHierarchy",
+		"getAllocationHistory",
+	},
+	"rpki": {
+		"getRPKIValidation",
+		"getRPKIHistory",
+	},
+	"historical": {
+		"getRoutingHistory",
+		"getRPKIHistory",
+		"getBGPlay",
+		"getBGPUpdates",
+		"getAllocationHistory",
+	},
+	"client": {
+		"getWhatsMyIP",
+	},
+}
+
+// ToolFilter selects which tools are exposed. Entries are tool names or
+// category names. An empty allow list allows every tool; deny entries always
+// win over allow entries.
+type ToolFilter struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Validate checks that every entry names a known tool or category.
+func (f ToolFilter) Validate() error {
+	known := knownTools()
+	for _, list := range [][]string{f.Allow, f.Deny} {
+		for _, entry := range list {
+			if !known[entry] && toolCategories[entry] == nil {
+				return fmt.Errorf("unknown tool or category: %q", entry)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Allows reports whether the filter exposes the named tool.
+func (f ToolFilter) Allows(name string) bool {
+	if matchesToolEntry(f.Deny, name) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchesToolEntry(f.Allow, name)
+}
+
+// clone returns a copy of f that shares no memory with it.
+func (f ToolFilter) clone() ToolFilter {
+	return ToolFilter{
+		Allow: append([]string(nil), f.Allow...),
+		Deny:  append([]string(nil), f.Deny...),
+	}
+}
+
+// matchesToolEntry reports whether name is listed directly or through one of
+// its categories.
+func matchesToolEntry(entries []string, name string) bool {
+	for _, entry := range entries {
+		if entry == name {
+			return true
+		}
+		for _, member := range toolCategories[entry] {
+			if member == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ToolCategories returns the category names with their member tools.
+func ToolCategories() map[string][]string {
+	categories := make(map[string][]string, len(toolCategories))
+	for category, tools := range toolCategories {
+		categories[category] = append([]string(nil), tools...)
+	}
+	return categories
+}
+
+// knownTools is the set of tool names in the tools list.
+var knownTools = sync.OnceValue(func() map[string]bool {
+	known := make(map[string]bool)
+	for _, tool := range CreateToolsList().Tools {
+		known[tool.Name] = true
+	}
+	return known
+})
+
+// toolMetricName returns the tool label used in metrics. Names that are not
+// tools are reported as "unknown" so that callers cannot create arbitrary
+// series.
+func toolMetricName(name string) string {
+	if knownTools()[name] {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseToolList splits a comma-separated list of tool or category names.
+func ParseToolList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// SetToolFilter replaces the tool filter. When the set of exposed tools
+// changes, a notifications/tools/list_changed notification is sent to every
+// subscribed session.
+func (s *Server) SetToolFilter(filter ToolFilter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	s.toolsMu.Lock()
+	before := s.enabledToolsLocked()
+	s.toolFilter = filter.clone()
+	after := s.enabledToolsLocked()
+	s.toolsMu.Unlock()
+
+	if strings.Join(before, ",") != strings.Join(after, ",") {
+		s.notify(NewNotification("notifications/tools/list_changed", nil))
+	}
+
+	return nil
+}
+
+// ToolFilter returns the current tool filter.
+func (s *Server) ToolFilter() ToolFilter {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	return s.toolFilter.clone()
+}
+
+// IsToolEnabled reports whether the named tool is currently exposed.
+func (s *Server) IsToolEnabled(name string) bool {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	return s.toolFilter.Allows(name)
+}
+
+// EnabledTools returns the sorted names of the currently exposed tools.
+func (s *Server) EnabledTools() []string {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	return s.enabledToolsLocked()
+}
+
+func (s *Server) enabledToolsLocked() []string {
+	var names []string
+	for _, tool := range CreateToolsList().Tools {
+		if s.toolFilter.Allows(tool.Name) {
+			names = append(names, tool.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
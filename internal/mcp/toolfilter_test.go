@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolCategories_ReferenceKnownTools(t *testing.T) {
+	known := make(map[string]bool)
+	for _, tool := range CreateToolsList().Tools {
+		known[tool.Name] = true
+	}
+
+	categorized := make(map[string]bool)
+	for category, tools := range toolCategories {
+		if known[category] {
+			t.Errorf("category %q collides with a tool name", category)
+		}
+		for _, tool := range tools {
+			if !known[tool] {
+				t.Errorf("category %q lists unknown tool %q", category, tool)
+			}
+			categorized[tool] = true
+		}
+	}
+
+	for name := range known {
+		if !categorized[name] {
+			t.Errorf("tool %q is not in any category", name)
+		}
+	}
+}
+
+func TestToolFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ToolFilter
+		tool   string
+		want   bool
+	}{
+		{name: "empty filter", filter: ToolFilter{}, tool: "getWhois", want: true},
+		{name: "denied by name", filter: ToolFilter{Deny: []string{"getWhois"}}, tool: "getWhois", want: false},
+		{name: "denied by category", filter: ToolFilter{Deny: []string{"historical"}}, tool: "getBGPlay", want: false},
+		{name: "other category not denied", filter: ToolFilter{Deny: []string{"historical"}}, tool: "getWhois", want: true},
+		{name: "allowed by category", filter: ToolFilter{Allow: []string{"rpki"}}, tool: "getRPKIValidation", want: true},
+		{name: "not in allow list", filter: ToolFilter{Allow: []string{"rpki"}}, tool: "getWhois", want: false},
+		{name: "deny wins over allow", filter: ToolFilter{Allow: []string{"rpki"}, Deny: []string{"historical"}}, tool: "getRPKIHistory", want: false},
+		{name: "allowed by name", filter: ToolFilter{Allow: []string{"getWhois"}}, tool: "getWhois", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.tool); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolFilter_Validate(t *testing.T) {
+	if err := (ToolFilter{Allow: []string{"getWhois", "rpki"}, Deny: []string{"client"}}).Validate(); err != nil {
+		t.Errorf("Expected valid filter, got %v", err)
+	}
+
+	err := ToolFilter{Deny: []string{"getNothing"}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "getNothing") {
+		t.Errorf("Expected error naming the unknown entry, got %v", err)
+	}
+}
+
+func TestParseToolList(t *testing.T) {
+	got := ParseToolList(" historical, getWhatsMyIP,,")
+	if strings.Join(got, "|") != "historical|getWhatsMyIP" {
+		t.Errorf("ParseToolList() = %q", got)
+	}
+	if ParseToolList("") != nil {
+		t.Error("Expected nil for empty list")
+	}
+}
+
+func TestServer_SetToolFilter(t *testing.T) {
+	server := NewServer("test-server", "1.0.0", false)
+	server.initialized = true
+
+	notifications, unsubscribe := server.SubscribeNotifications("session-1")
+	defer unsubscribe()
+
+	if err := server.SetToolFilter(ToolFilter{Deny: []string{"unknown"}}); err == nil {
+		t.Fatal("Expected error for unknown entry")
+	}
+
+	if err := server.SetToolFilter(ToolFilter{Deny: []string{"historical"}}); err != nil {
+		t.Fatalf("SetToolFilter failed: %v", err)
+	}
+
+	select {
+	case notif := <-notifications:
+		if notif.Method != "notifications/tools/list_changed" {
+			t.Errorf("Expected list_changed notification, got %s", notif.Method)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected list_changed notification")
+	}
+
+	// Setting an equivalent filter does not notify.
+	if err := server.SetToolFilter(ToolFilter{Deny: []string{"getRoutingHistory", "getRPKIHistory", "getBGPlay", "getBGPUpdates", "getAllocationHistory"}}); err != nil {
+		t.Fatalf("SetToolFilter failed: %v", err)
+	}
+	select {
+	case notif := <-notifications:
+		t.Errorf("Unexpected notification %s", notif.Method)
+	default:
+	}
+
+	response := listToolsPage(t, server, "")
+	for _, tool := range response.Result.(*ToolsListResult).Tools {
+		if matchesToolEntry([]string{"historical"}, tool.Name) {
+			t.Errorf("Denied tool %s listed", tool.Name)
+		}
+	}
+
+	_, err := server.executeToolCall(context.Background(), &CallToolParams{Name: "getBGPlay", Arguments: map[string]interface{}{"resource": "193.0.0.0/21"}})
+	if err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("Expected disabled error, got %v", err)
+	}
+
+	if server.IsToolEnabled("getBGPlay") || !server.IsToolEnabled("getWhois") {
+		t.Error("IsToolEnabled does not reflect the filter")
+	}
+}
+
+func TestNotificationHub_Unsubscribe(t *testing.T) {
+	hub := newNotificationHub()
+
+	ch, unsubscribe := hub.subscribe("session-1")
+	unsubscribe()
+	unsubscribe() // Safe to call twice.
+
+	if _, ok := <-ch; ok {
+		t.Error("Expected channel to be closed")
+	}
+	if hub.count() != 0 {
+		t.Errorf("Expected no subscribers, got %d", hub.count())
+	}
+
+	// Broadcasting without subscribers must not block.
+	hub.broadcast(NewNotification("notifications/tools/list_changed", nil))
+}
+
+func TestNotificationHub_DropsForSlowSubscriber(t *testing.T) {
+	hub := newNotificationHub()
+
+	ch, unsubscribe := hub.subscribe("slow")
+	defer unsubscribe()
+
+	for i := 0; i < notificationBuffer+5; i++ {
+		hub.broadcast(NewNotification("notifications/tools/list_changed", nil))
+	}
+
+	if len(ch) != notificationBuffer {
+		t.Errorf("Expected %d queued notifications, got %d", notificationBuffer, len(ch))
+	}
+}
+
+func TestToolMetricName(t *testing.T) {
+	if got := toolMetricName("getWhois"); got != "getWhois" {
+		t.Errorf("Expected known tool name to be kept, got %s", got)
+	}
+	if got := toolMetricName("getWhois\x00random"); got != "unknown" {
+		t.Errorf("Expected unknown tool to be reported as unknown, got %s", got)
+	}
+}
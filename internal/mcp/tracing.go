@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taihen/mcp-ripestat/internal/tracing"
+)
+
+// startRequestSpan starts the root span of a JSON-RPC request. The trace is
+// continued from params._meta.traceparent or, failing that, from the HTTP
+// request's traceparent header.
+func startRequestSpan(ctx context.Context, req *Request) (context.Context, *tracing.Span) {
+	if sc, ok := metaTraceparent(req.Params); ok {
+		ctx = tracing.ContextWithRemoteParent(ctx, sc)
+	} else if r, ok := HTTPRequestFromContext(ctx); ok {
+		if sc, err := tracing.ParseTraceparent(r.Header.Get(tracing.TraceparentHeader)); err == nil {
+			ctx = tracing.ContextWithRemoteParent(ctx, sc)
+		}
+	}
+
+	ctx, span := tracing.Start(ctx, req.Method, tracing.KindServer)
+	span.SetAttributes(
+		tracing.String("rpc.system", "jsonrpc"),
+		tracing.String("rpc.method", req.Method),
+		tracing.String("rpc.jsonrpc.request_id", fmt.Sprint(req.ID)),
+	)
+	if sessionID, ok := SessionIDFromContext(ctx); ok {
+		span.SetAttributes(tracing.String("mcp.session.id", sessionID))
+	}
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		span.SetAttributes(tracing.String("mcp.request.id", requestID))
+	}
+	return ctx, span
+}
+
+// endRequestSpan records the JSON-RPC error, if any, and ends the span.
+func endRequestSpan(span *tracing.Span, result interface{}) {
+	if response, ok := result.(*Response); ok && response.Error != nil {
+		span.SetAttributes(tracing.Int("rpc.jsonrpc.error_code", response.Error.Code))
+		span.SetError(response.Error.Message)
+	}
+	span.End()
+}
+
+// metaTraceparent returns the trace context a client sent in params._meta.
+func metaTraceparent(params interface{}) (tracing.SpanContext, bool) {
+	p, ok := params.(map[string]interface{})
+	if !ok {
+		return tracing.SpanContext{}, false
+	}
+	meta, ok := p["_meta"].(map[string]interface{})
+	if !ok {
+		return tracing.SpanContext{}, false
+	}
+	value, ok := meta[tracing.TraceparentHeader].(string)
+	if !ok {
+		return tracing.SpanContext{}, false
+	}
+
+	sc, err := tracing.ParseTraceparent(value)
+	return sc, err == nil
+}
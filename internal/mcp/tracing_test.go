@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/tracing"
+)
+
+// recordingExporter keeps exported spans in memory.
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (e *recordingExporter) Export(_ context.Context, spans []tracing.SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// useRecordingTracer traces to an in-memory exporter. The returned function
+// flushes the tracer and returns the exported spans.
+func useRecordingTracer(t *testing.T) func() []tracing.SpanData {
+	t.Helper()
+
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+	previous := tracing.SetTracer(tracer)
+	t.Cleanup(func() { tracing.SetTracer(previous) })
+
+	return func() []tracing.SpanData {
+		t.Helper()
+		tracing.SetTracer(previous)
+		if err := tracer.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown failed: %v", err)
+		}
+		return exporter.spans
+	}
+}
+
+func TestHandleRequest_Tracing(t *testing.T) {
+	useStubRIPEstat(t)
+	flush := useRecordingTracer(t)
+
+	server := NewServer("test", "1.0.0", false)
+	server.initialized = true
+
+	httpReq := httptest.NewRequest("POST", "/mcp", nil)
+	httpReq.Header.Set("traceparent", "00-11111111111111111111111111111111-2222222222222222-01")
+	ctx := WithSessionID(WithHTTPRequest(context.Background(), httpReq), "s1")
+
+	// The trace context in _meta wins over the HTTP header.
+	call := NewRequest("tools/call", map[string]interface{}{
+		"name":      "getNetworkInfo",
+		"arguments": map[string]interface{}{"resource": "193.0.0.1"},
+		"_meta":     map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}, 1)
+	if _, err := server.handleRequest(ctx, call); err != nil {
+		t.Fatalf("handleRequest failed: %v", err)
+	}
+	if _, err := server.handleRequest(ctx, NewRequest("unknown/method", nil, 2)); err != nil {
+		t.Fatalf("handleRequest failed: %v", err)
+	}
+
+	spans := flush()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d: %+v", len(spans), spans)
+	}
+	upstream, root, unknown := spans[0], spans[1], spans[2]
+
+	if root.Name != "tools/call" || root.Kind != tracing.KindServer ||
+		root.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || root.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("Unexpected root span %+v", root)
+	}
+	if root.Attributes["mcp.tool.name"] != "getNetworkInfo" || root.Attributes["mcp.tool.status"] != "ok" ||
+		root.Attributes["mcp.session.id"] != "s1" {
+		t.Errorf("Unexpected root attributes %v", root.Attributes)
+	}
+
+	if upstream.Name != "ripestat network-info" || upstream.Kind != tracing.KindClient ||
+		upstream.TraceID != root.TraceID || upstream.ParentSpanID != root.SpanID {
+		t.Errorf("Unexpected upstream span %+v", upstream)
+	}
+	if upstream.Attributes["ripestat.cache"] != "miss" || upstream.Attributes["ripestat.query_id"] != "20250618120000-stub" {
+		t.Errorf("Unexpected upstream attributes %v", upstream.Attributes)
+	}
+	if _, ok := upstream.Attributes["ripestat.limiter_wait_ms"]; !ok {
+		t.Errorf("Expected limiter wait in %v", upstream.Attributes)
+	}
+
+	if unknown.TraceID.String() != "11111111111111111111111111111111" || !unknown.Error ||
+		unknown.Attributes["rpc.jsonrpc.error_code"] != int64(MethodNotFound) {
+		t.Errorf("Expected failed span continuing the HTTP trace, got %+v", unknown)
+	}
+}
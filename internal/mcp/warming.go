@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+)
+
+// IsTool reports whether name is a tool in the tools list.
+func IsTool(name string) bool {
+	return knownTools()[name]
+}
+
+// RefreshTool runs a tool call with fresh RIPEstat data, renewing the cached
+// responses it uses, and returns the upstream calls it made. It serves cache
+// warming: the call is not audited, counted against quotas or remembered for
+// completion. A tool error result is returned as an error.
+func (s *Server) RefreshTool(ctx context.Context, name string, args map[string]interface{}) ([]client.UpstreamCall, error) {
+	recorder := &client.CallRecorder{}
+	ctx = client.WithCallRecorder(client.WithCacheBypass(ctx), recorder)
+
+	result, err := s.callTool(ctx, name, args)
+	if err != nil {
+		return recorder.Calls(), err
+	}
+	if result != nil && result.IsError {
+		if len(result.Content) > 0 {
+			return recorder.Calls(), errors.New(result.Content[0].Text)
+		}
+		return recorder.Calls(), fmt.Errorf("tool %s failed", name)
+	}
+	return recorder.Calls(), nil
+}
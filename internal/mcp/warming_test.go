@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	ripeconfig "github.com/taihen/mcp-ripestat/internal/ripestat/config"
+)
+
+func TestRefreshTool(t *testing.T) {
+	var requests atomic.Int32
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"status": "ok", "query_id": "warm-%d", "data": {"holder": "RIPE NCC"}}`, requests.Add(1))
+	}))
+	defer stub.Close()
+
+	cfg := ripeconfig.DefaultConfig()
+	cfg.BaseURL = stub.URL
+	cfg.RetryCount = 0
+	ripeconfig.SetDefault(cfg)
+	defer ripeconfig.SetDefault(nil)
+
+	server := NewServer("test-server", "1.0.0", false)
+	args := map[string]interface{}{"resource": "AS64496"}
+
+	for i := 1; i <= 2; i++ {
+		calls, err := server.RefreshTool(context.Background(), "getASOverview", args)
+		if err != nil {
+			t.Fatalf("RefreshTool failed: %v", err)
+		}
+		want := client.UpstreamCall{Endpoint: "as-overview", Cache: client.CacheMiss, QueryID: fmt.Sprintf("warm-%d", i)}
+		if len(calls) != 1 || calls[0].Endpoint != want.Endpoint || calls[0].Cache != want.Cache || calls[0].QueryID != want.QueryID {
+			t.Errorf("Expected a fresh upstream call %+v, got %+v", want, calls)
+		}
+	}
+
+	// Tool calls afterwards are served from the refreshed cache.
+	result, err := server.executeToolCall(context.Background(), &CallToolParams{Name: "getASOverview", Arguments: args})
+	if err != nil || result.IsError || requests.Load() != 2 {
+		t.Errorf("Expected a cached result, got %+v (%v) after %d requests", result, err, requests.Load())
+	}
+
+	if _, err := server.RefreshTool(context.Background(), "getASOverview", map[string]interface{}{}); err == nil {
+		t.Error("Expected a tool error result to be returned as an error")
+	}
+	if _, err := server.RefreshTool(context.Background(), "getNothing", nil); err == nil {
+		t.Error("Expected an unknown tool to fail")
+	}
+
+	if !IsTool("getASOverview") || IsTool("getNothing") {
+		t.Error("Expected IsTool to recognise tools in the tools list")
+	}
+}
@@ -0,0 +1,175 @@
+// Package origin implements the Origin header policy for the HTTP transport.
+//
+// Browsers send an Origin header with cross-origin requests. Validating it
+// protects local servers against DNS rebinding, where a hostile page makes
+// the browser talk to the server under an attacker-controlled name.
+package origin
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Policy modes.
+const (
+	// ModeAllowlist accepts origins matching one of the configured patterns.
+	ModeAllowlist = "allowlist"
+
+	// ModeDenyAll rejects every request that carries an Origin header, so
+	// only non-browser clients can connect.
+	ModeDenyAll = "deny-all"
+)
+
+// Policy decides which Origin header values are accepted.
+type Policy struct {
+	denyAll bool
+	rules   []rule
+}
+
+// rule is a parsed allowlist pattern.
+type rule struct {
+	scheme   string
+	host     string // Lowercase host; for wildcards, the parent domain.
+	wildcard bool   // Matches subdomains of host, not host itself.
+	port     string // Empty matches any port.
+}
+
+// NewPolicy compiles an origin policy. Patterns have the form
+// scheme://host[:port]; a host of "*.example.com" matches any subdomain of
+// example.com, and a pattern without a port matches any port.
+func NewPolicy(mode string, patterns []string) (*Policy, error) {
+	switch mode {
+	case "", ModeAllowlist:
+	case ModeDenyAll:
+		return &Policy{denyAll: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown origin mode %q", mode)
+	}
+
+	policy := &Policy{}
+	for _, pattern := range patterns {
+		r, err := parseRule(pattern)
+		if err != nil {
+			return nil, err
+		}
+		policy.rules = append(policy.rules, r)
+	}
+
+	return policy, nil
+}
+
+// Allows reports whether the Origin header value is accepted. Malformed
+// origins, including "null", are never accepted.
+func (p *Policy) Allows(value string) bool {
+	if p == nil || p.denyAll {
+		return false
+	}
+
+	scheme, host, port, ok := parseOrigin(value)
+	if !ok {
+		return false
+	}
+
+	for _, r := range p.rules {
+		if r.matches(scheme, host, port) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r rule) matches(scheme, host, port string) bool {
+	if scheme != r.scheme {
+		return false
+	}
+	if r.port != "" && port != r.port {
+		return false
+	}
+	if r.wildcard {
+		return strings.HasSuffix(host, "."+r.host)
+	}
+	return host == r.host
+}
+
+// parseRule parses an allowlist pattern.
+func parseRule(pattern string) (rule, error) {
+	invalid := func(reason string) (rule, error) {
+		return rule{}, fmt.Errorf("invalid origin pattern %q: %s", pattern, reason)
+	}
+
+	wildcard := false
+	value := pattern
+	if scheme, rest, ok := strings.Cut(pattern, "://*."); ok {
+		wildcard = true
+		value = scheme + "://" + rest
+	}
+
+	scheme, host, port, err := splitOrigin(value)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	if strings.Contains(host, "*") {
+		return invalid("wildcards are only allowed as the first label")
+	}
+	if wildcard && (net.ParseIP(host) != nil || !strings.Contains(host, ".")) {
+		return invalid("wildcards need a parent domain with at least two labels")
+	}
+
+	return rule{scheme: scheme, host: host, wildcard: wildcard, port: port}, nil
+}
+
+// parseOrigin parses an Origin header value into its scheme, host and port.
+// The port is normalized to the scheme's default when omitted.
+func parseOrigin(value string) (string, string, string, bool) {
+	scheme, host, port, err := splitOrigin(value)
+	if err != nil {
+		return "", "", "", false
+	}
+	if port == "" {
+		port = defaultPort(scheme)
+	}
+	return scheme, host, port, true
+}
+
+// splitOrigin validates a serialized origin and splits it into lowercase
+// scheme, host and port. Paths, queries, fragments and user info are
+// rejected; they never appear in a genuine Origin header.
+func splitOrigin(value string) (string, string, string, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", "", "", fmt.Errorf("not a URL")
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", "", "", fmt.Errorf("scheme must be http or https")
+	}
+	if u.User != nil || u.Opaque != "" || u.RawQuery != "" || u.Fragment != "" || strings.Contains(value, "#") {
+		return "", "", "", fmt.Errorf("unexpected URL components")
+	}
+	if u.Path != "" && u.Path != "/" {
+		return "", "", "", fmt.Errorf("unexpected path")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", "", "", fmt.Errorf("missing host")
+	}
+
+	port := u.Port()
+	if strings.HasSuffix(u.Host, ":") {
+		return "", "", "", fmt.Errorf("empty port")
+	}
+
+	return scheme, host, port, nil
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
@@ -0,0 +1,142 @@
+package origin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicy_Allows(t *testing.T) {
+	policy, err := NewPolicy(ModeAllowlist, []string{
+		"http://localhost",
+		"http://127.0.0.1",
+		"http://[::1]",
+		"https://claude.ai",
+		"https://console.example.net:8443",
+		"https://*.example.com",
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy failed: %v", err)
+	}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		// Exact matches and any port for patterns without one.
+		{"http://localhost", true},
+		{"http://localhost:3000", true},
+		{"http://127.0.0.1:8080", true},
+		{"http://[::1]:3000", true},
+		{"https://claude.ai", true},
+		{"https://claude.ai/", true},
+		{"HTTPS://CLAUDE.AI", true},
+		{"https://claude.ai.", true},
+
+		// Explicit ports must match, with default ports normalized.
+		{"https://console.example.net:8443", true},
+		{"https://console.example.net", false},
+		{"https://console.example.net:443", false},
+
+		// Wildcards match subdomains only.
+		{"https://app.example.com", true},
+		{"https://a.b.example.com:9000", true},
+		{"https://example.com", false},
+		{"https://evilexample.com", false},
+		{"http://app.example.com", false},
+
+		// DNS rebinding and prefix tricks.
+		{"http://localhost.evil.com", false},
+		{"http://localhost.evil.com:3000", false},
+		{"http://127.0.0.1.nip.io", false},
+		{"https://claude.ai.evil.com", false},
+		{"https://claude.aievil.com", false},
+		{"http://localhost@evil.com", false},
+		{"http://evil.com#localhost", false},
+		{"http://evil.com/?http://localhost", false},
+		{"http://evil.com/http://localhost", false},
+
+		// Wrong scheme and malformed values.
+		{"https://localhost", false},
+		{"ws://localhost", false},
+		{"file://localhost", false},
+		{"null", false},
+		{"", false},
+		{"localhost", false},
+		{"http://", false},
+		{"http://localhost:", false},
+		{"http://local host", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := policy.Allows(tt.origin); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_DenyAll(t *testing.T) {
+	policy, err := NewPolicy(ModeDenyAll, []string{"http://localhost"})
+	if err != nil {
+		t.Fatalf("NewPolicy failed: %v", err)
+	}
+
+	if policy.Allows("http://localhost") {
+		t.Error("Expected deny-all policy to reject every origin")
+	}
+}
+
+func TestPolicy_EmptyAllowlist(t *testing.T) {
+	policy, err := NewPolicy("", nil)
+	if err != nil {
+		t.Fatalf("NewPolicy failed: %v", err)
+	}
+
+	if policy.Allows("http://localhost") {
+		t.Error("Expected empty allowlist to reject every origin")
+	}
+}
+
+func TestNewPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		pattern string
+		want    string
+	}{
+		{name: "unknown mode", mode: "open", want: "unknown origin mode"},
+		{name: "missing scheme", pattern: "localhost", want: "scheme"},
+		{name: "unsupported scheme", pattern: "ftp://localhost", want: "scheme"},
+		{name: "path", pattern: "https://example.com/app", want: "path"},
+		{name: "query", pattern: "https://example.com?a=b", want: "URL components"},
+		{name: "user info", pattern: "https://user@example.com", want: "URL components"},
+		{name: "missing host", pattern: "https://", want: "missing host"},
+		{name: "inner wildcard", pattern: "https://app.*.example.com", want: "first label"},
+		{name: "bare wildcard", pattern: "https://*", want: "wildcards"},
+		{name: "wildcard tld", pattern: "https://*.com", want: "two labels"},
+		{name: "wildcard ip", pattern: "http://*.127.0.0.1", want: "two labels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := tt.mode
+			if mode == "" {
+				mode = ModeAllowlist
+			}
+
+			var patterns []string
+			if tt.pattern != "" {
+				patterns = []string{tt.pattern}
+			}
+
+			_, err := NewPolicy(mode, patterns)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
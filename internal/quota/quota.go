@@ -0,0 +1,357 @@
+// Package quota enforces per-caller rate limits and daily quotas on tool
+// calls, so that a single client cannot exhaust the shared RIPEstat budget.
+//
+// Callers are identified by authenticated principal when available, and by
+// session or client address otherwise. Rates use a token bucket refilled every
+// minute; daily quotas reset at midnight UTC.
+package quota
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Limit names reported in LimitError.
+const (
+	LimitRate  = "rate"
+	LimitDaily = "daily_quota"
+)
+
+// Caller kinds.
+const (
+	KindPrincipal = "principal"
+	KindSession   = "session"
+	KindClient    = "client"
+)
+
+// Limits are the limits applied to one caller. Zero values mean unlimited.
+type Limits struct {
+	// RatePerMinute is the sustained number of calls allowed per minute.
+	RatePerMinute int `json:"rate_per_minute"`
+
+	// Burst is the number of calls allowed at once; zero uses RatePerMinute.
+	Burst int `json:"burst"`
+
+	// Daily is the number of calls allowed per UTC day.
+	Daily int `json:"daily_quota"`
+}
+
+func (l Limits) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.RatePerMinute
+}
+
+// Policy selects the limits for a caller: principal-specific limits first,
+// then the first of the principal's groups with limits, then Default.
+type Policy struct {
+	Enabled    bool
+	Default    Limits
+	Groups     map[string]Limits
+	Principals map[string]Limits
+}
+
+// limitsFor resolves the limits for caller.
+func (p Policy) limitsFor(c Caller) Limits {
+	if c.Kind == KindPrincipal {
+		if limits, ok := p.Principals[c.ID]; ok {
+			return limits
+		}
+		for _, group := range c.Groups {
+			if limits, ok := p.Groups[group]; ok {
+				return limits
+			}
+		}
+	}
+	return p.Default
+}
+
+// Caller identifies who is making a call.
+type Caller struct {
+	Kind   string
+	ID     string
+	Groups []string
+}
+
+func (c Caller) key() string {
+	return c.Kind + ":" + c.ID
+}
+
+// LimitError reports a call rejected by a limit.
+type LimitError struct {
+	Limit   string    `json:"limit"`
+	Caller  string    `json:"caller"`
+	Allowed int       `json:"allowed"`
+	Window  string    `json:"window"`
+	ResetAt time.Time `json:"reset_at"`
+
+	// RetryAfter is the number of seconds until the call can succeed.
+	RetryAfter int `json:"retry_after_seconds"`
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d calls per %s exceeded for %s; resets at %s",
+		e.Limit, e.Allowed, e.Window, e.Caller, e.ResetAt.Format(time.RFC3339))
+}
+
+// Usage is the recorded activity of one caller.
+type Usage struct {
+	Caller        string `json:"caller"`
+	Kind          string `json:"kind"`
+	Calls         int64  `json:"calls"`
+	CallsToday    int    `json:"calls_today"`
+	DailyQuota    int    `json:"daily_quota,omitempty"`
+	RatePerMinute int    `json:"rate_per_minute,omitempty"`
+	RateLimited   int64  `json:"rate_limited"`
+	QuotaExceeded int64  `json:"quota_exceeded"`
+}
+
+// idleTTL is how long an inactive caller's state is kept. It exceeds one
+// day so that daily quotas cannot be reset by going quiet.
+const idleTTL = 25 * time.Hour
+
+// pruneEvery is how many calls pass between sweeps of idle callers.
+const pruneEvery = 1024
+
+// defaultMaxCallers bounds the number of callers tracked at once. When it is
+// reached, idle callers are pruned and then the least recently seen caller
+// is forgotten.
+const defaultMaxCallers = 100000
+
+// Manager tracks usage and enforces a Policy. It is safe for concurrent use.
+type Manager struct {
+	mu      sync.Mutex
+	policy  Policy
+	callers map[string]*callerState
+	calls   int
+	now     func() time.Time
+
+	maxCallers int
+}
+
+type callerState struct {
+	kind          string
+	limits        Limits // Limits applied to the latest call.
+	tokens        float64
+	refilled      time.Time
+	day           string
+	today         int
+	calls         int64
+	rateLimited   int64
+	quotaExceeded int64
+	lastSeen      time.Time
+}
+
+// NewManager returns a manager enforcing policy.
+func NewManager(policy Policy) *Manager {
+	return &Manager{
+		policy:  policy,
+		callers: make(map[string]*callerState),
+		now:     time.Now,
+
+		maxCallers: defaultMaxCallers,
+	}
+}
+
+// SetPolicy replaces the policy. Recorded usage is kept.
+func (m *Manager) SetPolicy(policy Policy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policy = policy
+}
+
+// Enabled reports whether limits are enforced.
+func (m *Manager) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.policy.Enabled
+}
+
+// Allow records a call by caller, returning a *LimitError when a limit
+// rejects it. Rejected calls do not count against the daily quota.
+func (m *Manager) Allow(c Caller) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.policy.Enabled {
+		return nil
+	}
+
+	now := m.now().UTC()
+	m.calls++
+	if m.calls%pruneEvery == 0 {
+		m.pruneLocked(now)
+	}
+
+	limits := m.policy.limitsFor(c)
+	state := m.stateLocked(c, limits, now)
+	state.lastSeen = now
+	state.limits = limits
+
+	if limits.Daily > 0 && state.today >= limits.Daily {
+		state.quotaExceeded++
+		resetAt := startOfDay(now).Add(24 * time.Hour)
+		return &LimitError{
+			Limit:      LimitDaily,
+			Caller:     c.key(),
+			Allowed:    limits.Daily,
+			Window:     "day",
+			ResetAt:    resetAt,
+			RetryAfter: retryAfter(now, resetAt),
+		}
+	}
+
+	if limits.RatePerMinute > 0 {
+		perSecond := float64(limits.RatePerMinute) / 60
+		state.tokens += now.Sub(state.refilled).Seconds() * perSecond
+		if burst := float64(limits.burst()); state.tokens > burst {
+			state.tokens = burst
+		}
+		state.refilled = now
+
+		if state.tokens < 1 {
+			state.rateLimited++
+			wait := time.Duration((1 - state.tokens) / perSecond * float64(time.Second))
+			resetAt := now.Add(wait)
+			return &LimitError{
+				Limit:      LimitRate,
+				Caller:     c.key(),
+				Allowed:    limits.RatePerMinute,
+				Window:     "minute",
+				ResetAt:    resetAt,
+				RetryAfter: retryAfter(now, resetAt),
+			}
+		}
+		state.tokens--
+	}
+
+	state.today++
+	state.calls++
+	return nil
+}
+
+// stateLocked returns the state for caller, creating it with a full bucket
+// and rolling the daily counter over at midnight UTC.
+func (m *Manager) stateLocked(c Caller, limits Limits, now time.Time) *callerState {
+	key := c.key()
+	state, ok := m.callers[key]
+	if !ok {
+		if len(m.callers) >= m.maxCallers {
+			m.evictLocked(now)
+		}
+		state = &callerState{
+			kind:     c.Kind,
+			tokens:   float64(limits.burst()),
+			refilled: now,
+		}
+		m.callers[key] = state
+	}
+
+	if day := now.Format(time.DateOnly); state.day != day {
+		state.day = day
+		state.today = 0
+	}
+
+	return state
+}
+
+// pruneLocked forgets callers idle for longer than idleTTL.
+func (m *Manager) pruneLocked(now time.Time) {
+	for key, state := range m.callers {
+		if now.Sub(state.lastSeen) > idleTTL {
+			delete(m.callers, key)
+		}
+	}
+}
+
+// evictLocked makes room for a new caller, pruning idle callers and, if
+// none were idle, forgetting the least recently seen one.
+func (m *Manager) evictLocked(now time.Time) {
+	m.pruneLocked(now)
+	if len(m.callers) < m.maxCallers {
+		return
+	}
+
+	var oldestKey string
+	var oldest time.Time
+	for key, state := range m.callers {
+		if oldestKey == "" || state.lastSeen.Before(oldest) {
+			oldestKey, oldest = key, state.lastSeen
+		}
+	}
+	delete(m.callers, oldestKey)
+}
+
+// Usage returns the recorded usage of every known caller, sorted by caller.
+func (m *Manager) Usage() []Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	today := m.now().UTC().Format(time.DateOnly)
+	usage := make([]Usage, 0, len(m.callers))
+	for key, state := range m.callers {
+		callsToday := state.today
+		if state.day != today {
+			callsToday = 0
+		}
+
+		usage = append(usage, Usage{
+			Caller:        key,
+			Kind:          state.kind,
+			Calls:         state.calls,
+			CallsToday:    callsToday,
+			DailyQuota:    state.limits.Daily,
+			RatePerMinute: state.limits.RatePerMinute,
+			RateLimited:   state.rateLimited,
+			QuotaExceeded: state.quotaExceeded,
+		})
+	}
+
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Caller < usage[j].Caller })
+	return usage
+}
+
+// Aggregate keeps the usage of principals and sums that of session and
+// client callers into one entry per kind, whose Caller is the kind. This keeps
+// session IDs and client addresses out of reports and bounds their size.
+// Aggregated entries carry no limits, as their callers may have different ones.
+func Aggregate(usage []Usage) []Usage {
+	aggregated := make([]Usage, 0, len(usage))
+	byKind := make(map[string]int)
+	for _, u := range usage {
+		if u.Kind == KindPrincipal {
+			aggregated = append(aggregated, u)
+			continue
+		}
+		i, ok := byKind[u.Kind]
+		if !ok {
+			i = len(aggregated)
+			byKind[u.Kind] = i
+			aggregated = append(aggregated, Usage{Caller: u.Kind, Kind: u.Kind})
+		}
+		aggregated[i].Calls += u.Calls
+		aggregated[i].CallsToday += u.CallsToday
+		aggregated[i].RateLimited += u.RateLimited
+		aggregated[i].QuotaExceeded += u.QuotaExceeded
+	}
+	sort.Slice(aggregated, func(i, j int) bool { return aggregated[i].Caller < aggregated[j].Caller })
+	return aggregated
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// retryAfter rounds the wait until resetAt up to whole seconds.
+func retryAfter(now, resetAt time.Time) int {
+	wait := resetAt.Sub(now)
+	seconds := int(wait / time.Second)
+	if wait%time.Second != 0 {
+		seconds++
+	}
+	return seconds
+}
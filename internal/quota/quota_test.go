@@ -0,0 +1,220 @@
+package quota
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestManager(policy Policy) (*Manager, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 6, 18, 23, 58, 0, 0, time.UTC)}
+	m := NewManager(policy)
+	m.now = clock.Now
+	return m, clock
+}
+
+func TestManager_Disabled(t *testing.T) {
+	m, _ := newTestManager(Policy{Default: Limits{RatePerMinute: 1, Daily: 1}})
+
+	for i := 0; i < 5; i++ {
+		if err := m.Allow(Caller{Kind: KindSession, ID: "s1"}); err != nil {
+			t.Fatalf("Expected no limits when disabled, got %v", err)
+		}
+	}
+	if len(m.Usage()) != 0 {
+		t.Error("Expected no usage to be recorded when disabled")
+	}
+}
+
+func TestManager_RateLimit(t *testing.T) {
+	m, clock := newTestManager(Policy{Enabled: true, Default: Limits{RatePerMinute: 60, Burst: 2}})
+	caller := Caller{Kind: KindSession, ID: "s1"}
+
+	for i := 0; i < 2; i++ {
+		if err := m.Allow(caller); err != nil {
+			t.Fatalf("Call %d within burst rejected: %v", i, err)
+		}
+	}
+
+	err := m.Allow(caller)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Expected LimitError, got %v", err)
+	}
+	if limitErr.Limit != LimitRate || limitErr.Allowed != 60 || limitErr.Window != "minute" {
+		t.Errorf("Unexpected limit error %+v", limitErr)
+	}
+	if limitErr.RetryAfter != 1 || !limitErr.ResetAt.Equal(clock.now.Add(time.Second)) {
+		t.Errorf("Expected reset in one second, got %+v", limitErr)
+	}
+
+	// Other callers have their own bucket.
+	if err := m.Allow(Caller{Kind: KindSession, ID: "s2"}); err != nil {
+		t.Errorf("Expected separate bucket for another session, got %v", err)
+	}
+
+	clock.Advance(time.Second)
+	if err := m.Allow(caller); err != nil {
+		t.Errorf("Expected refilled token after one second, got %v", err)
+	}
+}
+
+func TestManager_DailyQuota(t *testing.T) {
+	m, clock := newTestManager(Policy{Enabled: true, Default: Limits{Daily: 2}})
+	caller := Caller{Kind: KindClient, ID: "192.0.2.1"}
+
+	for i := 0; i < 2; i++ {
+		if err := m.Allow(caller); err != nil {
+			t.Fatalf("Call %d within quota rejected: %v", i, err)
+		}
+	}
+
+	var limitErr *LimitError
+	if err := m.Allow(caller); !errors.As(err, &limitErr) || limitErr.Limit != LimitDaily {
+		t.Fatalf("Expected daily quota error, got %v", err)
+	}
+	midnight := time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC)
+	if !limitErr.ResetAt.Equal(midnight) || limitErr.RetryAfter != 120 {
+		t.Errorf("Expected reset at midnight UTC, got %+v", limitErr)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if err := m.Allow(caller); err != nil {
+		t.Errorf("Expected quota to reset at midnight, got %v", err)
+	}
+}
+
+func TestManager_PrincipalAndGroupLimits(t *testing.T) {
+	m, _ := newTestManager(Policy{
+		Enabled:    true,
+		Default:    Limits{Daily: 1},
+		Groups:     map[string]Limits{"research": {Daily: 3}},
+		Principals: map[string]Limits{"ci": {Daily: 2}},
+	})
+
+	allowed := func(c Caller) int {
+		n := 0
+		for i := 0; i < 10 && m.Allow(c) == nil; i++ {
+			n++
+		}
+		return n
+	}
+
+	tests := []struct {
+		name   string
+		caller Caller
+		want   int
+	}{
+		{name: "default", caller: Caller{Kind: KindPrincipal, ID: "alice"}, want: 1},
+		{name: "group", caller: Caller{Kind: KindPrincipal, ID: "bob", Groups: []string{"staff", "research"}}, want: 3},
+		{name: "principal wins over group", caller: Caller{Kind: KindPrincipal, ID: "ci", Groups: []string{"research"}}, want: 2},
+		{name: "sessions ignore principal limits", caller: Caller{Kind: KindSession, ID: "ci"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowed(tt.caller); got != tt.want {
+				t.Errorf("Expected %d allowed calls, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestManager_Usage(t *testing.T) {
+	m, clock := newTestManager(Policy{Enabled: true, Default: Limits{RatePerMinute: 60, Burst: 1, Daily: 100}})
+
+	alice := Caller{Kind: KindPrincipal, ID: "alice"}
+	_ = m.Allow(alice)
+	_ = m.Allow(alice) // Rate limited.
+	_ = m.Allow(Caller{Kind: KindSession, ID: "s1"})
+
+	usage := m.Usage()
+	if len(usage) != 2 {
+		t.Fatalf("Expected 2 callers, got %d", len(usage))
+	}
+
+	got := usage[0]
+	if got.Caller != "principal:alice" || got.Kind != KindPrincipal || got.Calls != 1 || got.CallsToday != 1 ||
+		got.RateLimited != 1 || got.DailyQuota != 100 || got.RatePerMinute != 60 {
+		t.Errorf("Unexpected usage %+v", got)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if usage := m.Usage(); usage[0].CallsToday != 0 || usage[0].Calls != 1 {
+		t.Errorf("Expected calls today to reset on a new day, got %+v", usage[0])
+	}
+}
+
+func TestManager_PrunesIdleCallers(t *testing.T) {
+	m, clock := newTestManager(Policy{Enabled: true})
+	_ = m.Allow(Caller{Kind: KindSession, ID: "old"})
+
+	clock.Advance(idleTTL + time.Minute)
+	for i := 0; i < pruneEvery; i++ {
+		_ = m.Allow(Caller{Kind: KindSession, ID: "new"})
+	}
+
+	for _, u := range m.Usage() {
+		if u.Caller == "session:old" {
+			t.Error("Expected idle caller to be pruned")
+		}
+	}
+}
+
+func TestManager_EvictsLeastRecentlySeenCaller(t *testing.T) {
+	m, clock := newTestManager(Policy{Enabled: true})
+	m.maxCallers = 2
+
+	_ = m.Allow(Caller{Kind: KindSession, ID: "old"})
+	clock.Advance(time.Minute)
+	_ = m.Allow(Caller{Kind: KindPrincipal, ID: "alice"})
+	clock.Advance(time.Minute)
+	_ = m.Allow(Caller{Kind: KindSession, ID: "new"})
+
+	usage := m.Usage()
+	if len(usage) != 2 || usage[0].Caller != "principal:alice" || usage[1].Caller != "session:new" {
+		t.Errorf("Expected the least recently seen caller to be evicted, got %+v", usage)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	usage := []Usage{
+		{Caller: "client:192.0.2.1", Kind: KindClient, Calls: 1, CallsToday: 1, DailyQuota: 10},
+		{Caller: "principal:alice", Kind: KindPrincipal, Calls: 4, CallsToday: 2, DailyQuota: 100},
+		{Caller: "session:s1", Kind: KindSession, Calls: 3, CallsToday: 3, QuotaExceeded: 1, DailyQuota: 10},
+		{Caller: "session:s2", Kind: KindSession, Calls: 2, CallsToday: 1, RateLimited: 2, DailyQuota: 10},
+	}
+
+	got := Aggregate(usage)
+	want := []Usage{
+		{Caller: KindClient, Kind: KindClient, Calls: 1, CallsToday: 1},
+		{Caller: "principal:alice", Kind: KindPrincipal, Calls: 4, CallsToday: 2, DailyQuota: 100},
+		{Caller: KindSession, Kind: KindSession, Calls: 5, CallsToday: 4, RateLimited: 2, QuotaExceeded: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestManager_SetPolicyKeepsUsage(t *testing.T) {
+	m, _ := newTestManager(Policy{Enabled: true, Default: Limits{Daily: 1}})
+	caller := Caller{Kind: KindSession, ID: "s1"}
+	_ = m.Allow(caller)
+
+	m.SetPolicy(Policy{Enabled: true, Default: Limits{Daily: 1}})
+	if err := m.Allow(caller); err == nil {
+		t.Error("Expected usage to survive a policy change")
+	}
+
+	m.SetPolicy(Policy{Enabled: true, Default: Limits{Daily: 2}})
+	if err := m.Allow(caller); err != nil {
+		t.Errorf("Expected raised quota to apply, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package resolve looks up the IP addresses of hostnames passed to tools that
+// only accept addresses, through the system resolver or a configured DNS
+// server.
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"sort"
+	"time"
+
+	ripestaterrors "github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+)
+
+const (
+	// DefaultTimeout bounds a lookup, A and AAAA queries together.
+	DefaultTimeout = 5 * time.Second
+
+	// DefaultMaxAddresses is the number of addresses a lookup returns at most.
+	DefaultMaxAddresses = 4
+)
+
+var (
+	// ErrNoAddresses is returned for names without A or AAAA records.
+	ErrNoAddresses = ripestaterrors.NewError("hostname has no addresses", http.StatusNotFound)
+
+	// ErrLookupFailed is returned when the resolver cannot answer.
+	ErrLookupFailed = ripestaterrors.NewError("hostname lookup failed", http.StatusServiceUnavailable)
+
+	// ErrLookupTimeout is returned when the resolver does not answer in time.
+	ErrLookupTimeout = ripestaterrors.NewError("hostname lookup timed out", http.StatusGatewayTimeout)
+)
+
+// Options configures a Resolver.
+type Options struct {
+	// Server is the "host:port" address of the DNS server to query. Empty
+	// uses the system resolver.
+	Server string
+	// Timeout bounds a lookup; zero uses DefaultTimeout.
+	Timeout time.Duration
+	// MaxAddresses caps the addresses returned; zero uses DefaultMaxAddresses.
+	MaxAddresses int
+}
+
+// Resolver looks up the A and AAAA records of hostnames.
+type Resolver struct {
+	server       string
+	resolver     *net.Resolver
+	timeout      time.Duration
+	maxAddresses int
+}
+
+// New creates a Resolver. It fails when the server address is not a
+// "host:port" pair.
+func New(opts Options) (*Resolver, error) {
+	r := &Resolver{
+		server:       opts.Server,
+		resolver:     net.DefaultResolver,
+		timeout:      opts.Timeout,
+		maxAddresses: opts.MaxAddresses,
+	}
+	if r.timeout <= 0 {
+		r.timeout = DefaultTimeout
+	}
+	if r.maxAddresses <= 0 {
+		r.maxAddresses = DefaultMaxAddresses
+	}
+
+	if opts.Server != "" {
+		if err := ValidateServer(opts.Server); err != nil {
+			return nil, err
+		}
+		var dialer net.Dialer
+		r.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, opts.Server)
+			},
+		}
+	}
+	return r, nil
+}
+
+// ValidateServer checks that server is a "host:port" DNS server address.
+func ValidateServer(server string) error {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		return fmt.Errorf("invalid DNS server address %q: %w", server, err)
+	}
+	if host == "" || port == "" {
+		return fmt.Errorf("invalid DNS server address %q: host and port are required", server)
+	}
+	return nil
+}
+
+// Server returns the configured DNS server address, or "" for the system
+// resolver.
+func (r *Resolver) Server() string {
+	return r.server
+}
+
+// LookupAddrs returns the addresses of host, IPv4 before IPv6, each family
+// in the order the resolver returned them, up to the configured maximum.
+// Errors are classified like RIPEstat errors: a name without addresses is not
+// found, a resolver that does not answer is unavailable or timed out.
+func (r *Resolver) LookupAddrs(ctx context.Context, host string) ([]netip.Addr, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// The name is fully qualified, so search domains must not be tried.
+	addrs, err := r.resolver.LookupNetIP(ctx, "ip", host+".")
+	if err != nil {
+		var dnsErr *net.DNSError
+		switch {
+		case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+			return nil, ErrNoAddresses.WithError(fmt.Errorf("no addresses for %s", host))
+		case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &dnsErr) && dnsErr.IsTimeout):
+			return nil, ErrLookupTimeout.WithError(fmt.Errorf("lookup %s: %w", host, err))
+		default:
+			return nil, ErrLookupFailed.WithError(fmt.Errorf("lookup %s: %w", host, err))
+		}
+	}
+
+	seen := make(map[netip.Addr]bool, len(addrs))
+	unique := make([]netip.Addr, 0, len(addrs))
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if !seen[addr] {
+			seen[addr] = true
+			unique = append(unique, addr)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, ErrNoAddresses.WithError(fmt.Errorf("no addresses for %s", host))
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return unique[i].Is4() && !unique[j].Is4()
+	})
+	if len(unique) > r.maxAddresses {
+		unique = unique[:r.maxAddresses]
+	}
+	return unique, nil
+}
@@ -0,0 +1,197 @@
+package resolve
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	ripestaterrors "github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+)
+
+const (
+	typeA    = 1
+	typeAAAA = 28
+)
+
+// startStubDNS serves the given records over UDP on a local port and returns
+// its address. Names without records get NXDOMAIN; queries are not answered
+// at all for names in silent.
+func startStubDNS(t *testing.T, records map[string][]string, silent ...string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			reply, ok := stubReply(buf[:n], records, silent)
+			if ok {
+				_, _ = conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// stubReply builds the answer to a single-question DNS query.
+func stubReply(query []byte, records map[string][]string, silent []string) ([]byte, bool) {
+	if len(query) < 12 {
+		return nil, false
+	}
+
+	// Read the question name.
+	var labels []string
+	offset := 12
+	for offset < len(query) && query[offset] != 0 {
+		length := int(query[offset])
+		if offset+1+length > len(query) {
+			return nil, false
+		}
+		labels = append(labels, string(query[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+	offset++ // Terminating zero label.
+	if offset+4 > len(query) {
+		return nil, false
+	}
+	qtype := binary.BigEndian.Uint16(query[offset:])
+	question := query[12 : offset+4]
+	name := strings.ToLower(strings.Join(labels, "."))
+	for _, s := range silent {
+		if s == name {
+			return nil, false
+		}
+	}
+
+	var answers [][]byte
+	addrs, known := records[name]
+	for _, a := range addrs {
+		addr := netip.MustParseAddr(a)
+		if (qtype == typeA) != addr.Is4() || (qtype != typeA && qtype != typeAAAA) {
+			continue
+		}
+		rdata := addr.AsSlice()
+		answer := []byte{0xc0, 0x0c} // Pointer to the question name.
+		answer = binary.BigEndian.AppendUint16(answer, qtype)
+		answer = binary.BigEndian.AppendUint16(answer, 1) // IN.
+		answer = binary.BigEndian.AppendUint32(answer, 60)
+		answer = binary.BigEndian.AppendUint16(answer, uint16(len(rdata)))
+		answers = append(answers, append(answer, rdata...))
+	}
+
+	flags := uint16(0x8180) // Response, recursion desired and available.
+	if !known {
+		flags |= 3 // NXDOMAIN.
+	}
+	reply := append([]byte{}, query[:2]...)
+	reply = binary.BigEndian.AppendUint16(reply, flags)
+	reply = binary.BigEndian.AppendUint16(reply, 1)
+	reply = binary.BigEndian.AppendUint16(reply, uint16(len(answers)))
+	reply = append(reply, 0, 0, 0, 0)
+	reply = append(reply, question...)
+	for _, answer := range answers {
+		reply = append(reply, answer...)
+	}
+	return reply, true
+}
+
+func TestResolver_LookupAddrs(t *testing.T) {
+	server := startStubDNS(t, map[string][]string{
+		"www.example.test": {"2001:db8::80", "192.0.2.80", "192.0.2.81"},
+		"v6.example.test":  {"2001:db8::6"},
+		"many.example.test": {
+			"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.5",
+		},
+	})
+
+	resolver, err := New(Options{Server: server, Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if resolver.Server() != server {
+		t.Errorf("Server() = %q, want %q", resolver.Server(), server)
+	}
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"www.example.test", []string{"192.0.2.80", "192.0.2.81", "2001:db8::80"}},
+		{"v6.example.test", []string{"2001:db8::6"}},
+		{"many.example.test", []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4"}},
+	}
+	for _, tt := range tests {
+		addrs, err := resolver.LookupAddrs(context.Background(), tt.host)
+		if err != nil {
+			t.Fatalf("LookupAddrs(%q) failed: %v", tt.host, err)
+		}
+		got := make([]string, len(addrs))
+		for i, addr := range addrs {
+			got[i] = addr.String()
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("LookupAddrs(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestResolver_LookupAddrs_Errors(t *testing.T) {
+	server := startStubDNS(t, map[string][]string{"empty.example.test": {}}, "slow.example.test")
+
+	resolver, err := New(Options{Server: server, Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := []struct {
+		host string
+		kind ripestaterrors.Kind
+		base error
+	}{
+		{"missing.example.test", ripestaterrors.KindNotFound, ErrNoAddresses},
+		{"empty.example.test", ripestaterrors.KindNotFound, ErrNoAddresses},
+		{"slow.example.test", ripestaterrors.KindTimeout, ErrLookupTimeout},
+	}
+	for _, tt := range tests {
+		_, err := resolver.LookupAddrs(context.Background(), tt.host)
+		if err == nil {
+			t.Fatalf("LookupAddrs(%q) succeeded", tt.host)
+		}
+		if !errors.Is(err, tt.base) {
+			t.Errorf("LookupAddrs(%q) error = %v, want %v", tt.host, err, tt.base)
+		}
+		if kind := ripestaterrors.KindOf(err); kind != tt.kind {
+			t.Errorf("LookupAddrs(%q) kind = %s, want %s", tt.host, kind, tt.kind)
+		}
+	}
+}
+
+func TestNew_InvalidServer(t *testing.T) {
+	for _, server := range []string{"192.0.2.53", ":53", "192.0.2.53:"} {
+		if _, err := New(Options{Server: server}); err == nil {
+			t.Errorf("New accepted server %q", server)
+		}
+	}
+
+	resolver, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New without server failed: %v", err)
+	}
+	if resolver.Server() != "" || resolver.timeout != DefaultTimeout || resolver.maxAddresses != DefaultMaxAddresses {
+		t.Errorf("Unexpected defaults: %+v", resolver)
+	}
+}
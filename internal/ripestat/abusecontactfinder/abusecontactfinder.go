@@ -45,7 +45,7 @@ func (c *Client) Get(ctx context.Context, resource string) (*APIResponse, error)
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get abuse contact information: %w", err))
+		return nil, errors.Wrap(err, "failed to get abuse contact information")
 	}
 
 	// Transform the response to the expected API format
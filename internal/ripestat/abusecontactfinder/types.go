@@ -28,3 +28,9 @@ type APIResponse struct {
 	Contacts  []string `json:"contacts"`
 	FetchedAt string   `json:"fetched_at"`
 }
+
+// IsEmpty reports whether RIPEstat found neither abuse contacts nor a
+// registry for the resource.
+func (r *Response) IsEmpty() bool {
+	return len(r.Data.AbuseContacts) == 0 && r.Data.AuthoritativeRIR == ""
+}
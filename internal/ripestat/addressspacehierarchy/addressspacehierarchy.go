@@ -45,7 +45,7 @@ func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get address space hierarchy: %w", err))
+		return nil, errors.Wrap(err, "failed to get address space hierarchy")
 	}
 
 	return &response, nil
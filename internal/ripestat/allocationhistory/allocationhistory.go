@@ -7,6 +7,7 @@ import (
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 )
 
 type Client struct {
@@ -24,17 +25,34 @@ func DefaultClient() *Client {
 	return NewClient(nil)
 }
 
+// TimeLimits are the time window limits of the allocation-history data call, which
+// serves its full history.
+var TimeLimits = timerange.Limits{}
+
+// GetOptions represents optional parameters for the allocation-history API.
+type GetOptions struct {
+	Window timerange.Window // Time range of the history; RIPEstat's default when zero
+}
+
 func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
+	return c.GetWithOptions(ctx, resource, nil)
+}
+
+// GetWithOptions retrieves the allocation history of the resource with optional parameters.
+func (c *Client) GetWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
 	if resource == "" {
 		return nil, errors.ErrInvalidParameter.WithError(fmt.Errorf("resource parameter is required"))
 	}
 
 	params := url.Values{}
 	params.Set("resource", resource)
+	if opts != nil {
+		opts.Window.SetParams(params)
+	}
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get allocation history data: %w", err))
+		return nil, errors.Wrap(err, "failed to get allocation history data")
 	}
 
 	return &response, nil
@@ -43,3 +61,8 @@ func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
 func GetAllocationHistory(ctx context.Context, resource string) (*Response, error) {
 	return DefaultClient().Get(ctx, resource)
 }
+
+// GetAllocationHistoryWithOptions retrieves the allocation history with options using the default client.
+func GetAllocationHistoryWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
+	return DefaultClient().GetWithOptions(ctx, resource, opts)
+}
@@ -6,7 +6,6 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
 )
@@ -402,44 +401,41 @@ func TestClient_Caching(t *testing.T) {
 	}
 }
 
-func TestClient_CacheExpiration(t *testing.T) {
-	// Create a client with a very short cache TTL for testing
-	c := &Client{
-		client: client.DefaultClient(),
-		cache:  make(map[cacheKey]*cacheEntry),
-	}
-
-	// Manually add an expired cache entry
-	key := cacheKey{resource: "AS1205", queryTime: "", lod: 0}
-	expiredEntry := &cacheEntry{
-		response: &APIResponse{
-			Resource:  "1205",
-			QueryTime: "2025-06-23T00:00:00",
-			Neighbours: []Neighbour{
-				{ASN: 1853, Type: "left"},
-			},
-			FetchedAt: "2025-06-23T21:24:00.704269",
-		},
-		timestamp: time.Now().Add(-20 * time.Minute), // Expired (older than 15 minutes)
-	}
+func TestClient_SharedCachePurge(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		callCount++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"resource": "1205", "neighbours": [{"asn": 1853, "type": "left"}]}}`))
+	}))
+	defer server.Close()
 
-	c.setCached(key, expiredEntry.response)
-	// Manually set the timestamp to simulate expiration
-	c.cache[key].timestamp = expiredEntry.timestamp
+	rc := client.New(server.URL, server.Client())
+	c := NewClient(rc)
+	ctx := context.Background()
 
-	// getCached should return nil for expired entry
-	cached := c.getCached(key)
-	if cached != nil {
-		t.Errorf("expected nil for expired cache entry, got %v", cached)
+	for range 2 {
+		result, err := c.Get(ctx, "AS1205", 0, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Neighbours) != 1 || result.Neighbours[0].ASN != 1853 {
+			t.Errorf("unexpected neighbours %+v", result.Neighbours)
+		}
+	}
+	if callCount != 1 {
+		t.Errorf("expected the transformed response to be cached, got %d server calls", callCount)
 	}
 
-	// The expired entry should be removed from cache
-	c.mutex.RLock()
-	_, exists := c.cache[key]
-	c.mutex.RUnlock()
-
-	if exists {
-		t.Errorf("expired cache entry should be removed")
+	// Responses are kept in the client's cache, so purges reach them.
+	if purged := rc.Cache.Purge("asn-neighbours", "as1205"); purged != 1 {
+		t.Errorf("expected 1 purged entry, got %d", purged)
+	}
+	if _, err := c.Get(ctx, "AS1205", 0, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if callCount != 2 {
+		t.Errorf("expected a server call after the purge, got %d", callCount)
 	}
 }
 
@@ -541,9 +537,6 @@ func TestNewClient(t *testing.T) {
 		if c.client != existingClient {
 			t.Errorf("NewClient() did not use provided client")
 		}
-		if c.cache == nil {
-			t.Errorf("NewClient() cache is nil")
-		}
 	})
 
 	t.Run("with nil client", func(t *testing.T) {
@@ -555,9 +548,6 @@ func TestNewClient(t *testing.T) {
 		if c.client == nil {
 			t.Errorf("NewClient() client field is nil")
 		}
-		if c.cache == nil {
-			t.Errorf("NewClient() cache is nil")
-		}
 	})
 }
 
@@ -570,43 +560,4 @@ func TestDefaultClient(t *testing.T) {
 	if c.client == nil {
 		t.Errorf("DefaultClient() client field is nil")
 	}
-	if c.cache == nil {
-		t.Errorf("DefaultClient() cache is nil")
-	}
-}
-
-func TestClearExpiredCache(t *testing.T) {
-	c := &Client{
-		client: client.DefaultClient(),
-		cache:  make(map[cacheKey]*cacheEntry),
-	}
-
-	// Add some cache entries
-	validKey := cacheKey{resource: "AS1205", queryTime: "", lod: 0}
-	expiredKey := cacheKey{resource: "AS1853", queryTime: "", lod: 0}
-
-	validEntry := &cacheEntry{
-		response:  &APIResponse{Resource: "1205"},
-		timestamp: time.Now(),
-	}
-	expiredEntry := &cacheEntry{
-		response:  &APIResponse{Resource: "1853"},
-		timestamp: time.Now().Add(-20 * time.Minute),
-	}
-
-	c.cache[validKey] = validEntry
-	c.cache[expiredKey] = expiredEntry
-
-	// Clear expired cache
-	c.clearExpiredCache()
-
-	// Valid entry should still exist
-	if _, exists := c.cache[validKey]; !exists {
-		t.Errorf("valid cache entry should still exist")
-	}
-
-	// Expired entry should be removed
-	if _, exists := c.cache[expiredKey]; exists {
-		t.Errorf("expired cache entry should be removed")
-	}
 }
@@ -28,3 +28,9 @@ type Block struct {
 	Desc     string `json:"desc"`
 	Name     string `json:"name"`
 }
+
+// IsEmpty reports whether RIPEstat knows nothing about the AS, as for ASNs
+// that are not assigned.
+func (r *Response) IsEmpty() bool {
+	return r.Data.Holder == "" && !r.Data.Announced
+}
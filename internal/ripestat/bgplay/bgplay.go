@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 )
 
 // Client provides access to the RIPEstat bgplay API.
@@ -19,9 +21,23 @@ func New(c *client.Client) *Client {
 	return &Client{client: c}
 }
 
+// TimeLimits are the time window limits of the bgplay data call. Replays of
+// longer windows are too large to serve.
+var TimeLimits = timerange.Limits{MaxSpan: 7 * 24 * time.Hour, DefaultSpan: 24 * time.Hour}
+
+// GetOptions represents optional parameters for the bgplay API.
+type GetOptions struct {
+	Window timerange.Window // Time range to replay; RIPEstat's default when zero
+}
+
 // Get retrieves BGP play data for the specified resource.
 // The resource can be an IP address or IP prefix.
 func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
+	return c.GetWithOptions(ctx, resource, nil)
+}
+
+// GetWithOptions retrieves BGP play data for the specified resource with optional parameters.
+func (c *Client) GetWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
 	if resource == "" {
 		return nil, errors.ErrInvalidParameter.WithError(fmt.Errorf("resource parameter is required"))
 	}
@@ -29,6 +45,10 @@ func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
 	params := url.Values{}
 	params.Set("resource", resource)
 
+	if opts != nil {
+		opts.Window.SetParams(params)
+	}
+
 	endpoint := "/data/bgplay/data.json"
 
 	var response Response
@@ -48,3 +68,8 @@ func DefaultClient() *Client {
 func GetBGPlay(ctx context.Context, resource string) (*Response, error) {
 	return DefaultClient().Get(ctx, resource)
 }
+
+// GetBGPlayWithOptions is a convenience function that uses the default client to get BGP play data with options.
+func GetBGPlayWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
+	return DefaultClient().GetWithOptions(ctx, resource, opts)
+}
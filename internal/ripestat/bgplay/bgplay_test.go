@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 )
 
 func TestClient_Get_Success(t *testing.T) {
@@ -113,6 +114,32 @@ func TestClient_Get_EmptyResource(t *testing.T) {
 	}
 }
 
+func TestClient_GetWithOptions_Window(t *testing.T) {
+	var gotStart, gotEnd string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotStart = r.URL.Query().Get("starttime")
+		gotEnd = r.URL.Query().Get("endtime")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data": {"resource": "8.8.8.8"}, "status": "ok"}`))
+	}))
+	defer ts.Close()
+
+	bgplayClient := New(client.New(ts.URL, ts.Client()))
+
+	window := timerange.Window{
+		Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+	}
+	if _, err := bgplayClient.GetWithOptions(context.Background(), "8.8.8.8", &GetOptions{Window: window}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if gotStart != "2024-03-01T00:00:00" || gotEnd != "2024-03-01T12:30:00" {
+		t.Errorf("Expected the window in starttime and endtime, got %q and %q", gotStart, gotEnd)
+	}
+}
+
 func TestClient_Get_HTTPError(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 )
 
 const EndpointPath = "/data/bgp-updates/data.json"
@@ -19,17 +21,33 @@ func NewClient(c *client.Client) *Client {
 	return &Client{client: c}
 }
 
+// TimeLimits are the time window limits of the bgp-updates data call.
+var TimeLimits = timerange.Limits{MaxSpan: 7 * 24 * time.Hour, DefaultSpan: 24 * time.Hour}
+
+// GetOptions represents optional parameters for the bgp-updates API.
+type GetOptions struct {
+	Window timerange.Window // Time range of the updates; RIPEstat's default when zero
+}
+
 func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
+	return c.GetWithOptions(ctx, resource, nil)
+}
+
+// GetWithOptions retrieves BGP updates for the resource with optional parameters.
+func (c *Client) GetWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
 	if resource == "" {
 		return nil, errors.ErrInvalidParameter.WithError(fmt.Errorf("resource parameter is required"))
 	}
 
 	params := url.Values{}
 	params.Set("resource", resource)
+	if opts != nil {
+		opts.Window.SetParams(params)
+	}
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get BGP updates: %w", err))
+		return nil, errors.Wrap(err, "failed to get BGP updates")
 	}
 
 	return &response, nil
@@ -42,3 +60,8 @@ func DefaultClient() *Client {
 func GetBGPUpdates(ctx context.Context, resource string) (*Response, error) {
 	return DefaultClient().Get(ctx, resource)
 }
+
+// GetBGPUpdatesWithOptions retrieves BGP updates with options using the default client.
+func GetBGPUpdatesWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
+	return DefaultClient().GetWithOptions(ctx, resource, opts)
+}
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
 	ripestaterrors "github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 )
 
 func TestClient_Get_Success(t *testing.T) {
@@ -70,6 +73,34 @@ func TestClient_Get_Success(t *testing.T) {
 	}
 }
 
+func TestClient_GetWithOptions_Window(t *testing.T) {
+	var gotQuery url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data": {"resource": "8.8.8.8", "updates": [], "nr_updates": 0}, "status": "ok"}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(client.New(ts.URL, ts.Client()))
+
+	if _, err := client.Get(context.Background(), "8.8.8.8"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gotQuery.Has("starttime") || gotQuery.Has("endtime") {
+		t.Errorf("Expected no time parameters without a window, got %q", gotQuery.Encode())
+	}
+
+	window := timerange.Window{End: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	if _, err := client.GetWithOptions(context.Background(), "8.8.8.8", &GetOptions{Window: window}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gotQuery.Has("starttime") || gotQuery.Get("endtime") != "2024-03-01T12:00:00" {
+		t.Errorf("Expected only endtime for an open start, got %q", gotQuery.Encode())
+	}
+}
+
 func TestClient_Get_EmptyResource(t *testing.T) {
 	client := NewClient(nil)
 	ctx := context.Background()
@@ -7,20 +7,36 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 )
 
+// DefaultFallbackTTL is the cache duration for endpoints without a configured TTL.
+const DefaultFallbackTTL = 5 * time.Minute
+
+// DefaultNegativeTTL is how long "not found" results are cached. It is capped
+// at the TTL of the endpoint.
+const DefaultNegativeTTL = time.Minute
+
 // Cache provides TTL-aware caching with endpoint-specific durations.
 type Cache struct {
-	data sync.Map
-	ttls map[string]time.Duration
-	mu   sync.RWMutex
+	data        sync.Map
+	ttls        map[string]time.Duration
+	fallbackTTL time.Duration
+	negativeTTL time.Duration
+	mu          sync.RWMutex
 }
 
-// entry represents a cached item with expiration.
+// entry represents a cached item with expiration. The endpoint type and
+// resource are kept so entries can be inspected and purged. Entries recording
+// that RIPEstat had no data for the request have notFound set.
 type entry struct {
 	data      interface{}
+	notFound  bool
+	endpoint  string
+	resource  string
+	storedAt  time.Time
 	expiresAt time.Time
 }
 
@@ -42,15 +58,88 @@ var DefaultTTLs = map[string]time.Duration{
 	"whats-my-ip":          5 * time.Minute,  // Dynamic but can be cached briefly
 }
 
+// DefaultParams lists, per endpoint type, parameter values RIPEstat assumes
+// when the parameter is missing. They are left out of cache keys so requests
+// with and without them share an entry.
+var DefaultParams = map[string]url.Values{
+	"asn-neighbours": {"lod": {"0"}},
+	"country-asns":   {"lod": {"0"}},
+}
+
+var (
+	defaultsMu         sync.RWMutex
+	configuredTTLs     map[string]time.Duration
+	configuredFallback = DefaultFallbackTTL
+	shared             *Cache
+)
+
+// SetDefaultTTLs overrides DefaultTTLs and the fallback TTL for caches created
+// by New afterwards and for the shared cache. Endpoints missing from ttls keep
+// their DefaultTTLs value. Passing nil and zero restores the built-in defaults.
+func SetDefaultTTLs(ttls map[string]time.Duration, fallback time.Duration) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+
+	configuredTTLs = nil
+	if ttls != nil {
+		configuredTTLs = make(map[string]time.Duration, len(ttls))
+		for endpoint, ttl := range ttls {
+			configuredTTLs[endpoint] = ttl
+		}
+	}
+
+	if fallback <= 0 {
+		fallback = DefaultFallbackTTL
+	}
+	configuredFallback = fallback
+
+	if shared != nil {
+		shared.resetTTLs(defaultTTLs(), configuredFallback)
+	}
+}
+
+// defaultTTLs merges DefaultTTLs with the configured TTLs. defaultsMu must be
+// held.
+func defaultTTLs() map[string]time.Duration {
+	ttls := make(map[string]time.Duration, len(DefaultTTLs)+len(configuredTTLs))
+	for endpoint, ttl := range DefaultTTLs {
+		ttls[endpoint] = ttl
+	}
+	for endpoint, ttl := range configuredTTLs {
+		ttls[endpoint] = ttl
+	}
+	return ttls
+}
+
 // New creates a new Cache with default TTLs.
 func New() *Cache {
-	return NewWithTTLs(DefaultTTLs)
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+
+	c := NewWithTTLs(defaultTTLs())
+	c.fallbackTTL = configuredFallback
+	return c
+}
+
+// Shared returns the process-wide cache used by RIPEstat clients, creating it
+// with the default TTLs on first use.
+func Shared() *Cache {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+
+	if shared == nil {
+		shared = NewWithTTLs(defaultTTLs())
+		shared.fallbackTTL = configuredFallback
+	}
+	return shared
 }
 
 // NewWithTTLs creates a new Cache with custom TTL configuration.
 func NewWithTTLs(ttls map[string]time.Duration) *Cache {
 	return &Cache{
-		ttls: ttls,
+		ttls:        ttls,
+		fallbackTTL: DefaultFallbackTTL,
+		negativeTTL: DefaultNegativeTTL,
 	}
 }
 
@@ -58,8 +147,8 @@ func NewWithTTLs(ttls map[string]time.Duration) *Cache {
 func generateKey(endpoint string, params url.Values) string {
 	// Include params in the key to ensure different parameter sets are cached separately
 	key := endpoint
-	if len(params) > 0 {
-		key += "?" + params.Encode()
+	if normalized := normalizeParams(getEndpointType(endpoint), params); len(normalized) > 0 {
+		key += "?" + normalized.Encode()
 	}
 
 	// Hash the key to ensure consistent length and avoid special characters
@@ -67,11 +156,41 @@ func generateKey(endpoint string, params url.Values) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// normalizeParams returns params as used in cache keys: values trimmed, empty
+// and default values dropped, and the resource in lower case, since RIPEstat
+// treats those requests alike.
+func normalizeParams(endpointType string, params url.Values) url.Values {
+	defaults := DefaultParams[endpointType]
+	normalized := make(url.Values, len(params))
+	for name, values := range params {
+		var kept []string
+		for _, value := range values {
+			value = strings.TrimSpace(value)
+			if name == "resource" {
+				value = strings.ToLower(value)
+			}
+			if value != "" {
+				kept = append(kept, value)
+			}
+		}
+		if len(kept) == 0 || (len(kept) == 1 && len(defaults[name]) == 1 && kept[0] == defaults[name][0]) {
+			continue
+		}
+		normalized[name] = kept
+	}
+	return normalized
+}
+
 // getEndpointType extracts the endpoint type from the full endpoint path.
 func getEndpointType(endpoint string) string {
-	// Extract the main endpoint type from paths like "/data/network-info"
+	// Fragments name variants of a data call, such as converted responses.
+	endpoint, _, _ = strings.Cut(endpoint, "#")
+
+	// Extract the main endpoint type from paths like "/data/network-info" and
+	// "/data/network-info/data.json"
 	if len(endpoint) > 6 && endpoint[:6] == "/data/" {
-		return endpoint[6:]
+		endpointType, _, _ := strings.Cut(endpoint[6:], "/")
+		return endpointType
 	}
 
 	// For other patterns, use the full endpoint
@@ -83,8 +202,11 @@ func (c *Cache) Get(_ context.Context, endpoint string, params url.Values) (inte
 	key := generateKey(endpoint, params)
 
 	if value, ok := c.data.Load(key); ok {
-		if entry, ok := value.(entry); ok {
+		if entry, ok := value.(*entry); ok {
 			if time.Now().Before(entry.expiresAt) {
+				if entry.notFound {
+					return nil, false
+				}
 				return entry.data, true
 			}
 			// Expired entry, remove it
@@ -97,24 +219,80 @@ func (c *Cache) Get(_ context.Context, endpoint string, params url.Values) (inte
 
 // Set stores a value in the cache with TTL based on endpoint type.
 func (c *Cache) Set(_ context.Context, endpoint string, params url.Values, data interface{}) {
-	key := generateKey(endpoint, params)
-	endpointType := getEndpointType(endpoint)
+	c.store(endpoint, params, entry{data: data})
+}
 
-	c.mu.RLock()
-	ttl, exists := c.ttls[endpointType]
-	c.mu.RUnlock()
+// NotFound reports whether RIPEstat had no data for a request recently, as
+// recorded by SetNotFound.
+func (c *Cache) NotFound(_ context.Context, endpoint string, params url.Values) bool {
+	value, ok := c.data.Load(generateKey(endpoint, params))
+	if !ok {
+		return false
+	}
+	entry, ok := value.(*entry)
+	return ok && entry.notFound && time.Now().Before(entry.expiresAt)
+}
 
-	if !exists {
-		// Default TTL for unknown endpoints
-		ttl = 5 * time.Minute
+// SetNotFound records that RIPEstat had no data for a request, so it is not
+// repeated for the negative TTL.
+func (c *Cache) SetNotFound(_ context.Context, endpoint string, params url.Values) {
+	if c.NegativeTTL() <= 0 {
+		return
 	}
+	c.store(endpoint, params, entry{notFound: true})
+}
+
+// store adds e for a request, setting its metadata and expiry.
+func (c *Cache) store(endpoint string, params url.Values, e entry) {
+	e.endpoint = getEndpointType(endpoint)
+	e.resource = params.Get("resource")
+	e.storedAt = time.Now()
+	e.expiresAt = e.storedAt.Add(c.entryTTL(e))
+
+	c.data.Store(generateKey(endpoint, params), &e)
+}
 
-	entry := entry{
-		data:      data,
-		expiresAt: time.Now().Add(ttl),
+// entryTTL returns how long e is kept: the TTL of its endpoint type, or the
+// negative TTL capped at it for "not found" entries.
+func (c *Cache) entryTTL(e entry) time.Duration {
+	ttl := c.TTL(e.endpoint)
+	if e.notFound {
+		return min(ttl, c.NegativeTTL())
 	}
+	return ttl
+}
+
+// NegativeTTL returns how long "not found" results are cached.
+func (c *Cache) NegativeTTL() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.negativeTTL
+}
 
-	c.data.Store(key, entry)
+// SetNegativeTTL changes how long "not found" results are cached; zero
+// disables negative caching. Entries already cached expire according to the
+// new TTL.
+func (c *Cache) SetNegativeTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.negativeTTL = max(ttl, 0)
+	c.mu.Unlock()
+
+	c.updateExpiry(func(string) bool { return true })
+}
+
+// TTL returns the TTL for an endpoint type, falling back to the default TTL.
+func (c *Cache) TTL(endpointType string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if ttl, exists := c.ttls[endpointType]; exists {
+		return ttl
+	}
+	// Default TTL for unknown endpoints
+	if c.fallbackTTL <= 0 {
+		return DefaultFallbackTTL
+	}
+	return c.fallbackTTL
 }
 
 // Delete removes a specific cache entry.
@@ -138,7 +316,7 @@ func (c *Cache) Stats() Stats {
 
 	c.data.Range(func(_, value interface{}) bool {
 		total++
-		if entry, ok := value.(entry); ok {
+		if entry, ok := value.(*entry); ok {
 			if now.After(entry.expiresAt) {
 				expired++
 			}
@@ -153,6 +331,54 @@ func (c *Cache) Stats() Stats {
 	}
 }
 
+// StatsByEndpoint returns cache statistics per endpoint type.
+func (c *Cache) StatsByEndpoint() map[string]Stats {
+	stats := make(map[string]Stats)
+	now := time.Now()
+
+	c.data.Range(func(_, value interface{}) bool {
+		if entry, ok := value.(*entry); ok {
+			s := stats[entry.endpoint]
+			s.TotalEntries++
+			if now.After(entry.expiresAt) {
+				s.ExpiredEntries++
+			} else {
+				s.ActiveEntries++
+			}
+			stats[entry.endpoint] = s
+		}
+		return true
+	})
+
+	return stats
+}
+
+// Purge removes the entries of an endpoint type for a resource and returns
+// how many were removed. An empty endpoint type or resource matches any;
+// resources are compared case-insensitively.
+func (c *Cache) Purge(endpointType, resource string) int {
+	var removed int
+	resource = strings.TrimSpace(resource)
+
+	c.data.Range(func(key, value interface{}) bool {
+		entry, ok := value.(*entry)
+		if !ok {
+			return true
+		}
+		if endpointType != "" && entry.endpoint != endpointType {
+			return true
+		}
+		if resource != "" && !strings.EqualFold(strings.TrimSpace(entry.resource), resource) {
+			return true
+		}
+		c.data.Delete(key)
+		removed++
+		return true
+	})
+
+	return removed
+}
+
 // Stats provides cache statistics.
 type Stats struct {
 	TotalEntries   int `json:"total_entries"`
@@ -166,7 +392,7 @@ func (c *Cache) CleanupExpired() int {
 	now := time.Now()
 
 	c.data.Range(func(key, value interface{}) bool {
-		if entry, ok := value.(entry); ok {
+		if entry, ok := value.(*entry); ok {
 			if now.After(entry.expiresAt) {
 				c.data.Delete(key)
 				removed++
@@ -178,11 +404,43 @@ func (c *Cache) CleanupExpired() int {
 	return removed
 }
 
-// SetTTL updates the TTL for a specific endpoint type.
+// SetTTL updates the TTL for a specific endpoint type. Entries already
+// cached for it expire according to the new TTL.
 func (c *Cache) SetTTL(endpointType string, ttl time.Duration) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.ttls == nil {
+		c.ttls = make(map[string]time.Duration)
+	}
 	c.ttls[endpointType] = ttl
+	c.mu.Unlock()
+
+	c.updateExpiry(func(endpoint string) bool { return endpoint == endpointType })
+}
+
+// resetTTLs replaces every TTL, including the fallback, and updates the
+// expiry of cached entries.
+func (c *Cache) resetTTLs(ttls map[string]time.Duration, fallback time.Duration) {
+	c.mu.Lock()
+	c.ttls = ttls
+	c.fallbackTTL = fallback
+	c.mu.Unlock()
+
+	c.updateExpiry(func(string) bool { return true })
+}
+
+// updateExpiry recomputes the expiry of the entries whose endpoint type
+// matches from the current TTLs. Entries are replaced only if they were not
+// stored again in the meantime, so a fresh entry is never overwritten by an
+// older one.
+func (c *Cache) updateExpiry(match func(endpoint string) bool) {
+	c.data.Range(func(key, value interface{}) bool {
+		if e, ok := value.(*entry); ok && match(e.endpoint) {
+			updated := *e
+			updated.expiresAt = updated.storedAt.Add(c.entryTTL(updated))
+			c.data.CompareAndSwap(key, e, &updated)
+		}
+		return true
+	})
 }
 
 // GetTTL returns the TTL for a specific endpoint type.
@@ -193,6 +451,23 @@ func (c *Cache) GetTTL(endpointType string) (time.Duration, bool) {
 	return ttl, exists
 }
 
+// TTLs returns the TTL of every endpoint type with its own and the fallback
+// TTL used for the others.
+func (c *Cache) TTLs() (ttls map[string]time.Duration, fallback time.Duration) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ttls = make(map[string]time.Duration, len(c.ttls))
+	for endpoint, ttl := range c.ttls {
+		ttls[endpoint] = ttl
+	}
+	fallback = c.fallbackTTL
+	if fallback <= 0 {
+		fallback = DefaultFallbackTTL
+	}
+	return ttls, fallback
+}
+
 // String returns a string representation of the cache.
 func (c *Cache) String() string {
 	stats := c.Stats()
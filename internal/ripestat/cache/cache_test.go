@@ -321,6 +321,143 @@ func TestCache_SetTTL(t *testing.T) {
 	}
 }
 
+func TestCache_SetTTL_ExistingEntries(t *testing.T) {
+	cache := NewWithTTLs(map[string]time.Duration{"routing-status": time.Hour})
+	ctx := context.Background()
+	params := url.Values{"resource": {"AS3333"}}
+	cache.Set(ctx, "/data/routing-status", params, "data")
+
+	cache.SetTTL("routing-status", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if _, found := cache.Get(ctx, "/data/routing-status", params); found {
+		t.Error("Expected the shortened TTL to expire the existing entry")
+	}
+}
+
+func TestCache_UpdateExpiryKeepsConcurrentSet(t *testing.T) {
+	cache := NewWithTTLs(map[string]time.Duration{"routing-status": time.Hour})
+	ctx := context.Background()
+	params := url.Values{"resource": {"AS3333"}}
+	cache.Set(ctx, "/data/routing-status", params, "old")
+
+	// A Set racing with a TTL change lands between reading and replacing the entry.
+	cache.updateExpiry(func(string) bool {
+		cache.Set(ctx, "/data/routing-status", params, "new")
+		return true
+	})
+
+	if data, found := cache.Get(ctx, "/data/routing-status", params); !found || data != "new" {
+		t.Errorf("Expected the fresh entry to survive the expiry update, got %v", data)
+	}
+}
+
+func TestCache_NotFound(t *testing.T) {
+	cache := NewWithTTLs(map[string]time.Duration{"as-overview": time.Hour, "bgplay": time.Nanosecond})
+	ctx := context.Background()
+	params := url.Values{"resource": {"AS64496"}}
+
+	cache.SetNotFound(ctx, "/data/as-overview/data.json", params)
+	if !cache.NotFound(ctx, "/data/as-overview/data.json", params) {
+		t.Error("Expected the request to be remembered as not found")
+	}
+	if _, found := cache.Get(ctx, "/data/as-overview/data.json", params); found {
+		t.Error("Expected no cached data for a not found request")
+	}
+
+	// A response replaces the negative entry.
+	cache.Set(ctx, "/data/as-overview/data.json", params, "data")
+	if cache.NotFound(ctx, "/data/as-overview/data.json", params) {
+		t.Error("Expected a cached response to replace the negative entry")
+	}
+
+	// The negative TTL is capped at the endpoint's TTL.
+	cache.SetNotFound(ctx, "/data/bgplay/data.json", params)
+	time.Sleep(time.Millisecond)
+	if cache.NotFound(ctx, "/data/bgplay/data.json", params) {
+		t.Error("Expected the negative entry to expire with the endpoint's TTL")
+	}
+
+	cache.SetNegativeTTL(0)
+	cache.SetNotFound(ctx, "/data/whois/data.json", params)
+	if cache.NotFound(ctx, "/data/whois/data.json", params) {
+		t.Error("Expected no negative caching with a zero negative TTL")
+	}
+}
+
+func TestCache_StatsByEndpoint(t *testing.T) {
+	cache := NewWithTTLs(map[string]time.Duration{"whois": time.Hour, "bgplay": time.Nanosecond})
+	ctx := context.Background()
+	cache.Set(ctx, "/data/whois", url.Values{"resource": {"193.0.0.1"}}, "a")
+	cache.Set(ctx, "/data/whois", url.Values{"resource": {"AS3333"}}, "b")
+	cache.Set(ctx, "/data/bgplay", url.Values{"resource": {"AS3333"}}, "c")
+	time.Sleep(time.Millisecond)
+
+	stats := cache.StatsByEndpoint()
+	if whois := stats["whois"]; whois.TotalEntries != 2 || whois.ActiveEntries != 2 {
+		t.Errorf("Unexpected whois stats %+v", whois)
+	}
+	if bgplay := stats["bgplay"]; bgplay.TotalEntries != 1 || bgplay.ExpiredEntries != 1 {
+		t.Errorf("Unexpected bgplay stats %+v", bgplay)
+	}
+}
+
+func TestCache_Purge(t *testing.T) {
+	cache := New()
+	ctx := context.Background()
+	fill := func() {
+		cache.Set(ctx, "/data/whois", url.Values{"resource": {"193.0.0.1"}}, "a")
+		cache.Set(ctx, "/data/whois", url.Values{"resource": {"AS3333"}}, "b")
+		cache.Set(ctx, "/data/routing-status/data.json", url.Values{"resource": {"AS3333"}}, "c")
+		cache.Set(ctx, "/data/whats-my-ip", nil, "d")
+	}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		resource string
+		purged   int
+	}{
+		{name: "endpoint", endpoint: "whois", purged: 2},
+		{name: "data call path", endpoint: "routing-status", purged: 1},
+		{name: "resource", resource: "as3333", purged: 2},
+		{name: "endpoint and resource", endpoint: "whois", resource: "AS3333", purged: 1},
+		{name: "everything", purged: 4},
+		{name: "no match", endpoint: "bgplay", purged: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache.Clear()
+			fill()
+			if purged := cache.Purge(tt.endpoint, tt.resource); purged != tt.purged {
+				t.Errorf("Purge(%q, %q) = %d, want %d", tt.endpoint, tt.resource, purged, tt.purged)
+			}
+			if total := cache.Stats().TotalEntries; total != 4-tt.purged {
+				t.Errorf("Expected %d entries left, got %d", 4-tt.purged, total)
+			}
+		})
+	}
+}
+
+func TestShared(t *testing.T) {
+	defer SetDefaultTTLs(nil, 0)
+
+	shared := Shared()
+	if Shared() != shared {
+		t.Fatal("Expected Shared to return the same cache")
+	}
+
+	SetDefaultTTLs(map[string]time.Duration{"whois": time.Minute}, time.Second)
+	ttls, fallback := shared.TTLs()
+	if ttls["whois"] != time.Minute || fallback != time.Second {
+		t.Errorf("Expected configured TTLs to apply to the shared cache, got %v and %v", ttls["whois"], fallback)
+	}
+	if ttls["bgplay"] != DefaultTTLs["bgplay"] {
+		t.Errorf("Expected unconfigured endpoints to keep their default TTL, got %v", ttls["bgplay"])
+	}
+}
+
 func TestGenerateKey(t *testing.T) {
 	endpoint := "/data/whois"
 
@@ -350,6 +487,29 @@ func TestGenerateKey(t *testing.T) {
 	}
 }
 
+func TestGenerateKey_Normalized(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		a, b     url.Values
+		same     bool
+	}{
+		{"default lod", "/data/asn-neighbours/data.json", url.Values{"resource": {"AS3333"}, "lod": {"0"}}, url.Values{"resource": {"AS3333"}}, true},
+		{"other lod", "/data/asn-neighbours/data.json", url.Values{"resource": {"AS3333"}, "lod": {"1"}}, url.Values{"resource": {"AS3333"}}, false},
+		{"lod without default", "/data/whois/data.json", url.Values{"resource": {"AS3333"}, "lod": {"0"}}, url.Values{"resource": {"AS3333"}}, false},
+		{"resource case and spaces", "/data/as-overview/data.json", url.Values{"resource": {" as3333 "}}, url.Values{"resource": {"AS3333"}}, true},
+		{"empty parameter", "/data/routing-history/data.json", url.Values{"resource": {"AS3333"}, "starttime": {""}}, url.Values{"resource": {"AS3333"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := generateKey(tt.endpoint, tt.a) == generateKey(tt.endpoint, tt.b); same != tt.same {
+				t.Errorf("Expected same key to be %v for %v and %v", tt.same, tt.a, tt.b)
+			}
+		})
+	}
+}
+
 func TestGetEndpointType(t *testing.T) {
 	tests := []struct {
 		endpoint string
@@ -357,6 +517,9 @@ func TestGetEndpointType(t *testing.T) {
 	}{
 		{"/data/whois", "whois"},
 		{"/data/network-info", "network-info"},
+		{"/data/network-info/data.json", "network-info"},
+		{"/data/asn-neighbours/data.json#derived", "asn-neighbours"},
+		{"/data/whois#derived", "whois"},
 		{"/other/endpoint", "/other/endpoint"},
 		{"simple", "simple"},
 	}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
@@ -14,10 +15,58 @@ import (
 	"github.com/taihen/mcp-ripestat/internal/ripestat/errors"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/logging"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/metrics"
+	"github.com/taihen/mcp-ripestat/internal/tracing"
 )
 
-// ripeLimiter enforces RIPE's 8 concurrent request limit with a safety margin.
-var ripeLimiter = make(chan struct{}, 7)
+// DefaultMaxConcurrentRequests keeps below RIPE's 8 concurrent request limit with a safety margin.
+const DefaultMaxConcurrentRequests = 7
+
+// MaxConcurrentRequestsLimit is RIPE's limit on concurrent requests per client.
+const MaxConcurrentRequestsLimit = 8
+
+var (
+	limiterMu sync.RWMutex
+
+	// ripeLimiter enforces RIPE's 8 concurrent request limit with a safety margin.
+	ripeLimiter = make(chan struct{}, DefaultMaxConcurrentRequests)
+)
+
+// SetMaxConcurrentRequests changes the number of concurrent upstream requests
+// shared by all clients. Requests already holding a slot release it to the
+// limiter they acquired it from, so the size can be changed at any time.
+func SetMaxConcurrentRequests(n int) error {
+	if err := ValidateMaxConcurrentRequests(n); err != nil {
+		return err
+	}
+
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+
+	if cap(ripeLimiter) != n {
+		ripeLimiter = make(chan struct{}, n)
+	}
+	return nil
+}
+
+// ValidateMaxConcurrentRequests reports whether SetMaxConcurrentRequests
+// accepts n.
+func ValidateMaxConcurrentRequests(n int) error {
+	if n < 1 || n > MaxConcurrentRequestsLimit {
+		return fmt.Errorf("max concurrent requests must be between 1 and %d, got %d", MaxConcurrentRequestsLimit, n)
+	}
+	return nil
+}
+
+// MaxConcurrentRequests returns the current number of concurrent upstream requests allowed.
+func MaxConcurrentRequests() int {
+	return cap(currentLimiter())
+}
+
+func currentLimiter() chan struct{} {
+	limiterMu.RLock()
+	defer limiterMu.RUnlock()
+	return ripeLimiter
+}
 
 // createOptimizedHTTPClient creates an HTTP client with connection pooling and HTTP/2 support.
 func createOptimizedHTTPClient(cfg *config.Config) *http.Client {
@@ -124,10 +173,35 @@ func NewWithConfig(cfg *config.Config, httpClient HTTPDoer) *Client {
 			MaxRetryWaitTime: cfg.MaxRetryWaitTime,
 		},
 		Logger: logging.DefaultLogger,
-		Cache:  cache.New(),
+		Cache:  newCache(cfg),
 	}
 }
 
+// newCache returns the shared response cache, or nil when caching is disabled
+// in cfg.
+func newCache(cfg *config.Config) *cache.Cache {
+	if cfg.DisableCache {
+		return nil
+	}
+	return cache.Shared()
+}
+
+// bypassCacheKey is the context key marking requests that skip cached
+// responses.
+const bypassCacheKey contextKey = "bypass_cache"
+
+// WithCacheBypass returns a context whose GetJSON calls ignore cached
+// responses. Fresh responses are still cached for later calls.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey, true)
+}
+
+// cacheBypassed reports whether ctx was created by WithCacheBypass.
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey).(bool)
+	return bypass
+}
+
 // DefaultClient returns a new Client with default settings.
 func DefaultClient() *Client {
 	return NewWithConfig(config.DefaultConfig(), nil)
@@ -137,26 +211,30 @@ func DefaultClient() *Client {
 func (c *Client) Get(ctx context.Context, endpoint string, params url.Values) (*http.Response, error) {
 	u, err := url.Parse(c.BaseURL + endpoint)
 	if err != nil {
-		c.Logger.Error("Failed to parse URL: %v", err)
+		c.Logger.Error(ctx, "invalid RIPEstat URL", logging.KeyEndpoint, endpoint, "err", err)
 		return nil, errors.ErrInvalidParameter.WithError(fmt.Errorf("failed to parse URL: %w", err))
 	}
 
-	if params == nil {
-		params = url.Values{}
+	// Copy params so the caller's values, which GetJSON also uses as the
+	// cache key, stay unchanged.
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
 	}
 
 	// Add sourceapp parameter for compliance
 	if c.SourceApp != "" {
-		params.Set("sourceapp", c.SourceApp)
+		query.Set("sourceapp", c.SourceApp)
 	}
 
-	u.RawQuery = params.Encode()
+	u.RawQuery = query.Encode()
 
-	c.Logger.Debug("Making request to %s", u.String())
+	call := dataCallName(endpoint)
+	c.Logger.Debug(ctx, "RIPEstat request", logging.KeyEndpoint, call, logging.URL(u))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		c.Logger.Error("Failed to create request: %v", err)
+		c.Logger.Error(ctx, "failed to create RIPEstat request", logging.KeyEndpoint, call, "err", err)
 		return nil, errors.ErrInvalidParameter.WithError(fmt.Errorf("failed to create request: %w", err))
 	}
 
@@ -171,65 +249,124 @@ func (c *Client) Get(ctx context.Context, endpoint string, params url.Values) (*
 	duration := time.Since(start)
 
 	if err != nil {
-		c.Logger.Error("Request failed after %v: %v", duration, err)
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("request failed: %w", err))
+		c.Logger.Error(ctx, "RIPEstat request failed", logging.KeyEndpoint, call, logging.URL(u), logging.Duration(duration), "err", err)
+		return nil, errors.FromRequestError(err)
 	}
 
 	// Log warning for requests taking more than 10 seconds.
+	attrs := []any{logging.KeyEndpoint, call, logging.URL(u), logging.Duration(duration), logging.KeyStatus, resp.StatusCode}
 	if duration > 10*time.Second {
-		c.Logger.Warning("Slow request to %s took %v", u.String(), duration)
+		c.Logger.Warn(ctx, "slow RIPEstat request", attrs...)
+	} else {
+		c.Logger.Debug(ctx, "RIPEstat request completed", attrs...)
 	}
 
-	c.Logger.Debug("Request to %s completed in %v with status: %d", u.String(), duration, resp.StatusCode)
-
 	return resp, nil
 }
 
 // GetJSON performs a GET request and decodes the JSON response into the provided target.
 func (c *Client) GetJSON(ctx context.Context, endpoint string, params url.Values, target interface{}) error {
+	return c.getJSON(ctx, endpoint, params, target, target, nil)
+}
+
+// GetDerived is GetJSON for responses converted before use, such as into an
+// APIResponse: the response is decoded into raw, convert fills target from
+// it, and target rather than raw is cached, so cache hits skip both.
+func (c *Client) GetDerived(ctx context.Context, endpoint string, params url.Values, raw, target interface{}, convert func() error) error {
+	return c.getJSON(ctx, endpoint, params, raw, target, convert)
+}
+
+// derivedSuffix marks the cache keys of converted responses. They share the
+// endpoint type, and so the TTL and purges, of the data call they come from.
+const derivedSuffix = "#derived"
+
+// getJSON implements GetJSON and GetDerived. Without convert, raw and target
+// are the same.
+func (c *Client) getJSON(ctx context.Context, endpoint string, params url.Values, raw, target interface{}, convert func() error) (err error) {
 	start := time.Now()
 	endpointType := extractEndpointType(endpoint)
+	cacheEndpoint := endpoint
+	if convert != nil {
+		cacheEndpoint += derivedSuffix
+	}
+
+	// Report the call to the caller's recorder, for example for audit logs,
+	// and to the trace of the request that made it.
+	call := UpstreamCall{Endpoint: dataCallName(endpoint), Cache: CacheMiss}
+	ctx, span := tracing.Start(ctx, "ripestat "+call.Endpoint, tracing.KindClient)
+	span.SetAttributes(tracing.String("ripestat.endpoint", call.Endpoint))
+	defer func() {
+		call.Failed = err != nil
+		call.Latency = time.Since(start)
+		recordCall(ctx, call)
+
+		span.SetAttributes(tracing.String("ripestat.cache", call.Cache))
+		traceUpstream(span, raw)
+		if err != nil {
+			if call.QueryID != "" {
+				span.SetAttributes(tracing.String("ripestat.query_id", call.QueryID))
+			}
+			span.SetError(err.Error())
+		}
+		span.End()
+	}()
 
 	// Check cache first
-	if c.Cache != nil {
-		if cached, found := c.Cache.Get(ctx, endpoint, params); found {
-			c.Logger.Debug("Cache hit for endpoint %s", endpoint)
-			metrics.RecordCacheHit()
+	if c.Cache != nil && !cacheBypassed(ctx) {
+		if c.Cache.NotFound(ctx, cacheEndpoint, params) {
+			metrics.RecordCacheResult(call.Endpoint, true)
+			metrics.EndRequest(endpointType, time.Since(start))
+			call.Cache = CacheHit
+			c.Logger.Debug(ctx, "RIPEstat negative cache hit", logging.KeyEndpoint, call.Endpoint)
+			return notFound(params)
+		}
+		if cached, found := c.Cache.Get(ctx, cacheEndpoint, params); found {
+			metrics.RecordCacheResult(call.Endpoint, true)
 
 			// Copy cached data to target
 			if err := copyInterface(cached, target); err != nil {
-				c.Logger.Warning("Failed to copy cached data: %v", err)
+				c.Logger.Warn(ctx, "failed to copy cached RIPEstat response", logging.KeyEndpoint, call.Endpoint, "err", err)
 				// Continue with API request on cache error
 			} else {
 				metrics.EndRequest(endpointType, time.Since(start))
+				call.Cache = CacheHit
+				call.QueryID = queryID(target)
+				c.Logger.Debug(ctx, "RIPEstat cache hit", logging.KeyEndpoint, call.Endpoint, logging.KeyQueryID, call.QueryID)
 				return nil
 			}
 		}
 	}
 
-	metrics.RecordCacheMiss()
+	metrics.RecordCacheResult(call.Endpoint, false)
 
 	// Acquire rate limiter semaphore
+	limiter := currentLimiter()
+	waitStart := time.Now()
 	select {
-	case ripeLimiter <- struct{}{}:
-		metrics.RecordRateLimitWait()
-		defer func() { <-ripeLimiter }()
+	case limiter <- struct{}{}:
+		metrics.ObserveRateLimitWait(time.Since(waitStart))
+		span.SetAttributes(tracing.Float64("ripestat.limiter_wait_ms", milliseconds(time.Since(waitStart))))
+		defer func() { <-limiter }()
 	case <-ctx.Done():
 		metrics.RecordRateLimitTimeout()
 		return ctx.Err()
 	}
 
-	c.Logger.Debug("Cache miss for endpoint %s, making API request", endpoint)
-
 	// Start request tracking
 	metrics.StartRequest()
 	defer func() {
 		metrics.EndRequest(endpointType, time.Since(start))
 	}()
 
+	requestStart := time.Now()
 	resp, err := c.Get(ctx, endpoint, params)
 	if err != nil {
+		// Requests abandoned by the caller say nothing about RIPEstat.
+		if ctx.Err() == nil {
+			recordOutcome(time.Now(), 0)
+		}
 		metrics.RecordRequest(endpointType, "error")
+		metrics.ObserveUpstreamRequest(call.Endpoint, "error", time.Since(requestStart))
 		return err
 	}
 
@@ -237,30 +374,71 @@ func (c *Client) GetJSON(ctx context.Context, endpoint string, params url.Values
 		_ = resp.Body.Close()
 	}()
 
+	recordOutcome(time.Now(), resp.StatusCode)
 	status := fmt.Sprintf("%d", resp.StatusCode)
+	span.SetAttributes(tracing.Int("http.response.status_code", resp.StatusCode))
 	metrics.RecordRequest(endpointType, status)
+	metrics.ObserveUpstreamRequest(call.Endpoint, status, time.Since(requestStart))
 
 	if resp.StatusCode != http.StatusOK {
-		c.Logger.Warning("Received non-OK status code: %d", resp.StatusCode)
+		// Error responses usually still carry a query ID for RIPE support.
+		call.QueryID = errorQueryID(resp.Body)
+		c.Logger.Warn(ctx, "RIPEstat returned an error status", logging.KeyEndpoint, call.Endpoint,
+			logging.KeyStatus, resp.StatusCode, logging.KeyQueryID, call.QueryID)
+		if resp.StatusCode == http.StatusNotFound && c.Cache != nil {
+			c.Cache.SetNotFound(ctx, cacheEndpoint, params)
+		}
 		return errors.FromHTTPResponse(resp, "request failed")
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
-		c.Logger.Error("Failed to decode response: %v", err)
-		return errors.ErrServerError.WithError(fmt.Errorf("failed to decode response: %w", err))
+	if err := json.NewDecoder(resp.Body).Decode(raw); err != nil {
+		c.Logger.Error(ctx, "failed to decode RIPEstat response", logging.KeyEndpoint, call.Endpoint, "err", err)
+		return errors.Wrap(err, "failed to decode response")
+	}
+	call.QueryID = queryID(raw)
+
+	// RIPEstat answers 200 with empty data for resources it knows nothing
+	// about, such as unallocated prefixes.
+	if e, ok := raw.(emptyResponse); ok && e.IsEmpty() {
+		c.Logger.Debug(ctx, "RIPEstat returned no data", logging.KeyEndpoint, call.Endpoint, logging.KeyQueryID, call.QueryID)
+		if c.Cache != nil {
+			c.Cache.SetNotFound(ctx, cacheEndpoint, params)
+		}
+		return notFound(params)
+	}
+
+	if convert != nil {
+		if err := convert(); err != nil {
+			c.Logger.Error(ctx, "failed to convert RIPEstat response", logging.KeyEndpoint, call.Endpoint, "err", err)
+			return errors.Wrap(err, "failed to convert response")
+		}
 	}
 
 	// Cache the successful response
 	if c.Cache != nil {
-		c.Cache.Set(ctx, endpoint, params, target)
-		c.Logger.Debug("Cached response for endpoint %s", endpoint)
+		c.Cache.Set(ctx, cacheEndpoint, params, target)
 	}
 
-	c.Logger.Debug("Successfully decoded response")
+	c.Logger.Debug(ctx, "RIPEstat response decoded", logging.KeyEndpoint, call.Endpoint,
+		logging.KeyQueryID, call.QueryID, logging.Duration(time.Since(requestStart)))
 
 	return nil
 }
 
+// emptyResponse is implemented by responses that can tell when RIPEstat has
+// no data for the requested resource.
+type emptyResponse interface {
+	IsEmpty() bool
+}
+
+// notFound returns the error for a request RIPEstat has no data for.
+func notFound(params url.Values) error {
+	if resource := params.Get("resource"); resource != "" {
+		return errors.ErrNotFound.WithError(fmt.Errorf("no data for %s", resource))
+	}
+	return errors.ErrNotFound
+}
+
 // extractEndpointType extracts the endpoint type from the full endpoint path.
 func extractEndpointType(endpoint string) string {
 	// Extract the main endpoint type from paths like "/data/network-info"
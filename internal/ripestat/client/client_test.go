@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -14,7 +15,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/config"
+	ripestaterrors "github.com/taihen/mcp-ripestat/internal/ripestat/errors"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/logging"
 )
 
@@ -78,6 +81,10 @@ func TestNewWithConfig(t *testing.T) {
 	if c.Logger == nil {
 		t.Error("Expected Logger to be non-nil")
 	}
+
+	if c.Cache != cache.Shared() {
+		t.Error("Expected clients built from a config to use the shared cache")
+	}
 }
 
 func TestClient_Get(t *testing.T) {
@@ -141,15 +148,17 @@ func TestClient_Get_WithCustomLogger(t *testing.T) {
 	defer server.Close()
 
 	// Create client with custom logger
+	var logOutput strings.Builder
 	c := New(server.URL, nil)
-	c.Logger = logging.NewLogger(logging.LogLevelDebug, nil)
+	c.SourceApp = "secret-app"
+	c.Logger = logging.New(slog.New(logging.NewHandler(slog.NewJSONHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))))
 
 	// Make request
-	ctx := context.Background()
+	ctx := logging.WithAttrs(context.Background(), slog.String(logging.KeySessionID, "session-1"))
 	params := url.Values{}
 	params.Set("resource", "test")
 
-	resp, err := c.Get(ctx, "/test", params)
+	resp, err := c.Get(ctx, "/data/test/data.json", params)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -158,6 +167,16 @@ func TestClient_Get_WithCustomLogger(t *testing.T) {
 	}
 
 	defer resp.Body.Close()
+
+	logContent := logOutput.String()
+	for _, want := range []string{`"msg":"RIPEstat request completed"`, `"endpoint":"test"`, `"status":200`, `"duration_ms":`, `"session_id":"session-1"`} {
+		if !strings.Contains(logContent, want) {
+			t.Errorf("Expected log to contain %s, got %s", want, logContent)
+		}
+	}
+	if strings.Contains(logContent, "secret-app") {
+		t.Errorf("Expected sourceapp to be left out of logs, got %s", logContent)
+	}
 }
 
 func TestClient_Get_Error(t *testing.T) {
@@ -459,7 +478,7 @@ func TestClient_GetJSON_SuccessfulDecode(t *testing.T) {
 
 	// Create client with debug logging to test the success path
 	c := New(server.URL, nil)
-	c.Logger = logging.NewLogger(logging.LogLevelDebug, nil)
+	c.Logger = logging.New(slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})))
 
 	// Make request
 	ctx := context.Background()
@@ -574,7 +593,7 @@ func TestClient_Get_SlowRequestWarning(t *testing.T) {
 	// Create client with custom logger to capture warning
 	var logOutput strings.Builder
 	c := New(server.URL, nil)
-	c.Logger = logging.NewLogger(logging.LogLevelWarning, &logOutput)
+	c.Logger = logging.New(slog.New(slog.NewJSONHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelWarn})))
 
 	// Make request
 	ctx := context.Background()
@@ -593,10 +612,10 @@ func TestClient_Get_SlowRequestWarning(t *testing.T) {
 
 	// Check that warning was logged
 	logContent := logOutput.String()
-	if !strings.Contains(logContent, "Slow request") {
+	if !strings.Contains(logContent, "slow RIPEstat request") {
 		t.Errorf("Expected warning about slow request, got log: %s", logContent)
 	}
-	if !strings.Contains(logContent, "took") {
+	if !strings.Contains(logContent, `"duration_ms":`) {
 		t.Errorf("Expected warning to include timing, got log: %s", logContent)
 	}
 }
@@ -756,6 +775,131 @@ func TestClient_CacheHit(t *testing.T) {
 	}
 }
 
+func TestClient_CacheBypass(t *testing.T) {
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"request_count": %d}`, requestCount.Add(1))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, nil)
+	ctx := context.Background()
+	params := url.Values{"resource": {"test"}}
+
+	var result map[string]interface{}
+	for _, ctx := range []context.Context{ctx, WithCacheBypass(ctx), ctx} {
+		if err := c.GetJSON(ctx, "/data/whois", params, &result); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if requestCount.Load() != 2 {
+		t.Errorf("Expected the bypassing request to reach the server, got %d requests", requestCount.Load())
+	}
+	// The refreshed response replaces the cached one.
+	if result["request_count"] != float64(2) {
+		t.Errorf("Expected the refreshed response from the cache, got %v", result)
+	}
+}
+
+// emptyData is a response with IsEmpty, like those of RIPEstat data calls
+// that can return no data.
+type emptyData struct {
+	Data struct {
+		Holder string `json:"holder"`
+	} `json:"data"`
+}
+
+func (r *emptyData) IsEmpty() bool { return r.Data.Holder == "" }
+
+func TestClient_NegativeCache(t *testing.T) {
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount.Add(1)
+		if r.URL.Query().Get("resource") == "AS0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data": {"holder": ""}}`)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, nil)
+	ctx := context.Background()
+
+	for _, resource := range []string{"AS64496", "AS0"} {
+		params := url.Values{"resource": {resource}}
+		for range 2 {
+			var result emptyData
+			err := c.GetJSON(ctx, "/data/as-overview/data.json", params, &result)
+			if !errors.Is(err, ripestaterrors.ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound for %s, got %v", resource, err)
+			}
+		}
+	}
+	if requestCount.Load() != 2 {
+		t.Errorf("Expected not found results to be cached, got %d requests", requestCount.Load())
+	}
+
+	var result emptyData
+	err := c.GetJSON(WithCacheBypass(ctx), "/data/as-overview/data.json", url.Values{"resource": {"AS0"}}, &result)
+	if !errors.Is(err, ripestaterrors.ErrNotFound) || requestCount.Load() != 3 {
+		t.Errorf("Expected bypassing requests to reach the server, got %v after %d requests", err, requestCount.Load())
+	}
+}
+
+func TestClient_GetDerived(t *testing.T) {
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requestCount.Add(1)
+		_, _ = io.WriteString(w, `{"query_id": "q1", "data": {"holder": "RIPE NCC"}}`)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, nil)
+	ctx := context.Background()
+	params := url.Values{"resource": {"AS3333"}}
+
+	var conversions int
+	for range 2 {
+		var raw emptyData
+		var derived struct {
+			Name string `json:"name"`
+		}
+		convert := func() error {
+			conversions++
+			derived.Name = strings.ToLower(raw.Data.Holder)
+			return nil
+		}
+		if err := c.GetDerived(ctx, "/data/as-overview/data.json", params, &raw, &derived, convert); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if derived.Name != "ripe ncc" {
+			t.Errorf("Unexpected derived value %+v", derived)
+		}
+	}
+	if requestCount.Load() != 1 || conversions != 1 {
+		t.Errorf("Expected the derived value to be cached, got %d requests and %d conversions", requestCount.Load(), conversions)
+	}
+
+	// The raw response is cached separately, under the same endpoint type.
+	var raw emptyData
+	if err := c.GetJSON(ctx, "/data/as-overview/data.json", params, &raw); err != nil || raw.Data.Holder != "RIPE NCC" {
+		t.Errorf("Expected the raw response, got %+v, %v", raw, err)
+	}
+	if stats := c.Cache.StatsByEndpoint()["as-overview"]; stats.ActiveEntries != 2 {
+		t.Errorf("Expected raw and derived entries for as-overview, got %+v", stats)
+	}
+
+	// Conversion failures are reported.
+	failing := func() error { return errors.New("unexpected data") }
+	var derived map[string]interface{}
+	err := c.GetDerived(WithCacheBypass(ctx), "/data/as-overview/data.json", params, &raw, &derived, failing)
+	if err == nil || !strings.Contains(err.Error(), "unexpected data") {
+		t.Errorf("Expected the conversion error, got %v", err)
+	}
+}
+
 func TestCopyInterface(t *testing.T) {
 	source := map[string]interface{}{
 		"key1": "value1",
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Upstream outcomes are counted in fixed buckets so that recent error rates
+// can be reported without keeping every request.
+const (
+	outcomeBucketWidth = 10 * time.Second
+	outcomeBuckets     = 60
+
+	// MaxOutcomeWindow is the longest window RecentOutcomes can report on.
+	MaxOutcomeWindow = outcomeBucketWidth * (outcomeBuckets - 1)
+)
+
+// Outcomes counts upstream requests made within a window. Failures are
+// requests that got no response or a 5xx or 429 status; Throttled counts the
+// 429 responses among them.
+type Outcomes struct {
+	Requests  int `json:"requests"`
+	Failures  int `json:"failures"`
+	Throttled int `json:"throttled"`
+}
+
+type outcomeBucket struct {
+	start time.Time
+	Outcomes
+}
+
+var (
+	outcomesMu sync.Mutex
+	outcomes   [outcomeBuckets]outcomeBucket
+)
+
+// recordOutcome counts an upstream request. A status of zero means no
+// response was received.
+func recordOutcome(now time.Time, status int) {
+	start := now.Truncate(outcomeBucketWidth)
+	i := int(start.Unix()/int64(outcomeBucketWidth/time.Second)) % outcomeBuckets
+
+	outcomesMu.Lock()
+	defer outcomesMu.Unlock()
+
+	b := &outcomes[i]
+	if !b.start.Equal(start) {
+		*b = outcomeBucket{start: start}
+	}
+	b.Requests++
+	switch {
+	case status == http.StatusTooManyRequests:
+		b.Failures++
+		b.Throttled++
+	case status == 0 || status >= http.StatusInternalServerError:
+		b.Failures++
+	}
+}
+
+// RecentOutcomes returns the upstream requests made within window, which is
+// capped at MaxOutcomeWindow and rounded to 10 second buckets.
+func RecentOutcomes(window time.Duration) Outcomes {
+	return recentOutcomes(time.Now(), window)
+}
+
+func recentOutcomes(now time.Time, window time.Duration) Outcomes {
+	window = min(window, MaxOutcomeWindow)
+	oldest := now.Truncate(outcomeBucketWidth).Add(-window)
+
+	outcomesMu.Lock()
+	defer outcomesMu.Unlock()
+
+	var total Outcomes
+	for _, b := range outcomes {
+		if b.start.IsZero() || b.start.Before(oldest) || b.start.After(now) {
+			continue
+		}
+		total.Requests += b.Requests
+		total.Failures += b.Failures
+		total.Throttled += b.Throttled
+	}
+	return total
+}
+
+// LimiterUsage returns the number of upstream requests holding a limiter slot
+// and the number of slots.
+func LimiterUsage() (inUse, capacity int) {
+	limiter := currentLimiter()
+	return len(limiter), cap(limiter)
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRecentOutcomes(t *testing.T) {
+	outcomesMu.Lock()
+	outcomes = [outcomeBuckets]outcomeBucket{}
+	outcomesMu.Unlock()
+
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+	recordOutcome(now.Add(-9*time.Minute), http.StatusInternalServerError)
+	recordOutcome(now.Add(-time.Minute), http.StatusOK)
+	recordOutcome(now.Add(-time.Minute), http.StatusNotFound)
+	recordOutcome(now.Add(-30*time.Second), http.StatusTooManyRequests)
+	recordOutcome(now, 0)
+
+	got := recentOutcomes(now, 5*time.Minute)
+	want := Outcomes{Requests: 4, Failures: 2, Throttled: 1}
+	if got != want {
+		t.Errorf("recentOutcomes(5m) = %+v, want %+v", got, want)
+	}
+
+	if got := recentOutcomes(now, time.Hour); got.Requests != 5 {
+		t.Errorf("Expected the window to be capped at the history, got %+v", got)
+	}
+
+	// Buckets reused after a full rotation forget their old counts.
+	recordOutcome(now.Add(10*time.Minute), http.StatusOK)
+	if got := recentOutcomes(now.Add(10*time.Minute), 0); got != (Outcomes{Requests: 1}) {
+		t.Errorf("Expected a reset bucket, got %+v", got)
+	}
+}
+
+func TestLimiterUsage(t *testing.T) {
+	inUse, capacity := LimiterUsage()
+	if capacity != MaxConcurrentRequests() || inUse < 0 || inUse > capacity {
+		t.Errorf("Unexpected limiter usage %d/%d", inUse, capacity)
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/tracing"
+)
+
+// Cache outcomes recorded for upstream calls.
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// UpstreamCall describes one GetJSON call. Endpoint is the data call name,
+// for example "network-info".
+type UpstreamCall struct {
+	Endpoint string        `json:"endpoint"`
+	Cache    string        `json:"cache"`
+	QueryID  string        `json:"query_id,omitempty"`
+	Failed   bool          `json:"failed,omitempty"`
+	Latency  time.Duration `json:"-"`
+}
+
+// CallRecorder collects the upstream calls made with a context. It is safe
+// for concurrent use.
+type CallRecorder struct {
+	mu    sync.Mutex
+	calls []UpstreamCall
+}
+
+// Calls returns the recorded calls in the order they completed.
+func (r *CallRecorder) Calls() []UpstreamCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]UpstreamCall, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+func (r *CallRecorder) add(call UpstreamCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// contextKey is a custom type for context keys to avoid collisions.
+type contextKey string
+
+// recorderKey is the context key for storing the call recorder.
+const recorderKey contextKey = "call_recorder"
+
+// WithCallRecorder returns a context whose GetJSON calls are added to r.
+func WithCallRecorder(ctx context.Context, r *CallRecorder) context.Context {
+	return context.WithValue(ctx, recorderKey, r)
+}
+
+// recordCall adds call to the context's recorder, if any.
+func recordCall(ctx context.Context, call UpstreamCall) {
+	if r, ok := ctx.Value(recorderKey).(*CallRecorder); ok && r != nil {
+		r.add(call)
+	}
+}
+
+// queryID returns the RIPEstat query ID of a decoded response.
+func queryID(response interface{}) string {
+	if r, ok := response.(interface{ UpstreamQueryID() string }); ok {
+		return r.UpstreamQueryID()
+	}
+	return ""
+}
+
+// maxErrorBodySize bounds how much of an error response is read for its
+// query ID.
+const maxErrorBodySize = 64 << 10
+
+// errorQueryID returns the query ID of a RIPEstat error response body, if it
+// is JSON carrying one.
+func errorQueryID(body io.Reader) string {
+	var response struct {
+		QueryID string `json:"query_id"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, maxErrorBodySize)).Decode(&response); err != nil {
+		return ""
+	}
+	return response.QueryID
+}
+
+// traceUpstream adds the RIPEstat server, query ID and processing time of a
+// decoded response to span.
+func traceUpstream(span *tracing.Span, response interface{}) {
+	r, ok := response.(interface {
+		UpstreamQueryID() string
+		UpstreamServerID() string
+		UpstreamProcessTime() time.Duration
+	})
+	if !ok || r.UpstreamQueryID() == "" {
+		return
+	}
+
+	span.SetAttributes(
+		tracing.String("ripestat.query_id", r.UpstreamQueryID()),
+		tracing.String("ripestat.server_id", r.UpstreamServerID()),
+		tracing.Float64("ripestat.process_time_ms", milliseconds(r.UpstreamProcessTime())),
+	)
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// dataCallName returns the data call name of an endpoint path such as
+// "/data/network-info/data.json".
+func dataCallName(endpoint string) string {
+	name := strings.TrimPrefix(endpoint, "/data/")
+	name, _, _ = strings.Cut(name, "/")
+	return name
+}
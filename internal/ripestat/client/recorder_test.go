@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/types"
+)
+
+func TestGetJSON_RecordsCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/data/broken/data.json" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path == "/data/rejected/data.json" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"query_id": "20250618120000-bad", "status": "error"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"query_id": "20250618120000-abc", "status": "ok"}`)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, nil)
+	recorder := &CallRecorder{}
+	ctx := WithCallRecorder(context.Background(), recorder)
+	params := url.Values{"resource": {"193.0.0.0/21"}}
+
+	for i := 0; i < 2; i++ {
+		var response types.BaseResponse
+		if err := c.GetJSON(ctx, "/data/network-info/data.json", params, &response); err != nil {
+			t.Fatalf("GetJSON failed: %v", err)
+		}
+	}
+	var response types.BaseResponse
+	_ = c.GetJSON(ctx, "/data/broken/data.json", params, &response)
+	_ = c.GetJSON(ctx, "/data/rejected/data.json", params, &response)
+
+	// Calls without a recorder are not recorded anywhere.
+	if err := c.GetJSON(context.Background(), "/data/network-info/data.json", params, &response); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+
+	calls := recorder.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("Expected 4 recorded calls, got %+v", calls)
+	}
+
+	want := []UpstreamCall{
+		{Endpoint: "network-info", Cache: CacheMiss, QueryID: "20250618120000-abc"},
+		{Endpoint: "network-info", Cache: CacheHit, QueryID: "20250618120000-abc"},
+		{Endpoint: "broken", Cache: CacheMiss, Failed: true},
+		{Endpoint: "rejected", Cache: CacheMiss, QueryID: "20250618120000-bad", Failed: true},
+	}
+	for i, call := range calls {
+		call.Latency = 0
+		if call != want[i] {
+			t.Errorf("Call %d = %+v, want %+v", i, call, want[i])
+		}
+	}
+}
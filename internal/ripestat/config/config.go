@@ -3,6 +3,7 @@ package config
 
 import (
 	"os"
+	"sync"
 	"time"
 )
 
@@ -73,10 +74,47 @@ type Config struct {
 	ForceHTTP2           bool          // Force HTTP/2 usage (with fallback to HTTP/1.1)
 	HTTP2ReadIdleTimeout time.Duration // HTTP/2 read idle timeout
 	HTTP2PingTimeout     time.Duration // HTTP/2 ping timeout
+
+	// DisableCache turns off response caching for clients built from this config.
+	DisableCache bool
+}
+
+var (
+	overrideMu sync.RWMutex
+	override   *Config
+)
+
+// SetDefault replaces the configuration returned by DefaultConfig, so that
+// clients created afterwards use it. Passing nil restores the built-in defaults.
+func SetDefault(cfg *Config) {
+	overrideMu.Lock()
+	defer overrideMu.Unlock()
+
+	if cfg == nil {
+		override = nil
+		return
+	}
+
+	c := *cfg
+	override = &c
 }
 
-// DefaultConfig returns a new Config with default settings.
+// DefaultConfig returns a new Config with default settings, or a copy of the
+// configuration set with SetDefault.
 func DefaultConfig() *Config {
+	overrideMu.RLock()
+	if override != nil {
+		c := *override
+		overrideMu.RUnlock()
+		return &c
+	}
+	overrideMu.RUnlock()
+
+	return builtinConfig()
+}
+
+// builtinConfig returns the built-in default configuration.
+func builtinConfig() *Config {
 	sourceApp := os.Getenv("RIPE_SOURCE_APP")
 	if sourceApp == "" {
 		sourceApp = DefaultSourceApp
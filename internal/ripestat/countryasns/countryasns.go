@@ -58,7 +58,7 @@ func (c *Client) Get(ctx context.Context, resource string, opts *GetOptions) (*R
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get country ASNs: %w", err))
+		return nil, errors.Wrap(err, "failed to get country ASNs")
 	}
 
 	return &response, nil
@@ -2,6 +2,8 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 )
@@ -14,6 +16,28 @@ var (
 	ErrForbidden        = NewError("forbidden", http.StatusForbidden)
 	ErrServerError      = NewError("server error", http.StatusInternalServerError)
 	ErrTimeout          = NewError("request timed out", http.StatusGatewayTimeout)
+	ErrRateLimited      = NewError("rate limited by RIPEstat", http.StatusTooManyRequests)
+	ErrUnavailable      = NewError("RIPEstat unavailable", http.StatusServiceUnavailable)
+)
+
+// Kind classifies errors by how callers should react to them.
+type Kind string
+
+// Error kinds.
+const (
+	// KindInvalidInput means the request was rejected; retrying it unchanged
+	// fails again.
+	KindInvalidInput Kind = "invalid_input"
+	// KindNotFound means RIPEstat has no data for the resource.
+	KindNotFound Kind = "not_found"
+	// KindRateLimited means RIPEstat throttled the request; retry later.
+	KindRateLimited Kind = "rate_limited"
+	// KindUnavailable means RIPEstat failed or could not be reached.
+	KindUnavailable Kind = "upstream_unavailable"
+	// KindTimeout means the request did not complete in time.
+	KindTimeout Kind = "timeout"
+	// KindInternal covers everything else.
+	KindInternal Kind = "internal"
 )
 
 // Error represents a standardized error from the RIPEstat API client.
@@ -58,6 +82,71 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether target is the base error e was created from, so
+// errors.Is(err, ErrNotFound) matches errors created with WithError and Wrap.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Err == nil && t.Message == e.Message && t.StatusCode == e.StatusCode
+}
+
+// Kind returns the kind of the error, derived from its status code.
+func (e *Error) Kind() Kind {
+	switch code := e.StatusCode; {
+	case code == http.StatusBadRequest || code == http.StatusUnprocessableEntity:
+		return KindInvalidInput
+	case code == http.StatusNotFound:
+		return KindNotFound
+	case code == http.StatusTooManyRequests:
+		return KindRateLimited
+	case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+		return KindTimeout
+	case code >= 500 || code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return KindUnavailable
+	default:
+		return KindInternal
+	}
+}
+
+// KindOf returns the kind of err: that of the first *Error in its chain, or
+// KindTimeout for deadlines exceeded outside of one.
+func KindOf(err error) Kind {
+	var e *Error
+	switch {
+	case err == nil:
+		return ""
+	case stderrors.As(err, &e):
+		return e.Kind()
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	default:
+		return KindInternal
+	}
+}
+
+// Wrap adds message to err while keeping its classification. The result has
+// the base error of err, ErrTimeout for exceeded deadlines or ErrServerError
+// otherwise.
+func Wrap(err error, message string) *Error {
+	var e *Error
+	switch {
+	case stderrors.As(err, &e):
+		// Keep a single class prefix in the message.
+		if top, ok := err.(*Error); ok {
+			err = top.Err
+		}
+	case stderrors.Is(err, context.DeadlineExceeded):
+		e = ErrTimeout
+	default:
+		e = ErrServerError
+	}
+
+	wrapped := stderrors.New(message)
+	if err != nil {
+		wrapped = fmt.Errorf("%s: %w", message, err)
+	}
+	return &Error{Message: e.Message, StatusCode: e.StatusCode, Err: wrapped}
+}
+
 // FromHTTPResponse creates an appropriate error based on the HTTP response status code.
 func FromHTTPResponse(resp *http.Response, defaultMessage string) error {
 	var baseErr *Error
@@ -71,6 +160,10 @@ func FromHTTPResponse(resp *http.Response, defaultMessage string) error {
 		baseErr = ErrForbidden
 	case http.StatusNotFound:
 		baseErr = ErrNotFound
+	case http.StatusTooManyRequests:
+		baseErr = ErrRateLimited
+	case http.StatusServiceUnavailable:
+		baseErr = ErrUnavailable
 	case http.StatusGatewayTimeout:
 		baseErr = ErrTimeout
 	default:
@@ -83,3 +176,13 @@ func FromHTTPResponse(resp *http.Response, defaultMessage string) error {
 
 	return baseErr.WithError(fmt.Errorf("HTTP status: %d", resp.StatusCode))
 }
+
+// FromRequestError classifies an error from sending a request: timeouts
+// become ErrTimeout and other failures ErrUnavailable.
+func FromRequestError(err error) error {
+	var timeout interface{ Timeout() bool }
+	if stderrors.Is(err, context.DeadlineExceeded) || (stderrors.As(err, &timeout) && timeout.Timeout()) {
+		return ErrTimeout.WithError(fmt.Errorf("request failed: %w", err))
+	}
+	return ErrUnavailable.WithError(fmt.Errorf("request failed: %w", err))
+}
@@ -2,6 +2,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -92,6 +93,8 @@ func TestFromHTTPResponse(t *testing.T) {
 		{"Forbidden", http.StatusForbidden, "forbidden"},
 		{"NotFound", http.StatusNotFound, "resource not found"},
 		{"Timeout", http.StatusGatewayTimeout, "request timed out"},
+		{"TooManyRequests", http.StatusTooManyRequests, "rate limited by RIPEstat"},
+		{"Unavailable", http.StatusServiceUnavailable, "RIPEstat unavailable"},
 		{"ServerError", http.StatusInternalServerError, "server error"},
 		{"OtherClientError", 418, "custom message"}, // I'm a teapot
 	}
@@ -156,3 +159,79 @@ func TestPredefinedErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestError_Kind(t *testing.T) {
+	testCases := []struct {
+		err  *Error
+		kind Kind
+	}{
+		{ErrInvalidParameter, KindInvalidInput},
+		{ErrNotFound, KindNotFound},
+		{ErrRateLimited, KindRateLimited},
+		{ErrTimeout, KindTimeout},
+		{ErrServerError, KindUnavailable},
+		{ErrUnavailable, KindUnavailable},
+		{NewError("teapot", 418), KindInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.err.Message, func(t *testing.T) {
+			if kind := tc.err.Kind(); kind != tc.kind {
+				t.Errorf("Expected kind %q, got %q", tc.kind, kind)
+			}
+		})
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		kind Kind
+	}{
+		{"nil", nil, ""},
+		{"wrapped", fmt.Errorf("lookup: %w", ErrNotFound.WithError(errors.New("no data"))), KindNotFound},
+		{"deadline", fmt.Errorf("waiting: %w", context.DeadlineExceeded), KindTimeout},
+		{"other", errors.New("boom"), KindInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if kind := KindOf(tc.err); kind != tc.kind {
+				t.Errorf("Expected kind %q, got %q", tc.kind, kind)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	err := Wrap(ErrNotFound.WithError(errors.New("HTTP status: 404")), "failed to get AS overview")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the wrapped error to stay ErrNotFound, got %v", err)
+	}
+	if err.Error() != "resource not found: failed to get AS overview: HTTP status: 404" {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+
+	if err := Wrap(context.DeadlineExceeded, "failed"); !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected exceeded deadlines to become ErrTimeout, got %v", err)
+	}
+	if err := Wrap(errors.New("boom"), "failed"); !errors.Is(err, ErrServerError) {
+		t.Errorf("Expected unclassified errors to become ErrServerError, got %v", err)
+	}
+}
+
+// timeoutError is a network error reporting a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "i/o timeout" }
+func (timeoutError) Timeout() bool { return true }
+
+func TestFromRequestError(t *testing.T) {
+	if err := FromRequestError(timeoutError{}); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected timeouts to become ErrTimeout, got %v", err)
+	}
+	if err := FromRequestError(errors.New("connection refused")); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected other failures to become ErrUnavailable, got %v", err)
+	}
+}
@@ -1,151 +1,142 @@
-// Package logging provides standardized logging for the RIPEstat API client.
+// Package logging adapts log/slog for the RIPEstat client. Records go to the
+// process-wide slog handler, so client logs share the server's format,
+// destination and level, and carry the request-scoped attributes stored in
+// their context, such as the session and request IDs.
 package logging
 
 import (
-	"fmt"
-	"io"
-	"log"
-	"os"
-	"sync"
+	"context"
+	"log/slog"
+	"net/url"
+	"time"
 )
 
-// LogLevel represents the severity level of a log message.
-type LogLevel int
-
+// Attribute keys shared by log lines across packages.
 const (
-	// LogLevelDebug is the most verbose log level.
-	LogLevelDebug LogLevel = iota
-	// LogLevelInfo is for general operational information.
-	LogLevelInfo
-	// LogLevelWarning is for important but non-critical issues.
-	LogLevelWarning
-	// LogLevelError is for critical issues that require attention.
-	LogLevelError
-	// LogLevelNone disables all logging.
-	LogLevelNone
+	KeyEndpoint  = "endpoint"
+	KeyURL       = "url"
+	KeyDuration  = "duration_ms"
+	KeyStatus    = "status"
+	KeyQueryID   = "query_id"
+	KeySessionID = "session_id"
+	KeyRequestID = "request_id"
+	KeyRPCID     = "rpc_id"
 )
 
-// String returns the string representation of the log level.
-func (l LogLevel) String() string {
-	switch l {
-	case LogLevelDebug:
-		return "DEBUG"
-	case LogLevelInfo:
-		return "INFO"
-	case LogLevelWarning:
-		return "WARNING"
-	case LogLevelError:
-		return "ERROR"
-	case LogLevelNone:
-		return "NONE"
-	default:
-		return fmt.Sprintf("UNKNOWN(%d)", l)
-	}
+type contextKey struct{}
+
+// WithAttrs returns a copy of ctx whose log records carry attrs in addition
+// to the attributes already stored in ctx.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := AttrsFromContext(ctx)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, contextKey{}, combined)
 }
 
-// Logger is a simple logger for the RIPEstat API client.
-type Logger struct {
-	mu       sync.Mutex
-	level    LogLevel
-	debugLog *log.Logger
-	infoLog  *log.Logger
-	warnLog  *log.Logger
-	errorLog *log.Logger
-	writer   io.Writer
-}
-
-// NewLogger creates a new Logger with the specified log level and writer.
-// If writer is nil, os.Stderr is used.
-func NewLogger(level LogLevel, writer io.Writer) *Logger {
-	if writer == nil {
-		writer = os.Stderr
+// AttrsFromContext returns the attributes stored in ctx by WithAttrs.
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
 	}
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
 
-	return &Logger{
-		level:    level,
-		debugLog: log.New(writer, "[DEBUG] ", log.LstdFlags),
-		infoLog:  log.New(writer, "[INFO] ", log.LstdFlags),
-		warnLog:  log.New(writer, "[WARNING] ", log.LstdFlags),
-		errorLog: log.New(writer, "[ERROR] ", log.LstdFlags),
-		writer:   writer,
-	}
+// Handler wraps a slog.Handler and adds the attributes stored in the context
+// of each record, so that every line logged while serving a request names it.
+type Handler struct {
+	slog.Handler
 }
 
-// SetLevel sets the log level.
-func (l *Logger) SetLevel(level LogLevel) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	l.level = level
+// NewHandler returns a Handler writing through next.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{Handler: next}
 }
 
-// GetLevel returns the current log level.
-func (l *Logger) GetLevel() LogLevel {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := AttrsFromContext(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
 
-	return l.level
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
 }
 
-// Debug logs a debug message if the log level is LogLevelDebug or lower.
-func (l *Logger) Debug(format string, v ...interface{}) {
-	if l.GetLevel() <= LogLevelDebug {
-		l.mu.Lock()
-		defer l.mu.Unlock()
-		l.debugLog.Printf(format, v...)
-	}
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
 }
 
-// Info logs an info message if the log level is LogLevelInfo or lower.
-func (l *Logger) Info(format string, v ...interface{}) {
-	if l.GetLevel() <= LogLevelInfo {
-		l.mu.Lock()
-		defer l.mu.Unlock()
-		l.infoLog.Printf(format, v...)
-	}
+// Logger logs RIPEstat client events through slog. A nil Logger, or one
+// created with a nil slog.Logger, uses slog.Default at the time of each
+// call, so that the level set by the server applies.
+type Logger struct {
+	logger *slog.Logger
 }
 
-// Warning logs a warning message if the log level is LogLevelWarning or lower.
-func (l *Logger) Warning(format string, v ...interface{}) {
-	if l.GetLevel() <= LogLevelWarning {
-		l.mu.Lock()
-		defer l.mu.Unlock()
-		l.warnLog.Printf(format, v...)
-	}
+// New returns a Logger writing to logger, or to slog.Default when nil.
+func New(logger *slog.Logger) *Logger {
+	return &Logger{logger: logger}
 }
 
-// Error logs an error message if the log level is LogLevelError or lower.
-func (l *Logger) Error(format string, v ...interface{}) {
-	if l.GetLevel() <= LogLevelError {
-		l.mu.Lock()
-		defer l.mu.Unlock()
-		l.errorLog.Printf(format, v...)
+// DefaultLogger is the logger used by RIPEstat clients unless replaced.
+var DefaultLogger = New(nil)
+
+func (l *Logger) base() *slog.Logger {
+	if l == nil || l.logger == nil {
+		return slog.Default()
 	}
+	return l.logger
 }
 
-// DefaultLogger is the default logger used by the RIPEstat API client.
-var DefaultLogger = NewLogger(LogLevelInfo, nil)
+// Enabled reports whether records at level are written.
+func (l *Logger) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.base().Enabled(ctx, level)
+}
 
-// SetDefaultLogLevel sets the log level for the default logger.
-func SetDefaultLogLevel(level LogLevel) {
-	DefaultLogger.SetLevel(level)
+// Debug logs at debug level.
+func (l *Logger) Debug(ctx context.Context, msg string, args ...any) {
+	l.base().DebugContext(ctx, msg, args...)
 }
 
-// Debug logs a debug message to the default logger.
-func Debug(format string, v ...interface{}) {
-	DefaultLogger.Debug(format, v...)
+// Info logs at info level.
+func (l *Logger) Info(ctx context.Context, msg string, args ...any) {
+	l.base().InfoContext(ctx, msg, args...)
 }
 
-// Info logs an info message to the default logger.
-func Info(format string, v ...interface{}) {
-	DefaultLogger.Info(format, v...)
+// Warn logs at warning level.
+func (l *Logger) Warn(ctx context.Context, msg string, args ...any) {
+	l.base().WarnContext(ctx, msg, args...)
 }
 
-// Warning logs a warning message to the default logger.
-func Warning(format string, v ...interface{}) {
-	DefaultLogger.Warning(format, v...)
+// Error logs at error level.
+func (l *Logger) Error(ctx context.Context, msg string, args ...any) {
+	l.base().ErrorContext(ctx, msg, args...)
+}
+
+// URL returns the url attribute for u without the sourceapp parameter, which
+// identifies the deployment rather than the query.
+func URL(u *url.URL) slog.Attr {
+	if u == nil {
+		return slog.String(KeyURL, "")
+	}
+	redacted := *u
+	query := redacted.Query()
+	if query.Has("sourceapp") {
+		query.Del("sourceapp")
+		redacted.RawQuery = query.Encode()
+	}
+	return slog.String(KeyURL, redacted.String())
 }
 
-// Error logs an error message to the default logger.
-func Error(format string, v ...interface{}) {
-	DefaultLogger.Error(format, v...)
+// Duration returns the duration attribute for d in milliseconds.
+func Duration(d time.Duration) slog.Attr {
+	return slog.Float64(KeyDuration, float64(d.Microseconds())/1000)
 }
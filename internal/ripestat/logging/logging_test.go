@@ -2,192 +2,116 @@ package logging
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
-func TestLogLevel_String(t *testing.T) {
-	testCases := []struct {
-		level    LogLevel
-		expected string
-	}{
-		{LogLevelDebug, "DEBUG"},
-		{LogLevelInfo, "INFO"},
-		{LogLevelWarning, "WARNING"},
-		{LogLevelError, "ERROR"},
-		{LogLevelNone, "NONE"},
-		{LogLevel(99), "UNKNOWN(99)"},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.expected, func(t *testing.T) {
-			if tc.level.String() != tc.expected {
-				t.Errorf("Expected %q, got %q", tc.expected, tc.level.String())
-			}
-		})
-	}
-}
-
-func TestLogger_SetLevel(t *testing.T) {
-	logger := NewLogger(LogLevelInfo, nil)
-
-	if logger.GetLevel() != LogLevelInfo {
-		t.Errorf("Expected initial level to be INFO, got %v", logger.GetLevel())
-	}
-
-	logger.SetLevel(LogLevelDebug)
-	if logger.GetLevel() != LogLevelDebug {
-		t.Errorf("Expected level to be DEBUG after SetLevel, got %v", logger.GetLevel())
-	}
+// decode returns the JSON log lines in buf.
+func decode(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
 }
 
-func TestLogger_Debug(t *testing.T) {
+func TestHandler_ContextAttrs(t *testing.T) {
 	var buf bytes.Buffer
-	logger := NewLogger(LogLevelDebug, &buf)
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
 
-	logger.Debug("test debug message")
-	if !strings.Contains(buf.String(), "test debug message") {
-		t.Errorf("Expected debug message to be logged, got %q", buf.String())
-	}
+	ctx := WithAttrs(context.Background(), slog.String(KeySessionID, "s1"))
+	ctx = WithAttrs(ctx, slog.Int(KeyRPCID, 7))
+	logger.InfoContext(ctx, "with context")
+	logger.Info("without context")
 
-	buf.Reset()
-	logger.SetLevel(LogLevelInfo)
-	logger.Debug("this should not be logged")
-	if buf.String() != "" {
-		t.Errorf("Expected no debug message when level is INFO, got %q", buf.String())
+	lines := decode(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
 	}
-}
-
-func TestLogger_Info(t *testing.T) {
-	var buf bytes.Buffer
-	logger := NewLogger(LogLevelInfo, &buf)
-
-	logger.Info("test info message")
-	if !strings.Contains(buf.String(), "test info message") {
-		t.Errorf("Expected info message to be logged, got %q", buf.String())
+	if lines[0][KeySessionID] != "s1" || lines[0][KeyRPCID] != float64(7) || lines[0]["component"] != "test" {
+		t.Errorf("Expected context attributes, got %v", lines[0])
 	}
-
-	buf.Reset()
-	logger.SetLevel(LogLevelWarning)
-	logger.Info("this should not be logged")
-	if buf.String() != "" {
-		t.Errorf("Expected no info message when level is WARNING, got %q", buf.String())
+	if _, ok := lines[1][KeySessionID]; ok {
+		t.Errorf("Expected no session without context, got %v", lines[1])
 	}
-}
-
-func TestLogger_Warning(t *testing.T) {
-	var buf bytes.Buffer
-	logger := NewLogger(LogLevelWarning, &buf)
-
-	logger.Warning("test warning message")
 
-	if !strings.Contains(buf.String(), "test warning message") {
-		t.Errorf("Expected warning message to be logged, got %q", buf.String())
+	// Attributes added later do not leak into the parent context.
+	if attrs := AttrsFromContext(WithAttrs(context.Background(), slog.String("a", "1"))); len(attrs) != 1 {
+		t.Errorf("Expected one attribute, got %v", attrs)
 	}
-
-	buf.Reset()
-	logger.SetLevel(LogLevelError)
-	logger.Warning("this should not be logged")
-	if buf.String() != "" {
-		t.Errorf("Expected no warning message when level is ERROR, got %q", buf.String())
+	if attrs := AttrsFromContext(context.Background()); attrs != nil {
+		t.Errorf("Expected no attributes, got %v", attrs)
 	}
 }
 
-func TestLogger_Error(t *testing.T) {
+func TestLogger(t *testing.T) {
 	var buf bytes.Buffer
-	logger := NewLogger(LogLevelError, &buf)
+	level := new(slog.LevelVar)
+	logger := New(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+
+	ctx := context.Background()
+	logger.Debug(ctx, "hidden")
+	logger.Info(ctx, "info", KeyEndpoint, "whois")
+	logger.Warn(ctx, "warn")
+	logger.Error(ctx, "error")
 
-	logger.Error("test error message")
-	if !strings.Contains(buf.String(), "test error message") {
-		t.Errorf("Expected error message to be logged, got %q", buf.String())
+	lines := decode(t, &buf)
+	if len(lines) != 3 || lines[0]["msg"] != "info" || lines[0][KeyEndpoint] != "whois" || lines[2]["level"] != "ERROR" {
+		t.Errorf("Unexpected log lines %v", lines)
 	}
 
-	buf.Reset()
-	logger.SetLevel(LogLevelNone)
-	logger.Error("this should not be logged")
-	if buf.String() != "" {
-		t.Errorf("Expected no error message when level is NONE, got %q", buf.String())
+	// The level is controlled by the handler.
+	level.Set(slog.LevelDebug)
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		t.Error("Expected debug logging after lowering the level")
 	}
 }
 
-func TestDefaultLogger(t *testing.T) {
-	// Save the original default logger
-	originalLogger := DefaultLogger
-	defer func() {
-		DefaultLogger = originalLogger
-	}()
-
+func TestLogger_Default(t *testing.T) {
 	var buf bytes.Buffer
-	DefaultLogger = NewLogger(LogLevelDebug, &buf)
-
-	Debug("test debug function")
-
-	if !strings.Contains(buf.String(), "test debug function") {
-		t.Errorf("Expected debug message to be logged via Debug function, got %q", buf.String())
-	}
-
-	buf.Reset()
-	Info("test info function")
-	if !strings.Contains(buf.String(), "test info function") {
-		t.Errorf("Expected info message to be logged via Info function, got %q", buf.String())
-	}
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
 
-	buf.Reset()
-	Warning("test warning function")
-
-	if !strings.Contains(buf.String(), "test warning function") {
-		t.Errorf("Expected warning message to be logged via Warning function, got %q", buf.String())
-	}
+	var nilLogger *Logger
+	nilLogger.Info(context.Background(), "from nil")
+	DefaultLogger.Info(context.Background(), "from default")
 
-	buf.Reset()
-	Error("test error function")
-	if !strings.Contains(buf.String(), "test error function") {
-		t.Errorf("Expected error message to be logged via Error function, got %q", buf.String())
+	if lines := decode(t, &buf); len(lines) != 2 {
+		t.Errorf("Expected both loggers to use slog.Default, got %v", lines)
 	}
 }
 
-func TestSetDefaultLogLevel(t *testing.T) {
-	// Save the original default logger
-	originalLogger := DefaultLogger
-	defer func() {
-		DefaultLogger = originalLogger
-	}()
-
-	var buf bytes.Buffer
-	DefaultLogger = NewLogger(LogLevelDebug, &buf)
-
-	Debug("debug message before")
-	if !strings.Contains(buf.String(), "debug message before") {
-		t.Errorf("Expected debug message to be logged before changing level, got %q", buf.String())
+func TestURL(t *testing.T) {
+	u, _ := url.Parse("https://stat.ripe.net/data/whois/data.json?resource=193.0.0.0&sourceapp=secret-app")
+	if got := URL(u).Value.String(); got != "https://stat.ripe.net/data/whois/data.json?resource=193.0.0.0" {
+		t.Errorf("Expected sourceapp to be removed, got %s", got)
 	}
-
-	buf.Reset()
-	SetDefaultLogLevel(LogLevelError)
-
-	Debug("debug message after")
-	if buf.String() != "" {
-		t.Errorf("Expected no debug message after changing level to ERROR, got %q", buf.String())
+	if !strings.Contains(u.RawQuery, "sourceapp") {
+		t.Error("Expected the original URL to be unchanged")
 	}
 
-	buf.Reset()
-	Error("error message after")
-	if !strings.Contains(buf.String(), "error message after") {
-		t.Errorf("Expected error message to be logged after changing level to ERROR, got %q", buf.String())
+	plain, _ := url.Parse("https://stat.ripe.net/data/whois/data.json?b=2&a=1")
+	if got := URL(plain).Value.String(); got != plain.String() {
+		t.Errorf("Expected URL without sourceapp to be kept as is, got %s", got)
 	}
 }
 
-func TestLogger_FormatWithArgs(t *testing.T) {
-	var buf bytes.Buffer
-	logger := NewLogger(LogLevelDebug, &buf)
-
-	logger.Debug("test %s with %d args", "message", 2)
-	if !strings.Contains(buf.String(), "test message with 2 args") {
-		t.Errorf("Expected formatted debug message, got %q", buf.String())
-	}
-
-	buf.Reset()
-	logger.Info("info %s: %.2f", "value", 3.14159)
-	if !strings.Contains(buf.String(), "info value: 3.14") {
-		t.Errorf("Expected formatted info message, got %q", buf.String())
+func TestDuration(t *testing.T) {
+	if got := Duration(1500 * time.Microsecond); got.Key != KeyDuration || got.Value.Float64() != 1.5 {
+		t.Errorf("Unexpected duration attribute %v", got)
 	}
 }
@@ -60,7 +60,7 @@ func (c *Client) Get(ctx context.Context, resource string, lookBackLimit int) (*
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get looking glass information: %w", err))
+		return nil, errors.Wrap(err, "failed to get looking glass information")
 	}
 
 	// Transform the response to the expected API format
@@ -0,0 +1,355 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition
+// format written by WritePrometheus.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric types used in Family.Type.
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of latency histograms.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Label is a Prometheus label pair.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one line of a metric family. Suffix is appended to the family
+// name, for example "_bucket" for histogram buckets.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Family is a named group of samples sharing help text and type.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// counterVec is a set of counters partitioned by label values.
+type counterVec struct {
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+func newCounterVec(labels ...string) *counterVec {
+	return &counterVec{labels: labels, series: make(map[string]*counterSeries)}
+}
+
+// Add adds delta to the counter for the given label values.
+func (c *counterVec) Add(delta float64, values ...string) {
+	key := strings.Join(values, "\xff")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	series, ok := c.series[key]
+	if !ok {
+		series = &counterSeries{values: values}
+		c.series[key] = series
+	}
+	series.value += delta
+}
+
+// snapshot returns the label values and counts of every series, sorted by
+// label values.
+func (c *counterVec) snapshot() []counterSeries {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	series := make([]counterSeries, 0, len(c.series))
+	for _, s := range c.series {
+		series = append(series, *s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return lessValues(series[i].values, series[j].values)
+	})
+	return series
+}
+
+// family returns the counters as a metric family.
+func (c *counterVec) family(name, help string) Family {
+	f := Family{Name: name, Help: help, Type: TypeCounter}
+	for _, s := range c.snapshot() {
+		f.Samples = append(f.Samples, Sample{Labels: labelPairs(c.labels, s.values), Value: s.value})
+	}
+	return f
+}
+
+// histogramVec is a set of histograms partitioned by label values.
+type histogramVec struct {
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newHistogramVec(buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+// Observe records v in the histogram for the given label values.
+func (h *histogramVec) Observe(v float64, values ...string) {
+	key := strings.Join(values, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{values: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+
+	// Bucket counts are stored per bucket and made cumulative when written.
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		series.counts[i]++
+	}
+	series.count++
+	series.sum += v
+}
+
+// family returns the histograms as a metric family with cumulative buckets.
+func (h *histogramVec) family(name, help string) Family {
+	h.mu.Lock()
+	series := make([]histogramSeries, 0, len(h.series))
+	for _, s := range h.series {
+		copied := *s
+		copied.counts = append([]uint64(nil), s.counts...)
+		series = append(series, copied)
+	}
+	h.mu.Unlock()
+
+	sort.Slice(series, func(i, j int) bool {
+		return lessValues(series[i].values, series[j].values)
+	})
+
+	f := Family{Name: name, Help: help, Type: TypeHistogram}
+	for _, s := range series {
+		labels := labelPairs(h.labels, s.values)
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			f.Samples = append(f.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: append(append([]Label(nil), labels...), Label{Name: "le", Value: formatFloat(bound)}),
+				Value:  float64(cumulative),
+			})
+		}
+		f.Samples = append(f.Samples,
+			Sample{Suffix: "_bucket", Labels: append(append([]Label(nil), labels...), Label{Name: "le", Value: "+Inf"}), Value: float64(s.count)},
+			Sample{Suffix: "_sum", Labels: labels, Value: s.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(s.count)},
+		)
+	}
+	return f
+}
+
+func labelPairs(names, values []string) []Label {
+	labels := make([]Label, len(names))
+	for i, name := range names {
+		labels[i] = Label{Name: name, Value: values[i]}
+	}
+	return labels
+}
+
+func lessValues(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// Labelled metrics that have no expvar equivalent.
+var (
+	upstreamDuration = newHistogramVec(LatencyBuckets, "endpoint", "status")
+	cacheRequests    = newCounterVec("endpoint", "result")
+	rateLimitWait    = newHistogramVec(LatencyBuckets)
+	toolCalls        = newCounterVec("tool", "status")
+	toolDuration     = newHistogramVec(LatencyBuckets, "tool")
+	toolsInFlight    int64
+)
+
+// ObserveUpstreamRequest records the latency of a RIPEstat request. Status is
+// the HTTP status code, or "error" when no response was received.
+func ObserveUpstreamRequest(endpoint, status string, duration time.Duration) {
+	upstreamDuration.Observe(duration.Seconds(), endpoint, status)
+}
+
+// RecordCacheResult records a cache lookup for endpoint and increments the
+// overall cache hit or miss counter.
+func RecordCacheResult(endpoint string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+		RecordCacheHit()
+	} else {
+		RecordCacheMiss()
+	}
+	cacheRequests.Add(1, endpoint, result)
+}
+
+// ObserveRateLimitWait records the time spent waiting for a rate limiter slot
+// and increments the rate limit wait counter.
+func ObserveRateLimitWait(duration time.Duration) {
+	RecordRateLimitWait()
+	rateLimitWait.Observe(duration.Seconds())
+}
+
+// StartToolCall increments the in-flight tool call gauge.
+func StartToolCall() {
+	atomic.AddInt64(&toolsInFlight, 1)
+}
+
+// EndToolCall decrements the in-flight tool call gauge and records the
+// outcome and latency of a tool call.
+func EndToolCall(tool, status string, duration time.Duration) {
+	atomic.AddInt64(&toolsInFlight, -1)
+	toolCalls.Add(1, tool, status)
+	toolDuration.Observe(duration.Seconds(), tool)
+}
+
+// PrometheusFamilies returns the client and tool call metrics as Prometheus
+// metric families.
+func PrometheusFamilies() []Family {
+	cache := cacheRequests.family("ripe_cache_requests_total", "Cache lookups by endpoint and result.")
+
+	// Hit ratios are derived from the lookup counters, per endpoint.
+	ratio := Family{Name: "ripe_cache_hit_ratio", Help: "Fraction of cache lookups served from cache, by endpoint.", Type: TypeGauge}
+	lookups := make(map[string][2]float64)
+	var endpoints []string
+	for _, s := range cache.Samples {
+		endpoint := s.Labels[0].Value
+		counts, ok := lookups[endpoint]
+		if !ok {
+			endpoints = append(endpoints, endpoint)
+		}
+		if s.Labels[1].Value == "hit" {
+			counts[0] += s.Value
+		}
+		counts[1] += s.Value
+		lookups[endpoint] = counts
+	}
+	for _, endpoint := range endpoints {
+		counts := lookups[endpoint]
+		ratio.Samples = append(ratio.Samples, Sample{
+			Labels: []Label{{Name: "endpoint", Value: endpoint}},
+			Value:  counts[0] / counts[1],
+		})
+	}
+
+	return []Family{
+		upstreamDuration.family("ripe_upstream_request_duration_seconds", "Latency of RIPEstat requests by endpoint and status."),
+		gauge("ripe_upstream_requests_in_flight", "RIPEstat requests in flight.", float64(GetInFlightCount())),
+		gauge("ripe_daily_request_count", "RIPEstat requests made in the current 24 hour window.", float64(GetDailyRequestCount())),
+		cache,
+		ratio,
+		gauge("ripe_cache_entries", "Entries in the response cache.", float64(globalMetrics.CacheTotalEntries.Value())),
+		rateLimitWait.family("ripe_rate_limit_wait_seconds", "Time spent waiting for a rate limiter slot."),
+		counter("ripe_rate_limit_timeouts_total", "Requests abandoned while waiting for a rate limiter slot.", float64(globalMetrics.RateLimitTimeouts.Value())),
+		toolCalls.family("mcp_tool_calls_total", "Tool calls by tool and status."),
+		toolDuration.family("mcp_tool_call_duration_seconds", "Latency of tool calls by tool."),
+		gauge("mcp_tool_calls_in_flight", "Tool calls in flight.", float64(atomic.LoadInt64(&toolsInFlight))),
+	}
+}
+
+func gauge(name, help string, value float64) Family {
+	return Family{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Value: value}}}
+}
+
+func counter(name, help string, value float64) Family {
+	return Family{Name: name, Help: help, Type: TypeCounter, Samples: []Sample{{Value: value}}}
+}
+
+// WritePrometheus writes families in the Prometheus text exposition format.
+func WritePrometheus(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name)
+			bw.WriteString(s.Suffix)
+			if len(s.Labels) > 0 {
+				bw.WriteByte('{')
+				for i, label := range s.Labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", label.Name, escapeLabelValue(label.Value))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogramVec(t *testing.T) {
+	h := newHistogramVec([]float64{0.1, 1}, "endpoint")
+	h.Observe(0.05, "whois")
+	h.Observe(0.1, "whois")
+	h.Observe(0.5, "whois")
+	h.Observe(2, "whois")
+
+	f := h.family("latency_seconds", "Latency.")
+	want := []struct {
+		suffix string
+		le     string
+		value  float64
+	}{
+		{"_bucket", "0.1", 2},
+		{"_bucket", "1", 3},
+		{"_bucket", "+Inf", 4},
+		{"_sum", "", 2.65},
+		{"_count", "", 4},
+	}
+	if len(f.Samples) != len(want) {
+		t.Fatalf("Expected %d samples, got %d", len(want), len(f.Samples))
+	}
+	for i, w := range want {
+		s := f.Samples[i]
+		if s.Suffix != w.suffix || s.Value != w.value {
+			t.Errorf("Sample %d = %+v, want %+v", i, s, w)
+		}
+		if s.Labels[0] != (Label{Name: "endpoint", Value: "whois"}) {
+			t.Errorf("Sample %d has labels %v", i, s.Labels)
+		}
+		if w.le != "" && s.Labels[len(s.Labels)-1] != (Label{Name: "le", Value: w.le}) {
+			t.Errorf("Sample %d has labels %v, want le=%s", i, s.Labels, w.le)
+		}
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	c := newCounterVec("tool", "status")
+	c.Add(1, "getWhois", "ok")
+	c.Add(2, "getWhois", "ok")
+	c.Add(1, "get\"Odd\\\n", "error")
+
+	var buf bytes.Buffer
+	families := []Family{
+		c.family("calls_total", "Calls by tool\nand status."),
+		gauge("in_flight", "In flight.", 3),
+	}
+	if err := WritePrometheus(&buf, families); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+
+	want := `# HELP calls_total Calls by tool\nand status.
+# TYPE calls_total counter
+calls_total{tool="get\"Odd\\\n",status="error"} 1
+calls_total{tool="getWhois",status="ok"} 3
+# HELP in_flight In flight.
+# TYPE in_flight gauge
+in_flight 3
+`
+	if buf.String() != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestPrometheusFamilies(t *testing.T) {
+	RecordCacheResult("test-endpoint", true)
+	RecordCacheResult("test-endpoint", false)
+	RecordCacheResult("test-endpoint", false)
+	RecordCacheResult("test-endpoint", false)
+	ObserveUpstreamRequest("test-endpoint", "200", 30*time.Millisecond)
+	ObserveRateLimitWait(time.Millisecond)
+	StartToolCall()
+	EndToolCall("getWhois", "ok", 40*time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, PrometheusFamilies()); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	output := buf.String()
+
+	for _, line := range []string{
+		`ripe_upstream_request_duration_seconds_bucket{endpoint="test-endpoint",status="200",le="0.05"} 1`,
+		`ripe_cache_requests_total{endpoint="test-endpoint",result="hit"} 1`,
+		`ripe_cache_requests_total{endpoint="test-endpoint",result="miss"} 3`,
+		`ripe_cache_hit_ratio{endpoint="test-endpoint"} 0.25`,
+		`mcp_tool_calls_total{tool="getWhois",status="ok"} 1`,
+		`mcp_tool_calls_in_flight 0`,
+		"# TYPE ripe_rate_limit_wait_seconds histogram",
+		"# TYPE ripe_upstream_requests_in_flight gauge",
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected line %q in output:\n%s", line, output)
+		}
+	}
+}
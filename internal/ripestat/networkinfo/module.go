@@ -49,7 +49,7 @@ func (m *Module) handleGetNetworkInfo(ctx context.Context, params interface{}) (
 	// Make the API request
 	var response Response
 	if err := m.Client().GetJSON(ctx, m.EndpointPath(), urlParams, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get network information: %w", err))
+		return nil, errors.Wrap(err, "failed to get network information")
 	}
 
 	return &response, nil
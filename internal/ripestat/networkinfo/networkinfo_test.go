@@ -56,6 +56,25 @@ func TestClient_Get_Success(t *testing.T) {
 	}
 }
 
+func TestClient_Get_Unannounced(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"asns":[],"prefix":""},"query_id":"test-id","status":"ok","status_code":200}`))
+	}))
+	defer ts.Close()
+
+	networkInfoClient := NewClient(client.New(ts.URL, ts.Client()))
+
+	// An address no routed prefix covers is a valid answer, not a missing resource.
+	resp, err := networkInfoClient.Get(context.Background(), "192.0.2.1")
+	if err != nil {
+		t.Fatalf("expected no error for an unannounced address, got %v", err)
+	}
+	if resp.Data.Prefix != "" || len(resp.Data.ASNs) != 0 {
+		t.Errorf("expected no prefix or ASNs, got %+v", resp.Data)
+	}
+}
+
 func TestClient_Get_HTTPError(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusBadGateway)
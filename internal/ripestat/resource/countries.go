@@ -0,0 +1,261 @@
+package resource
+
+// Country is an ISO 3166-1 alpha-2 country code with its English short name.
+type Country struct {
+	Code string
+	Name string
+}
+
+// Countries is the ISO 3166-1 country table, sorted by code. Country code
+// resources must appear in it.
+var Countries = []Country{
+	{Code: "ad", Name: "Andorra"},
+	{Code: "ae", Name: "United Arab Emirates"},
+	{Code: "af", Name: "Afghanistan"},
+	{Code: "ag", Name: "Antigua and Barbuda"},
+	{Code: "ai", Name: "Anguilla"},
+	{Code: "al", Name: "Albania"},
+	{Code: "am", Name: "Armenia"},
+	{Code: "ao", Name: "Angola"},
+	{Code: "aq", Name: "Antarctica"},
+	{Code: "ar", Name: "Argentina"},
+	{Code: "as", Name: "American Samoa"},
+	{Code: "at", Name: "Austria"},
+	{Code: "au", Name: "Australia"},
+	{Code: "aw", Name: "Aruba"},
+	{Code: "ax", Name: "Åland Islands"},
+	{Code: "az", Name: "Azerbaijan"},
+	{Code: "ba", Name: "Bosnia and Herzegovina"},
+	{Code: "bb", Name: "Barbados"},
+	{Code: "bd", Name: "Bangladesh"},
+	{Code: "be", Name: "Belgium"},
+	{Code: "bf", Name: "Burkina Faso"},
+	{Code: "bg", Name: "Bulgaria"},
+	{Code: "bh", Name: "Bahrain"},
+	{Code: "bi", Name: "Burundi"},
+	{Code: "bj", Name: "Benin"},
+	{Code: "bl", Name: "Saint Barthélemy"},
+	{Code: "bm", Name: "Bermuda"},
+	{Code: "bn", Name: "Brunei Darussalam"},
+	{Code: "bo", Name: "Bolivia"},
+	{Code: "bq", Name: "Bonaire, Sint Eustatius and Saba"},
+	{Code: "br", Name: "Brazil"},
+	{Code: "bs", Name: "Bahamas"},
+	{Code: "bt", Name: "Bhutan"},
+	{Code: "bv", Name: "Bouvet Island"},
+	{Code: "bw", Name: "Botswana"},
+	{Code: "by", Name: "Belarus"},
+	{Code: "bz", Name: "Belize"},
+	{Code: "ca", Name: "Canada"},
+	{Code: "cc", Name: "Cocos (Keeling) Islands"},
+	{Code: "cd", Name: "Congo, The Democratic Republic of the"},
+	{Code: "cf", Name: "Central African Republic"},
+	{Code: "cg", Name: "Congo"},
+	{Code: "ch", Name: "Switzerland"},
+	{Code: "ci", Name: "Côte d'Ivoire"},
+	{Code: "ck", Name: "Cook Islands"},
+	{Code: "cl", Name: "Chile"},
+	{Code: "cm", Name: "Cameroon"},
+	{Code: "cn", Name: "China"},
+	{Code: "co", Name: "Colombia"},
+	{Code: "cr", Name: "Costa Rica"},
+	{Code: "cu", Name: "Cuba"},
+	{Code: "cv", Name: "Cabo Verde"},
+	{Code: "cw", Name: "Curaçao"},
+	{Code: "cx", Name: "Christmas Island"},
+	{Code: "cy", Name: "Cyprus"},
+	{Code: "cz", Name: "Czechia"},
+	{Code: "de", Name: "Germany"},
+	{Code: "dj", Name: "Djibouti"},
+	{Code: "dk", Name: "Denmark"},
+	{Code: "dm", Name: "Dominica"},
+	{Code: "do", Name: "Dominican Republic"},
+	{Code: "dz", Name: "Algeria"},
+	{Code: "ec", Name: "Ecuador"},
+	{Code: "ee", Name: "Estonia"},
+	{Code: "eg", Name: "Egypt"},
+	{Code: "eh", Name: "Western Sahara"},
+	{Code: "er", Name: "Eritrea"},
+	{Code: "es", Name: "Spain"},
+	{Code: "et", Name: "Ethiopia"},
+	{Code: "fi", Name: "Finland"},
+	{Code: "fj", Name: "Fiji"},
+	{Code: "fk", Name: "Falkland Islands (Malvinas)"},
+	{Code: "fm", Name: "Micronesia, Federated States of"},
+	{Code: "fo", Name: "Faroe Islands"},
+	{Code: "fr", Name: "France"},
+	{Code: "ga", Name: "Gabon"},
+	{Code: "gb", Name: "United Kingdom"},
+	{Code: "gd", Name: "Grenada"},
+	{Code: "ge", Name: "Georgia"},
+	{Code: "gf", Name: "French Guiana"},
+	{Code: "gg", Name: "Guernsey"},
+	{Code: "gh", Name: "Ghana"},
+	{Code: "gi", Name: "Gibraltar"},
+	{Code: "gl", Name: "Greenland"},
+	{Code: "gm", Name: "Gambia"},
+	{Code: "gn", Name: "Guinea"},
+	{Code: "gp", Name: "Guadeloupe"},
+	{Code: "gq", Name: "Equatorial Guinea"},
+	{Code: "gr", Name: "Greece"},
+	{Code: "gs", Name: "South Georgia and the South Sandwich Islands"},
+	{Code: "gt", Name: "Guatemala"},
+	{Code: "gu", Name: "Guam"},
+	{Code: "gw", Name: "Guinea-Bissau"},
+	{Code: "gy", Name: "Guyana"},
+	{Code: "hk", Name: "Hong Kong"},
+	{Code: "hm", Name: "Heard Island and McDonald Islands"},
+	{Code: "hn", Name: "Honduras"},
+	{Code: "hr", Name: "Croatia"},
+	{Code: "ht", Name: "Haiti"},
+	{Code: "hu", Name: "Hungary"},
+	{Code: "id", Name: "Indonesia"},
+	{Code: "ie", Name: "Ireland"},
+	{Code: "il", Name: "Israel"},
+	{Code: "im", Name: "Isle of Man"},
+	{Code: "in", Name: "India"},
+	{Code: "io", Name: "British Indian Ocean Territory"},
+	{Code: "iq", Name: "Iraq"},
+	{Code: "ir", Name: "Iran"},
+	{Code: "is", Name: "Iceland"},
+	{Code: "it", Name: "Italy"},
+	{Code: "je", Name: "Jersey"},
+	{Code: "jm", Name: "Jamaica"},
+	{Code: "jo", Name: "Jordan"},
+	{Code: "jp", Name: "Japan"},
+	{Code: "ke", Name: "Kenya"},
+	{Code: "kg", Name: "Kyrgyzstan"},
+	{Code: "kh", Name: "Cambodia"},
+	{Code: "ki", Name: "Kiribati"},
+	{Code: "km", Name: "Comoros"},
+	{Code: "kn", Name: "Saint Kitts and Nevis"},
+	{Code: "kp", Name: "North Korea"},
+	{Code: "kr", Name: "South Korea"},
+	{Code: "kw", Name: "Kuwait"},
+	{Code: "ky", Name: "Cayman Islands"},
+	{Code: "kz", Name: "Kazakhstan"},
+	{Code: "la", Name: "Laos"},
+	{Code: "lb", Name: "Lebanon"},
+	{Code: "lc", Name: "Saint Lucia"},
+	{Code: "li", Name: "Liechtenstein"},
+	{Code: "lk", Name: "Sri Lanka"},
+	{Code: "lr", Name: "Liberia"},
+	{Code: "ls", Name: "Lesotho"},
+	{Code: "lt", Name: "Lithuania"},
+	{Code: "lu", Name: "Luxembourg"},
+	{Code: "lv", Name: "Latvia"},
+	{Code: "ly", Name: "Libya"},
+	{Code: "ma", Name: "Morocco"},
+	{Code: "mc", Name: "Monaco"},
+	{Code: "md", Name: "Moldova"},
+	{Code: "me", Name: "Montenegro"},
+	{Code: "mf", Name: "Saint Martin (French part)"},
+	{Code: "mg", Name: "Madagascar"},
+	{Code: "mh", Name: "Marshall Islands"},
+	{Code: "mk", Name: "North Macedonia"},
+	{Code: "ml", Name: "Mali"},
+	{Code: "mm", Name: "Myanmar"},
+	{Code: "mn", Name: "Mongolia"},
+	{Code: "mo", Name: "Macao"},
+	{Code: "mp", Name: "Northern Mariana Islands"},
+	{Code: "mq", Name: "Martinique"},
+	{Code: "mr", Name: "Mauritania"},
+	{Code: "ms", Name: "Montserrat"},
+	{Code: "mt", Name: "Malta"},
+	{Code: "mu", Name: "Mauritius"},
+	{Code: "mv", Name: "Maldives"},
+	{Code: "mw", Name: "Malawi"},
+	{Code: "mx", Name: "Mexico"},
+	{Code: "my", Name: "Malaysia"},
+	{Code: "mz", Name: "Mozambique"},
+	{Code: "na", Name: "Namibia"},
+	{Code: "nc", Name: "New Caledonia"},
+	{Code: "ne", Name: "Niger"},
+	{Code: "nf", Name: "Norfolk Island"},
+	{Code: "ng", Name: "Nigeria"},
+	{Code: "ni", Name: "Nicaragua"},
+	{Code: "nl", Name: "Netherlands"},
+	{Code: "no", Name: "Norway"},
+	{Code: "np", Name: "Nepal"},
+	{Code: "nr", Name: "Nauru"},
+	{Code: "nu", Name: "Niue"},
+	{Code: "nz", Name: "New Zealand"},
+	{Code: "om", Name: "Oman"},
+	{Code: "pa", Name: "Panama"},
+	{Code: "pe", Name: "Peru"},
+	{Code: "pf", Name: "French Polynesia"},
+	{Code: "pg", Name: "Papua New Guinea"},
+	{Code: "ph", Name: "Philippines"},
+	{Code: "pk", Name: "Pakistan"},
+	{Code: "pl", Name: "Poland"},
+	{Code: "pm", Name: "Saint Pierre and Miquelon"},
+	{Code: "pn", Name: "Pitcairn"},
+	{Code: "pr", Name: "Puerto Rico"},
+	{Code: "ps", Name: "Palestine, State of"},
+	{Code: "pt", Name: "Portugal"},
+	{Code: "pw", Name: "Palau"},
+	{Code: "py", Name: "Paraguay"},
+	{Code: "qa", Name: "Qatar"},
+	{Code: "re", Name: "Réunion"},
+	{Code: "ro", Name: "Romania"},
+	{Code: "rs", Name: "Serbia"},
+	{Code: "ru", Name: "Russian Federation"},
+	{Code: "rw", Name: "Rwanda"},
+	{Code: "sa", Name: "Saudi Arabia"},
+	{Code: "sb", Name: "Solomon Islands"},
+	{Code: "sc", Name: "Seychelles"},
+	{Code: "sd", Name: "Sudan"},
+	{Code: "se", Name: "Sweden"},
+	{Code: "sg", Name: "Singapore"},
+	{Code: "sh", Name: "Saint Helena, Ascension and Tristan da Cunha"},
+	{Code: "si", Name: "Slovenia"},
+	{Code: "sj", Name: "Svalbard and Jan Mayen"},
+	{Code: "sk", Name: "Slovakia"},
+	{Code: "sl", Name: "Sierra Leone"},
+	{Code: "sm", Name: "San Marino"},
+	{Code: "sn", Name: "Senegal"},
+	{Code: "so", Name: "Somalia"},
+	{Code: "sr", Name: "Suriname"},
+	{Code: "ss", Name: "South Sudan"},
+	{Code: "st", Name: "Sao Tome and Principe"},
+	{Code: "sv", Name: "El Salvador"},
+	{Code: "sx", Name: "Sint Maarten (Dutch part)"},
+	{Code: "sy", Name: "Syria"},
+	{Code: "sz", Name: "Eswatini"},
+	{Code: "tc", Name: "Turks and Caicos Islands"},
+	{Code: "td", Name: "Chad"},
+	{Code: "tf", Name: "French Southern Territories"},
+	{Code: "tg", Name: "Togo"},
+	{Code: "th", Name: "Thailand"},
+	{Code: "tj", Name: "Tajikistan"},
+	{Code: "tk", Name: "Tokelau"},
+	{Code: "tl", Name: "Timor-Leste"},
+	{Code: "tm", Name: "Turkmenistan"},
+	{Code: "tn", Name: "Tunisia"},
+	{Code: "to", Name: "Tonga"},
+	{Code: "tr", Name: "Türkiye"},
+	{Code: "tt", Name: "Trinidad and Tobago"},
+	{Code: "tv", Name: "Tuvalu"},
+	{Code: "tw", Name: "Taiwan"},
+	{Code: "tz", Name: "Tanzania"},
+	{Code: "ua", Name: "Ukraine"},
+	{Code: "ug", Name: "Uganda"},
+	{Code: "um", Name: "United States Minor Outlying Islands"},
+	{Code: "us", Name: "United States"},
+	{Code: "uy", Name: "Uruguay"},
+	{Code: "uz", Name: "Uzbekistan"},
+	{Code: "va", Name: "Holy See (Vatican City State)"},
+	{Code: "vc", Name: "Saint Vincent and the Grenadines"},
+	{Code: "ve", Name: "Venezuela"},
+	{Code: "vg", Name: "Virgin Islands, British"},
+	{Code: "vi", Name: "Virgin Islands, U.S."},
+	{Code: "vn", Name: "Vietnam"},
+	{Code: "vu", Name: "Vanuatu"},
+	{Code: "wf", Name: "Wallis and Futuna"},
+	{Code: "ws", Name: "Samoa"},
+	{Code: "ye", Name: "Yemen"},
+	{Code: "yt", Name: "Mayotte"},
+	{Code: "za", Name: "South Africa"},
+	{Code: "zm", Name: "Zambia"},
+	{Code: "zw", Name: "Zimbabwe"},
+}
@@ -0,0 +1,336 @@
+// Package resource classifies, validates and canonicalizes the resources
+// passed to RIPEstat: AS numbers, IP addresses, prefixes, address ranges,
+// hostnames and country codes.
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a resource.
+type Kind string
+
+// Resource kinds.
+const (
+	KindASN      Kind = "asn"
+	KindIPv4     Kind = "ipv4"
+	KindIPv6     Kind = "ipv6"
+	KindPrefix   Kind = "prefix"
+	KindRange    Kind = "range"
+	KindHostname Kind = "hostname"
+	KindCountry  Kind = "country"
+)
+
+// Kinds lists every resource kind.
+var Kinds = []Kind{KindASN, KindIPv4, KindIPv6, KindPrefix, KindRange, KindHostname, KindCountry}
+
+// Description returns the kind as used in error messages, e.g. "an IPv4 address".
+func (k Kind) Description() string {
+	switch k {
+	case KindASN:
+		return "an AS number"
+	case KindIPv4:
+		return "an IPv4 address"
+	case KindIPv6:
+		return "an IPv6 address"
+	case KindPrefix:
+		return "a prefix"
+	case KindRange:
+		return "an address range"
+	case KindHostname:
+		return "a hostname"
+	case KindCountry:
+		return "a country code"
+	default:
+		return string(k)
+	}
+}
+
+// maxASN is the largest 32-bit AS number.
+const maxASN = 1<<32 - 1
+
+// reservedASNs are AS numbers that can never identify a network: 0 (RFC 7607),
+// AS_TRANS (RFC 6793), and the last 16-bit and 32-bit numbers (RFC 7300).
+var reservedASNs = map[uint64]string{
+	0:      "reserved (RFC 7607)",
+	23456:  "AS_TRANS, reserved for 4-byte AS transition (RFC 6793)",
+	65535:  "reserved (RFC 7300)",
+	maxASN: "reserved (RFC 7300)",
+}
+
+// ErrEmpty is returned for an empty resource.
+var ErrEmpty = errors.New("resource is empty")
+
+// Resource is a parsed resource.
+type Resource struct {
+	Kind Kind
+	// Value is the canonical form of the resource: "AS3333", a compressed
+	// address, a prefix with its host bits cleared, "first-last" for ranges,
+	// and lowercase hostnames and country codes.
+	Value string
+}
+
+// String returns the canonical form of the resource.
+func (r Resource) String() string {
+	return r.Value
+}
+
+// Parse classifies input and returns its canonical form. URLs are reduced to
+// their host, which must be a hostname or an IP address. Input that looks
+// like a kind but is not a valid one, such as a reserved AS number or a
+// prefix with a bad mask, is rejected with an error naming the problem.
+func Parse(input string) (Resource, error) {
+	value := strings.TrimSpace(input)
+	if value == "" {
+		return Resource{}, ErrEmpty
+	}
+
+	switch {
+	case strings.Contains(value, "://"):
+		return parseURL(value)
+	case strings.Contains(value, "/"):
+		return parsePrefix(value)
+	case strings.Contains(value, "-") && looksLikeRange(value):
+		return parseRange(value)
+	case strings.Contains(value, ":"):
+		return parseAddress(value)
+	case isASN(value):
+		return parseASN(value)
+	case isDottedNumeric(value):
+		return parseAddress(value)
+	case len(value) == 2 && isLetters(value):
+		return parseCountry(value)
+	case strings.Contains(value, "."):
+		return parseHostname(value)
+	default:
+		return Resource{}, fmt.Errorf("%q is not an AS number, IP address, prefix, address range, hostname or country code", value)
+	}
+}
+
+// ParseAs parses input and checks that it is one of the accepted kinds. With
+// no accepted kinds every kind is allowed.
+func ParseAs(input string, accepted ...Kind) (Resource, error) {
+	r, err := Parse(input)
+	if err != nil {
+		return Resource{}, err
+	}
+	if len(accepted) == 0 {
+		return r, nil
+	}
+	for _, kind := range accepted {
+		if r.Kind == kind {
+			return r, nil
+		}
+	}
+	return Resource{}, fmt.Errorf("%q is %s; expected %s", r.Value, r.Kind.Description(), describeKinds(accepted))
+}
+
+// describeKinds joins kind descriptions as "a, b or c".
+func describeKinds(kinds []Kind) string {
+	descriptions := make([]string, len(kinds))
+	for i, kind := range kinds {
+		descriptions[i] = kind.Description()
+	}
+	if len(descriptions) == 1 {
+		return descriptions[0]
+	}
+	return strings.Join(descriptions[:len(descriptions)-1], ", ") + " or " + descriptions[len(descriptions)-1]
+}
+
+// isASN reports whether value is shaped like an AS number.
+func isASN(value string) bool {
+	_, ok := asnDigits(value)
+	return ok
+}
+
+// asnDigits returns the number of "AS3333", "as3333", "AS 3333" or "3333",
+// and whether value has that shape.
+func asnDigits(value string) (string, bool) {
+	digits := value
+	if len(digits) >= 2 && strings.EqualFold(digits[:2], "AS") {
+		digits = strings.TrimLeft(digits[2:], " ")
+	}
+	return digits, isDigits(digits)
+}
+
+// parseASN parses an AS number in any of the forms accepted by asnDigits.
+func parseASN(value string) (Resource, error) {
+	digits, _ := asnDigits(value)
+	number, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil || number > maxASN {
+		return Resource{}, fmt.Errorf("AS number %s is out of range: AS numbers are at most %d", digits, uint64(maxASN))
+	}
+	if reason, ok := reservedASNs[number]; ok {
+		return Resource{}, fmt.Errorf("AS%d is %s", number, reason)
+	}
+	return Resource{Kind: KindASN, Value: "AS" + strconv.FormatUint(number, 10)}, nil
+}
+
+// parseAddress parses an IPv4 or IPv6 address. IPv4-mapped IPv6 addresses are
+// returned as IPv4.
+func parseAddress(value string) (Resource, error) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return Resource{}, fmt.Errorf("invalid IP address %q", value)
+	}
+	if addr.Zone() != "" {
+		return Resource{}, fmt.Errorf("invalid IP address %q: zones are not allowed", value)
+	}
+	addr = addr.Unmap()
+	if addr.Is4() {
+		return Resource{Kind: KindIPv4, Value: addr.String()}, nil
+	}
+	return Resource{Kind: KindIPv6, Value: addr.String()}, nil
+}
+
+// parsePrefix parses a prefix in CIDR notation and clears its host bits.
+func parsePrefix(value string) (Resource, error) {
+	addrPart, bitsPart, _ := strings.Cut(value, "/")
+	addr, err := parseAddress(strings.TrimSpace(addrPart))
+	if err != nil {
+		return Resource{}, fmt.Errorf("invalid prefix %q: %w", value, err)
+	}
+	bitsPart = strings.TrimSpace(bitsPart)
+	if bitsPart == "" || !isDigits(bitsPart) {
+		return Resource{}, fmt.Errorf("invalid prefix %q: prefix length %q is not a number", value, bitsPart)
+	}
+	maxBits := 32
+	if addr.Kind == KindIPv6 {
+		maxBits = 128
+	}
+	bits, err := strconv.Atoi(bitsPart)
+	if err != nil || bits > maxBits {
+		return Resource{}, fmt.Errorf("invalid prefix %q: prefix length %s exceeds %d for %s", value, bitsPart, maxBits, addr.Kind.Description())
+	}
+	prefix := netip.PrefixFrom(netip.MustParseAddr(addr.Value), bits).Masked()
+	return Resource{Kind: KindPrefix, Value: prefix.String()}, nil
+}
+
+// looksLikeRange reports whether value splits on its first "-" into two
+// addresses rather than being a hostname with a hyphen.
+func looksLikeRange(value string) bool {
+	first, last, _ := strings.Cut(value, "-")
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+	return looksLikeAddress(first) && looksLikeAddress(last)
+}
+
+// looksLikeAddress reports whether value is shaped like an IP address.
+func looksLikeAddress(value string) bool {
+	return strings.Contains(value, ":") || isDottedNumeric(value)
+}
+
+// parseRange parses "first-last" or "first - last" with both addresses in the
+// same family and first not after last.
+func parseRange(value string) (Resource, error) {
+	firstPart, lastPart, _ := strings.Cut(value, "-")
+	first, err := parseAddress(strings.TrimSpace(firstPart))
+	if err != nil {
+		return Resource{}, fmt.Errorf("invalid address range %q: %w", value, err)
+	}
+	last, err := parseAddress(strings.TrimSpace(lastPart))
+	if err != nil {
+		return Resource{}, fmt.Errorf("invalid address range %q: %w", value, err)
+	}
+	if first.Kind != last.Kind {
+		return Resource{}, fmt.Errorf("invalid address range %q: mixes IPv4 and IPv6 addresses", value)
+	}
+	if netip.MustParseAddr(first.Value).Compare(netip.MustParseAddr(last.Value)) > 0 {
+		return Resource{}, fmt.Errorf("invalid address range %q: %s is after %s", value, first.Value, last.Value)
+	}
+	return Resource{Kind: KindRange, Value: first.Value + "-" + last.Value}, nil
+}
+
+// parseURL parses a URL such as "https://www.ripe.net/x" and returns its host.
+func parseURL(value string) (Resource, error) {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return Resource{}, fmt.Errorf("invalid URL %q", value)
+	}
+	host := u.Hostname()
+	if strings.Contains(host, ":") || isDottedNumeric(host) {
+		return parseAddress(host)
+	}
+	return parseHostname(host)
+}
+
+// parseCountry parses an ISO 3166-1 alpha-2 country code.
+func parseCountry(value string) (Resource, error) {
+	code := strings.ToLower(value)
+	for _, country := range Countries {
+		if country.Code == code {
+			return Resource{Kind: KindCountry, Value: code}, nil
+		}
+	}
+	return Resource{}, fmt.Errorf("%q is not an ISO 3166-1 country code", value)
+}
+
+// parseHostname parses a fully qualified hostname. The trailing dot of an
+// absolute name is dropped.
+func parseHostname(value string) (Resource, error) {
+	name := strings.ToLower(strings.TrimSuffix(value, "."))
+	if len(name) > 253 {
+		return Resource{}, fmt.Errorf("invalid hostname %q: longer than 253 characters", value)
+	}
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return Resource{}, fmt.Errorf("invalid hostname %q: not fully qualified", value)
+	}
+	for _, label := range labels {
+		if err := checkLabel(label); err != nil {
+			return Resource{}, fmt.Errorf("invalid hostname %q: %w", value, err)
+		}
+	}
+	if isDigits(labels[len(labels)-1]) {
+		return Resource{}, fmt.Errorf("invalid hostname %q: top-level domain is numeric", value)
+	}
+	return Resource{Kind: KindHostname, Value: name}, nil
+}
+
+// checkLabel validates a single DNS label.
+func checkLabel(label string) error {
+	if label == "" {
+		return errors.New("empty label")
+	}
+	if len(label) > 63 {
+		return fmt.Errorf("label %q is longer than 63 characters", label)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("label %q contains %q", label, r)
+		}
+	}
+	return nil
+}
+
+// isDottedNumeric reports whether value consists of digits and dots only.
+func isDottedNumeric(value string) bool {
+	return strings.Contains(value, ".") && strings.Trim(value, "0123456789.") == ""
+}
+
+// isDigits reports whether value consists of ASCII digits only.
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// isLetters reports whether value consists of ASCII letters only.
+func isLetters(value string) bool {
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  Kind
+		value string
+	}{
+		{"AS3333", KindASN, "AS3333"},
+		{"as3333", KindASN, "AS3333"},
+		{"3333", KindASN, "AS3333"},
+		{"AS 3333", KindASN, "AS3333"},
+		{" AS03333 ", KindASN, "AS3333"},
+		{"4294967294", KindASN, "AS4294967294"},
+		{"193.0.0.1", KindIPv4, "193.0.0.1"},
+		{"2001:DB8::0:1", KindIPv6, "2001:db8::1"},
+		{"::ffff:193.0.0.1", KindIPv4, "193.0.0.1"},
+		{"193.0.0.1/21", KindPrefix, "193.0.0.0/21"},
+		{"193.0.0.0/21", KindPrefix, "193.0.0.0/21"},
+		{"2001:DB8:1::/32", KindPrefix, "2001:db8::/32"},
+		{"193.0.0.0 / 21", KindPrefix, "193.0.0.0/21"},
+		{"193.0.0.0-193.0.7.255", KindRange, "193.0.0.0-193.0.7.255"},
+		{"193.0.0.0 - 193.0.7.255", KindRange, "193.0.0.0-193.0.7.255"},
+		{"2001:db8::-2001:db8::ff", KindRange, "2001:db8::-2001:db8::ff"},
+		{"WWW.RIPE.NET.", KindHostname, "www.ripe.net"},
+		{"k-root.example", KindHostname, "k-root.example"},
+		{"https://WWW.RIPE.NET/analyse?x=1", KindHostname, "www.ripe.net"},
+		{"http://193.0.0.1:8080/", KindIPv4, "193.0.0.1"},
+		{"https://[2001:db8::1]/", KindIPv6, "2001:db8::1"},
+		{"NL", KindCountry, "nl"},
+		{"de", KindCountry, "de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			r, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if r.Kind != tt.kind || r.Value != tt.value {
+				t.Errorf("Parse(%q) = {%s %s}, want {%s %s}", tt.input, r.Kind, r.Value, tt.kind, tt.value)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"AS0", "AS0 is reserved"},
+		{"23456", "AS23456 is AS_TRANS"},
+		{"AS65535", "AS65535 is reserved"},
+		{"AS4294967295", "AS4294967295 is reserved"},
+		{"AS4294967296", "out of range"},
+		{"AS99999999999999999999", "out of range"},
+		{"193.0.0.0/33", "prefix length 33 exceeds 32"},
+		{"2001:db8::/129", "prefix length 129 exceeds 128"},
+		{"193.0.0.0/", "is not a number"},
+		{"193.0.0.0/-1", "is not a number"},
+		{"256.0.0.0/8", "invalid prefix"},
+		{"193.0.0.256", "invalid IP address"},
+		{"fe80::1%eth0", "zones are not allowed"},
+		{"193.0.7.255-193.0.0.0", "193.0.7.255 is after 193.0.0.0"},
+		{"193.0.0.0-2001:db8::", "mixes IPv4 and IPv6"},
+		{"zz", "not an ISO 3166-1 country code"},
+		{"-bad.example", "starts or ends with a hyphen"},
+		{"bad..example", "empty label"},
+		{"host.123", "top-level domain is numeric"},
+		{"ripe", "is not an AS number"},
+		{"https:///path", "invalid URL"},
+		{"https://intranet/", "not fully qualified"},
+		{"ripe net.example", "contains ' '"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want error containing %q", tt.input, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.input, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	if _, err := Parse("  "); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Parse of blank input error = %v, want ErrEmpty", err)
+	}
+}
+
+func TestParseAs(t *testing.T) {
+	r, err := ParseAs("as 3333", KindASN)
+	if err != nil {
+		t.Fatalf("ParseAs error: %v", err)
+	}
+	if r.Value != "AS3333" {
+		t.Errorf("ParseAs value = %q, want AS3333", r.Value)
+	}
+
+	if _, err := ParseAs("193.0.0.0/21"); err != nil {
+		t.Errorf("ParseAs without accepted kinds error: %v", err)
+	}
+
+	_, err = ParseAs("AS3333", KindIPv4, KindIPv6, KindPrefix)
+	if err == nil {
+		t.Fatal("ParseAs accepted an ASN for address kinds")
+	}
+	want := `"AS3333" is an AS number; expected an IPv4 address, an IPv6 address or a prefix`
+	if err.Error() != want {
+		t.Errorf("ParseAs error = %q, want %q", err, want)
+	}
+}
+
+func TestCountries_Sorted(t *testing.T) {
+	for i := 1; i < len(Countries); i++ {
+		if Countries[i-1].Code >= Countries[i].Code {
+			t.Errorf("Countries not sorted at %s, %s", Countries[i-1].Code, Countries[i].Code)
+		}
+	}
+}
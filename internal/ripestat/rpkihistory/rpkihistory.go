@@ -9,6 +9,7 @@ import (
 
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 )
 
 const (
@@ -34,8 +35,26 @@ func DefaultClient() *Client {
 	return NewClient(nil)
 }
 
+// TimeLimits are the time window limits of the rpki-history data call, which
+// serves its full history.
+var TimeLimits = timerange.Limits{}
+
+// GetOptions represents optional parameters for RPKI History.
+type GetOptions struct {
+	// Window limits the timeseries to entries within it. The data call has
+	// no time parameters, so the full series is fetched and cached and the
+	// window is applied to it.
+	Window timerange.Window
+}
+
 // Get retrieves RPKI History information for the given resource.
 func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
+	return c.GetWithOptions(ctx, resource, nil)
+}
+
+// GetWithOptions retrieves RPKI History information for the given resource
+// with optional parameters.
+func (c *Client) GetWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
 	if resource == "" {
 		return nil, errors.ErrInvalidParameter.WithError(fmt.Errorf("resource parameter is required"))
 	}
@@ -45,7 +64,21 @@ func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get RPKI history: %w", err))
+		return nil, errors.Wrap(err, "failed to get RPKI history")
+	}
+
+	if opts != nil && !opts.Window.IsZero() {
+		// The cache holds the decoded response, so the window is applied to a
+		// copy rather than to the response or its series.
+		timeseries := make([]TimeseriesEntry, 0, len(response.Data.Timeseries))
+		for _, entry := range response.Data.Timeseries {
+			if opts.Window.Contains(entry.Time) {
+				timeseries = append(timeseries, entry)
+			}
+		}
+		windowed := response
+		windowed.Data.Timeseries = timeseries
+		return &windowed, nil
 	}
 
 	return &response, nil
@@ -55,3 +88,9 @@ func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
 func GetRPKIHistory(ctx context.Context, resource string) (*Response, error) {
 	return DefaultClient().Get(ctx, resource)
 }
+
+// GetRPKIHistoryWithOptions retrieves RPKI History information with options
+// using the default client.
+func GetRPKIHistoryWithOptions(ctx context.Context, resource string, opts *GetOptions) (*Response, error) {
+	return DefaultClient().GetWithOptions(ctx, resource, opts)
+}
@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/taihen/mcp-ripestat/internal/ripestat/cache"
 	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/timerange"
 )
 
 func TestClient_Get(t *testing.T) {
@@ -122,6 +124,78 @@ func TestClient_Get(t *testing.T) {
 	}
 }
 
+func TestClient_GetWithOptions_Window(t *testing.T) {
+	var gotQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"timeseries": [
+			{"prefix": "193.0.22.0/23", "time": "2015-02-11T00:00:00Z", "count": 1},
+			{"prefix": "193.0.22.0/23", "time": "2020-06-01T00:00:00Z", "count": 2},
+			{"prefix": "193.0.22.0/23", "time": "2024-01-01T00:00:00Z", "count": 3}
+		]}, "status": "ok"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(client.New(ts.URL, ts.Client()))
+
+	window := timerange.Window{
+		Start: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	result, err := c.GetWithOptions(context.Background(), "193.0.22.0/23", &GetOptions{Window: window})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if strings.Contains(gotQuery, "time") {
+		t.Errorf("Expected the window to be applied locally, got query %q", gotQuery)
+	}
+	if len(result.Data.Timeseries) != 2 || result.Data.Timeseries[0].Count != 2 {
+		t.Errorf("Expected the entries within the window, got %+v", result.Data.Timeseries)
+	}
+}
+
+func TestClient_GetWithOptions_WindowKeepsCachedSeries(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"timeseries": [
+			{"prefix": "193.0.22.0/23", "time": "2015-02-11T00:00:00Z", "count": 1},
+			{"prefix": "193.0.22.0/23", "time": "2020-06-01T00:00:00Z", "count": 2}
+		]}, "status": "ok"}`))
+	}))
+	defer ts.Close()
+
+	ripeClient := client.New(ts.URL, ts.Client())
+	ripeClient.Cache = cache.New()
+	c := NewClient(ripeClient)
+
+	window := timerange.Window{
+		Start: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	windowed, err := c.GetWithOptions(context.Background(), "193.0.22.0/23", &GetOptions{Window: window})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(windowed.Data.Timeseries) != 1 {
+		t.Errorf("Expected 1 entry within the window, got %+v", windowed.Data.Timeseries)
+	}
+
+	full, err := c.Get(context.Background(), "193.0.22.0/23")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second call to be served from cache, got %d requests", requests)
+	}
+	if len(full.Data.Timeseries) != 2 || full.Data.Timeseries[0].Count != 1 {
+		t.Errorf("Expected the full cached series, got %+v", full.Data.Timeseries)
+	}
+}
+
 func TestClient_GetWithHTTPError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
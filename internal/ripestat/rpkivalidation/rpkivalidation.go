@@ -50,7 +50,7 @@ func (c *Client) Get(ctx context.Context, resource, prefix string) (*APIResponse
 
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get RPKI validation status: %w", err))
+		return nil, errors.Wrap(err, "failed to get RPKI validation status")
 	}
 
 	// Transform the response to the expected API format
@@ -0,0 +1,57 @@
+// Package searchcomplete provides access to the RIPEstat searchcomplete API.
+package searchcomplete
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/errors"
+)
+
+const (
+	// EndpointPath is the path to the RIPEstat data API for search completion.
+	EndpointPath = "/data/searchcomplete/data.json"
+)
+
+// Client provides methods to interact with the RIPEstat searchcomplete API.
+type Client struct {
+	client *client.Client
+}
+
+// NewClient creates a new Client for the RIPEstat searchcomplete API.
+func NewClient(c *client.Client) *Client {
+	if c == nil {
+		c = client.DefaultClient()
+	}
+
+	return &Client{client: c}
+}
+
+// DefaultClient returns a new Client with default settings.
+func DefaultClient() *Client {
+	return NewClient(nil)
+}
+
+// Get fetches resource suggestions for the specified (partial) search term.
+func (c *Client) Get(ctx context.Context, resource string) (*Response, error) {
+	if resource == "" {
+		return nil, errors.ErrInvalidParameter.WithError(fmt.Errorf("resource parameter is required"))
+	}
+
+	params := url.Values{}
+	params.Set("resource", resource)
+
+	var response Response
+	if err := c.client.GetJSON(ctx, EndpointPath, params, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to get search suggestions")
+	}
+
+	return &response, nil
+}
+
+// GetSearchComplete is a convenience function that uses the default client to get search suggestions.
+func GetSearchComplete(ctx context.Context, resource string) (*Response, error) {
+	return DefaultClient().Get(ctx, resource)
+}
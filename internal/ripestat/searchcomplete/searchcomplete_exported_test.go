@@ -0,0 +1,54 @@
+package searchcomplete_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+	"github.com/taihen/mcp-ripestat/internal/ripestat/searchcomplete"
+)
+
+func TestSearchComplete_Integration(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"data": {
+				"categories": [
+					{
+						"category": "ASNs",
+						"suggestions": [
+							{"label": "AS3333", "value": "AS3333", "description": "RIPE-NCC-AS", "link": ""}
+						]
+					}
+				]
+			},
+			"status": "ok",
+			"status_code": 200
+		}`))
+	}))
+	defer ts.Close()
+
+	c := searchcomplete.NewClient(client.New(ts.URL, ts.Client()))
+
+	resp, err := c.Get(context.Background(), "AS333")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(resp.Data.Categories) != 1 {
+		t.Fatalf("expected 1 category, got %d", len(resp.Data.Categories))
+	}
+
+	if got := resp.Data.Categories[0].Suggestions[0].Description; got != "RIPE-NCC-AS" {
+		t.Errorf("expected description RIPE-NCC-AS, got %q", got)
+	}
+}
+
+func TestDefaultClient(t *testing.T) {
+	if searchcomplete.DefaultClient() == nil {
+		t.Fatal("expected DefaultClient to return a client")
+	}
+}
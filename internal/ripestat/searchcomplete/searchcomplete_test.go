@@ -0,0 +1,145 @@
+package searchcomplete
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+)
+
+const testResponse = `{
+	"messages": [],
+	"see_also": [],
+	"version": "2.1",
+	"data_call_name": "searchcomplete",
+	"data_call_status": "supported",
+	"cached": false,
+	"data": {
+		"categories": [
+			{
+				"category": "ASNs",
+				"suggestions": [
+					{"label": "AS3333", "value": "AS3333", "description": "RIPE-NCC-AS", "link": "https://stat.ripe.net/AS3333"},
+					{"label": "AS33333", "value": "AS33333", "description": "EXAMPLE-AS", "link": "https://stat.ripe.net/AS33333"}
+				]
+			},
+			{
+				"category": "IPv4",
+				"suggestions": [
+					{"label": "193.0.0.0/21", "value": "193.0.0.0/21", "description": "RIPE-NCC", "link": "https://stat.ripe.net/193.0.0.0/21"}
+				]
+			}
+		],
+		"query_time": "2025-06-23T20:09:57"
+	},
+	"query_id": "test-query-id",
+	"process_time": 12,
+	"server_id": "app194",
+	"build_version": "main-2025.06.23",
+	"status": "ok",
+	"status_code": 200,
+	"time": "2025-06-23T20:09:57.048781"
+}`
+
+func TestClient_Get(t *testing.T) {
+	tests := []struct {
+		name       string
+		resource   string
+		statusCode int
+		body       string
+		wantValues []string
+		wantErr    bool
+	}{
+		{
+			name:       "successful response",
+			resource:   "3333",
+			statusCode: http.StatusOK,
+			body:       testResponse,
+			wantValues: []string{"AS3333", "AS33333", "193.0.0.0/21"},
+		},
+		{
+			name:       "empty categories",
+			resource:   "zzz",
+			statusCode: http.StatusOK,
+			body:       `{"data": {"categories": []}, "status": "ok"}`,
+			wantValues: nil,
+		},
+		{
+			name:     "empty resource",
+			resource: "",
+			wantErr:  true,
+		},
+		{
+			name:       "server error",
+			resource:   "3333",
+			statusCode: http.StatusInternalServerError,
+			body:       `{}`,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid JSON",
+			resource:   "3333",
+			statusCode: http.StatusOK,
+			body:       `not json`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasPrefix(r.URL.Path, "/data/searchcomplete/") {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if got := r.URL.Query().Get("resource"); got != tt.resource {
+					t.Errorf("expected resource %q, got %q", tt.resource, got)
+				}
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			c := NewClient(client.New(ts.URL, ts.Client()))
+			resp, err := c.Get(context.Background(), tt.resource)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			values := resp.Data.Values()
+			if len(values) != len(tt.wantValues) {
+				t.Fatalf("expected %d values, got %d (%v)", len(tt.wantValues), len(values), values)
+			}
+			for i, want := range tt.wantValues {
+				if values[i] != want {
+					t.Errorf("value %d: expected %q, got %q", i, want, values[i])
+				}
+			}
+		})
+	}
+}
+
+func TestData_Values_SkipsEmpty(t *testing.T) {
+	data := Data{
+		Categories: []Category{
+			{Category: "ASNs", Suggestions: []Suggestion{{Label: "no value"}, {Value: "AS1"}}},
+		},
+	}
+
+	values := data.Values()
+	if len(values) != 1 || values[0] != "AS1" {
+		t.Errorf("expected [AS1], got %v", values)
+	}
+}
+
+func TestNewClient_NilClient(t *testing.T) {
+	c := NewClient(nil)
+	if c == nil || c.client == nil {
+		t.Fatal("expected NewClient(nil) to create a default client")
+	}
+}
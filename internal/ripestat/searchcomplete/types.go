@@ -0,0 +1,45 @@
+// Package searchcomplete provides access to the RIPEstat searchcomplete API.
+package searchcomplete
+
+import (
+	"github.com/taihen/mcp-ripestat/internal/ripestat/types"
+)
+
+// Response represents the top-level response from the RIPEstat searchcomplete endpoint.
+type Response struct {
+	types.BaseResponse
+	Data Data `json:"data"`
+}
+
+// Data represents the 'data' field in the response.
+type Data struct {
+	Categories []Category `json:"categories"`
+	QueryTime  string     `json:"query_time"`
+}
+
+// Category groups suggestions of the same kind (e.g. "ASNs", "IPv4").
+type Category struct {
+	Category    string       `json:"category"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Suggestion represents a single resource suggested for the search term.
+type Suggestion struct {
+	Label       string `json:"label"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+	Link        string `json:"link"`
+}
+
+// Values returns the suggested values of all categories in response order.
+func (d *Data) Values() []string {
+	var values []string
+	for _, category := range d.Categories {
+		for _, suggestion := range category.Suggestions {
+			if suggestion.Value != "" {
+				values = append(values, suggestion.Value)
+			}
+		}
+	}
+	return values
+}
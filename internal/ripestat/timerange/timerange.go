@@ -0,0 +1,249 @@
+// Package timerange parses the time arguments of historical tools, such as
+// "2 hours ago", "yesterday", "2024-01-31" or Unix timestamps, and checks the
+// resulting windows against the limits of RIPEstat data calls.
+package timerange
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Granularity is the precision of relative expressions and of "now". Rounding
+// them down lets repeated calls share cached responses.
+const Granularity = time.Minute
+
+// layouts are the absolute formats accepted besides RFC 3339. Times without a
+// zone are UTC.
+var layouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ErrEmpty is returned for an empty time expression.
+var ErrEmpty = errors.New("time is empty")
+
+// Parse returns the UTC time of expr, relative to now where it is relative.
+// It accepts RFC 3339 timestamps, dates and date-times without a zone (read
+// as UTC), Unix timestamps in seconds, "now", "today", "yesterday", "N units
+// ago" and "-N units", with units from seconds to years.
+func Parse(expr string, now time.Time) (time.Time, error) {
+	value := strings.ToLower(strings.TrimSpace(expr))
+	if value == "" {
+		return time.Time{}, ErrEmpty
+	}
+	now = now.UTC().Truncate(Granularity)
+
+	switch value {
+	case "now":
+		return now, nil
+	case "today":
+		return startOfDay(now), nil
+	case "yesterday":
+		return startOfDay(now).AddDate(0, 0, -1), nil
+	}
+
+	if rest, ok := strings.CutSuffix(value, " ago"); ok {
+		return parseRelative(expr, strings.TrimSpace(rest), now)
+	}
+	if rest, ok := strings.CutPrefix(value, "-"); ok {
+		return parseRelative(expr, strings.TrimSpace(rest), now)
+	}
+
+	if isDigits(value) {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seconds > maxUnix {
+			return time.Time{}, fmt.Errorf("timestamp %s is out of range", value)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	absolute := strings.ToUpper(value)
+	if t, err := time.Parse(time.RFC3339Nano, absolute); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, absolute); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time %q: use RFC 3339 (2024-01-31T12:00:00Z), a date (2024-01-31), a Unix timestamp, \"now\", \"today\", \"yesterday\" or a relative time such as \"2 hours ago\"", expr)
+}
+
+// maxUnix is the Unix time of 9999-12-31T23:59:59Z.
+const maxUnix = 253402300799
+
+// units maps relative time units to their length. Months and years are
+// calendar units and handled separately.
+var units = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// parseRelative parses "N unit" and returns now minus that amount.
+func parseRelative(expr, value string, now time.Time) (time.Time, error) {
+	i := 0
+	for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+		i++
+	}
+	count, err := strconv.Atoi(value[:i])
+	unit := strings.TrimSpace(value[i:])
+	if err != nil || count > 100000 {
+		return time.Time{}, fmt.Errorf("invalid relative time %q: expected a count and a unit, such as \"2 hours ago\"", expr)
+	}
+
+	switch unit {
+	case "month", "months", "mo":
+		return now.AddDate(0, -count, 0), nil
+	case "year", "years", "y":
+		return now.AddDate(-count, 0, 0), nil
+	}
+	d, ok := units[unit]
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid relative time %q: unknown unit %q", expr, unit)
+	}
+	return now.Add(-time.Duration(count) * d).Truncate(Granularity), nil
+}
+
+// startOfDay returns midnight UTC of the day of t.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// isDigits reports whether value consists of ASCII digits only.
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// Limits are the time window constraints of a RIPEstat data call.
+type Limits struct {
+	// MaxSpan is the longest window the data call serves; zero is unlimited.
+	MaxSpan time.Duration
+	// DefaultSpan is the window length used when only the end is given;
+	// zero leaves the start open.
+	DefaultSpan time.Duration
+}
+
+// Window is the time range of a historical query. A zero Start leaves the
+// start to RIPEstat.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsZero reports whether no window was requested.
+func (w Window) IsZero() bool {
+	return w.Start.IsZero() && w.End.IsZero()
+}
+
+// String formats the window as "start to end" in RFC 3339.
+func (w Window) String() string {
+	start := "(data call default)"
+	if !w.Start.IsZero() {
+		start = w.Start.Format(time.RFC3339)
+	}
+	return start + " to " + w.End.Format(time.RFC3339)
+}
+
+// ParamLayout is the time format sent to RIPEstat, in UTC.
+const ParamLayout = "2006-01-02T15:04:05"
+
+// FormatParam formats t for a RIPEstat starttime or endtime parameter.
+func FormatParam(t time.Time) string {
+	return t.UTC().Format(ParamLayout)
+}
+
+// SetParams sets the starttime and endtime parameters of a data call to the
+// bounds of the window that are set.
+func (w Window) SetParams(params url.Values) {
+	if !w.Start.IsZero() {
+		params.Set("starttime", FormatParam(w.Start))
+	}
+	if !w.End.IsZero() {
+		params.Set("endtime", FormatParam(w.End))
+	}
+}
+
+// Contains reports whether t lies within the window. An open start admits
+// every time up to the end.
+func (w Window) Contains(t time.Time) bool {
+	if !w.Start.IsZero() && t.Before(w.Start) {
+		return false
+	}
+	return w.End.IsZero() || !t.After(w.End)
+}
+
+// Resolve builds the window of the start and end expressions. Without either
+// it returns the zero window, leaving both to RIPEstat. The end defaults to
+// now and is capped at now; the start defaults to DefaultSpan before the end.
+// Windows that are reversed, start in the future or exceed MaxSpan are
+// rejected.
+func Resolve(startExpr, endExpr string, now time.Time, limits Limits) (Window, error) {
+	if strings.TrimSpace(startExpr) == "" && strings.TrimSpace(endExpr) == "" {
+		return Window{}, nil
+	}
+	now = now.UTC().Truncate(Granularity)
+
+	window := Window{End: now}
+	if strings.TrimSpace(endExpr) != "" {
+		end, err := Parse(endExpr, now)
+		if err != nil {
+			return Window{}, fmt.Errorf("end_time: %w", err)
+		}
+		if end.Before(now) {
+			window.End = end
+		}
+	}
+
+	if strings.TrimSpace(startExpr) != "" {
+		start, err := Parse(startExpr, now)
+		if err != nil {
+			return Window{}, fmt.Errorf("start_time: %w", err)
+		}
+		if start.After(now) {
+			return Window{}, fmt.Errorf("start_time: %s is in the future", start.Format(time.RFC3339))
+		}
+		window.Start = start
+	} else if limits.DefaultSpan > 0 {
+		window.Start = window.End.Add(-limits.DefaultSpan)
+	}
+
+	if !window.Start.IsZero() && window.Start.After(window.End) {
+		return Window{}, fmt.Errorf("start_time %s is after end_time %s", window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339))
+	}
+	if limits.MaxSpan > 0 {
+		if window.Start.IsZero() {
+			window.Start = window.End.Add(-limits.MaxSpan)
+		}
+		if span := window.End.Sub(window.Start); span > limits.MaxSpan {
+			return Window{}, fmt.Errorf("window of %s exceeds the maximum of %s", FormatSpan(span), FormatSpan(limits.MaxSpan))
+		}
+	}
+	return window, nil
+}
+
+// FormatSpan formats d in days when it is a whole number of days, for
+// example "7d", and as a duration otherwise.
+func FormatSpan(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return strconv.Itoa(int(d/day)) + "d"
+	}
+	return d.String()
+}
@@ -0,0 +1,189 @@
+package timerange
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// now is the reference time of the tests, a Wednesday.
+var now = time.Date(2024, 3, 13, 15, 42, 17, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"now", "2024-03-13T15:42:00Z"},
+		{" Today ", "2024-03-13T00:00:00Z"},
+		{"yesterday", "2024-03-12T00:00:00Z"},
+		{"2 hours ago", "2024-03-13T13:42:00Z"},
+		{"2h ago", "2024-03-13T13:42:00Z"},
+		{"90 minutes ago", "2024-03-13T14:12:00Z"},
+		{"1 day ago", "2024-03-12T15:42:00Z"},
+		{"-7d", "2024-03-06T15:42:00Z"},
+		{"-1w", "2024-03-06T15:42:00Z"},
+		{"3 months ago", "2023-12-13T15:42:00Z"},
+		{"1 year ago", "2023-03-13T15:42:00Z"},
+		{"2024-01-31T12:00:00Z", "2024-01-31T12:00:00Z"},
+		{"2024-01-31T14:00:00+02:00", "2024-01-31T12:00:00Z"},
+		{"2024-01-31t12:00:00.5z", "2024-01-31T12:00:00.5Z"},
+		{"2024-01-31T12:00:00", "2024-01-31T12:00:00Z"},
+		{"2024-01-31 12:00", "2024-01-31T12:00:00Z"},
+		{"2024-01-31", "2024-01-31T00:00:00Z"},
+		{"1706702400", "2024-01-31T12:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr, now)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("Parse(%q) location = %v, want UTC", tt.expr, got.Location())
+			}
+			if got.Format(time.RFC3339Nano) != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.expr, got.Format(time.RFC3339Nano), tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"last tuesday", "unrecognized time"},
+		{"2024-13-01", "unrecognized time"},
+		{"two hours ago", "expected a count and a unit"},
+		{"2 fortnights ago", `unknown unit "fortnights"`},
+		{"99999999999999", "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr, now)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) error = %v, want it to contain %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse(" ", now); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Parse of blank input error = %v, want ErrEmpty", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	tests := []struct {
+		name   string
+		start  string
+		end    string
+		limits Limits
+		want   string
+	}{
+		{name: "both", start: "2024-03-01", end: "2024-03-02", want: "2024-03-01T00:00:00Z to 2024-03-02T00:00:00Z"},
+		{name: "end defaults to now", start: "2 hours ago", want: "2024-03-13T13:42:00Z to 2024-03-13T15:42:00Z"},
+		{name: "end capped at now", start: "yesterday", end: "2030-01-01", want: "2024-03-12T00:00:00Z to 2024-03-13T15:42:00Z"},
+		{name: "open start", end: "2024-03-01", want: "(data call default) to 2024-03-01T00:00:00Z"},
+		{name: "default span", end: "2024-03-01", limits: Limits{DefaultSpan: 8 * time.Hour}, want: "2024-02-29T16:00:00Z to 2024-03-01T00:00:00Z"},
+		{name: "start bounded by max span", end: "2024-03-01", limits: Limits{MaxSpan: week}, want: "2024-02-23T00:00:00Z to 2024-03-01T00:00:00Z"},
+		{name: "within max span", start: "-7d", limits: Limits{MaxSpan: week}, want: "2024-03-06T15:42:00Z to 2024-03-13T15:42:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := Resolve(tt.start, tt.end, now, tt.limits)
+			if err != nil {
+				t.Fatalf("Resolve error: %v", err)
+			}
+			if window.String() != tt.want {
+				t.Errorf("Resolve = %s, want %s", window, tt.want)
+			}
+		})
+	}
+
+	window, err := Resolve("", " ", now, Limits{})
+	if err != nil || !window.IsZero() {
+		t.Errorf("Resolve without times = %v, %v; want the zero window", window, err)
+	}
+}
+
+func TestResolve_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  string
+		end    string
+		limits Limits
+		want   string
+	}{
+		{name: "reversed", start: "2024-03-02", end: "2024-03-01", want: "start_time 2024-03-02T00:00:00Z is after end_time 2024-03-01T00:00:00Z"},
+		{name: "future start", start: "2030-01-01", want: "start_time: 2030-01-01T00:00:00Z is in the future"},
+		{name: "too long", start: "30 days ago", limits: Limits{MaxSpan: 7 * 24 * time.Hour}, want: "window of 30d exceeds the maximum of 7d"},
+		{name: "bad start", start: "soon", want: "start_time: unrecognized time"},
+		{name: "bad end", end: "later", want: "end_time: unrecognized time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Resolve(tt.start, tt.end, now, tt.limits)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Resolve error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow_SetParams(t *testing.T) {
+	params := url.Values{}
+	Window{End: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}.SetParams(params)
+	if params.Encode() != "endtime=2024-03-01T12%3A00%3A00" {
+		t.Errorf("SetParams with open start = %q", params.Encode())
+	}
+
+	params = url.Values{}
+	Window{Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600)), End: now}.SetParams(params)
+	if params.Get("starttime") != "2024-02-29T23:00:00" || params.Get("endtime") != "2024-03-13T15:42:17" {
+		t.Errorf("SetParams = %q", params.Encode())
+	}
+
+	params = url.Values{}
+	Window{}.SetParams(params)
+	if len(params) != 0 {
+		t.Errorf("SetParams of the zero window = %q", params.Encode())
+	}
+}
+
+func TestWindow_Contains(t *testing.T) {
+	window := Window{Start: now.Add(-time.Hour), End: now}
+	for at, want := range map[time.Time]bool{
+		now.Add(-2 * time.Hour): false,
+		now.Add(-time.Hour):     true,
+		now:                     true,
+		now.Add(time.Second):    false,
+	} {
+		if got := window.Contains(at); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", at, got, want)
+		}
+	}
+	if !(Window{End: now}).Contains(time.Unix(0, 0)) {
+		t.Error("Expected an open start to contain early times")
+	}
+}
+
+func TestFormatSpan(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		7 * 24 * time.Hour: "7d",
+		36 * time.Hour:     "36h0m0s",
+		90 * time.Minute:   "1h30m0s",
+	} {
+		if got := FormatSpan(d); got != want {
+			t.Errorf("FormatSpan(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
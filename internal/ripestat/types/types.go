@@ -23,6 +23,22 @@ type BaseResponse struct {
 	Time           string        `json:"time"`
 }
 
+// UpstreamQueryID returns the RIPEstat query ID, which identifies the request
+// in RIPEstat's own logs.
+func (r *BaseResponse) UpstreamQueryID() string {
+	return r.QueryID
+}
+
+// UpstreamServerID returns the RIPEstat server that answered the request.
+func (r *BaseResponse) UpstreamServerID() string {
+	return r.ServerID
+}
+
+// UpstreamProcessTime returns the time RIPEstat spent on the request.
+func (r *BaseResponse) UpstreamProcessTime() time.Duration {
+	return time.Duration(r.ProcessTime) * time.Millisecond
+}
+
 // CustomTime is a wrapper around time.Time that handles time strings without timezone.
 type CustomTime struct {
 	time.Time
@@ -3,7 +3,6 @@ package whatsmyip
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"net/http"
 	"strings"
@@ -40,7 +39,7 @@ func DefaultClient() *Client {
 func (c *Client) Get(ctx context.Context) (*APIResponse, error) {
 	var response Response
 	if err := c.client.GetJSON(ctx, EndpointPath, nil, &response); err != nil {
-		return nil, errors.ErrServerError.WithError(fmt.Errorf("failed to get IP address: %w", err))
+		return nil, errors.Wrap(err, "failed to get IP address")
 	}
 
 	// Transform the response to the expected API format
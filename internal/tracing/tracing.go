@@ -0,0 +1,396 @@
+// Package tracing records OpenTelemetry-compatible spans for MCP requests and
+// the RIPEstat calls they make, and propagates W3C trace context.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Span kinds, as defined by OpenTelemetry.
+const (
+	KindInternal = "internal"
+	KindServer   = "server"
+	KindClient   = "client"
+)
+
+// TraceparentHeader is the W3C trace context header.
+const TraceparentHeader = "traceparent"
+
+// Export batching.
+const (
+	queueSize     = 2048
+	maxBatchSize  = 512
+	flushInterval = 5 * time.Second
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// String returns the ID as lowercase hex.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the ID as lowercase hex.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats sc as a version 00 traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := 0
+	if sc.Sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses a W3C traceparent header value. Unknown future
+// versions are accepted as long as they start with the version 00 fields.
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("invalid traceparent %q", value)
+	}
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, fmt.Errorf("invalid traceparent version in %q", value)
+	}
+
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || len(version) != 1 {
+		return sc, fmt.Errorf("invalid traceparent version in %q", value)
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, fmt.Errorf("invalid trace ID in %q", value)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, fmt.Errorf("invalid parent ID in %q", value)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, fmt.Errorf("invalid trace flags in %q", value)
+	}
+	if strings.ToLower(value) != value {
+		return sc, fmt.Errorf("traceparent must be lowercase: %q", value)
+	}
+	if !sc.IsValid() {
+		return sc, errors.New("traceparent has an all-zero trace or parent ID")
+	}
+
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+// Attribute is a span attribute. Values are strings, int64, float64 or bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Float64 returns a floating point attribute.
+func Float64(key string, value float64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData is a finished span as handed to exporters.
+type SpanData struct {
+	Name          string
+	Kind          string
+	TraceID       TraceID
+	SpanID        SpanID
+	ParentSpanID  SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]interface{}
+	Error         bool
+	StatusMessage string
+}
+
+// Span is an operation being timed. A nil *Span is valid and records
+// nothing, so callers need not check whether tracing is enabled.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span's trace and span IDs.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttributes adds attributes to the span, replacing existing keys. It
+// has no effect once the span has ended.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+// SetError marks the span as failed with the given message.
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.data.Error = true
+	s.data.StatusMessage = message
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.enqueue(data)
+}
+
+// Exporter sends finished spans to a backend.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer creates spans and exports them in batches from a background
+// goroutine. Spans are dropped rather than blocking callers when the export
+// queue is full.
+type Tracer struct {
+	exporter Exporter
+	queue    chan SpanData
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	dropped  atomic.Int64
+}
+
+// NewTracer returns a tracer exporting to exporter. Call Shutdown to flush
+// and stop it.
+func NewTracer(exporter Exporter) *Tracer {
+	t := &Tracer{
+		exporter: exporter,
+		queue:    make(chan SpanData, queueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Start begins a span that is a child of the span or remote parent in ctx,
+// or the root of a new trace. The returned context carries the new span.
+// Spans whose remote parent is not sampled are not recorded.
+func (t *Tracer) Start(ctx context.Context, name, kind string) (context.Context, *Span) {
+	var sc SpanContext
+	var parent SpanID
+
+	if p := SpanFromContext(ctx); p != nil {
+		sc.TraceID = p.sc.TraceID
+		parent = p.sc.SpanID
+	} else if remote, ok := ctx.Value(remoteKey).(SpanContext); ok {
+		if !remote.Sampled {
+			return ctx, nil
+		}
+		sc.TraceID = remote.TraceID
+		parent = remote.SpanID
+	} else {
+		_, _ = rand.Read(sc.TraceID[:])
+	}
+	_, _ = rand.Read(sc.SpanID[:])
+	sc.Sampled = true
+
+	span := &Span{
+		tracer: t,
+		sc:     sc,
+		data: SpanData{
+			Name:         name,
+			Kind:         kind,
+			TraceID:      sc.TraceID,
+			SpanID:       sc.SpanID,
+			ParentSpanID: parent,
+			Start:        time.Now(),
+			Attributes:   make(map[string]interface{}),
+		},
+	}
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	select {
+	case t.queue <- data:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// run exports queued spans when a batch fills up, every flushInterval and
+// on shutdown.
+func (t *Tracer) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []SpanData
+	flush := func() {
+		if dropped := t.dropped.Swap(0); dropped > 0 {
+			slog.Warn("dropped spans, export queue full", "count", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), flushInterval)
+		defer cancel()
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			slog.Warn("failed to export spans", "count", len(batch), "err", err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.stop:
+			for {
+				select {
+				case data := <-t.queue:
+					batch = append(batch, data)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// Shutdown exports the queued spans and shuts the exporter down. Spans ended
+// afterwards are discarded.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.once.Do(func() { close(t.stop) })
+
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+// global is the tracer used by Start.
+var global atomic.Pointer[Tracer]
+
+// SetTracer replaces the tracer used by Start and returns the previous one,
+// which the caller should shut down. A nil tracer disables tracing.
+func SetTracer(t *Tracer) *Tracer {
+	return global.Swap(t)
+}
+
+// Start begins a span with the tracer set by SetTracer. It returns ctx and a
+// nil span when tracing is disabled.
+func Start(ctx context.Context, name, kind string) (context.Context, *Span) {
+	t := global.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	return t.Start(ctx, name, kind)
+}
+
+// contextKey is a custom type for context keys to avoid collisions.
+type contextKey string
+
+const (
+	spanKey   contextKey = "span"
+	remoteKey contextKey = "remote_span_context"
+)
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// ContextWithRemoteParent returns a context whose next span continues the
+// trace described by sc, typically parsed from an incoming traceparent.
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey, sc)
+}
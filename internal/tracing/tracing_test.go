@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingExporter keeps exported spans in memory.
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("ParseTraceparent failed: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Errorf("Unexpected span context %+v", sc)
+	}
+	if got := sc.Traceparent(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Traceparent() = %s", got)
+	}
+
+	if sc, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil || sc.Sampled {
+		t.Errorf("Expected a future version to parse as unsampled, got %+v, %v", sc, err)
+	}
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceparent(value); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
+
+func TestTracer_Start(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemoteParent(context.Background(), remote)
+
+	ctx, root := tracer.Start(ctx, "tools/call", KindServer)
+	_, child := tracer.Start(ctx, "ripestat whois", KindClient)
+	child.SetAttributes(String("ripestat.cache", "miss"), Int("http.response.status_code", 200))
+	child.SetError("boom")
+	child.End()
+	child.SetAttributes(String("late", "ignored"))
+	root.End()
+	root.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(exporter.spans))
+	}
+	gotChild, gotRoot := exporter.spans[0], exporter.spans[1]
+	if gotRoot.TraceID != remote.TraceID || gotRoot.ParentSpanID != remote.SpanID {
+		t.Errorf("Expected root to continue the remote trace, got %+v", gotRoot)
+	}
+	if gotChild.TraceID != remote.TraceID || gotChild.ParentSpanID != gotRoot.SpanID {
+		t.Errorf("Expected child of root, got %+v", gotChild)
+	}
+	if gotChild.Attributes["ripestat.cache"] != "miss" || gotChild.Attributes["http.response.status_code"] != int64(200) {
+		t.Errorf("Unexpected attributes %v", gotChild.Attributes)
+	}
+	if _, ok := gotChild.Attributes["late"]; ok {
+		t.Error("Expected attributes set after End to be ignored")
+	}
+	if !gotChild.Error || gotChild.StatusMessage != "boom" {
+		t.Errorf("Expected error status, got %+v", gotChild)
+	}
+}
+
+func TestTracer_UnsampledParent(t *testing.T) {
+	tracer := NewTracer(&recordingExporter{})
+	defer func() { _ = tracer.Shutdown(context.Background()) }()
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx := ContextWithRemoteParent(context.Background(), remote)
+	if _, span := tracer.Start(ctx, "tools/call", KindServer); span != nil {
+		t.Error("Expected no span for an unsampled parent")
+	}
+}
+
+func TestStart_Disabled(t *testing.T) {
+	previous := SetTracer(nil)
+	defer SetTracer(previous)
+
+	ctx, span := Start(context.Background(), "tools/call", KindServer)
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Error("Expected no span when tracing is disabled")
+	}
+
+	// Methods on a nil span are no-ops.
+	span.SetAttributes(String("key", "value"))
+	span.SetError("ignored")
+	span.End()
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer(NewWriterExporter(&buf))
+
+	_, span := tracer.Start(context.Background(), "ping", KindServer)
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected one JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["name"] != "ping" || line["kind"] != KindServer || len(line["trace_id"].(string)) != 32 {
+		t.Errorf("Unexpected span line %v", line)
+	}
+	if _, ok := line["parent_span_id"]; ok {
+		t.Errorf("Expected no parent for a root span, got %v", line)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body map[string]interface{}
+	var header string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Token")
+		if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL, "mcp-ripestat", map[string]string{"X-Token": "secret"})
+	tracer := NewTracer(exporter)
+	_, span := tracer.Start(context.Background(), "ripestat whois", KindClient)
+	span.SetAttributes(Float64("ripestat.limiter_wait_ms", 1.5), Bool("cached", false))
+	span.SetError("upstream failed")
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if header != "secret" {
+		t.Errorf("Expected configured header, got %q", header)
+	}
+
+	encoded, _ := json.Marshal(body)
+	for _, want := range []string{
+		`"service.name","value":{"stringValue":"mcp-ripestat"}`,
+		`"name":"ripestat whois"`,
+		`"kind":3`,
+		`"key":"ripestat.limiter_wait_ms","value":{"doubleValue":1.5}`,
+		`"key":"cached","value":{"boolValue":false}`,
+		`"status":{"code":2,"message":"upstream failed"}`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("Expected %s in %s", want, encoded)
+		}
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	err := NewOTLPExporter(failing.URL, "mcp-ripestat", nil).Export(context.Background(), []SpanData{{Name: "x"}})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected collector error, got %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+// Package warming keeps the cached responses of a watchlist of tool calls
+// fresh by repeating the calls shortly before the responses expire.
+package warming
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+)
+
+// minInterval bounds how often a target is refreshed.
+var minInterval = time.Second
+
+const (
+	// retryDelay is the wait before retrying a failed refresh; it doubles
+	// with every further failure up to maxRetryDelay.
+	retryDelay    = 30 * time.Second
+	maxRetryDelay = 15 * time.Minute
+
+	// idleWait is how long the worker sleeps without targets.
+	idleWait = time.Hour
+)
+
+// Target is a tool call kept warm, written as "tool?argument=value&...".
+type Target struct {
+	Tool      string
+	Arguments map[string]string
+}
+
+// ParseTarget parses a watchlist entry such as
+// "getRPKIValidation?resource=AS3333&prefix=193.0.0.0/21".
+func ParseTarget(entry string) (Target, error) {
+	tool, query, _ := strings.Cut(strings.TrimSpace(entry), "?")
+	if tool == "" {
+		return Target{}, fmt.Errorf("missing tool name in %q", entry)
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return Target{}, fmt.Errorf("invalid arguments in %q: %w", entry, err)
+	}
+
+	target := Target{Tool: tool, Arguments: make(map[string]string, len(values))}
+	for name, list := range values {
+		if name == "" || len(list) != 1 {
+			return Target{}, fmt.Errorf("argument %q must be given once in %q", name, entry)
+		}
+		target.Arguments[name] = list[0]
+	}
+	return target, nil
+}
+
+// String returns the target in watchlist form, with sorted arguments.
+func (t Target) String() string {
+	values := url.Values{}
+	for name, value := range t.Arguments {
+		values.Set(name, value)
+	}
+	if len(values) == 0 {
+		return t.Tool
+	}
+	return t.Tool + "?" + values.Encode()
+}
+
+// Caller runs a tool call with fresh RIPEstat data and returns the upstream
+// calls it made.
+type Caller func(ctx context.Context, tool string, args map[string]interface{}) ([]client.UpstreamCall, error)
+
+// Options control when and how many targets are refreshed.
+type Options struct {
+	// RefreshBefore is how long before the earliest expiry of a target's
+	// cached responses it is refreshed.
+	RefreshBefore time.Duration
+
+	// MaxConcurrent is the number of targets refreshed at the same time, and
+	// so the share of the upstream concurrency limit warming may use.
+	MaxConcurrent int
+}
+
+// Status describes a target for operators.
+type Status struct {
+	Target      string     `json:"target"`
+	LastRefresh *time.Time `json:"last_refresh,omitempty"`
+	NextRefresh time.Time  `json:"next_refresh"`
+	Failures    int        `json:"failures,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// target is the schedule of one watchlist entry.
+type target struct {
+	Target
+	next      time.Time
+	refreshed time.Time
+	failures  int
+	err       string
+}
+
+// Worker refreshes the configured targets in the background.
+type Worker struct {
+	ttl func(endpoint string) time.Duration
+
+	mu      sync.Mutex
+	opts    Options
+	targets map[string]*target
+	changed chan struct{}
+}
+
+// New creates a Worker without targets. ttl returns the cache TTL of a
+// RIPEstat data call.
+func New(ttl func(endpoint string) time.Duration) *Worker {
+	return &Worker{
+		ttl:     ttl,
+		targets: make(map[string]*target),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Configure replaces the targets and options. Targets kept from the previous
+// configuration keep their schedule; new targets are refreshed right away.
+func (w *Worker) Configure(targets []Target, opts Options) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next := make(map[string]*target, len(targets))
+	for _, t := range targets {
+		key := t.String()
+		if existing, ok := w.targets[key]; ok {
+			next[key] = existing
+			continue
+		}
+		next[key] = &target{Target: t}
+	}
+	w.targets = next
+	w.opts = opts
+
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Status returns the schedule of every target, ordered by target.
+func (w *Worker) Status() []Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	statuses := make([]Status, 0, len(w.targets))
+	for key, t := range w.targets {
+		status := Status{Target: key, NextRefresh: t.next, Failures: t.failures, Error: t.err}
+		if !t.refreshed.IsZero() {
+			refreshed := t.refreshed
+			status.LastRefresh = &refreshed
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Target < statuses[j].Target })
+	return statuses
+}
+
+// Run refreshes due targets with call until ctx is done.
+func (w *Worker) Run(ctx context.Context, call Caller) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.changed:
+		case <-timer.C:
+		}
+
+		w.refreshDue(ctx, call)
+		timer.Reset(w.untilNext(time.Now()))
+	}
+}
+
+// refreshDue refreshes the targets that are due, at most MaxConcurrent at a
+// time, and returns when all of them are done.
+func (w *Worker) refreshDue(ctx context.Context, call Caller) {
+	w.mu.Lock()
+	now := time.Now()
+	var due []Target
+	for _, t := range w.targets {
+		if !t.next.After(now) {
+			due = append(due, t.Target)
+		}
+	}
+	opts := w.opts
+	w.mu.Unlock()
+
+	slots := make(chan struct{}, max(opts.MaxConcurrent, 1))
+	var wg sync.WaitGroup
+	for _, t := range due {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			w.refresh(ctx, call, t, opts.RefreshBefore)
+		}()
+	}
+	wg.Wait()
+}
+
+// refresh runs one target and schedules its next refresh.
+func (w *Worker) refresh(ctx context.Context, call Caller, t Target, refreshBefore time.Duration) {
+	args := make(map[string]interface{}, len(t.Arguments))
+	for name, value := range t.Arguments {
+		args[name] = value
+	}
+
+	key := t.String()
+	calls, err := call(ctx, t.Tool, args)
+	if ctx.Err() != nil {
+		// Shutting down; the outcome says nothing about the target.
+		return
+	}
+	interval, ok := w.interval(calls, refreshBefore)
+	if err == nil && !ok {
+		err = fmt.Errorf("no RIPEstat response was cached")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// The target may have been removed by Configure in the meantime.
+	state, exists := w.targets[key]
+	if !exists {
+		return
+	}
+
+	now := time.Now()
+	if err != nil {
+		state.failures++
+		state.err = err.Error()
+		state.next = now.Add(backoff(state.failures))
+		slog.WarnContext(ctx, "cache warming failed", "target", key, "failures", state.failures, "err", err)
+		return
+	}
+
+	state.failures = 0
+	state.err = ""
+	state.refreshed = now
+	state.next = now.Add(interval)
+	slog.DebugContext(ctx, "cache warmed", "target", key, "next_refresh", state.next)
+}
+
+// interval returns how long until a target whose last run made calls should
+// be refreshed: refreshBefore ahead of the earliest expiry among its cached
+// responses, or halfway through TTLs shorter than refreshBefore.
+func (w *Worker) interval(calls []client.UpstreamCall, refreshBefore time.Duration) (time.Duration, bool) {
+	var ttl time.Duration
+	for _, call := range calls {
+		if call.Failed {
+			continue
+		}
+		if d := w.ttl(call.Endpoint); ttl == 0 || d < ttl {
+			ttl = d
+		}
+	}
+	if ttl <= 0 {
+		return 0, false
+	}
+
+	interval := ttl - refreshBefore
+	if ttl <= refreshBefore {
+		interval = ttl / 2
+	}
+	return max(interval, minInterval), true
+}
+
+// untilNext returns how long until the next target is due.
+func (w *Worker) untilNext(now time.Time) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wait := idleWait
+	for _, t := range w.targets {
+		if d := t.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	return max(wait, 0)
+}
+
+// backoff returns the wait before retrying a target after failures.
+func backoff(failures int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
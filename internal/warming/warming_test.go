@@ -0,0 +1,152 @@
+package warming
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/taihen/mcp-ripestat/internal/ripestat/client"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		entry   string
+		want    string
+		wantErr bool
+	}{
+		{entry: "getASOverview?resource=AS3333", want: "getASOverview?resource=AS3333"},
+		{entry: " getRPKIValidation?resource=AS3333&prefix=193.0.0.0/21 ", want: "getRPKIValidation?prefix=193.0.0.0%2F21&resource=AS3333"},
+		{entry: "getWhatsMyIP", want: "getWhatsMyIP"},
+		{entry: "?resource=AS3333", wantErr: true},
+		{entry: "getASOverview?resource=AS3333&resource=AS1", wantErr: true},
+		{entry: "getASOverview?resource=%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			target, err := ParseTarget(tt.entry)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTarget failed: %v", err)
+			}
+			if target.String() != tt.want {
+				t.Errorf("String() = %q, want %q", target.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWorker_Interval(t *testing.T) {
+	ttls := map[string]time.Duration{"as-overview": time.Hour, "bgplay": 2 * time.Minute, "looking-glass": 30 * time.Second}
+	w := New(func(endpoint string) time.Duration { return ttls[endpoint] })
+
+	tests := []struct {
+		name  string
+		calls []client.UpstreamCall
+		want  time.Duration
+		ok    bool
+	}{
+		{name: "before expiry", calls: []client.UpstreamCall{{Endpoint: "as-overview"}}, want: 59 * time.Minute, ok: true},
+		{name: "earliest expiry", calls: []client.UpstreamCall{{Endpoint: "as-overview"}, {Endpoint: "bgplay"}}, want: time.Minute, ok: true},
+		{name: "short TTL", calls: []client.UpstreamCall{{Endpoint: "looking-glass"}}, want: 15 * time.Second, ok: true},
+		{name: "failed calls ignored", calls: []client.UpstreamCall{{Endpoint: "as-overview"}, {Endpoint: "bgplay", Failed: true}}, want: 59 * time.Minute, ok: true},
+		{name: "nothing cached", calls: []client.UpstreamCall{{Endpoint: "bgplay", Failed: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := w.interval(tt.calls, time.Minute)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("interval() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if backoff(1) != retryDelay || backoff(2) != 2*retryDelay {
+		t.Errorf("Expected doubling delays, got %v and %v", backoff(1), backoff(2))
+	}
+	if backoff(100) != maxRetryDelay {
+		t.Errorf("Expected delays capped at %v, got %v", maxRetryDelay, backoff(100))
+	}
+}
+
+func TestWorker_Run(t *testing.T) {
+	defer func(previous time.Duration) { minInterval = previous }(minInterval)
+	minInterval = time.Millisecond
+
+	w := New(func(string) time.Duration { return 40 * time.Millisecond })
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var running, peak atomic.Int32
+	call := func(_ context.Context, tool string, args map[string]interface{}) ([]client.UpstreamCall, error) {
+		if n := running.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer running.Add(-1)
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		counts[tool+" "+args["resource"].(string)]++
+		mu.Unlock()
+
+		if tool == "getBroken" {
+			return nil, errors.New("upstream down")
+		}
+		return []client.UpstreamCall{{Endpoint: "as-overview"}}, nil
+	}
+
+	targets := []Target{
+		{Tool: "getASOverview", Arguments: map[string]string{"resource": "AS3333"}},
+		{Tool: "getAnnouncedPrefixes", Arguments: map[string]string{"resource": "AS3333"}},
+		{Tool: "getBroken", Arguments: map[string]string{"resource": "AS3333"}},
+	}
+	w.Configure(targets, Options{RefreshBefore: 20 * time.Millisecond, MaxConcurrent: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx, call)
+		close(done)
+	}()
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if counts["getASOverview AS3333"] < 3 {
+		t.Errorf("Expected repeated refreshes ahead of expiry, got %v", counts)
+	}
+	if counts["getBroken AS3333"] != 1 {
+		t.Errorf("Expected a failed target to back off, got %v", counts)
+	}
+	if peak.Load() != 1 {
+		t.Errorf("Expected at most 1 concurrent refresh, got %d", peak.Load())
+	}
+
+	statuses := w.Status()
+	if len(statuses) != 3 || statuses[0].Target != "getASOverview?resource=AS3333" || statuses[0].LastRefresh == nil {
+		t.Fatalf("Unexpected status %+v", statuses)
+	}
+	if broken := statuses[2]; broken.Failures != 1 || broken.Error != "upstream down" || broken.LastRefresh != nil {
+		t.Errorf("Unexpected status of the failing target %+v", broken)
+	}
+
+	// Reconfiguring keeps the schedule of remaining targets and drops the rest.
+	next := statuses[0].NextRefresh
+	w.Configure(targets[:1], Options{RefreshBefore: time.Minute, MaxConcurrent: 1})
+	if statuses := w.Status(); len(statuses) != 1 || !statuses[0].NextRefresh.Equal(next) {
+		t.Errorf("Unexpected status after reconfiguring %+v", statuses)
+	}
+}